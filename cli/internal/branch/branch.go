@@ -20,6 +20,7 @@ type BranchListItem struct {
 type BranchService struct {
 	refService    *core.RefService
 	objectService *core.ObjectService
+	worktrees     *core.WorktreeRegistry
 	workspace     *domain.Workspace
 }
 
@@ -27,11 +28,13 @@ type BranchService struct {
 func NewBranchService(
 	refService *core.RefService,
 	objectService *core.ObjectService,
+	worktrees *core.WorktreeRegistry,
 	workspace *domain.Workspace,
 ) *BranchService {
 	return &BranchService{
 		refService:    refService,
 		objectService: objectService,
+		worktrees:     worktrees,
 		workspace:     workspace,
 	}
 }
@@ -57,7 +60,7 @@ func (b *BranchService) List() ([]BranchListItem, error) {
 
 			// TODO: Ensure the branch is valid
 
-			ref := strings.TrimPrefix(p, b.workspace.GelDir.String()+"/")
+			ref := strings.TrimPrefix(p, b.workspace.CommonDir.String()+"/")
 			name := strings.TrimPrefix(p, headsDir+"/")
 			isCurrent := ref == currentBranchRef
 			branches[name] = isCurrent
@@ -149,7 +152,7 @@ func (b *BranchService) Create(name string, startPoint string) error {
 }
 
 // Delete removes a branch by name.
-// Deleting the currently checked-out branch is rejected.
+// Deleting a branch checked out here or in another worktree is rejected.
 func (b *BranchService) Delete(name string) error {
 	if err := validateBranchName(name); err != nil {
 		return fmt.Errorf("branch: '%s': %w", name, err)
@@ -164,6 +167,9 @@ func (b *BranchService) Delete(name string) error {
 	if refToDelete == currRef {
 		return fmt.Errorf("branch: '%s': %w", name, ErrDeleteCurrentBranch)
 	}
+	if err := b.CheckNotCheckedOutElsewhere(name); err != nil {
+		return err
+	}
 	if err := b.refService.Delete(refToDelete); err != nil {
 		return fmt.Errorf("branch: failed to delete '%s': %w", name, err)
 	}
@@ -180,6 +186,20 @@ func (b *BranchService) Exists(name string) (bool, error) {
 	return ok, nil
 }
 
+// CheckNotCheckedOutElsewhere returns ErrBranchCheckedOut when another
+// worktree has branch name checked out.
+func (b *BranchService) CheckNotCheckedOutElsewhere(name string) error {
+	ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, name)
+	worktree, err := b.worktrees.CheckedOutElsewhere(ref)
+	if err != nil {
+		return fmt.Errorf("branch: %w", err)
+	}
+	if worktree != nil {
+		return fmt.Errorf("branch: '%s': %w at '%s'", name, ErrBranchCheckedOut, worktree.Dir)
+	}
+	return nil
+}
+
 // validateBranchName applies local branch naming rules.
 func validateBranchName(name string) error {
 	switch {
@@ -12,6 +12,9 @@ var (
 	// ErrDeleteCurrentBranch is returned when trying to delete the currently checked-out branch.
 	ErrDeleteCurrentBranch = errors.New("cannot delete the current branch")
 
+	// ErrBranchCheckedOut is returned when a branch is checked out in another worktree.
+	ErrBranchCheckedOut = errors.New("branch is checked out in another worktree")
+
 	// ErrInvalidBranchName is returned when a branch name violates naming rules.
 	ErrInvalidBranchName = errors.New("invalid branch name")
 
@@ -35,6 +35,8 @@ type SwitchService struct {
 	objectService   *core.ObjectService
 	readTreeService *tree.ReadTreeService
 	treeResolver    *core.TreeResolver
+	sparseCheckout  *core.SparseCheckout
+	hookRunner      *core.HookRunner
 	workspace       *domain.Workspace
 }
 
@@ -45,6 +47,7 @@ func NewSwitchService(
 	objectService *core.ObjectService,
 	readTreeService *tree.ReadTreeService,
 	treeResolver *core.TreeResolver,
+	sparseCheckout *core.SparseCheckout,
 	workspace *domain.Workspace,
 ) *SwitchService {
 	return &SwitchService{
@@ -53,12 +56,19 @@ func NewSwitchService(
 		objectService:   objectService,
 		readTreeService: readTreeService,
 		treeResolver:    treeResolver,
+		sparseCheckout:  sparseCheckout,
 		workspace:       workspace,
 	}
 }
 
+// SetHookRunner enables the post-checkout hook.
+func (s *SwitchService) SetHookRunner(hookRunner *core.HookRunner) {
+	s.hookRunner = hookRunner
+}
+
 // Switch changes the current branch and updates working tree/index to match target commit.
 // When Force is false, it aborts if local changes would be overwritten.
+// The post-checkout hook runs after a successful switch.
 func (s *SwitchService) Switch(branch string, options SwitchOptions) (*SwitchResult, error) {
 	targetRef, created, err := s.resolveTargetRef(branch, options.Create)
 	if err != nil {
@@ -66,7 +76,7 @@ func (s *SwitchService) Switch(branch string, options SwitchOptions) (*SwitchRes
 	}
 
 	headCommitHash, err := s.refService.Resolve(domain.HeadFileName)
-	if err != nil {
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
 		return nil, fmt.Errorf("switch: %w", err)
 	}
 
@@ -78,11 +88,12 @@ func (s *SwitchService) Switch(branch string, options SwitchOptions) (*SwitchRes
 		if err := s.refService.WriteSymbolic(domain.HeadFileName, targetRef); err != nil {
 			return nil, fmt.Errorf("switch: %w", err)
 		}
+		s.hookRunner.RunPost(core.HookPostCheckout, headCommitHash.String(), targetCommitHash.String(), "1")
 		return &SwitchResult{Branch: branch, Created: created}, nil
 	}
 
 	if !options.Force {
-		conflicts, err := s.findOverwriteConflicts(headCommitHash, targetCommitHash)
+		conflicts, err := s.FindOverwriteConflicts(headCommitHash, targetCommitHash)
 		if err != nil {
 			return nil, fmt.Errorf("switch: %w", err)
 		}
@@ -91,7 +102,7 @@ func (s *SwitchService) Switch(branch string, options SwitchOptions) (*SwitchRes
 		}
 	}
 
-	if err := s.checkoutWorkingTree(headCommitHash, targetCommitHash); err != nil {
+	if err := s.CheckoutWorkingTree(headCommitHash, targetCommitHash); err != nil {
 		return nil, fmt.Errorf("switch: %w", err)
 	}
 
@@ -105,6 +116,7 @@ func (s *SwitchService) Switch(branch string, options SwitchOptions) (*SwitchRes
 	if err := s.refService.WriteSymbolic(domain.HeadFileName, targetRef); err != nil {
 		return nil, fmt.Errorf("switch: %w", err)
 	}
+	s.hookRunner.RunPost(core.HookPostCheckout, headCommitHash.String(), targetCommitHash.String(), "1")
 	return &SwitchResult{Branch: branch, Created: created}, nil
 }
 
@@ -133,19 +145,23 @@ func (s *SwitchService) resolveTargetRef(branch string, create bool) (string, bo
 	if !exists {
 		return "", false, fmt.Errorf("switch: '%s': %w", branch, ErrBranchNotFound)
 	}
+	if err := s.branchService.CheckNotCheckedOutElsewhere(branch); err != nil {
+		return "", false, fmt.Errorf("switch: %w", err)
+	}
 	return targetRef, false, nil
 }
 
-// findOverwriteConflicts returns changed target paths that would lose local staged/unstaged state.
-func (s *SwitchService) findOverwriteConflicts(currentCommitHash, targetCommitHash domain.Hash) (
+// FindOverwriteConflicts returns changed target paths that would lose local staged/unstaged state.
+// An empty currentCommitHash is treated as an unborn branch with an empty snapshot.
+func (s *SwitchService) FindOverwriteConflicts(currentCommitHash, targetCommitHash domain.Hash) (
 	[]domain.NormalizedPath, error,
 ) {
-	oldPathHashes, err := s.treeResolver.ResolveCommit(currentCommitHash)
+	oldPathHashes, err := s.resolveCommitOrEmpty(currentCommitHash)
 	if err != nil {
 		return nil, err
 	}
 
-	targetPathHashes, err := s.treeResolver.ResolveCommit(targetCommitHash)
+	targetPathHashes, err := s.resolveCommitOrEmpty(targetCommitHash)
 	if err != nil {
 		return nil, err
 	}
@@ -174,37 +190,49 @@ func (s *SwitchService) findOverwriteConflicts(currentCommitHash, targetCommitHa
 	return conflicts, nil
 }
 
-// checkoutWorkingTree applies target commit file contents and removes paths absent in target.
-func (s *SwitchService) checkoutWorkingTree(oldCommitHash, targetCommitHash domain.Hash) error {
-	oldPathHashes, err := s.treeResolver.ResolveCommit(oldCommitHash)
+// CheckoutWorkingTree applies target commit file contents and removes paths absent in target.
+// An empty oldCommitHash is treated as an unborn branch with an empty snapshot.
+// Paths outside the sparse checkout cone are left alone.
+func (s *SwitchService) CheckoutWorkingTree(oldCommitHash, targetCommitHash domain.Hash) error {
+	cone, err := s.sparseCheckout.Cone()
 	if err != nil {
 		return err
 	}
 
-	targetPathHashes, err := s.treeResolver.ResolveCommit(targetCommitHash)
+	oldPathHashes, err := s.resolveCommitOrEmpty(oldCommitHash)
 	if err != nil {
 		return err
 	}
-	for targetPath, targetHash := range targetPathHashes {
+
+	targetPathHashes, err := s.resolveCommitOrEmpty(targetCommitHash)
+	if err != nil {
+		return err
+	}
+
+	oldPathModes, err := s.resolveCommitModesOrEmpty(oldCommitHash)
+	if err != nil {
+		return err
+	}
+
+	targetPathModes, err := s.resolveCommitModesOrEmpty(targetCommitHash)
+	if err != nil {
+		return err
+	}
+	for _, targetPath := range core.CheckoutOrder(targetPathHashes.ExtractPaths()) {
+		targetHash := targetPathHashes[targetPath]
 		oldHash, ok := oldPathHashes[targetPath]
-		if ok && oldHash == targetHash {
+		if ok && oldHash == targetHash && oldPathModes[targetPath].SameType(targetPathModes[targetPath]) {
 			continue
 		}
-
-		blob, err := s.objectService.ReadBlob(targetHash)
-		if err != nil {
-			return err
+		if cone != nil && !cone.Includes(targetPath) {
+			continue
 		}
 
 		absPath, err := targetPath.ToAbsolutePath(s.workspace.RepoDir)
 		if err != nil {
 			return err
 		}
-
-		if err := os.MkdirAll(filepath.Dir(absPath.String()), domain.DefaultDirPermission); err != nil {
-			return err
-		}
-		if err := os.WriteFile(absPath.String(), blob.Body(), domain.DefaultFilePermission); err != nil {
+		if err := s.objectService.CheckoutBlob(targetHash, absPath, targetPathModes[targetPath]); err != nil {
 			return err
 		}
 	}
@@ -213,11 +241,18 @@ func (s *SwitchService) checkoutWorkingTree(oldCommitHash, targetCommitHash doma
 		if _, existsInTarget := targetPathHashes[oldPath]; existsInTarget {
 			continue
 		}
+		if cone != nil && !cone.Includes(oldPath) {
+			continue
+		}
 
 		absPath, err := oldPath.ToAbsolutePath(s.workspace.RepoDir)
 		if err != nil {
 			return err
 		}
+		// A checked-out submodule holds its own repository and is left in place.
+		if core.IsRepositoryRoot(absPath.String()) {
+			continue
+		}
 		if err := os.Remove(absPath.String()); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return err
 		}
@@ -225,6 +260,22 @@ func (s *SwitchService) checkoutWorkingTree(oldCommitHash, targetCommitHash doma
 	return nil
 }
 
+// resolveCommitOrEmpty resolves a commit snapshot, mapping the zero hash to an empty snapshot.
+func (s *SwitchService) resolveCommitOrEmpty(commitHash domain.Hash) (core.PathHashes, error) {
+	if commitHash.IsEmpty() {
+		return make(core.PathHashes), nil
+	}
+	return s.treeResolver.ResolveCommit(commitHash)
+}
+
+// resolveCommitModesOrEmpty resolves commit file modes, mapping the zero hash to an empty snapshot.
+func (s *SwitchService) resolveCommitModesOrEmpty(commitHash domain.Hash) (core.PathModes, error) {
+	if commitHash.IsEmpty() {
+		return make(core.PathModes), nil
+	}
+	return s.treeResolver.ResolveCommitModes(commitHash)
+}
+
 // pathState models one path's snapshot state, including deletion via exists=false.
 type pathState struct {
 	exists bool
@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"Gel/internal/domain"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Signature is the first line of every Gel bundle file.
+const Signature = "# gel bundle v1"
+
+// Ref is one ref recorded in a bundle header.
+type Ref struct {
+	// Name is the full ref name, such as refs/heads/main, or HEAD.
+	Name string
+	// Hash is the commit the ref points at.
+	Hash domain.Hash
+}
+
+// Bundle is a decoded bundle: refs, prerequisite commits, and packed objects.
+//
+// The file layout is a text header followed by a Gel pack:
+//
+//	# gel bundle v1
+//	-<prerequisite hash>     (zero or more)
+//	<hash> <ref name>        (one or more)
+//	<empty line>
+//	<pack>
+type Bundle struct {
+	Refs          []Ref
+	Prerequisites []domain.Hash
+	Objects       map[domain.Hash]domain.Object
+}
+
+// Encode writes b in bundle file format.
+func (b *Bundle) Encode(writer io.Writer, order []domain.Hash) error {
+	var header bytes.Buffer
+	header.WriteString(Signature + "\n")
+	for _, prerequisite := range b.Prerequisites {
+		fmt.Fprintf(&header, "-%s\n", prerequisite.Hex())
+	}
+	for _, ref := range b.Refs {
+		fmt.Fprintf(&header, "%s %s\n", ref.Hash.Hex(), ref.Name)
+	}
+	header.WriteString("\n")
+
+	pack, err := EncodePack(b.Objects, order)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err = writer.Write(pack)
+	return err
+}
+
+// IsBundleFile reports whether path is a regular file starting with the bundle signature.
+func IsBundleFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	return err == nil && strings.TrimSuffix(line, "\n") == Signature
+}
+
+// ReadFile decodes and verifies the bundle at path.
+func ReadFile(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	return Decode(data)
+}
+
+// Decode parses bundle file content and verifies its pack.
+func Decode(data []byte) (*Bundle, error) {
+	signature, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || string(signature) != Signature {
+		return nil, fmt.Errorf("%w: missing signature", ErrInvalidBundle)
+	}
+
+	b := &Bundle{}
+	for {
+		line, next, ok := bytes.Cut(rest, []byte("\n"))
+		if !ok {
+			return nil, fmt.Errorf("%w: unterminated header", ErrInvalidBundle)
+		}
+		rest = next
+		if len(line) == 0 {
+			break
+		}
+
+		text := string(line)
+		if prerequisite, ok := strings.CutPrefix(text, "-"); ok {
+			hexHash, _, _ := strings.Cut(prerequisite, " ")
+			hash, err := domain.NewHashFromHex(hexHash)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+			}
+			b.Prerequisites = append(b.Prerequisites, hash)
+			continue
+		}
+
+		hexHash, name, ok := strings.Cut(text, " ")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: malformed ref line %q", ErrInvalidBundle, text)
+		}
+		hash, err := domain.NewHashFromHex(hexHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		b.Refs = append(b.Refs, Ref{Name: name, Hash: hash})
+	}
+	if len(b.Refs) == 0 {
+		return nil, fmt.Errorf("%w: no refs", ErrInvalidBundle)
+	}
+
+	objects, err := DecodePack(rest)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	b.Objects = objects
+	return b, nil
+}
+
+// ReadObject returns an object stored in the bundle.
+func (b *Bundle) ReadObject(hash domain.Hash) (domain.Object, error) {
+	object, ok := b.Objects[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotInBundle, hash)
+	}
+	return object, nil
+}
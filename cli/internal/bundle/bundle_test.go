@@ -0,0 +1,234 @@
+package bundle
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBundle returns a bundle of two blobs and a tree holding them, with
+// the tree as the target of its refs and two prerequisites.
+func newTestBundle(t *testing.T) (*Bundle, []domain.Hash) {
+	t.Helper()
+	objects := make(map[domain.Hash]domain.Object)
+	var order []domain.Hash
+	add := func(object domain.Object) domain.Hash {
+		hash, err := domain.NewHashFromHex(core.ComputeSHA256(object.Serialize()))
+		require.NoError(t, err)
+		objects[hash] = object
+		order = append(order, hash)
+		return hash
+	}
+	first := add(domain.NewBlob([]byte("first\n")))
+	second := add(domain.NewBlob([]byte("second\n")))
+	tree, err := domain.NewTreeFromEntries(
+		[]domain.TreeEntry{
+			domain.NewTreeEntry(domain.FileModeRegular, first, "a.txt"),
+			domain.NewTreeEntry(domain.FileModeRegular, second, "b.txt"),
+		},
+	)
+	require.NoError(t, err)
+	treeHash := add(tree)
+
+	firstPrerequisite, err := domain.NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	secondPrerequisite, err := domain.NewHashFromHex(strings.Repeat("cd", 32))
+	require.NoError(t, err)
+	return &Bundle{
+		Refs: []Ref{
+			{Name: "refs/heads/main", Hash: treeHash},
+			{Name: domain.HeadFileName, Hash: treeHash},
+		},
+		Prerequisites: []domain.Hash{firstPrerequisite, secondPrerequisite},
+		Objects:       objects,
+	}, order
+}
+
+// encodeTestBundle returns the file content of newTestBundle.
+func encodeTestBundle(t *testing.T) []byte {
+	t.Helper()
+	b, order := newTestBundle(t)
+	var buffer bytes.Buffer
+	require.NoError(t, b.Encode(&buffer, order))
+	return buffer.Bytes()
+}
+
+// withPackChecksum returns body followed by its pack checksum.
+func withPackChecksum(body []byte) []byte {
+	checksum := sha256.Sum256(body)
+	return append(append([]byte(nil), body...), checksum[:]...)
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(b *Bundle)
+	}{
+		{name: "prerequisites", mutate: func(b *Bundle) {}},
+		{name: "no prerequisites", mutate: func(b *Bundle) { b.Prerequisites = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				b, order := newTestBundle(t)
+				tt.mutate(b)
+				var buffer bytes.Buffer
+				require.NoError(t, b.Encode(&buffer, order))
+
+				decoded, err := Decode(buffer.Bytes())
+
+				require.NoError(t, err)
+				assert.Equal(t, b.Refs, decoded.Refs)
+				assert.Equal(t, b.Prerequisites, decoded.Prerequisites)
+				require.Len(t, decoded.Objects, len(b.Objects))
+				for hash, object := range b.Objects {
+					assert.Equal(t, object.Serialize(), decoded.Objects[hash].Serialize())
+				}
+			},
+		)
+	}
+}
+
+func TestDecodeAcceptsPrerequisiteComment(t *testing.T) {
+	data := encodeTestBundle(t)
+	prerequisite := "-" + strings.Repeat("ab", 32) + "\n"
+	data = bytes.Replace(data, []byte(prerequisite), []byte("-"+strings.Repeat("ab", 32)+" base commit\n"), 1)
+
+	decoded, err := Decode(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("ab", 32), decoded.Prerequisites[0].Hex())
+}
+
+func TestDecodeRejectsCorruptHeader(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "empty", header: ""},
+		{name: "wrong signature", header: "# v2 git bundle\n" + hash + " refs/heads/main\n\n"},
+		{name: "unterminated header", header: Signature + "\n" + hash + " refs/heads/main"},
+		{name: "no refs", header: Signature + "\n-" + hash + "\n\n"},
+		{name: "ref without name", header: Signature + "\n" + hash + "\n\n"},
+		{name: "ref with empty name", header: Signature + "\n" + hash + " \n\n"},
+		{name: "short ref hash", header: Signature + "\n" + hash[:40] + " refs/heads/main\n\n"},
+		{name: "non-hex ref hash", header: Signature + "\n" + strings.Repeat("zz", 32) + " refs/heads/main\n\n"},
+		{name: "empty prerequisite", header: Signature + "\n-\n" + hash + " refs/heads/main\n\n"},
+		{name: "short prerequisite", header: Signature + "\n-" + hash[:40] + "\n" + hash + " refs/heads/main\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := Decode([]byte(tt.header))
+
+				require.ErrorIs(t, err, ErrInvalidBundle)
+			},
+		)
+	}
+}
+
+func TestDecodePackRejectsCorruptData(t *testing.T) {
+	b, order := newTestBundle(t)
+	pack, err := EncodePack(b.Objects, order)
+	require.NoError(t, err)
+	body := pack[:len(pack)-sha256.Size]
+	firstEntry := packHeaderLen + domain.SHA256ByteLength + 4
+	firstLength := int(binary.BigEndian.Uint32(body[packHeaderLen+domain.SHA256ByteLength:]))
+
+	tests := []struct {
+		name string
+		data func() []byte
+	}{
+		{name: "empty", data: func() []byte { return nil }},
+		{name: "truncated header", data: func() []byte { return pack[:packHeaderLen] }},
+		{
+			name: "bad signature",
+			data: func() []byte { return withPackChecksum(append([]byte("GIT!"), body[4:]...)) },
+		},
+		{
+			name: "unsupported version",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				binary.BigEndian.PutUint32(corrupt[4:8], packVersion+1)
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "checksum mismatch",
+			data: func() []byte {
+				corrupt := bytes.Clone(pack)
+				corrupt[len(corrupt)-1] ^= 0xff
+				return corrupt
+			},
+		},
+		{
+			name: "count beyond entries",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				binary.BigEndian.PutUint32(corrupt[8:12], uint32(len(order)+1))
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "huge count",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				binary.BigEndian.PutUint32(corrupt[8:12], 0xffffffff)
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "count below entries",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				binary.BigEndian.PutUint32(corrupt[8:12], uint32(len(order)-1))
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "entry length beyond pack",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				binary.BigEndian.PutUint32(corrupt[packHeaderLen+domain.SHA256ByteLength:], 0xffffffff)
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "truncated entry",
+			data: func() []byte { return withPackChecksum(body[:firstEntry+firstLength-1]) },
+		},
+		{
+			name: "entry not compressed",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				corrupt[firstEntry] ^= 0xff
+				return withPackChecksum(corrupt)
+			},
+		},
+		{
+			name: "entry hash mismatch",
+			data: func() []byte {
+				corrupt := bytes.Clone(body)
+				corrupt[packHeaderLen] ^= 0xff
+				return withPackChecksum(corrupt)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := DecodePack(tt.data())
+
+				require.ErrorIs(t, err, ErrInvalidPack)
+			},
+		)
+	}
+}
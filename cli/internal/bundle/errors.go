@@ -0,0 +1,23 @@
+package bundle
+
+import "errors"
+
+var (
+	// ErrInvalidBundle is returned when a bundle header or pack cannot be parsed.
+	ErrInvalidBundle = errors.New("invalid bundle")
+
+	// ErrInvalidPack is returned when pack data is malformed or fails checksum verification.
+	ErrInvalidPack = errors.New("invalid pack")
+
+	// ErrEmptyBundle is returned when a rev-range selects no commits.
+	ErrEmptyBundle = errors.New("refusing to create empty bundle")
+
+	// ErrBundleNeedsRef is returned when an included revision is not a ref name.
+	ErrBundleNeedsRef = errors.New("bundle revisions must name a branch or ref")
+
+	// ErrMissingPrerequisites is returned when prerequisite commits are absent locally.
+	ErrMissingPrerequisites = errors.New("repository lacks prerequisite commits")
+
+	// ErrObjectNotInBundle is returned when a requested object is not stored in the bundle.
+	ErrObjectNotInBundle = errors.New("object not in bundle")
+)
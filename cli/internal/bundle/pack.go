@@ -0,0 +1,96 @@
+package bundle
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	packSignature = "GPAK"
+	packVersion   = 1
+	packHeaderLen = 12
+)
+
+// EncodePack serializes objects into a Gel pack.
+//
+// Layout: "GPAK", version and object count as big-endian uint32, then per
+// object its 32-byte hash, the compressed length as uint32 and the zlib
+// compressed serialized object, followed by a SHA-256 of everything before it.
+func EncodePack(objects map[domain.Hash]domain.Object, order []domain.Hash) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(packSignature)
+	_ = binary.Write(&buffer, binary.BigEndian, uint32(packVersion))
+	_ = binary.Write(&buffer, binary.BigEndian, uint32(len(order)))
+
+	for _, hash := range order {
+		compressed, err := core.Compress(objects[hash].Serialize())
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(hash[:])
+		_ = binary.Write(&buffer, binary.BigEndian, uint32(len(compressed)))
+		buffer.Write(compressed)
+	}
+	checksum := sha256.Sum256(buffer.Bytes())
+	buffer.Write(checksum[:])
+	return buffer.Bytes(), nil
+}
+
+// DecodePack parses a Gel pack, verifying its checksum and that every object
+// hashes to its recorded name.
+func DecodePack(data []byte) (map[domain.Hash]domain.Object, error) {
+	if len(data) < packHeaderLen+sha256.Size || string(data[:4]) != packSignature {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidPack)
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != packVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidPack, version)
+	}
+
+	body := data[:len(data)-sha256.Size]
+	if checksum := sha256.Sum256(body); !bytes.Equal(checksum[:], data[len(body):]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidPack)
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+	// Every entry takes at least its hash and length, which bounds the
+	// preallocation for a corrupt count.
+	objects := make(map[domain.Hash]domain.Object, min(int(count), len(body)/(domain.SHA256ByteLength+4)))
+	offset := packHeaderLen
+	for i := uint32(0); i < count; i++ {
+		if offset+domain.SHA256ByteLength+4 > len(body) {
+			return nil, fmt.Errorf("%w: truncated entry %d", ErrInvalidPack, i)
+		}
+		hash, err := domain.NewHashFromBytes(body[offset : offset+domain.SHA256ByteLength])
+		if err != nil {
+			return nil, err
+		}
+		offset += domain.SHA256ByteLength
+		length := int(binary.BigEndian.Uint32(body[offset:]))
+		offset += 4
+		if offset+length > len(body) {
+			return nil, fmt.Errorf("%w: truncated entry %d", ErrInvalidPack, i)
+		}
+
+		data, err := core.Decompress(body[offset : offset+length])
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %s: %v", ErrInvalidPack, hash, err)
+		}
+		offset += length
+		if core.ComputeSHA256(data) != hash.Hex() {
+			return nil, fmt.Errorf("%w: entry %s does not match its content", ErrInvalidPack, hash)
+		}
+		object, err := domain.DeserializeObject(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %s: %v", ErrInvalidPack, hash, err)
+		}
+		objects[hash] = object
+	}
+	if offset != len(body) {
+		return nil, fmt.Errorf("%w: trailing data after %d entries", ErrInvalidPack, count)
+	}
+	return objects, nil
+}
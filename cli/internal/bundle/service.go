@@ -0,0 +1,355 @@
+package bundle
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CreateOptions controls which history a bundle contains.
+type CreateOptions struct {
+	// All includes HEAD and every local branch in addition to the given revisions.
+	All bool
+}
+
+// CreateResult reports the bundle written by Create.
+type CreateResult struct {
+	// Refs lists the refs recorded in the bundle header.
+	Refs []Ref
+	// Prerequisites lists commits a receiving repository must already have.
+	Prerequisites []domain.Hash
+	// ObjectCount is the number of objects packed in the bundle.
+	ObjectCount int
+}
+
+// VerifyResult reports the content of a bundle and the prerequisites missing locally.
+type VerifyResult struct {
+	Refs          []Ref
+	Prerequisites []domain.Hash
+	Missing       []domain.Hash
+	ObjectCount   int
+}
+
+// BundleService writes and verifies bundle files for offline history transfer.
+type BundleService struct {
+	objectService  *core.ObjectService
+	refService     *core.RefService
+	branchService  *branch.BranchService
+	commitResolver *core.CommitResolver
+	shallowService *core.ShallowService
+}
+
+// NewBundleService creates a bundle service.
+func NewBundleService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	branchService *branch.BranchService,
+	commitResolver *core.CommitResolver,
+	shallowService *core.ShallowService,
+) *BundleService {
+	return &BundleService{
+		objectService:  objectService,
+		refService:     refService,
+		branchService:  branchService,
+		commitResolver: commitResolver,
+		shallowService: shallowService,
+	}
+}
+
+// Create writes a bundle of the history selected by revisions to path.
+//
+// Each revision is a ref to include (branch name, refs/... or HEAD), an
+// exclusion "^<commit>", or a range "<commit>..<ref>" where an empty right
+// side means HEAD. Commits reachable from an exclusion are left out; the
+// excluded parents of included commits become the bundle's prerequisites.
+func (b *BundleService) Create(path string, revisions []string, options CreateOptions) (*CreateResult, error) {
+	refs, excludes, err := b.parseRevisions(revisions, options)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("bundle: %w", ErrEmptyBundle)
+	}
+
+	grafts, err := b.shallowService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	excluded, err := b.ancestors(excludes, grafts)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+
+	objects := make(map[domain.Hash]domain.Object)
+	var order []domain.Hash
+	add := func(hash domain.Hash, object domain.Object) {
+		objects[hash] = object
+		order = append(order, hash)
+	}
+
+	var commits []*domain.Commit
+	var prerequisites []domain.Hash
+	seen := make(map[domain.Hash]bool)
+	var queue []domain.Hash
+	for _, ref := range refs {
+		queue = append(queue, ref.Hash)
+	}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		if excluded[hash] {
+			prerequisites = append(prerequisites, hash)
+			continue
+		}
+
+		commit, err := b.objectService.ReadCommit(hash)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+		add(hash, commit)
+		commits = append(commits, commit)
+		queue = append(queue, grafts.Parents(hash, commit)...)
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("bundle: %w", ErrEmptyBundle)
+	}
+
+	// Trees and blobs the prerequisites already carry are not repacked.
+	known := make(map[domain.Hash]bool)
+	for _, prerequisite := range prerequisites {
+		commit, err := b.objectService.ReadCommit(prerequisite)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+		if err := b.walkTree(commit.TreeHash, known, nil); err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+	}
+	for _, commit := range commits {
+		if err := b.walkTree(commit.TreeHash, known, add); err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+	}
+
+	bundle := &Bundle{Refs: refs, Prerequisites: prerequisites, Objects: objects}
+	var buffer bytes.Buffer
+	if err := bundle.Encode(&buffer, order); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), domain.DefaultFilePermission); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+
+	return &CreateResult{
+		Refs:          refs,
+		Prerequisites: prerequisites,
+		ObjectCount:   len(order),
+	}, nil
+}
+
+// Verify decodes the bundle at path and checks its prerequisites exist locally.
+// The result is returned together with ErrMissingPrerequisites when some are absent.
+func (b *BundleService) Verify(path string) (*VerifyResult, error) {
+	bundle, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{
+		Refs:          bundle.Refs,
+		Prerequisites: bundle.Prerequisites,
+		ObjectCount:   len(bundle.Objects),
+	}
+	for _, prerequisite := range bundle.Prerequisites {
+		exists, err := b.objectService.Exists(prerequisite)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+		if !exists {
+			result.Missing = append(result.Missing, prerequisite)
+		}
+	}
+	if len(result.Missing) > 0 {
+		return result, fmt.Errorf("bundle: %w", ErrMissingPrerequisites)
+	}
+	return result, nil
+}
+
+// parseRevisions splits revisions into the refs to record and the commits to exclude.
+func (b *BundleService) parseRevisions(revisions []string, options CreateOptions) ([]Ref, []domain.Hash, error) {
+	var refs []Ref
+	var excludes []domain.Hash
+	recorded := make(map[string]bool)
+	include := func(rev string) error {
+		resolved, err := b.resolveRefs(rev)
+		if err != nil {
+			return err
+		}
+		for _, ref := range resolved {
+			if !recorded[ref.Name] {
+				recorded[ref.Name] = true
+				refs = append(refs, ref)
+			}
+		}
+		return nil
+	}
+	exclude := func(rev string) error {
+		hash, err := b.commitResolver.Resolve(rev)
+		if err != nil {
+			return err
+		}
+		excludes = append(excludes, hash)
+		return nil
+	}
+
+	if options.All {
+		if _, err := b.commitResolver.Resolve(domain.HeadFileName); err == nil {
+			if err := include(domain.HeadFileName); err != nil {
+				return nil, nil, err
+			}
+		}
+		branches, err := b.branchService.List()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range branches {
+			if err := include(item.Name); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, rev := range revisions {
+		if from, to, ok := strings.Cut(rev, ".."); ok {
+			if to == "" {
+				to = domain.HeadFileName
+			}
+			if from != "" {
+				if err := exclude(from); err != nil {
+					return nil, nil, err
+				}
+			}
+			if err := include(to); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if excluded, ok := strings.CutPrefix(rev, "^"); ok {
+			if err := exclude(excluded); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err := include(rev); err != nil {
+			return nil, nil, err
+		}
+	}
+	return refs, excludes, nil
+}
+
+// resolveRefs maps an included revision to the refs recorded for it.
+// HEAD is recorded together with the branch it points at.
+func (b *BundleService) resolveRefs(rev string) ([]Ref, error) {
+	if rev == domain.HeadFileName {
+		hash, err := b.commitResolver.Resolve(domain.HeadFileName)
+		if err != nil {
+			return nil, err
+		}
+		refs := []Ref{{Name: domain.HeadFileName, Hash: hash}}
+		branchRef, err := b.refService.ReadSymbolic(domain.HeadFileName)
+		if err == nil && strings.HasPrefix(branchRef, domain.RefsDirName+"/") {
+			refs = append(refs, Ref{Name: branchRef, Hash: hash})
+		}
+		return refs, nil
+	}
+
+	ref := rev
+	if !strings.HasPrefix(rev, domain.RefsDirName+"/") {
+		ref = filepath.Join(domain.RefsDirName, domain.HeadsDirName, rev)
+	}
+	hash, err := b.refService.Read(ref)
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return nil, fmt.Errorf("%w: '%s'", ErrBundleNeedsRef, rev)
+		}
+		return nil, err
+	}
+	if hash.IsEmpty() {
+		return nil, nil
+	}
+	return []Ref{{Name: ref, Hash: hash}}, nil
+}
+
+// ancestors returns every commit reachable from tips.
+func (b *BundleService) ancestors(tips []domain.Hash, grafts domain.ShallowGrafts) (map[domain.Hash]bool, error) {
+	reachable := make(map[domain.Hash]bool)
+	queue := append([]domain.Hash(nil), tips...)
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if reachable[hash] {
+			continue
+		}
+		reachable[hash] = true
+
+		commit, err := b.objectService.ReadCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, grafts.Parents(hash, commit)...)
+	}
+	return reachable, nil
+}
+
+// walkTree visits treeHash and everything below it not yet in known, passing
+// each object to add when add is non-nil.
+func (b *BundleService) walkTree(
+	treeHash domain.Hash,
+	known map[domain.Hash]bool,
+	add func(domain.Hash, domain.Object),
+) error {
+	if known[treeHash] {
+		return nil
+	}
+	known[treeHash] = true
+
+	tree, err := b.objectService.ReadTree(treeHash)
+	if err != nil {
+		return err
+	}
+	if add != nil {
+		add(treeHash, tree)
+	}
+	for _, entry := range tree.Entries() {
+		if entry.Mode.IsDirectory() {
+			if err := b.walkTree(entry.Hash, known, add); err != nil {
+				return err
+			}
+			continue
+		}
+		// A submodule commit lives in another repository.
+		if entry.Mode.IsGitlink() || known[entry.Hash] {
+			continue
+		}
+		known[entry.Hash] = true
+		if add == nil {
+			continue
+		}
+		blob, err := b.objectService.ReadBlob(entry.Hash)
+		if err != nil {
+			return err
+		}
+		add(entry.Hash, blob)
+	}
+	return nil
+}
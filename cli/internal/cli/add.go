@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"Gel/internal/diff"
 	"Gel/internal/staging"
+	"fmt"
 
 	"github.com/spf13/cobra"
 )
@@ -9,19 +11,35 @@ import (
 var (
 	addDryRunFlag  bool
 	addVerboseFlag bool
+	addForceFlag   bool
+	addPatchFlag   bool
+
+	addIntentToAddFlag bool
 )
 
 // addCmd stages file content into the index using pathspec semantics.
 var addCmd = &cobra.Command{
 	Use:   "add <pathspec>...",
 	Short: "Add file contents to the index",
-	Args:  cobra.MinimumNArgs(1),
+	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if addPatchFlag {
+			selector := diff.NewPatchSelector(
+				cmd.InOrStdin(), cmd.OutOrStderr(), "Stage", diff.PatchDirectionForward, editor.Edit,
+			)
+			return addService.AddPatch(args, selector).Error
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("add: nothing specified, nothing added")
+		}
+
 		addResult := addService.Add(
 			args,
 			staging.AddOptions{
-				Verbose: addVerboseFlag,
-				DryRun:  addDryRunFlag,
+				Verbose:     addVerboseFlag,
+				DryRun:      addDryRunFlag,
+				Force:       addForceFlag,
+				IntentToAdd: addIntentToAddFlag,
 			},
 		)
 		if addResult.Error != nil {
@@ -46,5 +64,17 @@ func init() {
 		&addVerboseFlag, "verbose", "v", false,
 		"Show verbose output of the add operation",
 	)
+	addCmd.Flags().BoolVarP(
+		&addForceFlag, "force", "f", false,
+		"Allow adding otherwise ignored files",
+	)
+	addCmd.Flags().BoolVarP(
+		&addPatchFlag, "patch", "p", false,
+		"Interactively choose hunks to stage (answers are read from stdin)",
+	)
+	addCmd.Flags().BoolVarP(
+		&addIntentToAddFlag, "intent-to-add", "N", false,
+		"Record only that untracked paths will be added later, without staging their content",
+	)
 	rootCmd.AddCommand(addCmd)
 }
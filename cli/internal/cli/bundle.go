@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"Gel/internal/bundle"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleCreateAllFlag bool
+)
+
+// bundleCmd groups the bundle subcommands.
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Move history between repositories through a single file",
+}
+
+// bundleCreateCmd writes the selected history and refs to a bundle file.
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <file> [<rev-range>...]",
+	Short: "Write refs and the objects they need to a bundle file",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := bundleService.Create(
+			args[0], args[1:], bundle.CreateOptions{All: bundleCreateAllFlag},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range result.Refs {
+			cmd.Printf("%s %s\n", ref.Hash, ref.Name)
+		}
+		for _, prerequisite := range result.Prerequisites {
+			cmd.Printf("-%s\n", prerequisite)
+		}
+		cmd.Printf("Wrote %d objects to %s\n", result.ObjectCount, args[0])
+		return nil
+	},
+}
+
+// bundleVerifyCmd checks a bundle file and whether this repository can apply it.
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify <file>",
+	Short: "Check that a bundle is valid and applies to this repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := bundleService.Verify(args[0])
+		if result != nil {
+			cmd.Printf("The bundle contains %d ref(s):\n", len(result.Refs))
+			for _, ref := range result.Refs {
+				cmd.Printf("%s %s\n", ref.Hash, ref.Name)
+			}
+			if len(result.Prerequisites) == 0 {
+				cmd.Printf("The bundle records a complete history.\n")
+			} else {
+				cmd.Printf("The bundle requires %d commit(s):\n", len(result.Prerequisites))
+				for _, prerequisite := range result.Prerequisites {
+					cmd.Printf("%s\n", prerequisite)
+				}
+			}
+			for _, missing := range result.Missing {
+				cmd.Printf("missing %s\n", missing)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		cmd.Printf("%s is okay\n", args[0])
+		return nil
+	},
+}
+
+// init registers the bundle command, its subcommands and flags.
+func init() {
+	bundleCreateCmd.Flags().BoolVar(
+		&bundleCreateAllFlag, "all", false,
+		"Include every local branch",
+	)
+	bundleCmd.AddCommand(bundleCreateCmd, bundleVerifyCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"Gel/internal/inspect"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkIgnoreVerboseFlag     bool
+	checkIgnoreNonMatchingFlag bool
+	checkIgnoreNoIndexFlag     bool
+)
+
+// checkIgnoreCmd reports which paths are excluded by ignore rules and why.
+var checkIgnoreCmd = &cobra.Command{
+	Use:   "check-ignore <path>...",
+	Short: "Debug .gelignore and exclude files",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := checkIgnoreService.CheckIgnore(
+			args, inspect.CheckIgnoreOptions{NoIndex: checkIgnoreNoIndexFlag},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			switch {
+			case checkIgnoreVerboseFlag && result.Rule != nil:
+				cmd.Printf(
+					"%s:%d:%s\t%s\n",
+					result.Rule.Source, result.Rule.Line, result.Rule.Pattern, result.Path,
+				)
+			case checkIgnoreVerboseFlag && checkIgnoreNonMatchingFlag:
+				cmd.Printf("::\t%s\n", result.Path)
+			case !checkIgnoreVerboseFlag && result.Ignored:
+				cmd.Printf("%s\n", result.Path)
+			}
+		}
+		return nil
+	},
+}
+
+// init registers the check-ignore command and its flags.
+func init() {
+	checkIgnoreCmd.Flags().BoolVarP(
+		&checkIgnoreVerboseFlag, "verbose", "v", false,
+		"Show the matching rule for each path",
+	)
+	checkIgnoreCmd.Flags().BoolVarP(
+		&checkIgnoreNonMatchingFlag, "non-matching", "n", false,
+		"With --verbose, also show paths that match no rule",
+	)
+	checkIgnoreCmd.Flags().BoolVar(
+		&checkIgnoreNoIndexFlag, "no-index", false,
+		"Do not treat tracked paths as never ignored",
+	)
+	rootCmd.AddCommand(checkIgnoreCmd)
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"Gel/internal/staging"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanDryRunFlag         bool
+	cleanForceFlag          bool
+	cleanDirectoriesFlag    bool
+	cleanIncludeIgnoredFlag bool
+	cleanOnlyIgnoredFlag    bool
+	cleanInteractiveFlag    bool
+	cleanQuietFlag          bool
+	cleanExcludeFlag        []string
+)
+
+// cleanCmd removes untracked files from the working tree.
+var cleanCmd = &cobra.Command{
+	Use:   "clean [<pathspec>...]",
+	Short: "Remove untracked files from the working tree",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cleanIncludeIgnoredFlag && cleanOnlyIgnoredFlag {
+			return errors.New("clean: -x and -X cannot be used together")
+		}
+		if !cleanForceFlag && !cleanDryRunFlag && !cleanInteractiveFlag {
+			requireForce, err := cleanService.RequireForce()
+			if err != nil {
+				return err
+			}
+			if requireForce {
+				return fmt.Errorf("clean: %w", staging.ErrCleanRequireForce)
+			}
+		}
+
+		plan, err := cleanService.Plan(
+			args, staging.CleanOptions{
+				Directories:     cleanDirectoriesFlag,
+				IncludeIgnored:  cleanIncludeIgnoredFlag,
+				OnlyIgnored:     cleanOnlyIgnoredFlag,
+				ExcludePatterns: cleanExcludeFlag,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if cleanDryRunFlag {
+			for _, path := range plan.SkippedRepositories {
+				cmd.Printf("Would skip repository %s/\n", path)
+			}
+			for _, candidate := range plan.Candidates {
+				cmd.Printf("Would remove %s\n", candidate)
+			}
+			return nil
+		}
+
+		candidates := plan.Candidates
+		if cleanInteractiveFlag {
+			candidates, err = selectCleanCandidates(cmd, candidates)
+			if err != nil {
+				return err
+			}
+		}
+		if !cleanQuietFlag {
+			for _, path := range plan.SkippedRepositories {
+				cmd.Printf("Skipping repository %s/\n", path)
+			}
+		}
+		if err := cleanService.Remove(candidates); err != nil {
+			return err
+		}
+		if !cleanQuietFlag {
+			for _, candidate := range candidates {
+				cmd.Printf("Removing %s\n", candidate)
+			}
+		}
+		return nil
+	},
+}
+
+// selectCleanCandidates asks about each candidate on standard input and
+// returns those confirmed. Answering "a" takes the rest, "q" stops asking.
+func selectCleanCandidates(
+	cmd *cobra.Command,
+	candidates []staging.CleanCandidate,
+) ([]staging.CleanCandidate, error) {
+	reader := bufio.NewReader(cmd.InOrStdin())
+	var selected []staging.CleanCandidate
+	for i, candidate := range candidates {
+		cmd.Printf("Remove %s [y,n,a,q]? ", candidate)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("clean: %w", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			selected = append(selected, candidate)
+		case "a", "all":
+			return append(selected, candidates[i:]...), nil
+		case "q", "quit":
+			return selected, nil
+		}
+		if errors.Is(err, io.EOF) {
+			cmd.Println()
+			return selected, nil
+		}
+	}
+	return selected, nil
+}
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanDryRunFlag, "dry-run", "n", false, "Only show what would be removed")
+	cleanCmd.Flags().BoolVarP(&cleanForceFlag, "force", "f", false, "Remove files even if clean.requireForce is set")
+	cleanCmd.Flags().BoolVarP(&cleanDirectoriesFlag, "directories", "d", false, "Remove untracked directories too")
+	cleanCmd.Flags().BoolVarP(
+		&cleanIncludeIgnoredFlag, "ignored", "x", false, "Remove ignored files along with untracked ones",
+	)
+	cleanCmd.Flags().BoolVarP(&cleanOnlyIgnoredFlag, "only-ignored", "X", false, "Remove only ignored files")
+	cleanCmd.Flags().BoolVarP(&cleanInteractiveFlag, "interactive", "i", false, "Ask before removing each path")
+	cleanCmd.Flags().BoolVarP(&cleanQuietFlag, "quiet", "q", false, "Do not report the removed paths")
+	cleanCmd.Flags().StringArrayVarP(
+		&cleanExcludeFlag, "exclude", "e", nil, "Add an ignore pattern for this run",
+	)
+	rootCmd.AddCommand(cleanCmd)
+}
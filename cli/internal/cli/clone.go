@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneDepthFlag  int
+	cloneFilterFlag string
+)
+
+// cloneCmd creates a new repository from a remote and checks out its default branch.
+var cloneCmd = &cobra.Command{
+	Use:   "clone <repository> [directory]",
+	Short: "Clone a repository into a new directory",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		}
+
+		filter, err := remote.ParseObjectFilter(cloneFilterFlag)
+		if err != nil {
+			return err
+		}
+
+		result, err := remote.NewCloneService().Clone(
+			args[0], path, remote.CloneOptions{
+				Depth:  cloneDepthFlag,
+				Filter: filter,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Cloned into '%s'\n", result.Workspace.RepoDir)
+
+		if result.CommitHash.IsEmpty() {
+			cmd.Println("warning: You appear to have cloned an empty repository.")
+			return nil
+		}
+
+		initializeServicesForWorkspace(result.Workspace)
+		if err := switchService.CheckoutWorkingTree(domain.Hash{}, result.CommitHash); err != nil {
+			return err
+		}
+		commit, err := objectService.ReadCommit(result.CommitHash)
+		if err != nil {
+			return err
+		}
+		return readTreeService.ReadTree(commit.TreeHash)
+	},
+}
+
+// init registers the clone command and its flags.
+func init() {
+	cloneCmd.Flags().IntVar(
+		&cloneDepthFlag, "depth", 0,
+		"Create a shallow clone truncated to the given number of commits",
+	)
+	cloneCmd.Flags().StringVar(
+		&cloneFilterFlag, "filter", "",
+		"Create a partial clone omitting objects (supported: blob:none)",
+	)
+	rootCmd.AddCommand(cloneCmd)
+}
@@ -1,22 +1,107 @@
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"Gel/internal/commit"
+
+	"github.com/spf13/cobra"
+)
 
 var (
-	commitMessageFlag string
+	commitMessageFlag    string
+	commitAmendFlag      bool
+	commitAllowEmptyFlag bool
+	commitAuthorFlag     string
+	commitDateFlag       string
+	commitAllFlag        bool
+	commitEditFlag       bool
+	commitNoEditFlag     bool
+	commitCleanupFlag    string
+	commitTrailerFlags   []string
+	commitNoVerifyFlag   bool
+	commitSignFlag       bool
+	commitNoSignFlag     bool
 )
 
 // commitCmd records the current index state as a new commit on the current branch.
 var commitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Record changes to the repository",
-	Args:  cobra.NoArgs,
+	Long: "Record changes to the repository.\n\n" +
+		"The author and committer default to user.name and user.email at the current\n" +
+		"time. GEL_AUTHOR_NAME, GEL_AUTHOR_EMAIL and GEL_AUTHOR_DATE override the author,\n" +
+		"and GEL_COMMITTER_NAME, GEL_COMMITTER_EMAIL and GEL_COMMITTER_DATE the committer.\n" +
+		"Dates are given as '[@]<unix timestamp> <+HHMM>', '@<unix timestamp>', ISO 8601\n" +
+		"or RFC 2822.\n\n" +
+		"Without -m the message is written in the editor from GEL_EDITOR or core.editor,\n" +
+		"pre-filled with commit.template and a summary of the staged changes. The\n" +
+		"message is then cleaned up according to --cleanup or commit.cleanup:\n" +
+		"strip drops '#' comment lines and surplus blank lines, whitespace only the\n" +
+		"blank lines, verbatim nothing, and scissors also everything below the\n" +
+		"scissors line. The default is strip for edited messages and whitespace\n" +
+		"otherwise.\n\n" +
+		"The pre-commit hook runs before the tree is written and the commit-msg hook\n" +
+		"with the path of the message file; either aborts the commit by exiting\n" +
+		"non-zero. --no-verify skips both. The post-commit hook runs afterwards.\n" +
+		"Hooks are executables in .gel/hooks, or in core.hooksPath when set. They run\n" +
+		"at the working tree root with GEL_DIR and GEL_INDEX_FILE set and empty\n" +
+		"standard input.\n\n" +
+		"-S signs the commit with the unencrypted ed25519 SSH private key named by\n" +
+		"user.signingkey; commit.gpgsign=true signs every commit.",
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return commitService.Commit(commitMessageFlag)
+		if commitAllFlag {
+			if err := addService.AddTracked().Error; err != nil {
+				return err
+			}
+		}
+		options := commit.CommitOptions{
+			Message:    commitMessageFlag,
+			Amend:      commitAmendFlag,
+			AllowEmpty: commitAllowEmptyFlag,
+			Author:     commitAuthorFlag,
+			Date:       commitDateFlag,
+			Cleanup:    commitCleanupFlag,
+			Trailers:   commitTrailerFlags,
+			NoVerify:   commitNoVerifyFlag,
+			Sign:       commitSignFlag,
+			NoSign:     commitNoSignFlag,
+		}
+		if commitEditFlag || (!cmd.Flags().Changed("message") && !commitNoEditFlag) {
+			options.Edit = editor.Edit
+		}
+		return commitService.Commit(options)
 	},
 }
 
 func init() {
 	commitCmd.Flags().StringVarP(&commitMessageFlag, "message", "m", "", "Commit message")
+	commitCmd.Flags().BoolVar(
+		&commitAmendFlag, "amend", false, "Replace the tip of the current branch, reusing its parents and message",
+	)
+	commitCmd.Flags().BoolVar(
+		&commitAllowEmptyFlag, "allow-empty", false, "Allow a commit with the same tree as its parent",
+	)
+	commitCmd.Flags().StringVar(&commitAuthorFlag, "author", "", "Override the commit author, as 'Name <email>'")
+	commitCmd.Flags().StringVar(&commitDateFlag, "date", "", "Override the author date")
+	commitCmd.Flags().BoolVarP(
+		&commitAllFlag, "all", "a", false, "Stage modified and deleted tracked files before committing",
+	)
+	commitCmd.Flags().BoolVarP(&commitEditFlag, "edit", "e", false, "Edit the message given with -m in the editor")
+	commitCmd.Flags().BoolVar(
+		&commitNoEditFlag, "no-edit", false, "Use the message of the amended commit without launching the editor",
+	)
+	commitCmd.Flags().StringVar(
+		&commitCleanupFlag, "cleanup", "", "How to clean up the message: default, strip, whitespace, verbatim or scissors",
+	)
+	commitCmd.Flags().StringArrayVar(
+		&commitTrailerFlags, "trailer", nil, "Add a trailer such as 'Signed-off-by=Name <email>' (repeatable)",
+	)
+	commitCmd.Flags().BoolVarP(
+		&commitNoVerifyFlag, "no-verify", "n", false, "Bypass the pre-commit and commit-msg hooks",
+	)
+	commitCmd.Flags().BoolVarP(&commitSignFlag, "gpg-sign", "S", false, "Sign the commit with user.signingkey")
+	commitCmd.Flags().BoolVar(&commitNoSignFlag, "no-gpg-sign", false, "Do not sign the commit despite commit.gpgsign")
+	commitCmd.MarkFlagsMutuallyExclusive("edit", "no-edit")
+	commitCmd.MarkFlagsMutuallyExclusive("gpg-sign", "no-gpg-sign")
 	rootCmd.AddCommand(commitCmd)
 }
@@ -72,29 +72,57 @@ func printDiffResults(cmd *cobra.Command, results []*diff.DiffResult) {
 	for _, result := range results {
 		switch result.Status {
 		case diff.DiffStatusAdded:
-			printAddedFileHeader(cmd, result.OldPath, result.NewPath, result.NewHash)
+			printAddedFileHeader(cmd, result.OldPath, result.NewPath, result.NewHash, result.NewMode)
 		case diff.DiffStatusModified:
-			printModifiedFileHeader(cmd, result.OldPath, result.NewPath, result.OldHash, result.NewHash)
+			printModifiedFileHeader(cmd, result.OldPath, result.NewPath, result.OldHash, result.NewHash, result.NewMode)
+		case diff.DiffStatusTypeChanged:
+			printTypeChangedFileHeader(cmd, result)
 		case diff.DiffStatusDeleted:
-			printDeletedFileHeader(cmd, result.OldPath, result.OldHash)
+			printDeletedFileHeader(cmd, result.OldPath, result.OldHash, result.OldMode)
+		}
+		if result.Binary {
+			printBinaryNotice(cmd, result)
+			continue
 		}
 		printHunks(cmd, result.Hunks)
 	}
 }
 
+// printBinaryNotice prints the line standing in for hunks of a binary file.
+func printBinaryNotice(cmd *cobra.Command, result *diff.DiffResult) {
+	oldName, newName := "a/"+result.OldPath.String(), "b/"+result.NewPath.String()
+	switch result.Status {
+	case diff.DiffStatusAdded:
+		oldName = "/dev/null"
+	case diff.DiffStatusDeleted:
+		newName = "/dev/null"
+	}
+	cmd.Printf("Binary files %s and %s differ\n", oldName, newName)
+}
+
 // printAddedFileHeader prints patch header lines for a newly added file.
-func printAddedFileHeader(cmd *cobra.Command, oldPath, newPath domain.NormalizedPath, hash domain.Hash) {
+func printAddedFileHeader(
+	cmd *cobra.Command,
+	oldPath, newPath domain.NormalizedPath,
+	hash domain.Hash,
+	mode domain.FileMode,
+) {
 	cmd.Printf("%sdiff --gel a/%s b/%s%s\n", core.ColorBold, oldPath, newPath, core.ColorReset)
-	cmd.Printf("%snew file mode %s%s\n", core.ColorBold, domain.FileModeRegular, core.ColorReset)
+	cmd.Printf("%snew file mode %s%s\n", core.ColorBold, displayFileMode(mode), core.ColorReset)
 	cmd.Printf("%sindex 00000000..%s%s\n", core.ColorBold, hash, core.ColorReset)
 	cmd.Printf("%s--- /dev/null%s\n", core.ColorBold, core.ColorReset)
 	cmd.Printf("%s+++ b/%s%s\n", core.ColorBold, newPath, core.ColorReset)
 }
 
 // printDeletedFileHeader prints patch header lines for a deleted file.
-func printDeletedFileHeader(cmd *cobra.Command, oldPath domain.NormalizedPath, oldHash domain.Hash) {
+func printDeletedFileHeader(
+	cmd *cobra.Command,
+	oldPath domain.NormalizedPath,
+	oldHash domain.Hash,
+	mode domain.FileMode,
+) {
 	cmd.Printf("%sdiff --gel a/%s b/%s%s\n", core.ColorBold, oldPath, oldPath, core.ColorReset)
-	cmd.Printf("%sdeleted file mode %s%s\n", core.ColorBold, domain.FileModeRegular, core.ColorReset)
+	cmd.Printf("%sdeleted file mode %s%s\n", core.ColorBold, displayFileMode(mode), core.ColorReset)
 	cmd.Printf("%sindex %s..00000000%s\n", core.ColorBold, oldHash, core.ColorReset)
 	cmd.Printf("%s--- a/%s%s\n", core.ColorBold, oldPath, core.ColorReset)
 	cmd.Printf("%s+++ /dev/null%s\n", core.ColorBold, core.ColorReset)
@@ -105,13 +133,32 @@ func printModifiedFileHeader(
 	cmd *cobra.Command,
 	oldPath, newPath domain.NormalizedPath,
 	oldHash, newHash domain.Hash,
+	mode domain.FileMode,
 ) {
 	cmd.Printf("%sdiff --gel a/%s b/%s%s\n", core.ColorBold, oldPath, newPath, core.ColorReset)
-	cmd.Printf("%sindex %s..%s %s%s\n", core.ColorBold, oldHash, newHash, domain.FileModeRegular, core.ColorReset)
+	cmd.Printf("%sindex %s..%s %s%s\n", core.ColorBold, oldHash, newHash, displayFileMode(mode), core.ColorReset)
 	cmd.Printf("%s--- a/%s%s\n", core.ColorBold, oldPath, core.ColorReset)
 	cmd.Printf("%s+++ b/%s%s\n", core.ColorBold, newPath, core.ColorReset)
 }
 
+// printTypeChangedFileHeader prints patch header lines for a path that switched between a file and a symlink.
+func printTypeChangedFileHeader(cmd *cobra.Command, result *diff.DiffResult) {
+	cmd.Printf("%sdiff --gel a/%s b/%s%s\n", core.ColorBold, result.OldPath, result.NewPath, core.ColorReset)
+	cmd.Printf("%sold mode %s%s\n", core.ColorBold, displayFileMode(result.OldMode), core.ColorReset)
+	cmd.Printf("%snew mode %s%s\n", core.ColorBold, displayFileMode(result.NewMode), core.ColorReset)
+	cmd.Printf("%sindex %s..%s%s\n", core.ColorBold, result.OldHash, result.NewHash, core.ColorReset)
+	cmd.Printf("%s--- a/%s%s\n", core.ColorBold, result.OldPath, core.ColorReset)
+	cmd.Printf("%s+++ b/%s%s\n", core.ColorBold, result.NewPath, core.ColorReset)
+}
+
+// displayFileMode renders mode for diff headers, falling back to the regular file mode when unknown.
+func displayFileMode(mode domain.FileMode) domain.FileMode {
+	if !mode.IsValid() {
+		return domain.FileModeRegular
+	}
+	return mode
+}
+
 // printHunks prints unified-style hunk ranges and per-line operations.
 func printHunks(cmd *cobra.Command, hunks []*diff.Hunk) {
 	for _, hunk := range hunks {
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"Gel/internal/gitbridge"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportGitLooseFlag bool
+)
+
+// exportGitCmd translates the branches of this repository into a Git repository.
+var exportGitCmd = &cobra.Command{
+	Use:   "export-git <path>",
+	Short: "Export branches and history to a Git repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := exportService.Export(args[0], gitbridge.ExportOptions{Loose: exportGitLooseFlag})
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Exported %d objects\n", result.ObjectsExported)
+		for _, updated := range result.Updated {
+			if updated.OldHash.IsEmpty() {
+				cmd.Printf(" * [new branch]      %s\n", updated.Name)
+			} else {
+				cmd.Printf(
+					"   %s..%s  %s\n",
+					updated.OldHash.String()[:7], updated.NewHash.String()[:7], updated.Name,
+				)
+			}
+		}
+		for _, skipped := range result.Skipped {
+			cmd.Printf(" ! [skipped]         %s (checked out)\n", skipped)
+		}
+		return nil
+	},
+}
+
+// init registers the export-git command and its flags.
+func init() {
+	exportGitCmd.Flags().BoolVar(
+		&exportGitLooseFlag, "loose", false,
+		"Write loose objects instead of a pack",
+	)
+	rootCmd.AddCommand(exportGitCmd)
+}
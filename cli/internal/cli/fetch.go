@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"Gel/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fetchDepthFlag  int
+	fetchFilterFlag string
+)
+
+// fetchCmd downloads objects and branches from a configured remote or a bundle file.
+var fetchCmd = &cobra.Command{
+	Use:   "fetch <remote|bundle-file>",
+	Short: "Download objects and refs from another repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := remote.ParseObjectFilter(fetchFilterFlag)
+		if err != nil {
+			return err
+		}
+
+		result, err := fetchService.Fetch(
+			args[0], remote.FetchOptions{
+				Depth:  fetchDepthFlag,
+				Filter: filter,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(result.Updated) > 0 {
+			cmd.Printf("From %s\n", result.URL)
+		}
+		for _, updated := range result.Updated {
+			if updated.OldHash.IsEmpty() {
+				cmd.Printf(" * [new branch]      %s\n", updated.Ref)
+			} else {
+				cmd.Printf(
+					"   %s..%s  %s\n",
+					updated.OldHash.String()[:7], updated.NewHash.String()[:7], updated.Ref,
+				)
+			}
+		}
+		return nil
+	},
+}
+
+// init registers the fetch command and its flags.
+func init() {
+	fetchCmd.Flags().IntVar(
+		&fetchDepthFlag, "depth", 0,
+		"Limit fetched history to the given number of commits per branch",
+	)
+	fetchCmd.Flags().StringVar(
+		&fetchFilterFlag, "filter", "",
+		"Omit objects from the fetch (supported: blob:none)",
+	)
+	rootCmd.AddCommand(fetchCmd)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"Gel/internal/inspect"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// fsckCmd verifies connectivity and integrity of reachable objects.
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Verify the connectivity and validity of objects in the database",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := fsckService.Fsck()
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range result.Problems {
+			cmd.Println(problem)
+		}
+		cmd.Printf("Checked %d objects", result.ObjectsChecked)
+		if result.PromisedMissing > 0 {
+			cmd.Printf(", %d promised blobs not present locally", result.PromisedMissing)
+		}
+		cmd.Println()
+
+		if len(result.Problems) > 0 {
+			return fmt.Errorf("fsck: %w: %d problem(s) found", inspect.ErrRepositoryCorrupt, len(result.Problems))
+		}
+		return nil
+	},
+}
+
+// init registers the fsck command.
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"Gel/internal/fsmonitor"
+
+	"github.com/spf13/cobra"
+)
+
+// fsmonitorDaemonCmd groups the built-in filesystem monitor subcommands.
+var fsmonitorDaemonCmd = &cobra.Command{
+	Use:   fsmonitor.DaemonCommandName,
+	Short: "Run the built-in filesystem monitor used when core.fsmonitor is true",
+}
+
+// fsmonitorDaemonStartCmd starts the daemon in the background.
+var fsmonitorDaemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the daemon in the background",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := fsmonitorService.Start(); err != nil {
+			return err
+		}
+		cmd.Printf("fsmonitor-daemon is watching '%s'\n", workspace.RepoDir)
+		return nil
+	},
+}
+
+// fsmonitorDaemonRunCmd runs the daemon in the foreground.
+var fsmonitorDaemonRunCmd = &cobra.Command{
+	Use:   fsmonitor.DaemonRunCommandName,
+	Short: "Run the daemon in the foreground",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fsmonitorService.Run()
+	},
+}
+
+// fsmonitorDaemonStopCmd stops a running daemon.
+var fsmonitorDaemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fsmonitorService.Stop()
+	},
+}
+
+// fsmonitorDaemonStatusCmd reports whether a daemon is running.
+var fsmonitorDaemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is watching the repository",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !fsmonitorService.Running() {
+			cmd.Printf("fsmonitor-daemon is not watching '%s'\n", workspace.RepoDir)
+			return nil
+		}
+		cmd.Printf("fsmonitor-daemon is watching '%s'\n", workspace.RepoDir)
+		return nil
+	},
+}
+
+// init registers the fsmonitor--daemon command and its subcommands.
+func init() {
+	fsmonitorDaemonCmd.AddCommand(
+		fsmonitorDaemonStartCmd, fsmonitorDaemonRunCmd, fsmonitorDaemonStopCmd, fsmonitorDaemonStatusCmd,
+	)
+	rootCmd.AddCommand(fsmonitorDaemonCmd)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"Gel/internal/domain"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// importGitCmd translates the branches of a Git repository into this repository.
+var importGitCmd = &cobra.Command{
+	Use:   "import-git <path>",
+	Short: "Import branches and history from a Git repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := importService.Import(args[0])
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Imported %d objects\n", result.ObjectsImported)
+		for _, updated := range result.Updated {
+			if updated.OldHash.IsEmpty() {
+				cmd.Printf(" * [new branch]      %s\n", updated.Name)
+			} else {
+				cmd.Printf(
+					"   %s..%s  %s\n",
+					updated.OldHash.String()[:7], updated.NewHash.String()[:7], updated.Name,
+				)
+			}
+		}
+		for _, skipped := range result.Skipped {
+			cmd.Printf(" ! [skipped]         %s (checked out)\n", skipped)
+		}
+		if result.HeadBranch != "" {
+			cmd.Printf("HEAD is now on branch '%s'\n", result.HeadBranch)
+		}
+
+		if result.CheckoutHash.IsEmpty() {
+			return nil
+		}
+		conflicts, err := switchService.FindOverwriteConflicts(domain.Hash{}, result.CheckoutHash)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("import-git: untracked file '%s' would be overwritten by checkout", conflicts[0])
+		}
+		if err := switchService.CheckoutWorkingTree(domain.Hash{}, result.CheckoutHash); err != nil {
+			return err
+		}
+		commit, err := objectService.ReadCommit(result.CheckoutHash)
+		if err != nil {
+			return err
+		}
+		return readTreeService.ReadTree(commit.TreeHash)
+	},
+}
+
+// init registers the import-git command.
+func init() {
+	rootCmd.AddCommand(importGitCmd)
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	initBareFlag bool
+)
+
 var initCmd = &cobra.Command{
 	Use:   "init [path]",
 	Short: "Initialize a new Gel repository",
@@ -16,7 +20,7 @@ var initCmd = &cobra.Command{
 			path = args[0]
 		}
 
-		message, err := setup.NewInitService().Init(path)
+		message, err := setup.NewInitService().Init(path, setup.InitOptions{Bare: initBareFlag})
 		if err != nil {
 			return err
 		}
@@ -27,5 +31,6 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
+	initCmd.Flags().BoolVar(&initBareFlag, "bare", false, "Create a bare repository without a working tree")
 	rootCmd.AddCommand(initCmd)
 }
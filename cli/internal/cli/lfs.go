@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"Gel/internal/lfs"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lfsFetchAllFlag    bool
+	lfsPushAllFlag     bool
+	lfsPruneDryRunFlag bool
+)
+
+// lfsCmd groups the large file storage subcommands.
+var lfsCmd = &cobra.Command{
+	Use:   "lfs",
+	Short: "Manage large files stored outside the object database",
+}
+
+// lfsLsFilesCmd lists paths whose blobs are large file pointers.
+var lfsLsFilesCmd = &cobra.Command{
+	Use:   "ls-files [<revision>]",
+	Short: "List large files in the index or a commit",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var revision string
+		if len(args) > 0 {
+			revision = args[0]
+		}
+		files, err := lfsService.LsFiles(revision)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			marker := "-"
+			if file.Present {
+				marker = "*"
+			}
+			cmd.Printf("%s %s %s\n", file.Pointer.OID.Hex()[:10], marker, file.Path)
+		}
+		return nil
+	},
+}
+
+// lfsFetchCmd downloads missing payloads from the remote store.
+var lfsFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Download large files referenced by HEAD from the remote store",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := lfsService.Fetch(lfs.FetchOptions{All: lfsFetchAllFlag})
+		if result != nil {
+			for _, pointer := range result.Fetched {
+				cmd.Printf("fetched %s (%d bytes)\n", pointer.OID, pointer.Size)
+			}
+			for _, pointer := range result.Missing {
+				cmd.Printf("missing %s\n", pointer.OID)
+			}
+			for _, path := range result.Updated {
+				cmd.Printf("updated '%s'\n", path)
+			}
+		}
+		return err
+	},
+}
+
+// lfsPushCmd uploads local payloads to the remote store.
+var lfsPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload large files referenced by the history of HEAD to the remote store",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := lfsService.Push(lfs.PushOptions{All: lfsPushAllFlag})
+		if result != nil {
+			for _, pointer := range result.Pushed {
+				cmd.Printf("pushed %s (%d bytes)\n", pointer.OID, pointer.Size)
+			}
+			for _, pointer := range result.Missing {
+				cmd.Printf("missing %s\n", pointer.OID)
+			}
+		}
+		return err
+	},
+}
+
+// lfsPruneCmd deletes local payloads that are no longer needed.
+var lfsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete local large files not referenced by the index or branch tips",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := lfsService.Prune(lfs.PruneOptions{DryRun: lfsPruneDryRunFlag})
+		if err != nil {
+			return err
+		}
+
+		verb := "pruned"
+		if lfsPruneDryRunFlag {
+			verb = "would prune"
+		}
+		for _, pointer := range result.Pruned {
+			cmd.Printf("%s %s (%d bytes)\n", verb, pointer.OID, pointer.Size)
+		}
+		cmd.Printf("%d object(s) %s, %d retained\n", len(result.Pruned), verb, result.Retained)
+		return nil
+	},
+}
+
+// init registers the lfs command, its subcommands and flags.
+func init() {
+	lfsFetchCmd.Flags().BoolVar(
+		&lfsFetchAllFlag, "all", false,
+		"Fetch large files for every local branch",
+	)
+	lfsPushCmd.Flags().BoolVar(
+		&lfsPushAllFlag, "all", false,
+		"Push large files for every local branch",
+	)
+	lfsPruneCmd.Flags().BoolVarP(
+		&lfsPruneDryRunFlag, "dry-run", "n", false,
+		"Only report the large files that would be deleted",
+	)
+	lfsCmd.AddCommand(lfsLsFilesCmd, lfsFetchCmd, lfsPushCmd, lfsPruneCmd)
+	rootCmd.AddCommand(lfsCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"Gel/internal/commit"
 	"Gel/internal/core"
 	"Gel/internal/domain"
+	"errors"
 
 	"github.com/spf13/cobra"
 )
@@ -13,6 +14,8 @@ var (
 	logOnelineFlag bool
 	logSinceFlag   string
 	logUntilFlag   string
+
+	logShowSignatureFlag bool
 )
 
 // logCmd prints commit history starting from HEAD or a provided revision.
@@ -47,14 +50,17 @@ var logCmd = &cobra.Command{
 			}
 		} else {
 			for _, entry := range entries {
-				cmd.Printf(
-					"%scommit %s%s\nDate:   %s\n\n    %s\n\n",
-					core.ColorGreen,
-					entry.Hash.String(),
-					core.ColorReset,
-					entry.Date,
-					entry.Message,
-				)
+				cmd.Printf("%scommit %s%s\n", core.ColorGreen, entry.Hash.String(), core.ColorReset)
+				if logShowSignatureFlag {
+					verification, err := verifyService.VerifyHash(entry.Hash)
+					for _, line := range formatSignature(verification, err) {
+						cmd.Println(line)
+					}
+					if err != nil && !errors.Is(err, core.ErrNoSignature) {
+						cmd.Printf("error: %v\n", err)
+					}
+				}
+				cmd.Printf("Date:   %s\n\n    %s\n\n", entry.Date, entry.Message)
 			}
 		}
 		return nil
@@ -78,5 +84,9 @@ func init() {
 		&logUntilFlag, "until", "U", "",
 		"Only commits before (inclusive) this date",
 	)
+	logCmd.Flags().BoolVar(
+		&logShowSignatureFlag, "show-signature", false,
+		"Check the signature of each signed commit",
+	)
 	rootCmd.AddCommand(logCmd)
 }
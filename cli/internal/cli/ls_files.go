@@ -11,6 +11,9 @@ var (
 	lsFilesCachedFlag   bool
 	lsFilesDeletedFlag  bool
 	lsFilesModifiedFlag bool
+	lsFilesOthersFlag   bool
+	lsFilesIgnoredFlag  bool
+	lsFilesTagsFlag     bool
 )
 var lsFilesCmd = &cobra.Command{
 	Use:   "ls-files",
@@ -21,7 +24,7 @@ var lsFilesCmd = &cobra.Command{
 		if len(args) > 0 {
 			pathspec = args[0]
 		}
-		if !lsFilesStageFlag && !lsFilesModifiedFlag && !lsFilesDeletedFlag {
+		if !lsFilesStageFlag && !lsFilesModifiedFlag && !lsFilesDeletedFlag && !lsFilesOthersFlag {
 			lsFilesCachedFlag = true
 		}
 		files, err := lsFilesService.LsFiles(
@@ -31,6 +34,9 @@ var lsFilesCmd = &cobra.Command{
 				Stage:    lsFilesStageFlag,
 				Modified: lsFilesModifiedFlag,
 				Deleted:  lsFilesDeletedFlag,
+				Others:   lsFilesOthersFlag,
+				Ignored:  lsFilesIgnoredFlag,
+				Tags:     lsFilesTagsFlag,
 			},
 		)
 		if err != nil {
@@ -57,5 +63,15 @@ func init() {
 	lsFilesCmd.Flags().BoolVarP(
 		&lsFilesDeletedFlag, "deleted", "d", false, "Show deleted files",
 	)
+	lsFilesCmd.Flags().BoolVarP(
+		&lsFilesOthersFlag, "others", "o", false, "Show untracked files",
+	)
+	lsFilesCmd.Flags().BoolVarP(
+		&lsFilesIgnoredFlag, "ignored", "i", false, "Show only ignored files (with --others)",
+	)
+	lsFilesCmd.Flags().BoolVarP(
+		&lsFilesTagsFlag, "tags", "v", false,
+		"Prefix each file with a status tag, lowercase for assume-unchanged files",
+	)
 	rootCmd.AddCommand(lsFilesCmd)
 }
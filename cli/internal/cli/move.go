@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"Gel/internal/staging"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	moveForceFlag   bool
+	moveDryRunFlag  bool
+	moveVerboseFlag bool
+)
+
+// moveCmd renames tracked files and directories in the working tree and the index.
+var moveCmd = &cobra.Command{
+	Use:   "mv <source>... <destination>",
+	Short: "Move or rename a file or directory",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := moveService.Move(
+			args[:len(args)-1], args[len(args)-1], staging.MoveOptions{
+				Force:  moveForceFlag,
+				DryRun: moveDryRunFlag,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if moveVerboseFlag || moveDryRunFlag {
+			for _, move := range result.Moves {
+				cmd.Printf("Renaming %s to %s\n", move.Source, move.Destination)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	moveCmd.Flags().BoolVarP(
+		&moveForceFlag, "force", "f", false,
+		"Overwrite existing destination files",
+	)
+	moveCmd.Flags().BoolVarP(
+		&moveDryRunFlag, "dry-run", "n", false,
+		"Show what would be moved without actually moving",
+	)
+	moveCmd.Flags().BoolVarP(
+		&moveVerboseFlag, "verbose", "v", false,
+		"Report the names of files as they are moved",
+	)
+	rootCmd.AddCommand(moveCmd)
+}
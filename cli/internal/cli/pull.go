@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"Gel/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pullFastForwardOnlyFlag bool
+	pullMergeFlag           bool
+	pullRebaseFlag          bool
+	pullNoVerifyFlag        bool
+)
+
+// pullCmd fetches the current branch's upstream and integrates it.
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Fetch from and integrate with the upstream branch",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := remote.PullOptions{Mode: remote.PullModeUnspecified, NoVerify: pullNoVerifyFlag}
+		switch {
+		case pullFastForwardOnlyFlag:
+			options.Mode = remote.PullModeFastForwardOnly
+		case pullMergeFlag:
+			options.Mode = remote.PullModeMerge
+		case pullRebaseFlag:
+			options.Mode = remote.PullModeRebase
+		}
+
+		result, err := pullService.Pull(options)
+		if err != nil {
+			return err
+		}
+
+		switch result.Outcome {
+		case remote.PullOutcomeUpToDate:
+			cmd.Println("Already up to date.")
+		case remote.PullOutcomeFastForward:
+			if result.OldHash.IsEmpty() {
+				cmd.Printf("Fast-forward to %s\n", result.NewHash.String()[:7])
+			} else {
+				cmd.Printf(
+					"Updating %s..%s\nFast-forward\n",
+					result.OldHash.String()[:7], result.NewHash.String()[:7],
+				)
+			}
+		case remote.PullOutcomeMerged:
+			cmd.Printf("Merge made by the 'path' strategy: %s\n", result.NewHash.String()[:7])
+		case remote.PullOutcomeRebased:
+			cmd.Printf("Successfully rebased and updated refs/heads/%s.\n", result.Branch)
+		}
+		return nil
+	},
+}
+
+// init registers the pull command and its flags.
+func init() {
+	pullCmd.Flags().BoolVar(
+		&pullFastForwardOnlyFlag, "ff-only", false,
+		"Refuse to pull unless the branch can be fast-forwarded",
+	)
+	pullCmd.Flags().BoolVar(
+		&pullMergeFlag, "merge", false,
+		"Create a merge commit when histories diverged",
+	)
+	pullCmd.Flags().BoolVar(
+		&pullRebaseFlag, "rebase", false,
+		"Replay local commits on top of the upstream branch",
+	)
+	pullCmd.Flags().BoolVar(
+		&pullNoVerifyFlag, "no-verify", false,
+		"Bypass the pre-merge-commit hook",
+	)
+	pullCmd.MarkFlagsMutuallyExclusive("ff-only", "merge", "rebase")
+	rootCmd.AddCommand(pullCmd)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"Gel/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pushForceFlag    bool
+	pushNoVerifyFlag bool
+)
+
+// pushCmd uploads a local branch to a configured remote.
+var pushCmd = &cobra.Command{
+	Use:   "push [<remote> [<branch>]]",
+	Short: "Update a remote branch with local commits",
+	Long: "Copy the commits of a local branch to a remote repository and move the\n" +
+		"remote branch of the same name to them. Without arguments the current branch\n" +
+		"is pushed to branch.<name>.remote, or origin when unset.\n\n" +
+		"The remote branch must be an ancestor of the pushed commit unless --force is\n" +
+		"given, and a branch checked out in a working tree of the remote is refused.\n\n" +
+		"The pre-push hook runs before the remote is written, with the remote name and\n" +
+		"URL as arguments and '<local ref> <local hash> <remote ref> <remote hash>' on\n" +
+		"standard input; it aborts the push by exiting non-zero. --no-verify skips it.",
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var remoteName, branchName string
+		if len(args) > 0 {
+			remoteName = args[0]
+		}
+		if len(args) > 1 {
+			branchName = args[1]
+		}
+
+		result, err := pushService.Push(
+			remoteName, branchName, remote.PushOptions{
+				Force:    pushForceFlag,
+				NoVerify: pushNoVerifyFlag,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if result.UpToDate() {
+			cmd.Println("Everything up-to-date")
+			return nil
+		}
+		cmd.Printf("To %s\n", result.URL)
+		switch {
+		case result.OldHash.IsEmpty():
+			cmd.Printf(" * [new branch]      %s\n", result.Ref)
+		case result.Forced:
+			cmd.Printf(
+				" + %s...%s %s (forced update)\n",
+				result.OldHash.String()[:7], result.NewHash.String()[:7], result.Ref,
+			)
+		default:
+			cmd.Printf(
+				"   %s..%s  %s\n",
+				result.OldHash.String()[:7], result.NewHash.String()[:7], result.Ref,
+			)
+		}
+		return nil
+	},
+}
+
+// init registers the push command and its flags.
+func init() {
+	pushCmd.Flags().BoolVarP(
+		&pushForceFlag, "force", "f", false,
+		"Update the remote branch even when the push is not a fast-forward",
+	)
+	pushCmd.Flags().BoolVar(
+		&pushNoVerifyFlag, "no-verify", false,
+		"Bypass the pre-push hook",
+	)
+	rootCmd.AddCommand(pushCmd)
+}
@@ -2,7 +2,11 @@ package cli
 
 import (
 	"Gel/internal"
+	"Gel/internal/diff"
+	"Gel/internal/domain"
+	"Gel/internal/inspect"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -11,6 +15,7 @@ var (
 	resetHardFlag  bool
 	resetSoftFlag  bool
 	resetMixedFlag bool
+	resetPatchFlag bool
 )
 
 func init() {
@@ -26,14 +31,25 @@ func init() {
 		&resetHardFlag, "hard", "H", false,
 		"Move HEAD, reset index, and discard working tree changes",
 	)
+	resetCmd.Flags().BoolVarP(
+		&resetPatchFlag, "patch", "p", false,
+		"Interactively choose hunks to unstage (answers are read from stdin)",
+	)
 	rootCmd.AddCommand(resetCmd)
 }
 
 var resetCmd = &cobra.Command{
-	Use:   "reset [target]",
+	Use:   "reset [target] | reset -p [target] [--] [path...]",
 	Short: "Reset the current HEAD to a specified state",
-	Args:  cobra.MaximumNArgs(1),
+	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if resetPatchFlag {
+			return runResetPatch(cmd, args)
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("reset: too many arguments")
+		}
+
 		mode, err := parseResetMode()
 		if err != nil {
 			return err
@@ -53,6 +69,54 @@ var resetCmd = &cobra.Command{
 	},
 }
 
+// runResetPatch unstages hunks by restoring them in the index from the target
+// commit (HEAD by default). The first argument is taken as the target when it
+// precedes "--" or names a commit rather than an existing path.
+func runResetPatch(cmd *cobra.Command, args []string) error {
+	if resetSoftFlag || resetMixedFlag || resetHardFlag {
+		return fmt.Errorf("reset: --patch is incompatible with --soft, --mixed and --hard")
+	}
+
+	target, paths := "", args
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		if dash > 1 {
+			return fmt.Errorf("reset: too many arguments before '--'")
+		}
+		if dash == 1 {
+			target = args[0]
+		}
+		paths = args[dash:]
+	} else if len(args) > 0 {
+		if _, statErr := os.Stat(args[0]); statErr != nil {
+			if _, err := commitResolver.Resolve(args[0]); err == nil {
+				target, paths = args[0], args[1:]
+			}
+		}
+	}
+
+	options := inspect.RestoreOptions{Mode: inspect.RestoreModeHEADVsIndex}
+	if target != "" {
+		commitHash, err := commitResolver.Resolve(target)
+		if err != nil {
+			return fmt.Errorf("reset: %w", err)
+		}
+		options = inspect.RestoreOptions{Mode: inspect.RestoreModeCommitVsIndex, Source: commitHash.Hex()}
+	}
+
+	var absolutePaths []domain.AbsolutePath
+	for _, path := range paths {
+		absolutePath, err := domain.NewAbsolutePath(path)
+		if err != nil {
+			return err
+		}
+		absolutePaths = append(absolutePaths, absolutePath)
+	}
+	selector := diff.NewPatchSelector(
+		cmd.InOrStdin(), cmd.OutOrStderr(), "Unstage", diff.PatchDirectionReverse, editor.Edit,
+	)
+	return restoreService.RestorePatch(absolutePaths, options, selector)
+}
+
 func parseResetMode() (internal.ResetMode, error) {
 	selected := 0
 	mode := internal.ResetModeMixed
@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"Gel/internal/diff"
 	"Gel/internal/domain"
 	"Gel/internal/inspect"
+	"fmt"
 
 	"github.com/spf13/cobra"
 )
@@ -10,14 +12,19 @@ import (
 var (
 	restoreStageFlag  bool
 	restoreSourceFlag string
+	restorePatchFlag  bool
 )
 
 // restoreCmd restores paths in the working tree or index depending on flags.
 var restoreCmd = &cobra.Command{
-	Use:   "restore",
+	Use:   "restore <path>...",
 	Short: "Restore working tree files",
-	Args:  cobra.MinimumNArgs(1),
+	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !restorePatchFlag {
+			return fmt.Errorf("restore: you must specify path(s) to restore")
+		}
+
 		var options inspect.RestoreOptions
 		switch {
 		case restoreStageFlag && restoreSourceFlag != "":
@@ -48,6 +55,16 @@ var restoreCmd = &cobra.Command{
 			}
 			absolutePaths = append(absolutePaths, absolutePath)
 		}
+		if restorePatchFlag {
+			action := "Discard"
+			if restoreStageFlag {
+				action = "Unstage"
+			}
+			selector := diff.NewPatchSelector(
+				cmd.InOrStdin(), cmd.OutOrStderr(), action, diff.PatchDirectionReverse, editor.Edit,
+			)
+			return restoreService.RestorePatch(absolutePaths, options, selector)
+		}
 		return restoreService.Restore(absolutePaths, options)
 	},
 }
@@ -62,5 +79,9 @@ func init() {
 		&restoreSourceFlag, "source", "S", "",
 		"Restore from specified source",
 	)
+	restoreCmd.Flags().BoolVarP(
+		&restorePatchFlag, "patch", "p", false,
+		"Interactively choose hunks to restore (answers are read from stdin)",
+	)
 	rootCmd.AddCommand(restoreCmd)
 }
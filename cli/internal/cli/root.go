@@ -3,16 +3,27 @@ package cli
 import (
 	"Gel/internal"
 	"Gel/internal/branch"
+	"Gel/internal/bundle"
 	"Gel/internal/commit"
 	"Gel/internal/core"
 	"Gel/internal/diff"
 	"Gel/internal/domain"
+	"Gel/internal/fsmonitor"
+	"Gel/internal/gitbridge"
 	"Gel/internal/inspect"
+	"Gel/internal/lfs"
+	"Gel/internal/merge"
+	"Gel/internal/remote"
+	"Gel/internal/sparse"
 	"Gel/internal/staging"
 	"Gel/internal/storage"
+	"Gel/internal/submodule"
+	"Gel/internal/tag"
 	"Gel/internal/tree"
+	"Gel/internal/worktree"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -28,7 +39,17 @@ var (
 	hashObjectService *core.HashObjectService
 	treeResolver      *core.TreeResolver
 	pathResolver      *core.PathResolver
+	ignoreMatcher     *core.IgnoreMatcher
+	worktreeRegistry  *core.WorktreeRegistry
+	attributeMatcher  *core.AttributeMatcher
+	lfsStore          *core.LFSStore
+	contentFilter     *core.ContentFilter
+	editor            *core.Editor
 	changeDetector    *core.ChangeDetector
+	shallowService    *core.ShallowService
+	sparseCheckout    *core.SparseCheckout
+	fsMonitor         *core.FSMonitor
+	hookRunner        *core.HookRunner
 )
 
 var (
@@ -44,22 +65,71 @@ var (
 	updateRefService   *core.UpdateRefService
 	commitService      *commit.CommitService
 	logService         *commit.LogService
+	verifyService      *commit.VerifyService
 	branchService      *branch.BranchService
+	tagService         *tag.TagService
 	restoreService     *inspect.RestoreService
 	removeService      *staging.RemoveService
+	moveService        *staging.MoveService
+	cleanService       *staging.CleanService
 	switchService      *branch.SwitchService
 	statusService      *inspect.StatusService
 	diffService        *diff.DiffService
 	showService        *inspect.ShowService
 	commitResolver     *core.CommitResolver
 	resetService       *internal.ResetService
+	mergeService       *merge.MergeService
+	fetchService       *remote.FetchService
+	pullService        *remote.PullService
+	pushService        *remote.PushService
+	fsckService        *inspect.FsckService
+	importService      *gitbridge.ImportService
+	exportService      *gitbridge.ExportService
+	bundleService      *bundle.BundleService
+	checkIgnoreService *inspect.CheckIgnoreService
+	lfsService         *lfs.LFSService
+	sparseService      *sparse.SparseCheckoutService
+	fsmonitorService   *fsmonitor.DaemonService
+	worktreeService    *worktree.WorktreeService
+	submoduleService   *submodule.SubmoduleService
 
 	isServicesInitialized bool
 )
 
 var commandsWithoutRepository = map[string]bool{
-	"init": true,
-	"help": true,
+	"init":  true,
+	"clone": true,
+	"help":  true,
+}
+
+// commandsRequiringWorkTree lists commands that read or modify working tree
+// files and therefore fail in bare repositories. Subcommands are keyed by
+// their path below the root command.
+var commandsRequiringWorkTree = map[string]bool{
+	"add":                     true,
+	"check-ignore":            true,
+	"clean":                   true,
+	"commit":                  true,
+	"diff":                    true,
+	"fsmonitor--daemon run":   true,
+	"fsmonitor--daemon start": true,
+	"lfs fetch":               true,
+	"mv":                      true,
+	"pull":                    true,
+	"reset":                   true,
+	"restore":                 true,
+	"rm":                      true,
+	"sparse-checkout add":     true,
+	"sparse-checkout disable": true,
+	"sparse-checkout set":     true,
+	"status":                  true,
+	"submodule add":           true,
+	"submodule foreach":       true,
+	"submodule init":          true,
+	"submodule status":        true,
+	"submodule update":        true,
+	"switch":                  true,
+	"update-index":            true,
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -67,26 +137,79 @@ var rootCmd = &cobra.Command{
 	Use:   "gel",
 	Short: "An Agentic Version Control System",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if commandsWithoutRepository[cmd.Name()] {
+		if commandsWithoutRepository[commandKey(cmd)] {
 			return nil
 		}
-		return initializeServices()
+		if err := initializeServices(); err != nil {
+			return err
+		}
+		if commandsRequiringWorkTree[commandKey(cmd)] {
+			if err := workspace.RequireWorkTree(); err != nil {
+				return fmt.Errorf("%s: %w", commandKey(cmd), err)
+			}
+		}
+		return nil
 	},
 }
 
+// commandKey returns the path of cmd below the root command, such as "worktree add".
+func commandKey(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() int {
 	rootCmd.SilenceErrors = true
 	rootCmd.SilenceUsage = true
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	reportFSMonitorHookErrors(rootCmd)
+	reportMissingLFSObjects(rootCmd)
+	reportHooks(rootCmd)
+	if err != nil {
 		fmt.Fprintf(rootCmd.ErrOrStderr(), "error: %v\n", err)
 		return 1
 	}
 	return 0
 }
 
+// reportFSMonitorHookErrors warns about fsmonitor hook failures that made
+// the command scan the whole working tree.
+func reportFSMonitorHookErrors(cmd *cobra.Command) {
+	if fsMonitor == nil {
+		return
+	}
+	for _, err := range fsMonitor.TakeHookErrors() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+	}
+}
+
+// reportHooks hints at hooks skipped because they are not executable and
+// warns about failed post-hooks.
+func reportHooks(cmd *cobra.Command) {
+	for _, name := range hookRunner.TakeIgnoredHooks() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "hint: the '%s' hook was ignored because it is not set as executable\n", name)
+	}
+	for _, err := range hookRunner.TakePostHookErrors() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+	}
+}
+
+// reportMissingLFSObjects warns about large files checked out as pointers
+// because their payload was not available.
+func reportMissingLFSObjects(cmd *cobra.Command) {
+	if contentFilter == nil {
+		return
+	}
+	for _, missing := range contentFilter.TakeMissingLFSObjects() {
+		fmt.Fprintf(
+			cmd.ErrOrStderr(), "warning: lfs object %s for '%s' is not available; checked out its pointer\n",
+			missing.OID, missing.Path,
+		)
+	}
+}
+
 // initializeServices sets up all services lazily when a command needs them
 func initializeServices() error {
 	if isServicesInitialized {
@@ -98,10 +221,22 @@ func initializeServices() error {
 		return err
 	}
 
-	workspace, err = domain.NewWorkspace(cwd)
+	var discovered *domain.Workspace
+	if gelDir, ok := os.LookupEnv(domain.GelDirEnvVar); ok && gelDir != "" {
+		discovered, err = domain.NewWorkspaceFromGelDir(gelDir)
+	} else {
+		discovered, err = domain.NewWorkspace(cwd)
+	}
 	if err != nil {
 		return err
 	}
+	initializeServicesForWorkspace(discovered)
+	return nil
+}
+
+// initializeServicesForWorkspace wires all services against an already discovered workspace.
+func initializeServicesForWorkspace(target *domain.Workspace) {
+	workspace = target
 
 	objectStorage := storage.NewObjectStorage(workspace)
 	indexStorage := storage.NewIndexStorage(workspace)
@@ -110,12 +245,25 @@ func initializeServices() error {
 	objectService = core.NewObjectService(objectStorage)
 	indexService = core.NewIndexService(indexStorage)
 	configService = core.NewConfigService(configStorage)
+	indexService.SetConfigService(configService)
+	fsMonitor = core.NewFSMonitor(configService, workspace)
+	hookRunner = core.NewHookRunner(configService, workspace)
+	indexService.SetFSMonitor(fsMonitor)
+	objectService.SetPromisorFetcher(remote.NewPromisorFetcher(objectService, configService))
+	shallowService = core.NewShallowService(workspace)
 	refService = core.NewRefService(workspace)
 	hashObjectService = core.NewHashObjectService(objectService)
-	pathResolver = core.NewPathResolver(workspace.RepoDir, nil)
+	ignoreMatcher = core.NewIgnoreMatcher(workspace, configService)
+	attributeMatcher = core.NewAttributeMatcher(workspace)
+	lfsStore = core.NewLFSStore(workspace.LFSObjectsDir.String())
+	contentFilter = core.NewContentFilter(attributeMatcher, lfsStore, configService, workspace)
+	objectService.SetContentFilter(contentFilter)
+	editor = core.NewEditor(configService)
+	pathResolver = core.NewPathResolver(workspace.RepoDir, ignoreMatcher)
 	changeDetector = core.NewChangeDetector(objectService, workspace.RepoDir)
+	indexService.SetChangeDetector(changeDetector)
 	treeResolver = core.NewTreeResolver(
-		objectService, indexService, refService, pathResolver, changeDetector, workspace,
+		objectService, indexService, refService, pathResolver, changeDetector, configService, workspace,
 	)
 	symbolicRefService = core.NewSymbolicRefService(refService)
 	updateRefService = core.NewUpdateRefService(refService)
@@ -124,30 +272,73 @@ func initializeServices() error {
 	updateIndexService = staging.NewUpdateIndexService(
 		indexService, objectService, hashObjectService, changeDetector, workspace,
 	)
-	addService = staging.NewAddService(indexService, updateIndexService, pathResolver, workspace)
-	lsFilesService = staging.NewLsFilesService(indexService, changeDetector, workspace)
+	addService = staging.NewAddService(
+		indexService, objectService, updateIndexService, pathResolver, changeDetector, workspace,
+	)
+	lsFilesService = staging.NewLsFilesService(indexService, changeDetector, pathResolver, workspace)
 	writeTreeService = tree.NewWriteTreeService(indexService, objectService)
-	readTreeService = tree.NewReadTreeService(indexService, objectService)
+	sparseCheckout = core.NewSparseCheckout(configService, workspace)
+	readTreeService = tree.NewReadTreeService(indexService, objectService, sparseCheckout)
 	lsTreeService = tree.NewLsTreeService(objectService)
 	commitTreeService = commit.NewCommitTreeService(objectService, configService)
-	commitService = commit.NewCommitService(writeTreeService, commitTreeService, refService, objectService)
-	logService = commit.NewLogService(refService, objectService)
-	branchService = branch.NewBranchService(refService, objectService, workspace)
+	signingService := core.NewSigningService(configService, workspace)
+	commitTreeService.SetSigningService(signingService)
+	logService = commit.NewLogService(refService, objectService, shallowService)
+	worktreeRegistry = core.NewWorktreeRegistry(workspace)
+	branchService = branch.NewBranchService(refService, objectService, worktreeRegistry, workspace)
 	switchService = branch.NewSwitchService(
-		refService, branchService, objectService, readTreeService, treeResolver, workspace,
+		refService, branchService, objectService, readTreeService, treeResolver, sparseCheckout, workspace,
 	)
+	switchService.SetHookRunner(hookRunner)
 	restoreService = inspect.NewRestoreService(
 		indexService, objectService, refService, treeResolver, changeDetector, workspace,
 	)
 	statusService = inspect.NewStatusService(indexService, objectService, branchService, treeResolver)
-	diffService = diff.NewDiffService(objectService, treeResolver, diff.NewMyersDiffAlgorithm(), workspace)
+	commitService = commit.NewCommitService(
+		writeTreeService, commitTreeService, refService, objectService, configService, statusService, workspace,
+	)
+	commitService.SetHookRunner(hookRunner)
+	diffService = diff.NewDiffService(
+		objectService, treeResolver, diff.NewMyersDiffAlgorithm(), contentFilter, workspace,
+	)
 	showService = inspect.NewShowService(objectService, refService, diffService)
 	commitResolver = core.NewCommitResolver(refService, objectService)
+	verifyService = commit.NewVerifyService(objectService, commitResolver, signingService)
+	tagService = tag.NewTagService(refService, objectService, commitResolver, commitTreeService, workspace)
+	tagService.SetSigningService(signingService)
 	resetService = internal.NewResetService(
 		refService, objectService, readTreeService, treeResolver, commitResolver, workspace,
 	)
+	resetService.SetHookRunner(hookRunner)
 	removeService = staging.NewRemoveService(indexService, treeResolver, changeDetector, workspace)
+	moveService = staging.NewMoveService(indexService, workspace)
+	cleanService = staging.NewCleanService(indexService, configService, ignoreMatcher, workspace)
+	sparseService = sparse.NewSparseCheckoutService(
+		indexService, objectService, changeDetector, sparseCheckout, workspace,
+	)
+	fsmonitorService = fsmonitor.NewDaemonService(fsMonitor, workspace)
+	worktreeService = worktree.NewWorktreeService(refService, branchService, worktreeRegistry, workspace)
+	submoduleService = submodule.NewSubmoduleService(indexService, configService, updateIndexService, workspace)
+	checkIgnoreService = inspect.NewCheckIgnoreService(indexService, ignoreMatcher, workspace)
+	fsckService = inspect.NewFsckService(objectService, refService, shallowService, configService, workspace)
+	importService = gitbridge.NewImportService(objectService, refService, workspace)
+	exportService = gitbridge.NewExportService(objectService, refService, branchService, workspace)
+	mergeService = merge.NewMergeService(objectService, writeTreeService, shallowService)
+	bundleService = bundle.NewBundleService(
+		objectService, refService, branchService, commitResolver, shallowService,
+	)
+	lfsService = lfs.NewLFSService(
+		objectService, refService, branchService, treeResolver, commitResolver,
+		shallowService, configService, lfsStore, workspace,
+	)
+	fetchService = remote.NewFetchService(objectService, refService, configService, shallowService)
+	pullService = remote.NewPullService(
+		fetchService, mergeService, switchService, branchService, commitTreeService,
+		readTreeService, refService, objectService, configService,
+	)
+	pullService.SetHookRunner(hookRunner)
+	pushService = remote.NewPushService(objectService, refService, configService, mergeService, workspace)
+	pushService.SetHookRunner(hookRunner)
 
 	isServicesInitialized = true
-	return nil
 }
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"Gel/internal/sparse"
+
+	"github.com/spf13/cobra"
+)
+
+// sparseCheckoutCmd groups the sparse-checkout subcommands.
+var sparseCheckoutCmd = &cobra.Command{
+	Use:   "sparse-checkout",
+	Short: "Restrict the working tree to a cone of directories",
+}
+
+// sparseCheckoutSetCmd replaces the cone.
+var sparseCheckoutSetCmd = &cobra.Command{
+	Use:   "set <dir>...",
+	Short: "Check out only the given directories and the files at the root",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := sparseService.Set(args)
+		if err != nil {
+			return err
+		}
+		printSparseApplyResult(cmd, result)
+		return nil
+	},
+}
+
+// sparseCheckoutAddCmd widens the cone.
+var sparseCheckoutAddCmd = &cobra.Command{
+	Use:   "add <dir>...",
+	Short: "Add directories to the sparse checkout",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := sparseService.Add(args)
+		if err != nil {
+			return err
+		}
+		printSparseApplyResult(cmd, result)
+		return nil
+	},
+}
+
+// sparseCheckoutListCmd prints the cone directories.
+var sparseCheckoutListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the directories in the sparse checkout",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dirs, err := sparseService.List()
+		if err != nil {
+			return err
+		}
+		for _, dir := range dirs {
+			cmd.Println(dir)
+		}
+		return nil
+	},
+}
+
+// sparseCheckoutDisableCmd restores the full working tree.
+var sparseCheckoutDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Turn sparse checkout off and restore every file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := sparseService.Disable()
+		if err != nil {
+			return err
+		}
+		printSparseApplyResult(cmd, result)
+		return nil
+	},
+}
+
+// printSparseApplyResult summarizes working tree updates and warns about kept files.
+func printSparseApplyResult(cmd *cobra.Command, result *sparse.ApplyResult) {
+	for _, path := range result.Kept {
+		cmd.Printf("warning: '%s' has local changes and was left in the working tree\n", path)
+	}
+	if len(result.Materialized) > 0 || len(result.Removed) > 0 {
+		cmd.Printf("Updated working tree: %d added, %d removed\n", len(result.Materialized), len(result.Removed))
+	}
+}
+
+// init registers the sparse-checkout command and its subcommands.
+func init() {
+	sparseCheckoutCmd.AddCommand(
+		sparseCheckoutSetCmd, sparseCheckoutAddCmd, sparseCheckoutListCmd, sparseCheckoutDisableCmd,
+	)
+	rootCmd.AddCommand(sparseCheckoutCmd)
+}
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"Gel/internal/core"
+	"Gel/internal/inspect"
+	"fmt"
 
 	"github.com/spf13/cobra"
 )
@@ -27,7 +29,7 @@ var statusCmd = &cobra.Command{
 			for _, staged := range result.Staged {
 				cmd.Printf(
 					"\t%s%s:  %s%s\n",
-					core.ColorGreen, staged.Status, staged.Path, core.ColorReset,
+					core.ColorGreen, staged.Status, formatStatusPath(staged), core.ColorReset,
 				)
 			}
 		}
@@ -36,7 +38,7 @@ var statusCmd = &cobra.Command{
 			for _, unstaged := range result.Unstaged {
 				cmd.Printf(
 					"\t%s:  %s%s\n",
-					unstaged.Status, unstaged.Path, core.ColorReset,
+					unstaged.Status, formatStatusPath(unstaged), core.ColorReset,
 				)
 			}
 		}
@@ -50,6 +52,14 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// formatStatusPath renders the path of a status line with its note, if any.
+func formatStatusPath(status inspect.FileStatus) string {
+	if status.Note == "" {
+		return status.Path.String()
+	}
+	return fmt.Sprintf("%s (%s)", status.Path, status.Note)
+}
+
 // init registers the status command.
 func init() {
 	rootCmd.AddCommand(statusCmd)
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"Gel/internal"
+	"Gel/internal/domain"
+	"Gel/internal/remote"
+	"Gel/internal/submodule"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	submoduleUpdateInitFlag bool
+)
+
+// submoduleCmd groups the subcommands managing submodules.
+var submoduleCmd = &cobra.Command{
+	Use:   "submodule",
+	Short: "Manage repositories embedded in the working tree",
+}
+
+// submoduleAddCmd clones a repository into the working tree and records it as a submodule.
+var submoduleAddCmd = &cobra.Command{
+	Use:   "add <repository> [<path>]",
+	Short: "Add a repository as a submodule at <path>",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		}
+		result, err := submoduleService.Add(args[0], path)
+		if err != nil {
+			return err
+		}
+		if !result.Cloned {
+			cmd.Printf("Adding existing repo at '%s' to the index\n", result.Submodule.Path)
+			return nil
+		}
+		cmd.Printf("Cloned into '%s'\n", result.Submodule.Path)
+		return withWorkspace(
+			result.Workspace, func() error {
+				return populateClone(result.CommitHash)
+			},
+		)
+	},
+}
+
+// submoduleInitCmd copies submodule URLs from .gelmodules into config.
+var submoduleInitCmd = &cobra.Command{
+	Use:   "init [<path>...]",
+	Short: "Initialize submodules so that update clones them",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		initialized, err := submoduleService.Init(args)
+		if err != nil {
+			return err
+		}
+		for _, sub := range initialized {
+			cmd.Printf("Submodule '%s' (%s) registered for path '%s'\n", sub.Name, sub.URL, sub.Path)
+		}
+		return nil
+	},
+}
+
+// submoduleUpdateCmd clones missing submodules and checks out their recorded commits.
+var submoduleUpdateCmd = &cobra.Command{
+	Use:   "update [<path>...]",
+	Short: "Clone missing submodules and check out the commits recorded in the index",
+	Long: "Clone missing submodules and check out the commits recorded in the index.\n\n" +
+		"Gel has no detached HEAD, so the branch checked out in each submodule is\n" +
+		"moved to the recorded commit. Submodules with staged or unstaged changes\n" +
+		"are refused.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if submoduleUpdateInitFlag {
+			initialized, err := submoduleService.Init(args)
+			if err != nil {
+				return err
+			}
+			for _, sub := range initialized {
+				cmd.Printf("Submodule '%s' (%s) registered for path '%s'\n", sub.Name, sub.URL, sub.Path)
+			}
+		}
+
+		targets, err := submoduleService.PrepareUpdate(args)
+		if err != nil {
+			return err
+		}
+		for _, target := range targets {
+			if target.Cloned {
+				cmd.Printf("Cloned into '%s'\n", target.Submodule.Path)
+			}
+			if err := withWorkspace(
+				target.Workspace, func() error {
+					if !target.ClonedHash.IsEmpty() {
+						if err := populateClone(target.ClonedHash); err != nil {
+							return err
+						}
+					}
+					return checkoutSubmodule(target)
+				},
+			); err != nil {
+				return err
+			}
+			cmd.Printf("Submodule path '%s': checked out '%s'\n", target.Submodule.Path, target.CommitHash)
+		}
+		return nil
+	},
+}
+
+// submoduleStatusCmd prints the commit checked out in each submodule.
+var submoduleStatusCmd = &cobra.Command{
+	Use:   "status [<path>...]",
+	Short: "Show the commit checked out in each submodule",
+	Long: "Show the commit checked out in each submodule.\n\n" +
+		"Each line is prefixed with '-' when the submodule is not checked out and\n" +
+		"with '+' when its checked-out commit differs from the one recorded in the index.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := submoduleService.Status(args)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			cmd.Println(formatSubmoduleStatus(status))
+		}
+		return nil
+	},
+}
+
+// submoduleForeachCmd runs a shell command in each checked-out submodule.
+var submoduleForeachCmd = &cobra.Command{
+	Use:   "foreach <command>...",
+	Short: "Run a shell command in each checked-out submodule",
+	Long: "Run a shell command in each checked-out submodule.\n\n" +
+		"The command sees $name, $sm_path, $sha1 and $toplevel. A command exiting\n" +
+		"with a non-zero status stops the iteration.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := submoduleService.Status(nil)
+		if err != nil {
+			return err
+		}
+		command := strings.Join(args, " ")
+		for _, status := range statuses {
+			if !status.CheckedOut {
+				continue
+			}
+			cmd.Printf("Entering '%s'\n", status.Submodule.Path)
+			if err := submoduleService.Foreach(status, command, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// checkoutSubmodule moves the submodule whose services are active to the
+// recorded commit, fetching it from the submodule's origin when missing.
+func checkoutSubmodule(target submodule.UpdateTarget) error {
+	status, err := statusService.Status()
+	if err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+	if len(status.Staged) > 0 || len(status.Unstaged) > 0 {
+		return fmt.Errorf("submodule: '%s' %w", target.Submodule.Path, submodule.ErrDirtySubmodule)
+	}
+	exists, err := objectService.Exists(target.CommitHash)
+	if err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+	if !exists {
+		if _, err := fetchService.Fetch(remote.DefaultRemoteName, remote.FetchOptions{}); err != nil {
+			return fmt.Errorf("submodule: '%s': %w", target.Submodule.Path, err)
+		}
+		if exists, err = objectService.Exists(target.CommitHash); err != nil {
+			return fmt.Errorf("submodule: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf(
+				"submodule: '%s': %s %w", target.Submodule.Path, target.CommitHash, submodule.ErrCommitNotFetched,
+			)
+		}
+	}
+	_, err = resetService.Reset(target.CommitHash.String(), internal.ResetOptions{Mode: internal.ResetModeHard})
+	if err != nil {
+		return fmt.Errorf("submodule: '%s': %w", target.Submodule.Path, err)
+	}
+	return nil
+}
+
+// populateClone checks out commitHash into the working tree and index of the
+// freshly cloned submodule whose services are active.
+func populateClone(commitHash domain.Hash) error {
+	if err := switchService.CheckoutWorkingTree(domain.Hash{}, commitHash); err != nil {
+		return err
+	}
+	commit, err := objectService.ReadCommit(commitHash)
+	if err != nil {
+		return err
+	}
+	return readTreeService.ReadTree(commit.TreeHash)
+}
+
+// formatSubmoduleStatus renders one line of submodule status output.
+func formatSubmoduleStatus(status submodule.Status) string {
+	switch {
+	case !status.CheckedOut:
+		return fmt.Sprintf("-%s %s", status.Recorded, status.Submodule.Path)
+	case status.Head != status.Recorded:
+		return fmt.Sprintf("+%s %s", status.Head, status.Submodule.Path)
+	default:
+		return fmt.Sprintf(" %s %s", status.Head, status.Submodule.Path)
+	}
+}
+
+// init registers the submodule command and its subcommands.
+func init() {
+	submoduleUpdateCmd.Flags().BoolVar(
+		&submoduleUpdateInitFlag, "init", false, "Initialize uninitialized submodules before updating",
+	)
+	submoduleCmd.AddCommand(
+		submoduleAddCmd, submoduleInitCmd, submoduleUpdateCmd, submoduleStatusCmd, submoduleForeachCmd,
+	)
+	rootCmd.AddCommand(submoduleCmd)
+}
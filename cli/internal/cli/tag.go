@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"Gel/internal/tag"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagAnnotateFlag bool
+	tagSignFlag     bool
+	tagMessageFlag  string
+	tagForceFlag    bool
+	tagDeleteFlag   bool
+)
+
+// tagCmd lists tags, creates lightweight, annotated, or signed tags, or deletes a tag with --delete.
+var tagCmd = &cobra.Command{
+	Use:   "tag [-a | -s] [-m <message>] [-f] <name> [<commit>]",
+	Short: "List, create, or delete tags",
+	Long: "Without arguments, list the tags in refs/tags. With a name, point\n" +
+		"refs/tags/<name> at <commit>, or HEAD when omitted.\n\n" +
+		"A tag is lightweight unless -a, -s, or -m is given: then the ref points at a\n" +
+		"tag object holding the message, the tagger from GEL_COMMITTER_* or user.name\n" +
+		"and user.email, and the tagged commit. -a and -s need -m. -s signs the tag\n" +
+		"object with the unencrypted ed25519 SSH private key named by user.signingkey;\n" +
+		"check it with verify-tag.\n\n" +
+		"Tags stay local: fetch, push, bundle, and export-git do not transfer them.",
+	Args: cobra.RangeArgs(0, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tagDeleteFlag {
+			if len(args) != 1 {
+				return fmt.Errorf("tag: --delete accepts exactly one tag name")
+			}
+			hash, err := tagService.Delete(args[0])
+			if err != nil {
+				return err
+			}
+			cmd.Printf("Deleted tag '%s' (was %s)\n", args[0], hash.String()[:7])
+			return nil
+		}
+		if len(args) == 0 {
+			names, err := tagService.List()
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				cmd.Println(name)
+			}
+			return nil
+		}
+
+		annotated := tagAnnotateFlag || tagSignFlag
+		if annotated && !cmd.Flags().Changed("message") {
+			return fmt.Errorf("tag: -a and -s need a message given with -m")
+		}
+		target := ""
+		if len(args) == 2 {
+			target = args[1]
+		}
+		result, err := tagService.Create(
+			args[0], target, tag.CreateOptions{
+				Message:  tagMessageFlag,
+				Annotate: annotated,
+				Sign:     tagSignFlag,
+				Force:    tagForceFlag,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if !result.OldHash.IsEmpty() && result.OldHash != result.Hash {
+			cmd.Printf("Updated tag '%s' (was %s)\n", args[0], result.OldHash.String()[:7])
+		}
+		return nil
+	},
+}
+
+// init registers the tag command and its flags.
+func init() {
+	tagCmd.Flags().BoolVarP(&tagAnnotateFlag, "annotate", "a", false, "Make an annotated tag object")
+	tagCmd.Flags().BoolVarP(&tagSignFlag, "sign", "s", false, "Make a tag object signed with user.signingkey")
+	tagCmd.Flags().StringVarP(&tagMessageFlag, "message", "m", "", "Tag message; makes an annotated tag")
+	tagCmd.Flags().BoolVarP(&tagForceFlag, "force", "f", false, "Replace an existing tag")
+	tagCmd.Flags().BoolVarP(&tagDeleteFlag, "delete", "d", false, "Delete tag")
+	tagCmd.MarkFlagsMutuallyExclusive("delete", "annotate")
+	tagCmd.MarkFlagsMutuallyExclusive("delete", "sign")
+	tagCmd.MarkFlagsMutuallyExclusive("delete", "message")
+	rootCmd.AddCommand(tagCmd)
+}
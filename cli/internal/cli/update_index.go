@@ -11,14 +11,41 @@ import (
 var (
 	updateIndexAddFlag    bool
 	updateIndexRemoveFlag bool
+
+	updateIndexRefreshFlag       bool
+	updateIndexReallyRefreshFlag bool
+
+	updateIndexAssumeUnchangedFlag   bool
+	updateIndexNoAssumeUnchangedFlag bool
+
+	updateIndexVersionFlag uint32
+	updateIndexVerboseFlag bool
 )
 
 // updateIndexCmd updates index entries directly for the provided path arguments.
 var updateIndexCmd = &cobra.Command{
 	Use:   "update-index <file>...",
 	Short: "Update the index with the current state of the working directory",
-	Args:  cobra.MinimumNArgs(1),
+	Long: "Update the index with the current state of the working directory.\n\n" +
+		"--index-version converts the index file in place to format version 2, 3 or 4.\n" +
+		"Version 4 compresses each path against the previous one. index.version sets\n" +
+		"the version of index files created later.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if updateIndexRefreshFlag || updateIndexReallyRefreshFlag || cmd.Flags().Changed("index-version") {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("index-version") {
+			if err := setIndexVersion(); err != nil {
+				return err
+			}
+			if len(args) == 0 && !updateIndexRefreshFlag && !updateIndexReallyRefreshFlag {
+				return nil
+			}
+		}
+
 		normalizedPaths := make([]domain.NormalizedPath, len(args))
 		for i, path := range args {
 			normalizedPath, err := domain.NewNormalizedPath(path, workspace.RepoDir)
@@ -31,14 +58,30 @@ var updateIndexCmd = &cobra.Command{
 		paths, err := updateIndexService.UpdateIndex(
 			normalizedPaths,
 			staging.UpdateIndexOptions{
-				Add:    updateIndexAddFlag,
-				Remove: updateIndexRemoveFlag,
-				Write:  true,
+				Add:               updateIndexAddFlag,
+				Remove:            updateIndexRemoveFlag,
+				Write:             true,
+				Refresh:           updateIndexRefreshFlag,
+				ReallyRefresh:     updateIndexReallyRefreshFlag,
+				AssumeUnchanged:   updateIndexAssumeUnchangedFlag,
+				NoAssumeUnchanged: updateIndexNoAssumeUnchangedFlag,
 			},
 		)
 		if err != nil {
 			return err
 		}
+		if updateIndexRefreshFlag || updateIndexReallyRefreshFlag {
+			for _, path := range paths {
+				fmt.Printf("%s: needs update\n", path)
+			}
+			if len(paths) > 0 {
+				return fmt.Errorf("update-index: %w", staging.ErrNeedsUpdate)
+			}
+			return nil
+		}
+		if updateIndexAssumeUnchangedFlag || updateIndexNoAssumeUnchangedFlag {
+			return nil
+		}
 		for _, path := range paths {
 			fmt.Println(path)
 		}
@@ -46,10 +89,41 @@ var updateIndexCmd = &cobra.Command{
 	},
 }
 
+// setIndexVersion converts the index to the version given by --index-version.
+func setIndexVersion() error {
+	previous, current, err := updateIndexService.SetIndexVersion(updateIndexVersionFlag)
+	if err != nil {
+		return err
+	}
+	if updateIndexVerboseFlag {
+		fmt.Printf("index-version: was %d, set to %d\n", previous, current)
+	}
+	return nil
+}
+
 func init() {
 	updateIndexCmd.Flags().BoolVarP(&updateIndexAddFlag, "add", "a", false, "Add specified files to the index")
 	updateIndexCmd.Flags().BoolVarP(
 		&updateIndexRemoveFlag, "remove", "r", false, "Remove specified files from the index",
 	)
+	updateIndexCmd.Flags().BoolVar(
+		&updateIndexRefreshFlag, "refresh", false, "Refresh the stat data of entries whose content is unchanged",
+	)
+	updateIndexCmd.Flags().BoolVar(
+		&updateIndexReallyRefreshFlag, "really-refresh", false, "Like --refresh, but re-check every entry's content",
+	)
+	updateIndexCmd.Flags().BoolVar(
+		&updateIndexAssumeUnchangedFlag, "assume-unchanged", false,
+		"Stop checking the files for changes, trusting the index entries instead",
+	)
+	updateIndexCmd.Flags().BoolVar(
+		&updateIndexNoAssumeUnchangedFlag, "no-assume-unchanged", false, "Check the files for changes again",
+	)
+	updateIndexCmd.Flags().Uint32Var(
+		&updateIndexVersionFlag, "index-version", 0, "Convert the index to format version 2, 3 or 4",
+	)
+	updateIndexCmd.Flags().BoolVarP(
+		&updateIndexVerboseFlag, "verbose", "v", false, "Report what is being changed",
+	)
 	rootCmd.AddCommand(updateIndexCmd)
 }
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"Gel/internal/core"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyCommitCmd checks the signatures of commits.
+var verifyCommitCmd = &cobra.Command{
+	Use:   "verify-commit <commit>...",
+	Short: "Check the SSH signatures of commits",
+	Long: "Check the SSH signatures of commits.\n\n" +
+		"A signature is good when it matches the commit and its key is listed in the\n" +
+		"allowed signers file named by gpg.ssh.allowedSignersFile, in the format of\n" +
+		"ssh-keygen: \"<principals> [<options>] ssh-ed25519 <base64 key>\" per line.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var failed error
+		for _, revision := range args {
+			_, verification, err := verifyService.Verify(revision)
+			for _, line := range formatSignature(verification, err) {
+				cmd.Println(line)
+			}
+			if err != nil && failed == nil {
+				failed = err
+			}
+		}
+		return failed
+	},
+}
+
+// formatSignature renders the outcome of a signature check in the style of
+// git. It returns nothing for unsigned commits and for failures that are not
+// about the signature itself.
+func formatSignature(verification *core.SignatureVerification, err error) []string {
+	if verification == nil {
+		if errors.Is(err, core.ErrBadSignature) {
+			return []string{fmt.Sprintf("Bad %q signature", core.SSHSignatureNamespace)}
+		}
+		return nil
+	}
+
+	keyType := strings.ToUpper(strings.TrimPrefix(verification.KeyType, "ssh-"))
+	key := fmt.Sprintf("%s key %s", keyType, verification.Fingerprint)
+	if verification.Principal == "" {
+		return []string{fmt.Sprintf("Good %q signature with %s", core.SSHSignatureNamespace, key)}
+	}
+	return []string{
+		fmt.Sprintf("Good %q signature for %s with %s", core.SSHSignatureNamespace, verification.Principal, key),
+	}
+}
+
+// init registers the verify-commit command.
+func init() {
+	rootCmd.AddCommand(verifyCommitCmd)
+}
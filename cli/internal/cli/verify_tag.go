@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// verifyTagCmd checks the signatures of annotated tags.
+var verifyTagCmd = &cobra.Command{
+	Use:   "verify-tag <tag>...",
+	Short: "Check the SSH signatures of tags",
+	Long: "Check the SSH signatures of annotated tags made with tag -s.\n\n" +
+		"A signature is good when it matches the tag object and its key is listed in\n" +
+		"the allowed signers file named by gpg.ssh.allowedSignersFile, as for\n" +
+		"verify-commit. The key must be allowed at the tagger date.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var failed error
+		for _, name := range args {
+			_, verification, err := tagService.Verify(name)
+			for _, line := range formatSignature(verification, err) {
+				cmd.Println(line)
+			}
+			if err != nil && failed == nil {
+				failed = err
+			}
+		}
+		return failed
+	},
+}
+
+// init registers the verify-tag command.
+func init() {
+	rootCmd.AddCommand(verifyTagCmd)
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/worktree"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	worktreeAddBranchFlag    string
+	worktreeAddForceFlag     bool
+	worktreeRemoveForceFlag  bool
+	worktreePruneDryRunFlag  bool
+	worktreePruneVerboseFlag bool
+)
+
+// worktreeCmd groups the subcommands managing linked working trees.
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Manage multiple working trees attached to this repository",
+}
+
+// worktreeAddCmd creates a linked worktree and checks out a branch in it.
+var worktreeAddCmd = &cobra.Command{
+	Use:   "add <path> [<branch>]",
+	Short: "Create a working tree at <path> and check out a branch in it",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startPoint := ""
+		if len(args) > 1 {
+			startPoint = args[1]
+		}
+		result, err := worktreeService.Add(
+			args[0], startPoint, worktree.AddOptions{
+				NewBranch: worktreeAddBranchFlag,
+				Force:     worktreeAddForceFlag,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if result.Created {
+			cmd.Printf("Preparing worktree (new branch '%s')\n", result.Branch)
+		} else {
+			cmd.Printf("Preparing worktree (checking out '%s')\n", result.Branch)
+		}
+
+		return withWorkspace(
+			result.Workspace, func() error {
+				if err := switchService.CheckoutWorkingTree(domain.Hash{}, result.CommitHash); err != nil {
+					return err
+				}
+				commit, err := objectService.ReadCommit(result.CommitHash)
+				if err != nil {
+					return err
+				}
+				return readTreeService.ReadTree(commit.TreeHash)
+			},
+		)
+	},
+}
+
+// worktreeListCmd prints every worktree with its HEAD.
+var worktreeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the working trees",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		worktrees, err := worktreeService.List()
+		if err != nil {
+			return err
+		}
+		for _, worktree := range worktrees {
+			cmd.Println(formatWorktree(worktree))
+		}
+		return nil
+	},
+}
+
+// worktreeRemoveCmd deletes a linked worktree.
+var worktreeRemoveCmd = &cobra.Command{
+	Use:   "remove <worktree>",
+	Short: "Remove a working tree and its metadata",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, err := worktreeService.Find(args[0])
+		if err != nil {
+			return err
+		}
+		if !worktreeRemoveForceFlag && !target.Prunable {
+			if err := checkWorktreeClean(target); err != nil {
+				return err
+			}
+		}
+		return worktreeService.Remove(target)
+	},
+}
+
+// worktreePruneCmd drops the metadata of worktrees whose directory is gone.
+var worktreePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Prune metadata of working trees that no longer exist",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pruned, err := worktreeService.Prune(worktreePruneDryRunFlag)
+		if err != nil {
+			return err
+		}
+		if worktreePruneVerboseFlag || worktreePruneDryRunFlag {
+			for _, worktree := range pruned {
+				cmd.Printf("Removing worktrees/%s: gel directory points to non-existent location\n", worktree.Name)
+			}
+		}
+		return nil
+	},
+}
+
+// checkWorktreeClean fails when the worktree has staged, unstaged or untracked changes.
+func checkWorktreeClean(target *core.Worktree) error {
+	targetWorkspace, err := domain.NewWorkspaceFromGelDir(target.GelDir)
+	if err != nil {
+		return fmt.Errorf("worktree: %w", err)
+	}
+	return withWorkspace(
+		targetWorkspace, func() error {
+			status, err := statusService.Status()
+			if err != nil {
+				return fmt.Errorf("worktree: %w", err)
+			}
+			if len(status.Staged) > 0 || len(status.Unstaged) > 0 || len(status.Untracked) > 0 {
+				return fmt.Errorf("worktree: '%s' %w", target.Dir, worktree.ErrDirtyWorktree)
+			}
+			return nil
+		},
+	)
+}
+
+// withWorkspace runs fn with every service wired to target, then wires them
+// back to the current workspace.
+func withWorkspace(target *domain.Workspace, fn func() error) error {
+	current := workspace
+	initializeServicesForWorkspace(target)
+	defer initializeServicesForWorkspace(current)
+	return fn()
+}
+
+// formatWorktree renders one line of worktree list output.
+func formatWorktree(worktree core.Worktree) string {
+	if worktree.Bare {
+		return fmt.Sprintf("%s (bare)", worktree.GelDir)
+	}
+	line := worktree.Dir
+	hash, err := refService.Read(worktree.HeadRef)
+	if err == nil && !hash.IsEmpty() {
+		line += " " + hash.String()[:7]
+	}
+	if branch, ok := strings.CutPrefix(worktree.HeadRef, domain.RefsDirName+"/"+domain.HeadsDirName+"/"); ok {
+		line += " [" + branch + "]"
+	}
+	if worktree.Prunable {
+		line += " prunable"
+	}
+	return line
+}
+
+// init registers the worktree command and its subcommands.
+func init() {
+	worktreeAddCmd.Flags().StringVarP(
+		&worktreeAddBranchFlag, "branch", "b", "", "Create a new branch at <branch> or HEAD and check it out",
+	)
+	worktreeAddCmd.Flags().BoolVarP(
+		&worktreeAddForceFlag, "force", "f", false, "Check out a branch even if another worktree has it checked out",
+	)
+	worktreeRemoveCmd.Flags().BoolVarP(
+		&worktreeRemoveForceFlag, "force", "f", false, "Remove the worktree even if it has changes",
+	)
+	worktreePruneCmd.Flags().BoolVarP(
+		&worktreePruneDryRunFlag, "dry-run", "n", false, "Only report what would be pruned",
+	)
+	worktreePruneCmd.Flags().BoolVarP(
+		&worktreePruneVerboseFlag, "verbose", "v", false, "Report pruned worktrees",
+	)
+	worktreeCmd.AddCommand(worktreeAddCmd, worktreeListCmd, worktreeRemoveCmd, worktreePruneCmd)
+	rootCmd.AddCommand(worktreeCmd)
+}
@@ -3,9 +3,14 @@ package commit
 import (
 	"Gel/internal/core"
 	"Gel/internal/domain"
+	"Gel/internal/inspect"
 	"Gel/internal/tree"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 type CommitService struct {
@@ -13,6 +18,10 @@ type CommitService struct {
 	commitTreeService *CommitTreeService
 	refService        *core.RefService
 	objectService     *core.ObjectService
+	configService     *core.ConfigService
+	statusService     *inspect.StatusService
+	hookRunner        *core.HookRunner
+	workspace         *domain.Workspace
 }
 
 // NewCommitService creates and initializes a CommitService with the provided dependencies.
@@ -21,54 +30,358 @@ func NewCommitService(
 	commitTreeService *CommitTreeService,
 	refService *core.RefService,
 	objectService *core.ObjectService,
+	configService *core.ConfigService,
+	statusService *inspect.StatusService,
+	workspace *domain.Workspace,
 ) *CommitService {
 	return &CommitService{
 		writeTreeService:  writeTreeService,
 		commitTreeService: commitTreeService,
 		refService:        refService,
 		objectService:     objectService,
+		configService:     configService,
+		statusService:     statusService,
+		workspace:         workspace,
 	}
 }
 
+// SetHookRunner enables the pre-commit, commit-msg and post-commit hooks.
+func (c *CommitService) SetHookRunner(hookRunner *core.HookRunner) {
+	c.hookRunner = hookRunner
+}
+
+// CommitOptions controls how Commit builds the new commit.
+type CommitOptions struct {
+	// Message is the commit message. When amending, an empty message reuses
+	// the message of the amended commit.
+	Message string
+	// Amend replaces the current branch tip with a commit on top of its parents.
+	Amend bool
+	// AllowEmpty records a commit whose tree matches its parent's.
+	AllowEmpty bool
+	// Author overrides the author name and email, in "Name <email>" format.
+	Author string
+	// Date overrides the author date, in any format domain.ParseCommitDate accepts.
+	Date string
+	// Edit, when non-nil, opens the message in an editor before committing.
+	// An empty message is pre-filled from commit.template.
+	Edit func(path string) error
+	// Cleanup names the domain.CleanupMode applied to the message, overriding
+	// commit.cleanup. Empty means the configured or default mode.
+	Cleanup string
+	// Trailers are added to the message as "<key>=<value>" or "<key>: <value>".
+	Trailers []string
+	// NoVerify skips the pre-commit and commit-msg hooks.
+	NoVerify bool
+	// Sign signs the commit with the SSH key in user.signingkey.
+	Sign bool
+	// NoSign leaves the commit unsigned even when commit.gpgsign is true.
+	NoSign bool
+}
+
 // Commit writes the current index tree and advances the current branch.
-// It refuses no-op commits when the new tree matches the parent tree and
-// rejects an empty initial commit when both parent and tree are empty.
-func (c *CommitService) Commit(message string) error {
-	var parentHashes []domain.Hash
+// It refuses no-op commits when the new tree matches the parent tree unless
+// options.AllowEmpty is set.
+//
+// The commit is signed when options.Sign is set or commit.gpgsign is true.
+//
+// With options.Amend the new commit replaces the branch tip: it takes over the
+// tip's parents, its author unless options.Author or options.Date override it,
+// and its message when options.Message is empty.
+//
+// The message gets options.Trailers, goes through the editor when options.Edit
+// is set, and is cleaned up; a message left empty aborts the commit.
+//
+// The pre-commit hook runs before the tree is written and the commit-msg hook
+// before the message is cleaned up; either aborts the commit by failing.
+// The post-commit hook runs once the branch has moved.
+func (c *CommitService) Commit(options CommitOptions) error {
 	headRef, err := c.refService.ReadSymbolic(domain.HeadFileName)
 	if err != nil {
 		return fmt.Errorf("commit: failed to read HEAD: %w", err)
 	}
 
-	parentHash, err := c.refService.Read(headRef)
-	if errors.Is(err, core.ErrRefNotFound) {
-		parentHashes = nil
-	} else if err != nil {
+	headHash, err := c.refService.Read(headRef)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
 		return fmt.Errorf("commit: failed to read parent ref '%s': %w", headRef, err)
 	}
 
+	var parentHashes []domain.Hash
+	var author *domain.Identity
+	message := options.Message
+	if options.Amend {
+		if headHash.IsEmpty() {
+			return fmt.Errorf("commit: %w", ErrNothingToAmend)
+		}
+		headCommit, err := c.objectService.ReadCommit(headHash)
+		if err != nil {
+			return fmt.Errorf("commit: failed to read commit '%s': %w", headHash, err)
+		}
+		parentHashes = headCommit.ParentHashes
+		author = &headCommit.Author
+		if message == "" {
+			message = headCommit.Message
+		}
+	} else if !headHash.IsEmpty() {
+		parentHashes = []domain.Hash{headHash}
+	}
+
+	author, err = c.resolveAuthor(author, options)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	if !options.NoVerify {
+		if err := c.hookRunner.Run(core.HookPreCommit); err != nil {
+			return fmt.Errorf("commit: %w", err)
+		}
+	}
+
 	treeHash, err := c.writeTreeService.WriteTree()
 	if err != nil {
 		return fmt.Errorf("commit: failed to write tree: %w", err)
 	}
 
-	if !parentHash.IsEmpty() {
-		parentHashes = append(parentHashes, parentHash)
-		parentCommit, err := c.objectService.ReadCommit(parentHash)
+	if len(parentHashes) > 0 && !options.AllowEmpty {
+		parentCommit, err := c.objectService.ReadCommit(parentHashes[0])
 		if err != nil {
-			return fmt.Errorf("commit: failed to read parent commit '%s': %w", parentHash, err)
+			return fmt.Errorf("commit: failed to read parent commit '%s': %w", parentHashes[0], err)
 		}
-		if parentCommit.TreeHash.Equals(treeHash) {
+		if parentCommit.TreeHash.Equals(treeHash) && (!options.Amend || len(parentHashes) == 1) {
 			return ErrNothingToCommit
 		}
 	}
 
-	commitHash, err := c.commitTreeService.CommitTree(treeHash, message, parentHashes)
+	message, err = c.prepareMessage(message, options)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	sign, err := c.shouldSign(options)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	commitTree := c.commitTreeService.CommitTreeWithAuthor
+	if sign {
+		commitTree = c.commitTreeService.CommitTreeSigned
+	}
+	commitHash, err := commitTree(treeHash, message, parentHashes, author)
 	if err != nil {
 		return fmt.Errorf("commit: %w", err)
 	}
 	if err := c.refService.Write(headRef, commitHash); err != nil {
 		return fmt.Errorf("commit: failed to update ref '%s': %w", headRef, err)
 	}
+	c.hookRunner.RunPost(core.HookPostCommit)
 	return nil
 }
+
+// resolveAuthor applies the --author and --date overrides of options to base,
+// or to the default author when base is nil. It returns base unchanged when
+// neither override is given.
+func (c *CommitService) resolveAuthor(base *domain.Identity, options CommitOptions) (*domain.Identity, error) {
+	if options.Author == "" && options.Date == "" {
+		return base, nil
+	}
+	var author domain.Identity
+	if base != nil {
+		author = *base
+	} else {
+		defaultAuthor, err := c.commitTreeService.DefaultAuthor()
+		if err != nil {
+			return nil, err
+		}
+		author = defaultAuthor
+	}
+
+	name, email := author.Name, author.Email
+	timestamp, timezone := author.Timestamp, author.Timezone
+	var err error
+	if options.Author != "" {
+		if name, email, err = domain.ParseIdentityPerson(options.Author); err != nil {
+			return nil, err
+		}
+	}
+	if options.Date != "" {
+		if timestamp, timezone, err = domain.ParseCommitDate(options.Date); err != nil {
+			return nil, err
+		}
+	}
+	identity, err := domain.NewIdentity(name, email, timestamp, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// prepareMessage adds the trailers of options to message, lets the user edit
+// it when options.Edit is set, and cleans it up.
+func (c *CommitService) prepareMessage(message string, options CommitOptions) (string, error) {
+	mode, err := c.cleanupMode(options.Cleanup)
+	if err != nil {
+		return "", err
+	}
+	trailers := make([]domain.Trailer, len(options.Trailers))
+	for i, value := range options.Trailers {
+		if trailers[i], err = domain.ParseTrailer(value); err != nil {
+			return "", err
+		}
+	}
+
+	template := ""
+	if options.Edit != nil && message == "" {
+		if template, err = c.readTemplate(); err != nil {
+			return "", err
+		}
+		message = template
+	}
+	message = domain.AppendTrailers(message, trailers)
+
+	edited := options.Edit != nil
+	if edited {
+		if message, err = c.editMessage(message, mode, options.Edit); err != nil {
+			return "", err
+		}
+	}
+	if !options.NoVerify {
+		if message, err = c.runCommitMsgHook(message); err != nil {
+			return "", err
+		}
+	}
+	message = domain.CleanupMessage(message, mode, edited)
+	if strings.TrimSpace(message) == "" {
+		return "", ErrEmptyCommitMessage
+	}
+	if template != "" && message == domain.CleanupMessage(domain.AppendTrailers(template, trailers), mode, edited) {
+		return "", ErrTemplateNotEdited
+	}
+	return message, nil
+}
+
+// shouldSign reports whether the commit is signed: as options ask, or as
+// commit.gpgsign says when they do not.
+func (c *CommitService) shouldSign(options CommitOptions) (bool, error) {
+	if options.Sign || options.NoSign {
+		return options.Sign, nil
+	}
+	value, ok, err := c.configService.GetOptional(core.ConfigSectionCommit, core.ConfigKeyGPGSign)
+	if err != nil || !ok {
+		return false, err
+	}
+	sign, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf(
+			"invalid %s.%s %q: expected true or false", core.ConfigSectionCommit, core.ConfigKeyGPGSign, value,
+		)
+	}
+	return sign, nil
+}
+
+// cleanupMode returns the cleanup mode named by override, or by commit.cleanup
+// when override is empty.
+func (c *CommitService) cleanupMode(override string) (domain.CleanupMode, error) {
+	if override != "" {
+		return domain.ParseCleanupMode(override)
+	}
+	value, ok, err := c.configService.GetOptional(core.ConfigSectionCommit, core.ConfigKeyCleanup)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return domain.CleanupDefault, nil
+	}
+	return domain.ParseCleanupMode(value)
+}
+
+// readTemplate returns the content of the commit.template file, or an empty
+// string when none is configured. A leading "~/" is the home directory and a
+// relative path is taken from the working tree root.
+func (c *CommitService) readTemplate() (string, error) {
+	path, ok, err := c.configService.GetOptional(core.ConfigSectionCommit, core.ConfigKeyTemplate)
+	if err != nil || !ok || path == "" {
+		return "", err
+	}
+	if path, err = core.ExpandPath(path, c.workspace.RepoDir.String()); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read commit template: %w", err)
+	}
+	return string(data), nil
+}
+
+// editMessage writes message followed by a commented summary of the staged
+// changes to COMMIT_EDITMSG, runs edit on it and returns the edited content.
+func (c *CommitService) editMessage(message string, mode domain.CleanupMode, edit func(path string) error) (
+	string, error,
+) {
+	status, err := c.statusService.Status()
+	if err != nil {
+		return "", err
+	}
+
+	var buffer strings.Builder
+	buffer.WriteString(message)
+	if message != "" && !strings.HasSuffix(message, "\n") {
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("\n")
+	switch mode {
+	case domain.CleanupScissors:
+		buffer.WriteString(domain.ScissorsLine + "\n")
+		buffer.WriteString("# Do not modify or remove the line above.\n")
+		buffer.WriteString("# Everything below it will be ignored.\n")
+	case domain.CleanupDefault, domain.CleanupStrip:
+		buffer.WriteString("# Please enter the commit message for your changes. Lines starting\n")
+		buffer.WriteString("# with '#' will be ignored, and an empty message aborts the commit.\n")
+	default:
+		buffer.WriteString("# Please enter the commit message for your changes. Lines starting\n")
+		buffer.WriteString("# with '#' will be kept; you may remove them yourself if you want to.\n")
+		buffer.WriteString("# An empty message aborts the commit.\n")
+	}
+	buffer.WriteString("#\n")
+	fmt.Fprintf(&buffer, "# On branch %s\n", status.CurrentBranch)
+	if len(status.Staged) > 0 {
+		buffer.WriteString("# Changes to be committed:\n")
+		for _, staged := range status.Staged {
+			fmt.Fprintf(&buffer, "#\t%s:  %s\n", staged.Status, staged.Path)
+		}
+		buffer.WriteString("#\n")
+	}
+
+	return c.rewriteMessage(buffer.String(), edit)
+}
+
+// runCommitMsgHook hands message to the commit-msg hook and returns the
+// message as the hook left it.
+func (c *CommitService) runCommitMsgHook(message string) (string, error) {
+	if c.hookRunner == nil {
+		return message, nil
+	}
+	if message != "" && !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	return c.rewriteMessage(
+		message, func(path string) error {
+			return c.hookRunner.Run(core.HookCommitMsg, path)
+		},
+	)
+}
+
+// rewriteMessage writes message to COMMIT_EDITMSG, runs rewrite on the file
+// and returns its new content.
+func (c *CommitService) rewriteMessage(message string, rewrite func(path string) error) (string, error) {
+	path := filepath.Join(c.workspace.GelDir.String(), domain.CommitEditMsgFileName)
+	if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+		return "", err
+	}
+	if err := rewrite(path); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -4,13 +4,15 @@ import (
 	"Gel/internal/core"
 	"Gel/internal/domain"
 	"fmt"
+	"os"
 	"time"
 )
 
 // CommitTreeService creates commit objects from an explicit tree hash.
 type CommitTreeService struct {
-	objectService *core.ObjectService
-	configService *core.ConfigService
+	objectService  *core.ObjectService
+	configService  *core.ConfigService
+	signingService *core.SigningService
 }
 
 // NewCommitTreeService creates a commit-tree service.
@@ -24,40 +26,83 @@ func NewCommitTreeService(
 	}
 }
 
+// SetSigningService enables CommitTreeSigned.
+func (c *CommitTreeService) SetSigningService(signingService *core.SigningService) {
+	c.signingService = signingService
+}
+
 // CommitTree creates a commit object with the provided tree and parent hashes.
-// Author/committer identity is loaded from config user.name and user.email.
+// Author/committer identity is loaded from config user.name and user.email,
+// unless overridden by the GEL_AUTHOR_* and GEL_COMMITTER_* environment variables.
 func (c *CommitTreeService) CommitTree(
 	hash domain.Hash,
 	message string,
 	parentHashes []domain.Hash,
 ) (
 	domain.Hash, error,
+) {
+	return c.CommitTreeWithAuthor(hash, message, parentHashes, nil)
+}
+
+// CommitTreeWithAuthor creates a commit object like CommitTree but keeps author
+// when it is non-nil. The committer is always the current committer identity,
+// which lets history-rewriting operations such as rebase preserve authorship.
+func (c *CommitTreeService) CommitTreeWithAuthor(
+	hash domain.Hash,
+	message string,
+	parentHashes []domain.Hash,
+	author *domain.Identity,
+) (
+	domain.Hash, error,
+) {
+	return c.commitTree(hash, message, parentHashes, author, false)
+}
+
+// CommitTreeSigned creates a commit object like CommitTreeWithAuthor and signs
+// it with the SSH key in user.signingkey. The signature covers the commit body
+// and is stored in its gpgsig header.
+func (c *CommitTreeService) CommitTreeSigned(
+	hash domain.Hash,
+	message string,
+	parentHashes []domain.Hash,
+	author *domain.Identity,
+) (
+	domain.Hash, error,
+) {
+	return c.commitTree(hash, message, parentHashes, author, true)
+}
+
+// commitTree builds, optionally signs, and writes a commit object.
+func (c *CommitTreeService) commitTree(
+	hash domain.Hash,
+	message string,
+	parentHashes []domain.Hash,
+	author *domain.Identity,
+	sign bool,
+) (
+	domain.Hash, error,
 ) {
 	_, err := c.objectService.ReadTree(hash)
 	if err != nil {
 		return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
 	}
 
-	name, email, err := c.configService.GetUserInfo()
+	identity, err := c.DefaultCommitter()
 	if err != nil {
 		return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
 	}
 
-	now := time.Now()
-	identity, err := domain.NewIdentity(
-		name,
-		email,
-		domain.FormatCommitTimestamp(now),
-		domain.FormatCommitTimezone(now),
-	)
-	if err != nil {
+	var authorIdentity domain.Identity
+	if author != nil {
+		authorIdentity = *author
+	} else if authorIdentity, err = c.DefaultAuthor(); err != nil {
 		return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
 	}
 
 	commitFields := domain.CommitFields{
 		TreeHash:     hash,
 		ParentHashes: parentHashes,
-		Author:       identity,
+		Author:       authorIdentity,
 		Committer:    identity,
 		Message:      message,
 	}
@@ -65,6 +110,19 @@ func (c *CommitTreeService) CommitTree(
 	if err != nil {
 		return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
 	}
+	if sign {
+		if c.signingService == nil {
+			return domain.Hash{}, fmt.Errorf("commit-tree: %w", core.ErrNoSigningKey)
+		}
+		signature, err := c.signingService.Sign(commit.Body())
+		if err != nil {
+			return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
+		}
+		commitFields.Signature = signature
+		if commit, err = domain.NewCommitFromFields(commitFields); err != nil {
+			return domain.Hash{}, fmt.Errorf("commit-tree: %w", err)
+		}
+	}
 
 	serializedData := commit.Serialize()
 	hexCommitHash := core.ComputeSHA256(serializedData)
@@ -77,3 +135,58 @@ func (c *CommitTreeService) CommitTree(
 	}
 	return commitHash, nil
 }
+
+// DefaultAuthor returns the author of a new commit: the configured user at the
+// current time, with each part overridden by GEL_AUTHOR_NAME, GEL_AUTHOR_EMAIL
+// and GEL_AUTHOR_DATE when set.
+func (c *CommitTreeService) DefaultAuthor() (domain.Identity, error) {
+	return c.identityFromEnvironment(domain.GelAuthorNameEnvVar, domain.GelAuthorEmailEnvVar, domain.GelAuthorDateEnvVar)
+}
+
+// DefaultCommitter returns the committer of a new commit, which is also the
+// tagger of a new tag: the configured user at the current time, with each
+// part overridden by GEL_COMMITTER_NAME, GEL_COMMITTER_EMAIL and
+// GEL_COMMITTER_DATE when set.
+func (c *CommitTreeService) DefaultCommitter() (domain.Identity, error) {
+	return c.identityFromEnvironment(
+		domain.GelCommitterNameEnvVar, domain.GelCommitterEmailEnvVar, domain.GelCommitterDateEnvVar,
+	)
+}
+
+// identityFromEnvironment builds an identity from the named environment
+// variables, falling back to config user.name, user.email and the current time.
+func (c *CommitTreeService) identityFromEnvironment(nameEnvVar, emailEnvVar, dateEnvVar string) (domain.Identity, error) {
+	name, err := c.identityField(nameEnvVar, core.ConfigKeyName)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	email, err := c.identityField(emailEnvVar, core.ConfigKeyEmail)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+
+	now := time.Now()
+	timestamp, timezone := domain.FormatCommitTimestamp(now), domain.FormatCommitTimezone(now)
+	if date, ok := os.LookupEnv(dateEnvVar); ok && date != "" {
+		if timestamp, timezone, err = domain.ParseCommitDate(date); err != nil {
+			return domain.Identity{}, fmt.Errorf("%s: %w", dateEnvVar, err)
+		}
+	}
+	return domain.NewIdentity(name, email, timestamp, timezone)
+}
+
+// identityField returns the environment variable envVar when set and the
+// user config key otherwise.
+func (c *CommitTreeService) identityField(envVar, key string) (string, error) {
+	if value, ok := os.LookupEnv(envVar); ok && value != "" {
+		return value, nil
+	}
+	value, ok, err := c.configService.GetOptional(core.ConfigSectionUser, key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("config: %s.%s is not set", core.ConfigSectionUser, key)
+	}
+	return value, nil
+}
@@ -6,6 +6,15 @@ var (
 	// ErrNothingToCommit is returned when the tree matches the parent commit.
 	ErrNothingToCommit = errors.New("nothing to commit")
 
+	// ErrNothingToAmend is returned when amending a branch with no commits.
+	ErrNothingToAmend = errors.New("nothing to amend: the current branch has no commits yet")
+
+	// ErrEmptyCommitMessage is returned when the commit message is empty after cleanup.
+	ErrEmptyCommitMessage = errors.New("aborting commit due to empty commit message")
+
+	// ErrTemplateNotEdited is returned when an edited message is still the commit template.
+	ErrTemplateNotEdited = errors.New("aborting commit; you did not edit the message")
+
 	// ErrNoCommitsYet is returned when trying to log a branch with no commits.
 	ErrNoCommitsYet = errors.New("no commits yet")
 )
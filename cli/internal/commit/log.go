@@ -25,24 +25,28 @@ type LogOptions struct {
 
 // LogService resolves a starting revision and walks commit history.
 type LogService struct {
-	refService    *core.RefService
-	objectService *core.ObjectService
+	refService     *core.RefService
+	objectService  *core.ObjectService
+	shallowService *core.ShallowService
 }
 
 // NewLogService creates a log service.
 func NewLogService(
 	refService *core.RefService,
 	objectService *core.ObjectService,
+	shallowService *core.ShallowService,
 ) *LogService {
 	return &LogService{
-		refService:    refService,
-		objectService: objectService,
+		refService:     refService,
+		objectService:  objectService,
+		shallowService: shallowService,
 	}
 }
 
 // Log returns commit history entries starting from name.
 // name may be HEAD, a branch name, a full refs/* path, or a commit hash.
-// The current traversal follows first-parent history only.
+// The current traversal follows first-parent history only and stops at
+// shallow boundary commits.
 func (l *LogService) Log(name string, options LogOptions) ([]*LogEntry, error) {
 	// TODO: handle --since and --until
 
@@ -50,6 +54,10 @@ func (l *LogService) Log(name string, options LogOptions) ([]*LogEntry, error) {
 	if err != nil {
 		return nil, fmt.Errorf("log: %w", err)
 	}
+	grafts, err := l.shallowService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
 
 	var entries []*LogEntry
 	count := 0
@@ -85,11 +93,12 @@ func (l *LogService) Log(name string, options LogOptions) ([]*LogEntry, error) {
 			)
 
 		}
-		if len(commit.ParentHashes) == 0 {
+		parentHashes := grafts.Parents(hash, commit)
+		if len(parentHashes) == 0 {
 			break
 		}
 		// TODO: use a priority queue/heap to interleave commits from all parents by date
-		hash = commit.ParentHashes[0]
+		hash = parentHashes[0]
 	}
 	return entries, nil
 }
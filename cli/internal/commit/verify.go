@@ -0,0 +1,53 @@
+package commit
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"fmt"
+)
+
+// VerifyService checks commit signatures.
+type VerifyService struct {
+	objectService  *core.ObjectService
+	commitResolver *core.CommitResolver
+	signingService *core.SigningService
+}
+
+// NewVerifyService creates a signature verification service.
+func NewVerifyService(
+	objectService *core.ObjectService,
+	commitResolver *core.CommitResolver,
+	signingService *core.SigningService,
+) *VerifyService {
+	return &VerifyService{
+		objectService:  objectService,
+		commitResolver: commitResolver,
+		signingService: signingService,
+	}
+}
+
+// Verify resolves revision and checks the signature of its commit against
+// the allowed signers file. It returns the commit hash, the verification of
+// a good signature even when its key is not allowed, and an error unless the
+// signature is good and made by an allowed signer.
+func (v *VerifyService) Verify(revision string) (domain.Hash, *core.SignatureVerification, error) {
+	hash, err := v.commitResolver.Resolve(revision)
+	if err != nil {
+		return domain.Hash{}, nil, fmt.Errorf("verify-commit: %w", err)
+	}
+	verification, err := v.VerifyHash(hash)
+	if err != nil {
+		return hash, verification, fmt.Errorf("verify-commit: %s: %w", hash, err)
+	}
+	return hash, verification, nil
+}
+
+// VerifyHash checks the signature of the commit hash like Verify. Its errors
+// are not prefixed, for callers such as log that report them per commit.
+func (v *VerifyService) VerifyHash(hash domain.Hash) (*core.SignatureVerification, error) {
+	commit, err := v.objectService.ReadCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+	return v.signingService.VerifyCommit(commit)
+}
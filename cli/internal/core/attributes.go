@@ -0,0 +1,226 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// AttributeSet is the value of an attribute listed bare ("text").
+	AttributeSet = "set"
+	// AttributeUnset is the value of an attribute listed with "-" ("-text").
+	AttributeUnset = "unset"
+
+	// AttributeText controls end-of-line normalization (set, unset, or "auto").
+	AttributeText = "text"
+	// AttributeEOL selects the working tree line ending ("lf" or "crlf") and implies text.
+	AttributeEOL = "eol"
+	// AttributeFilter names the clean/smudge driver configured under [filter].
+	AttributeFilter = "filter"
+	// AttributeDiff disables textual diffs when unset or names a driver configured under [diff].
+	AttributeDiff = "diff"
+	// AttributeMerge is unset by the binary macro.
+	AttributeMerge = "merge"
+	// AttributeBinary is the macro for "-text -diff -merge".
+	AttributeBinary = "binary"
+)
+
+// AttributeRule is one pattern line read from an attributes file.
+type AttributeRule struct {
+	// Source is the file the rule was read from, repository-relative when it
+	// lives inside the repository.
+	Source string
+	// Line is the 1-based line number of the rule within Source.
+	Line int
+	// Pattern is the path pattern as written.
+	Pattern string
+	// Attributes maps attribute names to AttributeSet, AttributeUnset, or a
+	// value. An empty string marks an attribute reset to unspecified with "!".
+	Attributes map[string]string
+
+	pattern IgnoreRule
+}
+
+// ParseAttributeRules parses attributes file content. Rules apply to paths below base.
+//
+// Each line is a pattern followed by attributes: "name" sets, "-name" unsets,
+// "!name" resets to unspecified, and "name=value" assigns a value. Patterns
+// follow .gelignore syntax except that negated patterns are not allowed.
+func ParseAttributeRules(data []byte, source, base string) []AttributeRule {
+	var rules []AttributeRule
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(strings.TrimSuffix(line, "\r"))
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+
+		patterns := ParseIgnoreRules([]byte(fields[0]), source, base)
+		if len(patterns) == 0 {
+			continue
+		}
+		rule := AttributeRule{
+			Source:     source,
+			Line:       i + 1,
+			Pattern:    fields[0],
+			Attributes: make(map[string]string),
+			pattern:    patterns[0],
+		}
+		for _, field := range fields[1:] {
+			switch {
+			case field == AttributeBinary:
+				rule.Attributes[AttributeText] = AttributeUnset
+				rule.Attributes[AttributeDiff] = AttributeUnset
+				rule.Attributes[AttributeMerge] = AttributeUnset
+			case strings.HasPrefix(field, "-"):
+				rule.Attributes[field[1:]] = AttributeUnset
+			case strings.HasPrefix(field, "!"):
+				rule.Attributes[field[1:]] = ""
+			default:
+				name, value, ok := strings.Cut(field, "=")
+				if !ok {
+					value = AttributeSet
+				}
+				rule.Attributes[name] = value
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Matches reports whether the rule's pattern matches the repository-relative file path.
+func (r AttributeRule) Matches(relPath string) bool {
+	return r.pattern.Matches(relPath, false)
+}
+
+// AttributeMatcher resolves the attributes that apply to working tree paths.
+//
+// Rules are read from .gelattributes files in every directory and from
+// .gel/info/attributes, in increasing order of precedence: a file deeper in
+// the tree overrides its parents and info/attributes overrides them all.
+// Within the same file later lines win. It is safe for concurrent use.
+type AttributeMatcher struct {
+	workspace *domain.Workspace
+
+	mutex     sync.Mutex
+	loaded    bool
+	infoRules []AttributeRule
+	dirRules  map[string][]AttributeRule
+}
+
+// NewAttributeMatcher creates an attribute matcher. Attribute files are read lazily on first use.
+func NewAttributeMatcher(workspace *domain.Workspace) *AttributeMatcher {
+	return &AttributeMatcher{
+		workspace: workspace,
+		dirRules:  make(map[string][]AttributeRule),
+	}
+}
+
+// Attributes returns the attributes specified for the repository-relative path.
+// Unspecified attributes are absent from the returned map.
+func (m *AttributeMatcher) Attributes(relPath string) (map[string]string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	dirs := []string{""}
+	if parent := path.Dir(relPath); parent != "." {
+		segments := strings.Split(parent, "/")
+		for i := range segments {
+			dirs = append(dirs, strings.Join(segments[:i+1], "/"))
+		}
+	}
+
+	attributes := make(map[string]string)
+	for _, dir := range dirs {
+		rules, err := m.rulesForDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		applyAttributeRules(attributes, rules, relPath)
+	}
+	applyAttributeRules(attributes, m.infoRules, relPath)
+	return attributes, nil
+}
+
+// applyAttributeRules overlays every rule in rules that matches relPath onto attributes.
+func applyAttributeRules(attributes map[string]string, rules []AttributeRule, relPath string) {
+	for _, rule := range rules {
+		if !rule.Matches(relPath) {
+			continue
+		}
+		for name, value := range rule.Attributes {
+			if value == "" {
+				delete(attributes, name)
+				continue
+			}
+			attributes[name] = value
+		}
+	}
+}
+
+// Reset drops cached rules so attribute files are read again on next use.
+// Checkout calls it after writing a .gelattributes file.
+func (m *AttributeMatcher) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.loaded = false
+	m.infoRules = nil
+	m.dirRules = make(map[string][]AttributeRule)
+}
+
+// rulesForDir returns the rules of the .gelattributes file in dir, reading it once.
+func (m *AttributeMatcher) rulesForDir(dir string) ([]AttributeRule, error) {
+	if rules, ok := m.dirRules[dir]; ok {
+		return rules, nil
+	}
+
+	source := path.Join(dir, domain.GelAttributesFileName)
+	rules, err := readAttributesFile(
+		filepath.Join(m.workspace.RepoDir.String(), filepath.FromSlash(source)), source, dir,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.dirRules[dir] = rules
+	return rules, nil
+}
+
+// load reads .gel/info/attributes.
+func (m *AttributeMatcher) load() error {
+	if m.loaded {
+		return nil
+	}
+
+	infoPath := filepath.Join(m.workspace.CommonDir.String(), domain.InfoDirName, domain.AttributesFileName)
+	source := path.Join(domain.GelDirName, domain.InfoDirName, domain.AttributesFileName)
+	rules, err := readAttributesFile(infoPath, source, "")
+	if err != nil {
+		return err
+	}
+	m.infoRules = rules
+
+	m.loaded = true
+	return nil
+}
+
+// readAttributesFile parses the attributes file at path; a missing file has no rules.
+func readAttributesFile(filePath, source, base string) ([]AttributeRule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("attributes: read %s: %w", source, err)
+	}
+	return ParseAttributeRules(data, source, base), nil
+}
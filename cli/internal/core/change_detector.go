@@ -44,12 +44,37 @@ func NewChangeDetector(objectService *ObjectService, repoDir domain.AbsolutePath
 //
 // It resolves the entry path, classifies missing files as FileStateDeleted,
 // returns FileStateUnchanged when stat metadata matches, and otherwise computes
-// a fresh blob hash and returns FileStateModified.
+// a fresh blob hash and returns FileStateModified. Entries outside the sparse
+// checkout are always FileStateUnchanged: their absence is intended.
+//
+// Matching stat data of a RacilyClean entry proves nothing, so its content is
+// hashed and compared instead.
+//
+// Entries the filesystem monitor vouches for (FSMonitorValid) are reported
+// unchanged without a stat; an entry found unchanged is marked so.
+//
+// A submodule is modified when the commit checked out in it differs from the
+// entry's; one that is not checked out is unchanged.
+//
+// Entries marked assume-unchanged are unchanged without looking at the file.
+// An intent-to-add entry records no content, so its file is always modified.
 func (c *ChangeDetector) DetectFileChange(entry *domain.IndexEntry) (ChangeResult, error) {
+	if entry.SkipWorktree() || entry.AssumeUnchanged() {
+		return ChangeResult{FileState: FileStateUnchanged}, nil
+	}
 	absPath, err := entry.Path.ToAbsolutePath(c.repoDir)
 	if err != nil {
 		return ChangeResult{}, err
 	}
+	if entry.IntentToAdd() {
+		return c.detectIntentToAddChange(absPath)
+	}
+	if domain.FileMode(entry.Mode).IsGitlink() {
+		return detectSubmoduleChange(entry, absPath)
+	}
+	if entry.FSMonitorValid {
+		return ChangeResult{FileState: FileStateUnchanged}, nil
+	}
 
 	stat, err := domain.NewFileStatFromPath(absPath)
 	if err != nil {
@@ -58,7 +83,8 @@ func (c *ChangeDetector) DetectFileChange(entry *domain.IndexEntry) (ChangeResul
 		}
 		return ChangeResult{}, err
 	}
-	if entry.MatchesStat(stat) {
+	if entry.MatchesStat(stat) && !entry.RacilyClean {
+		entry.FSMonitorValid = true
 		return ChangeResult{FileState: FileStateUnchanged}, nil
 	}
 
@@ -66,5 +92,127 @@ func (c *ChangeDetector) DetectFileChange(entry *domain.IndexEntry) (ChangeResul
 	if err != nil {
 		return ChangeResult{}, err
 	}
+	if entry.MatchesStat(stat) && hash == entry.Hash {
+		entry.FSMonitorValid = true
+		return ChangeResult{FileState: FileStateUnchanged}, nil
+	}
 	return ChangeResult{FileState: FileStateModified, NewHash: hash}, nil
 }
+
+// SmudgeRacilyClean smudges every racily clean entry of index whose file
+// still matches the entry's stat data but no longer its content. Without
+// this, rewriting the index would make the stale stat data look trustworthy.
+func (c *ChangeDetector) SmudgeRacilyClean(index *domain.Index) error {
+	var racy []*domain.IndexEntry
+	for _, entry := range index.Entries {
+		if entry.RacilyClean && !entry.SkipWorktree() && !entry.AssumeUnchanged() && !entry.IntentToAdd() &&
+			entry.GetStage() == 0 && !domain.FileMode(entry.Mode).IsGitlink() {
+			racy = append(racy, entry)
+		}
+	}
+	return RunParallel(
+		len(racy), DefaultWorkers(), func(i int) error {
+			entry := racy[i]
+			absPath, err := entry.Path.ToAbsolutePath(c.repoDir)
+			if err != nil {
+				return err
+			}
+			stat, err := domain.NewFileStatFromPath(absPath)
+			if err != nil || !entry.MatchesStat(stat) {
+				// A missing or restat'ed file is detected without help.
+				return nil
+			}
+			hash, _, err := c.objectService.ComputeObjectHash(absPath)
+			if err != nil {
+				return err
+			}
+			if hash != entry.Hash {
+				entry.Smudge()
+				entry.FSMonitorValid = false
+			}
+			return nil
+		},
+	)
+}
+
+// RefreshEntry brings the stat data of entry up to date when its file still
+// holds the indexed content, and reports whether the entry is up to date.
+// Entries whose stat data matches are trusted unless they are racily clean
+// or really is set, in which case the content is hashed and compared. A
+// smudged entry whose content still matches gets its stat data back.
+// Assume-unchanged and intent-to-add entries have no stat data to refresh.
+func (c *ChangeDetector) RefreshEntry(entry *domain.IndexEntry, really bool) (bool, error) {
+	if entry.SkipWorktree() || entry.AssumeUnchanged() || entry.IntentToAdd() {
+		return true, nil
+	}
+	if really {
+		entry.FSMonitorValid = false
+	} else if entry.FSMonitorValid {
+		return true, nil
+	}
+	absPath, err := entry.Path.ToAbsolutePath(c.repoDir)
+	if err != nil {
+		return false, err
+	}
+	if domain.FileMode(entry.Mode).IsGitlink() {
+		changeResult, err := detectSubmoduleChange(entry, absPath)
+		return changeResult.FileState == FileStateUnchanged, err
+	}
+
+	stat, err := domain.NewFileStatFromPath(absPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !really && entry.MatchesStat(stat) && !entry.RacilyClean {
+		return true, nil
+	}
+
+	mode, err := domain.NewFileModeFromOSMode(stat.Mode)
+	if err != nil || mode.Uint32() != entry.Mode {
+		return false, nil
+	}
+	hash, _, err := c.objectService.ComputeObjectHash(absPath)
+	if err != nil {
+		return false, err
+	}
+	if hash != entry.Hash {
+		return false, nil
+	}
+	entry.RefreshStat(stat)
+	return true, nil
+}
+
+// detectIntentToAddChange reports the file at absPath of an intent-to-add
+// entry as modified, with its content hash, or as deleted when it is gone.
+func (c *ChangeDetector) detectIntentToAddChange(absPath domain.AbsolutePath) (ChangeResult, error) {
+	hash, _, err := c.objectService.ComputeObjectHash(absPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ChangeResult{FileState: FileStateDeleted}, nil
+		}
+		return ChangeResult{}, err
+	}
+	return ChangeResult{FileState: FileStateModified, NewHash: hash}, nil
+}
+
+// detectSubmoduleChange compares the commit checked out in the submodule at
+// absPath with the commit recorded by entry.
+func detectSubmoduleChange(entry *domain.IndexEntry, absPath domain.AbsolutePath) (ChangeResult, error) {
+	if _, err := os.Lstat(absPath.String()); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ChangeResult{FileState: FileStateDeleted}, nil
+		}
+		return ChangeResult{}, err
+	}
+	head, ok, err := ReadSubmoduleHead(absPath)
+	if err != nil {
+		return ChangeResult{}, err
+	}
+	if !ok || head == entry.Hash {
+		return ChangeResult{FileState: FileStateUnchanged}, nil
+	}
+	return ChangeResult{FileState: FileStateModified, NewHash: head}, nil
+}
@@ -50,6 +50,9 @@ func (r *CommitResolver) Resolve(target string) (domain.Hash, error) {
 	return r.walkNParents(baseHash, steps)
 }
 
+// resolveBase resolves HEAD, a full hash, a ref, or a branch name, falling
+// back to a tag name when no branch has that name. Annotated tags are peeled
+// to the commit they point at.
 func (r *CommitResolver) resolveBase(base string) (domain.Hash, error) {
 	switch {
 	case base == domain.HeadFileName:
@@ -60,27 +63,40 @@ func (r *CommitResolver) resolveBase(base string) (domain.Hash, error) {
 		if err != nil {
 			return domain.Hash{}, err
 		}
-		if err := r.ensureCommit(hash); err != nil {
-			return domain.Hash{}, err
-		}
-		return hash, nil
+		return r.peelToCommit(hash)
 
 	case strings.HasPrefix(base, domain.RefsDirName+"/"):
 		hash, err := r.refService.Read(base)
 		if err != nil {
 			return domain.Hash{}, err
 		}
-		if err := r.ensureCommit(hash); err != nil {
-			return domain.Hash{}, err
-		}
-		return hash, nil
+		return r.peelToCommit(hash)
 	}
 
 	branchRef := filepath.Join(domain.RefsDirName, domain.HeadsDirName, base)
 	hash, err := r.refService.Read(branchRef)
+	if errors.Is(err, ErrRefNotFound) {
+		tagRef := filepath.Join(domain.RefsDirName, domain.TagsDirName, base)
+		if tagHash, tagErr := r.refService.Read(tagRef); tagErr == nil {
+			return r.peelToCommit(tagHash)
+		}
+	}
 	if err != nil {
 		return domain.Hash{}, err
 	}
+	return r.peelToCommit(hash)
+}
+
+// peelToCommit follows annotated tags from hash and returns the commit they
+// end at.
+func (r *CommitResolver) peelToCommit(hash domain.Hash) (domain.Hash, error) {
+	for !hash.IsEmpty() {
+		tag, err := r.objectService.ReadTag(hash)
+		if err != nil {
+			break
+		}
+		hash = tag.ObjectHash
+	}
 	if err := r.ensureCommit(hash); err != nil {
 		return domain.Hash{}, err
 	}
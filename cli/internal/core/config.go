@@ -7,18 +7,113 @@ import (
 	"bytes"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 const (
+	// ConfigSectionCore stores repository-wide behavior settings.
+	ConfigSectionCore = "core"
+	// ConfigKeyExcludesFile is the global ignore file key under [core].
+	ConfigKeyExcludesFile = "excludesfile"
+	// ConfigKeyEditor is the editor command key under [core].
+	ConfigKeyEditor = "editor"
+	// ConfigKeyEOL is the default checkout line ending for text files under [core] (lf, crlf, or native).
+	ConfigKeyEOL = "eol"
+	// ConfigKeyAutoCRLF enables line-ending normalization of files without a text attribute (true or input).
+	ConfigKeyAutoCRLF = "autocrlf"
+	// ConfigKeySparseCheckout enables sparse checkout under [core] (true or false).
+	ConfigKeySparseCheckout = "sparsecheckout"
+	// ConfigKeyUntrackedCache stores working tree directory listings in the index under [core] (true or false).
+	ConfigKeyUntrackedCache = "untrackedcache"
+	// ConfigKeyScanWorkers bounds the goroutines that stat and hash files during a working tree scan.
+	ConfigKeyScanWorkers = "scanworkers"
+	// ConfigKeyHooksPath is the directory holding hooks under [core], replacing .gel/hooks.
+	ConfigKeyHooksPath = "hookspath"
+	// ConfigKeyFSMonitor selects the filesystem monitor under [core]: true for the built-in daemon, or a hook command.
+	ConfigKeyFSMonitor = "fsmonitor"
+
 	// ConfigSectionUser stores author/committer identity defaults.
 	ConfigSectionUser = "user"
 	// ConfigKeyName is the user name key under [user].
 	ConfigKeyName = "name"
 	// ConfigKeyEmail is the user email key under [user].
 	ConfigKeyEmail = "email"
+	// ConfigKeySigningKey is the path of the SSH private key signing commits under [user].
+	ConfigKeySigningKey = "signingkey"
+
+	// ConfigSectionGPG stores commit signature settings.
+	ConfigSectionGPG = "gpg"
+	// ConfigKeyAllowedSignersFile is the path of the SSH allowed signers file under [gpg].
+	ConfigKeyAllowedSignersFile = "ssh.allowedsignersfile"
+
+	// ConfigSectionRemote stores remote repository settings as "<name>.<key>" keys.
+	ConfigSectionRemote = "remote"
+	// ConfigKeyURL is the remote location key suffix under [remote] ("<name>.url"),
+	// and the directory of the remote large file store under [lfs].
+	ConfigKeyURL = "url"
+	// ConfigKeyPromisor marks a remote as the source of objects omitted by a partial clone.
+	ConfigKeyPromisor = "promisor"
+	// ConfigKeyPartialCloneFilter is the object filter key suffix under [remote].
+	ConfigKeyPartialCloneFilter = "partialclonefilter"
+
+	// ConfigSectionBranch stores per-branch settings as "<branch>.<key>" keys.
+	ConfigSectionBranch = "branch"
+	// ConfigKeyRemote is the upstream remote key suffix under [branch] ("<branch>.remote").
+	ConfigKeyRemote = "remote"
+	// ConfigKeyMerge is the upstream ref key suffix under [branch] ("<branch>.merge").
+	ConfigKeyMerge = "merge"
+
+	// ConfigSectionPull stores pull integration defaults.
+	ConfigSectionPull = "pull"
+	// ConfigKeyMode is the pull mode key under [pull] (ff-only, merge, or rebase).
+	ConfigKeyMode = "mode"
+
+	// ConfigSectionFilter stores clean/smudge drivers as "<driver>.<key>" keys.
+	ConfigSectionFilter = "filter"
+	// ConfigKeyClean is the command key suffix run when content enters the object store.
+	ConfigKeyClean = "clean"
+	// ConfigKeySmudge is the command key suffix run when content is checked out.
+	ConfigKeySmudge = "smudge"
+	// ConfigKeyRequired makes a missing or failing driver an error instead of a pass-through.
+	ConfigKeyRequired = "required"
+
+	// ConfigSectionDiff stores diff drivers as "<driver>.<key>" keys.
+	ConfigSectionDiff = "diff"
+	// ConfigKeyTextConv is the command key suffix converting content to text before diffing.
+	ConfigKeyTextConv = "textconv"
+
+	// ConfigSectionLFS stores large file storage settings.
+	ConfigSectionLFS = "lfs"
+	// ConfigKeyThreshold is the file size under [lfs] above which content is stored as a pointer.
+	ConfigKeyThreshold = "threshold"
+
+	// ConfigSectionClean stores gel clean settings.
+	ConfigSectionClean = "clean"
+	// ConfigKeyRequireForce makes gel clean refuse to delete without -f, -n or -i (default true).
+	ConfigKeyRequireForce = "requireforce"
+
+	// ConfigSectionIndex stores index file settings.
+	ConfigSectionIndex = "index"
+	// ConfigKeyVersion is the format version of new index files under [index] (2, 3 or 4).
+	ConfigKeyVersion = "version"
+
+	// ConfigSectionCommit stores commit message defaults.
+	ConfigSectionCommit = "commit"
+	// ConfigKeyTemplate is the path of the file pre-filling edited commit messages under [commit].
+	ConfigKeyTemplate = "template"
+	// ConfigKeyGPGSign signs every commit under [commit] (true or false).
+	ConfigKeyGPGSign = "gpgsign"
+	// ConfigKeyCleanup is the commit message cleanup mode under [commit] (default, strip, whitespace, verbatim or scissors).
+	ConfigKeyCleanup = "cleanup"
+
+	// ConfigSectionSubmodule stores initialized submodules as "<name>.url" keys.
+	ConfigSectionSubmodule = "submodule"
+
+	// DefaultLFSRemoteName is the remote whose large file store is used when lfs.url is unset.
+	DefaultLFSRemoteName = "origin"
 )
 
 // ConfigService manages repository config stored in .gel/config.toml.
@@ -52,6 +147,51 @@ func (c *ConfigService) GetUserInfo() (string, string, error) {
 	return name, email, nil
 }
 
+// GetOptional returns a config value by section and key and reports whether it is set.
+// Unlike Get, a missing key is not an error.
+func (c *ConfigService) GetOptional(section, key string) (string, bool, error) {
+	config, err := c.Read()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := config.Get(section, key)
+	return value, ok, nil
+}
+
+// PromisorRemote returns the name of the remote marked with remote.<name>.promisor=true.
+// It reports false when the repository is not a partial clone.
+func (c *ConfigService) PromisorRemote() (string, bool, error) {
+	config, err := c.Read()
+	if err != nil {
+		return "", false, err
+	}
+
+	suffix := "." + ConfigKeyPromisor
+	for _, entry := range config.Entries() {
+		if entry.Section != ConfigSectionRemote || !strings.HasSuffix(entry.Key, suffix) {
+			continue
+		}
+		if entry.Value == "true" {
+			return strings.TrimSuffix(entry.Key, suffix), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// IndexVersion returns the index format version configured by index.version.
+// It reports false when the key is unset.
+func (c *ConfigService) IndexVersion() (uint32, bool, error) {
+	value, ok, err := c.GetOptional(ConfigSectionIndex, ConfigKeyVersion)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || !domain.IsSupportedIndexVersion(uint32(version)) {
+		return 0, false, fmt.Errorf("invalid %s.%s %q: expected 2, 3 or 4", ConfigSectionIndex, ConfigKeyVersion, value)
+	}
+	return uint32(version), true, nil
+}
+
 // Set writes section.key=value to config, creating missing sections as needed.
 func (c *ConfigService) Set(section, key, value string) error {
 	config, err := c.Read()
@@ -131,7 +271,7 @@ func (c *ConfigService) Read() (*domain.Config, error) {
 	}
 
 	sections := make(map[string]domain.ConfigSection)
-	if _, err := toml.Decode(string(data), sections); err != nil {
+	if _, err := toml.Decode(string(data), &sections); err != nil {
 		return nil, fmt.Errorf("config: failed to decode config: %w", err)
 	}
 
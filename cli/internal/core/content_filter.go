@@ -0,0 +1,338 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// eolLF and eolCRLF are the values accepted by the eol attribute and core.eol.
+	eolLF   = "lf"
+	eolCRLF = "crlf"
+	// eolNative selects the platform line ending for core.eol.
+	eolNative = "native"
+	// textAuto makes the text attribute and core.autocrlf skip binary content.
+	textAuto = "auto"
+	// autoCRLFInput normalizes on the way in without converting on checkout.
+	autoCRLFInput = "input"
+	// binarySniffLength is how many leading bytes are checked for NUL when detecting binary content.
+	binarySniffLength = 8000
+	// LFSFilterName is the built-in filter driver storing content in the large file store.
+	LFSFilterName = "lfs"
+)
+
+// DiffDriver describes how diff presents one path.
+type DiffDriver struct {
+	// Binary suppresses the textual diff ("-diff" or the binary macro).
+	Binary bool
+	// TextConv converts content to text before diffing. It is read from
+	// diff.<driver>.textconv and receives the content on standard input.
+	TextConv string
+}
+
+// MissingLFSObject is a large file whose payload was neither stored locally
+// nor available from the remote store, so its pointer was checked out.
+type MissingLFSObject struct {
+	// Path is the repository-relative path of the file.
+	Path string
+	// OID is the SHA-256 of the payload named by the pointer.
+	OID domain.Hash
+}
+
+// ContentFilter converts file content between its working tree form and the
+// form stored in blobs, as directed by attributes and config.
+//
+// Clean runs on the way into the object store: the filter driver's clean
+// command first, then CRLF to LF normalization of text files. Smudge runs on
+// checkout in the opposite order: LF to CRLF for text files checked out with
+// CRLF endings, then the driver's smudge command. Paths outside the working
+// tree pass through unchanged.
+//
+// Large files bypass both pipelines: content of paths with filter=lfs, or at
+// least lfs.threshold bytes long, is moved to the large file store and cleaned
+// to a pointer, and pointers are smudged back to their payload.
+type ContentFilter struct {
+	attributeMatcher *AttributeMatcher
+	lfsStore         *LFSStore
+	configService    *ConfigService
+	workspace        *domain.Workspace
+
+	mutex             sync.Mutex
+	missingLFSObjects []MissingLFSObject
+}
+
+// NewContentFilter creates a content filter.
+func NewContentFilter(
+	attributeMatcher *AttributeMatcher,
+	lfsStore *LFSStore,
+	configService *ConfigService,
+	workspace *domain.Workspace,
+) *ContentFilter {
+	return &ContentFilter{
+		attributeMatcher: attributeMatcher,
+		lfsStore:         lfsStore,
+		configService:    configService,
+		workspace:        workspace,
+	}
+}
+
+// Clean converts working tree content at path to its repository form.
+func (f *ContentFilter) Clean(path domain.AbsolutePath, data []byte) ([]byte, error) {
+	relPath, ok := f.relativePath(path)
+	if !ok {
+		return data, nil
+	}
+	attributes, config, err := f.load(relPath)
+	if err != nil {
+		return nil, err
+	}
+	if pointer, ok, err := f.cleanLFS(config, attributes, data); err != nil || ok {
+		return pointer, err
+	}
+
+	data, err = runFilterDriver(config, attributes, ConfigKeyClean, relPath, f.workspace.RepoDir, data)
+	if err != nil {
+		return nil, err
+	}
+	if convertsText(config, attributes, data) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return data, nil
+}
+
+// Smudge converts repository content for path to its working tree form.
+func (f *ContentFilter) Smudge(path domain.AbsolutePath, data []byte) ([]byte, error) {
+	relPath, ok := f.relativePath(path)
+	if !ok {
+		return data, nil
+	}
+	if pointer, err := domain.ParseLFSPointer(data); err == nil {
+		return f.smudgeLFS(relPath, pointer, data)
+	}
+	attributes, config, err := f.load(relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if convertsText(config, attributes, data) && checkoutEOL(config, attributes) == eolCRLF {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+	return runFilterDriver(config, attributes, ConfigKeySmudge, relPath, f.workspace.RepoDir, data)
+}
+
+// cleanLFS moves data to the large file store and returns its pointer when the
+// path has filter=lfs or data reaches lfs.threshold. Content that already is a
+// pointer is kept as is. It reports false when the path is not a large file.
+func (f *ContentFilter) cleanLFS(
+	config *domain.Config,
+	attributes map[string]string,
+	data []byte,
+) ([]byte, bool, error) {
+	if attributes[AttributeFilter] != LFSFilterName {
+		value, _ := config.Get(ConfigSectionLFS, ConfigKeyThreshold)
+		threshold, err := ParseLFSThreshold(value)
+		if err != nil {
+			return nil, false, err
+		}
+		if threshold == 0 || int64(len(data)) < threshold {
+			return nil, false, nil
+		}
+	}
+	if domain.IsLFSPointer(data) {
+		return data, true, nil
+	}
+	pointer, err := f.lfsStore.Put(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return pointer.Serialize(), true, nil
+}
+
+// smudgeLFS returns the payload for pointer, fetching it from the remote large
+// file store when it is not stored locally. Without the payload the pointer
+// itself is checked out, so checkout keeps working offline, and the path is
+// recorded for TakeMissingLFSObjects.
+func (f *ContentFilter) smudgeLFS(relPath string, pointer domain.LFSPointer, data []byte) ([]byte, error) {
+	payload, err := f.lfsStore.Get(pointer)
+	if errors.Is(err, ErrLFSObjectNotFound) {
+		if remote, remoteErr := RemoteLFSStore(f.configService); remoteErr == nil {
+			if f.lfsStore.CopyFrom(remote, pointer) == nil {
+				payload, err = f.lfsStore.Get(pointer)
+			}
+		}
+	}
+	if errors.Is(err, ErrLFSObjectNotFound) {
+		f.mutex.Lock()
+		f.missingLFSObjects = append(f.missingLFSObjects, MissingLFSObject{Path: relPath, OID: pointer.OID})
+		f.mutex.Unlock()
+		return data, nil
+	}
+	return payload, err
+}
+
+// TakeMissingLFSObjects returns the large files whose pointers Smudge checked
+// out since the last call, in the order they were written.
+func (f *ContentFilter) TakeMissingLFSObjects() []MissingLFSObject {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	missing := f.missingLFSObjects
+	f.missingLFSObjects = nil
+	return missing
+}
+
+// DiffDriver returns the diff settings for the repository-relative path.
+func (f *ContentFilter) DiffDriver(path domain.NormalizedPath) (DiffDriver, error) {
+	attributes, config, err := f.load(path.String())
+	if err != nil {
+		return DiffDriver{}, err
+	}
+
+	switch name := attributes[AttributeDiff]; name {
+	case "", AttributeSet:
+		return DiffDriver{}, nil
+	case AttributeUnset:
+		return DiffDriver{Binary: true}, nil
+	default:
+		textConv, _ := config.Get(ConfigSectionDiff, name+"."+ConfigKeyTextConv)
+		return DiffDriver{TextConv: textConv}, nil
+	}
+}
+
+// TextConv runs a diff driver's textconv command over data.
+func (f *ContentFilter) TextConv(command string, data []byte) ([]byte, error) {
+	output, err := runFilterCommand(command, "", f.workspace.RepoDir, data)
+	if err != nil {
+		return nil, fmt.Errorf("textconv '%s': %w", command, err)
+	}
+	return output, nil
+}
+
+// IsBinary reports whether data looks binary, i.e. has a NUL byte near its start.
+func IsBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLength)], 0) >= 0
+}
+
+// relativePath converts path to a repository-relative path, reporting false outside the working tree.
+func (f *ContentFilter) relativePath(path domain.AbsolutePath) (string, bool) {
+	normalizedPath, err := path.ToNormalizedPath(f.workspace.RepoDir)
+	if err != nil || normalizedPath.String() == "" {
+		return "", false
+	}
+	return normalizedPath.String(), true
+}
+
+// load returns the attributes for relPath together with the current config.
+func (f *ContentFilter) load(relPath string) (map[string]string, *domain.Config, error) {
+	attributes, err := f.attributeMatcher.Attributes(relPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := f.configService.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	return attributes, config, nil
+}
+
+// convertsText reports whether line endings of data are normalized for a path with attributes.
+// The text attribute decides when specified; eol implies text; otherwise core.autocrlf
+// enables normalization of content that is not binary.
+func convertsText(config *domain.Config, attributes map[string]string, data []byte) bool {
+	switch attributes[AttributeText] {
+	case AttributeSet:
+		return true
+	case AttributeUnset:
+		return false
+	case textAuto:
+		return !IsBinary(data)
+	}
+	if _, ok := attributes[AttributeEOL]; ok {
+		return true
+	}
+	autoCRLF, _ := config.Get(ConfigSectionCore, ConfigKeyAutoCRLF)
+	if autoCRLF == "true" || autoCRLF == autoCRLFInput {
+		return !IsBinary(data)
+	}
+	return false
+}
+
+// checkoutEOL returns the line ending text files are checked out with: the eol
+// attribute, then core.autocrlf, then core.eol, defaulting to the native ending.
+func checkoutEOL(config *domain.Config, attributes map[string]string) string {
+	if eol, ok := attributes[AttributeEOL]; ok {
+		return eol
+	}
+	switch autoCRLF, _ := config.Get(ConfigSectionCore, ConfigKeyAutoCRLF); autoCRLF {
+	case "true":
+		return eolCRLF
+	case autoCRLFInput:
+		return eolLF
+	}
+	eol, _ := config.Get(ConfigSectionCore, ConfigKeyEOL)
+	if eol == eolLF || eol == eolCRLF {
+		return eol
+	}
+	if runtime.GOOS == "windows" {
+		return eolCRLF
+	}
+	return eolLF
+}
+
+// runFilterDriver runs the clean or smudge command of the path's filter driver.
+// Without a driver the data is returned as is. A driver that is not configured
+// or fails is skipped unless filter.<driver>.required is true.
+func runFilterDriver(
+	config *domain.Config,
+	attributes map[string]string,
+	key, relPath string,
+	repoDir domain.AbsolutePath,
+	data []byte,
+) ([]byte, error) {
+	name := attributes[AttributeFilter]
+	if name == "" || name == AttributeSet || name == AttributeUnset {
+		return data, nil
+	}
+
+	required, _ := config.Get(ConfigSectionFilter, name+"."+ConfigKeyRequired)
+	command, _ := config.Get(ConfigSectionFilter, name+"."+key)
+	if strings.TrimSpace(command) == "" {
+		if required == "true" {
+			return nil, fmt.Errorf("%w: filter.%s.%s is not set for '%s'", ErrFilterFailed, name, key, relPath)
+		}
+		return data, nil
+	}
+
+	output, err := runFilterCommand(command, relPath, repoDir, data)
+	if err != nil {
+		if required == "true" {
+			return nil, fmt.Errorf("%w: %s filter '%s' on '%s': %v", ErrFilterFailed, key, name, relPath, err)
+		}
+		return data, nil
+	}
+	return output, nil
+}
+
+// runFilterCommand runs command through the shell at repoDir with data on
+// standard input and returns its standard output. "%f" in command expands to relPath.
+func runFilterCommand(command, relPath string, repoDir domain.AbsolutePath, data []byte) ([]byte, error) {
+	script := strings.ReplaceAll(command, "%f", `"$1"`)
+	cmd := exec.Command("sh", "-c", script, command, relPath)
+	cmd.Dir = repoDir.String()
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
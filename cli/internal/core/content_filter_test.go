@@ -0,0 +1,53 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestContentFilter initializes a repository and returns its content
+// filter, its large file store, and its working tree root.
+func newTestContentFilter(t *testing.T) (*ContentFilter, *LFSStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+	require.NoError(t, err)
+	workspace, err := domain.NewWorkspace(dir)
+	require.NoError(t, err)
+	lfsStore := NewLFSStore(workspace.LFSObjectsDir.String())
+	configService := NewConfigService(storage.NewConfigStorage(workspace))
+	return NewContentFilter(NewAttributeMatcher(workspace), lfsStore, configService, workspace), lfsStore,
+		workspace.RepoDir.String()
+}
+
+func TestContentFilterSmudgeRecordsMissingLFSObjects(t *testing.T) {
+	contentFilter, lfsStore, dir := newTestContentFilter(t)
+	stored, err := lfsStore.Put([]byte("stored payload"))
+	require.NoError(t, err)
+	missing, err := lfsStore.Put([]byte("missing payload"))
+	require.NoError(t, err)
+	require.NoError(t, lfsStore.Remove(missing.OID))
+
+	storedPath, err := domain.NewAbsolutePath(filepath.Join(dir, "stored.bin"))
+	require.NoError(t, err)
+	data, err := contentFilter.Smudge(storedPath, stored.Serialize())
+	require.NoError(t, err)
+	assert.Equal(t, "stored payload", string(data))
+
+	missingPath, err := domain.NewAbsolutePath(filepath.Join(dir, "dir", "missing.bin"))
+	require.NoError(t, err)
+	data, err = contentFilter.Smudge(missingPath, missing.Serialize())
+	require.NoError(t, err)
+	assert.Equal(t, missing.Serialize(), data)
+
+	assert.Equal(
+		t, []MissingLFSObject{{Path: "dir/missing.bin", OID: missing.OID}}, contentFilter.TakeMissingLFSObjects(),
+	)
+	assert.Empty(t, contentFilter.TakeMissingLFSObjects())
+}
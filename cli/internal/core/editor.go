@@ -0,0 +1,63 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultEditor is used when no editor is configured.
+const defaultEditor = "vi"
+
+// Editor launches the user's text editor on a file.
+type Editor struct {
+	configService *ConfigService
+}
+
+// NewEditor creates an editor launcher.
+func NewEditor(configService *ConfigService) *Editor {
+	return &Editor{
+		configService: configService,
+	}
+}
+
+// Command returns the editor command line. It is taken from GEL_EDITOR,
+// core.editor, VISUAL and EDITOR in that order, falling back to vi.
+func (e *Editor) Command() (string, error) {
+	if command := strings.TrimSpace(os.Getenv(domain.GelEditorEnvVar)); command != "" {
+		return command, nil
+	}
+	command, ok, err := e.configService.GetOptional(ConfigSectionCore, ConfigKeyEditor)
+	if err != nil {
+		return "", err
+	}
+	if ok && strings.TrimSpace(command) != "" {
+		return command, nil
+	}
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if command := strings.TrimSpace(os.Getenv(name)); command != "" {
+			return command, nil
+		}
+	}
+	return defaultEditor, nil
+}
+
+// Edit opens path in the editor and waits for it to exit.
+// The command is run through the shell so it may carry arguments.
+func (e *Editor) Edit(path string) error {
+	command, err := e.Command()
+	if err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", command+` "$@"`, command, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor: '%s' failed: %w", command, err)
+	}
+	return nil
+}
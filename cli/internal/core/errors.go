@@ -20,4 +20,49 @@ var (
 
 	// ErrUnknownPathspecType is returned when a pathspec cannot be classified.
 	ErrUnknownPathspecType = errors.New("unknown pathspec type")
+
+	// ErrFilterFailed is returned when a required clean/smudge filter driver is missing or fails.
+	ErrFilterFailed = errors.New("content filter failed")
+
+	// ErrLFSObjectNotFound is returned when a large file payload is not in the store.
+	ErrLFSObjectNotFound = errors.New("lfs object not found")
+
+	// ErrLFSObjectCorrupt is returned when a stored payload does not match its pointer.
+	ErrLFSObjectCorrupt = errors.New("lfs object does not match its pointer")
+
+	// ErrNoLFSRemote is returned when no large file store is configured to fetch from or push to.
+	ErrNoLFSRemote = errors.New("no lfs remote configured")
+
+	// ErrHookFailed is returned when a hook that can abort an operation exits with a non-zero status.
+	ErrHookFailed = errors.New("hook failed")
+
+	// ErrInvalidSSHKey is returned when an SSH key cannot be parsed.
+	ErrInvalidSSHKey = errors.New("invalid ssh key")
+
+	// ErrEncryptedSSHKey is returned for a passphrase-protected SSH private key.
+	ErrEncryptedSSHKey = errors.New("encrypted ssh private keys are not supported")
+
+	// ErrUnsupportedSSHKeyType is returned for SSH keys other than ed25519.
+	ErrUnsupportedSSHKeyType = errors.New("unsupported ssh key type")
+
+	// ErrInvalidSSHSignature is returned when an SSH signature cannot be parsed.
+	ErrInvalidSSHSignature = errors.New("invalid ssh signature")
+
+	// ErrBadSignature is returned when a signature does not match the signed data.
+	ErrBadSignature = errors.New("bad signature")
+
+	// ErrNoSigningKey is returned when signing without user.signingkey.
+	ErrNoSigningKey = errors.New("user.signingkey is not set")
+
+	// ErrNoAllowedSigners is returned when verifying without gpg.ssh.allowedSignersFile.
+	ErrNoAllowedSigners = errors.New("gpg.ssh.allowedSignersFile needs to be configured")
+
+	// ErrNoSignature is returned when verifying a commit that is not signed.
+	ErrNoSignature = errors.New("no signature found")
+
+	// ErrNoPrincipal is returned when no allowed signer matches the key of a good signature.
+	ErrNoPrincipal = errors.New("no principal matched")
+
+	// ErrInvalidFSMonitorResponse is returned when a filesystem monitor answer has no token.
+	ErrInvalidFSMonitorResponse = errors.New("fsmonitor response has no token")
 )
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FSMonitorProtocolVersion is the hook protocol version passed as the first hook argument.
+	FSMonitorProtocolVersion = 2
+	// FSMonitorAllPaths is the path a monitor reports when every path may have changed.
+	FSMonitorAllPaths = "/"
+	// FSMonitorSocketName is the socket of the built-in daemon inside the metadata directory.
+	FSMonitorSocketName = "fsmonitor--daemon.ipc"
+	// FSMonitorQueryCommand asks the built-in daemon for changes since a token.
+	FSMonitorQueryCommand = "query"
+	// FSMonitorStopCommand asks the built-in daemon to exit.
+	FSMonitorStopCommand = "stop"
+
+	// fsmonitorDialTimeout bounds how long a query waits for the built-in daemon.
+	fsmonitorDialTimeout = time.Second
+)
+
+// FSMonitorResult is a filesystem monitor's answer to "what changed since token".
+type FSMonitorResult struct {
+	// Token identifies the moment of this answer; the next query passes it back.
+	Token string
+	// All is set when every path must be treated as changed.
+	All bool
+	// Paths lists the repository-relative paths reported as changed. A
+	// directory path covers everything below it.
+	Paths []string
+	// HookError is set when the hook failed or its answer could not be
+	// parsed. All is then set.
+	HookError error
+}
+
+// FSMonitor asks a filesystem monitor which paths changed since a token so
+// working tree scans can skip everything else.
+//
+// core.fsmonitor selects the monitor: "true" uses the built-in daemon
+// (gel fsmonitor--daemon), any other non-false value is a hook command. A
+// hook is run as "<command> 2 <token>" and prints the new token and the
+// changed paths, all NUL-terminated; the path "/" means everything changed.
+type FSMonitor struct {
+	configService *ConfigService
+	workspace     *domain.Workspace
+
+	mutex      sync.Mutex
+	lastToken  string
+	lastResult *FSMonitorResult
+	hookErrors []error
+}
+
+// NewFSMonitor creates a filesystem monitor client.
+func NewFSMonitor(configService *ConfigService, workspace *domain.Workspace) *FSMonitor {
+	return &FSMonitor{
+		configService: configService,
+		workspace:     workspace,
+	}
+}
+
+// Query returns the changes since token. It reports false when no monitor is
+// configured. A built-in daemon that is not running, or a failing hook,
+// yields a result with All set and no token, so callers fall back to a full
+// scan; a failing hook also sets HookError.
+func (f *FSMonitor) Query(token string) (FSMonitorResult, bool, error) {
+	value, ok, err := f.configService.GetOptional(ConfigSectionCore, ConfigKeyFSMonitor)
+	if err != nil || !ok || strings.TrimSpace(value) == "" {
+		return FSMonitorResult{}, false, err
+	}
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		if !enabled {
+			return FSMonitorResult{}, false, nil
+		}
+		return f.queryDaemon(token), true, nil
+	}
+	return f.queryHook(value, token), true, nil
+}
+
+// Refresh brings the fsmonitor state of a freshly read index up to date: it
+// asks the monitor what changed since index.FSMonitorToken, clears the
+// FSMonitorValid bits of reported entries and stores the new token. Without
+// a monitor, or when it cannot tell, every bit is cleared. Answers are
+// remembered per token so repeated reads in one command query only once.
+// Hook failures are kept for TakeHookErrors.
+func (f *FSMonitor) Refresh(index *domain.Index) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.lastResult == nil || f.lastToken != index.FSMonitorToken {
+		result, ok, err := f.Query(index.FSMonitorToken)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			result = FSMonitorResult{All: true}
+		}
+		if result.HookError != nil {
+			f.hookErrors = append(f.hookErrors, result.HookError)
+		}
+		f.lastToken, f.lastResult = index.FSMonitorToken, &result
+	}
+
+	index.ApplyFSMonitorChanges(f.lastResult.Paths, f.lastResult.All || f.lastResult.Token == "")
+	index.FSMonitorToken = f.lastResult.Token
+	return nil
+}
+
+// TakeHookErrors returns the hook failures Refresh met since the last call.
+// Each made Refresh fall back to a full scan.
+func (f *FSMonitor) TakeHookErrors() []error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	hookErrors := f.hookErrors
+	f.hookErrors = nil
+	return hookErrors
+}
+
+// SocketPath returns the location of the built-in daemon's socket.
+func (f *FSMonitor) SocketPath() string {
+	return filepath.Join(f.workspace.GelDir.String(), FSMonitorSocketName)
+}
+
+// queryHook runs the hook command and parses its answer.
+func (f *FSMonitor) queryHook(command, token string) FSMonitorResult {
+	cmd := exec.Command("sh", "-c", command+` "$@"`, command, strconv.Itoa(FSMonitorProtocolVersion), token)
+	cmd.Dir = f.workspace.RepoDir.String()
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return FSMonitorResult{All: true, HookError: fmt.Errorf("fsmonitor hook '%s' failed: %w", command, err)}
+	}
+	result, err := ParseFSMonitorResponse(output)
+	if err != nil {
+		return FSMonitorResult{All: true, HookError: fmt.Errorf("fsmonitor hook '%s': %w", command, err)}
+	}
+	return result
+}
+
+// queryDaemon asks the built-in daemon over its socket.
+func (f *FSMonitor) queryDaemon(token string) FSMonitorResult {
+	conn, err := net.DialTimeout("unix", f.SocketPath(), fsmonitorDialTimeout)
+	if err != nil {
+		return FSMonitorResult{All: true}
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "%s %s\n", FSMonitorQueryCommand, token); err != nil {
+		return FSMonitorResult{All: true}
+	}
+	output, err := io.ReadAll(bufio.NewReader(conn))
+	if err != nil {
+		return FSMonitorResult{All: true}
+	}
+	result, err := ParseFSMonitorResponse(output)
+	if err != nil {
+		return FSMonitorResult{All: true}
+	}
+	return result
+}
+
+// ParseFSMonitorResponse parses "<token> NUL (<path> NUL)*".
+func ParseFSMonitorResponse(data []byte) (FSMonitorResult, error) {
+	fields := bytes.Split(data, []byte{0})
+	if len(fields) < 2 || len(fields[0]) == 0 {
+		return FSMonitorResult{}, ErrInvalidFSMonitorResponse
+	}
+
+	result := FSMonitorResult{Token: string(fields[0])}
+	for _, field := range fields[1:] {
+		path := string(field)
+		switch path {
+		case "":
+			continue
+		case FSMonitorAllPaths:
+			result.All = true
+		default:
+			result.Paths = append(result.Paths, strings.Trim(filepath.ToSlash(path), "/"))
+		}
+	}
+	return result, nil
+}
+
+// FormatFSMonitorResponse encodes a result in the form ParseFSMonitorResponse reads.
+func FormatFSMonitorResponse(result FSMonitorResult) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(result.Token)
+	buffer.WriteByte(0)
+	if result.All {
+		buffer.WriteString(FSMonitorAllPaths)
+		buffer.WriteByte(0)
+		return buffer.Bytes()
+	}
+	for _, path := range result.Paths {
+		buffer.WriteString(path)
+		buffer.WriteByte(0)
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSMonitorRefreshKeepsHookErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		hook          string
+		wantToken     string
+		wantHookError bool
+	}{
+		{name: "answer", hook: `printf 'token\0a.txt\0'`, wantToken: "token"},
+		{name: "failing hook", hook: "exit 3", wantHookError: true},
+		{name: "malformed answer", hook: "printf garbage", wantHookError: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+				require.NoError(t, err)
+				workspace, err := domain.NewWorkspace(dir)
+				require.NoError(t, err)
+				configService := NewConfigService(storage.NewConfigStorage(workspace))
+				require.NoError(t, configService.Set(ConfigSectionCore, ConfigKeyFSMonitor, tt.hook))
+				fsMonitor := NewFSMonitor(configService, workspace)
+
+				index := domain.NewEmptyIndex()
+				require.NoError(t, fsMonitor.Refresh(index))
+
+				assert.Equal(t, tt.wantToken, index.FSMonitorToken)
+				hookErrors := fsMonitor.TakeHookErrors()
+				if tt.wantHookError {
+					require.Len(t, hookErrors, 1)
+					assert.Contains(t, hookErrors[0].Error(), tt.hook)
+				} else {
+					assert.Empty(t, hookErrors)
+				}
+				assert.Empty(t, fsMonitor.TakeHookErrors())
+			},
+		)
+	}
+}
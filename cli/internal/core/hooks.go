@@ -0,0 +1,159 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Hook names. Each hook is an executable named after its hook in the hooks
+// directory, run at the working tree root (the repository directory when
+// bare) with its standard output sent to standard error. Standard input is
+// empty unless the hook documents otherwise. The environment is that of the
+// command plus GEL_DIR, the per-worktree metadata directory, so that gel
+// commands run by the hook address the same repository, and GEL_INDEX_FILE,
+// the index path. Pre-hooks abort their operation by exiting with a non-zero
+// status; the exit status of post-hooks is only reported.
+const (
+	// HookPreCommit runs before commit writes the tree, without arguments.
+	// It may stage files, which then become part of the commit.
+	HookPreCommit = "pre-commit"
+	// HookCommitMsg runs after the commit message is written, with the path of
+	// the file holding the message. It may rewrite the message in place.
+	HookCommitMsg = "commit-msg"
+	// HookPostCommit runs after commit moves the branch, without arguments.
+	HookPostCommit = "post-commit"
+	// HookPreMergeCommit runs before pull records a merge commit, without arguments.
+	HookPreMergeCommit = "pre-merge-commit"
+	// HookPostMerge runs after pull merges or fast-forwards the current branch,
+	// with "0" as its argument since Gel has no squash merges.
+	HookPostMerge = "post-merge"
+	// HookPostCheckout runs after switch and reset --hard update the working
+	// tree, with the previous and new HEAD commits and "1" for a branch checkout.
+	HookPostCheckout = "post-checkout"
+	// HookPrePush runs before push copies objects or updates the remote, with
+	// the remote name and URL. Standard input holds one line per pushed ref:
+	// "<local ref> <local hash> <remote ref> <remote hash>", where a zero
+	// remote hash means the remote ref does not exist yet.
+	HookPrePush = "pre-push"
+)
+
+// HookRunner runs the hooks in .gel/hooks, or in core.hooksPath when set. A
+// nil HookRunner runs no hooks.
+//
+// Hooks skipped because they are not executable and failures of post-hooks
+// do not fail the command; they are kept for TakeIgnoredHooks and
+// TakePostHookErrors so the caller can report them.
+type HookRunner struct {
+	configService *ConfigService
+	workspace     *domain.Workspace
+
+	ignoredHooks   []string
+	postHookErrors []error
+}
+
+// NewHookRunner creates a hook runner for the repository of workspace.
+func NewHookRunner(configService *ConfigService, workspace *domain.Workspace) *HookRunner {
+	return &HookRunner{
+		configService: configService,
+		workspace:     workspace,
+	}
+}
+
+// Dir returns the hooks directory. A relative core.hooksPath is taken from
+// the working tree root and a leading "~/" is the home directory.
+func (h *HookRunner) Dir() (string, error) {
+	path, ok, err := h.configService.GetOptional(ConfigSectionCore, ConfigKeyHooksPath)
+	if err != nil {
+		return "", err
+	}
+	if !ok || strings.TrimSpace(path) == "" {
+		return filepath.Join(h.workspace.CommonDir.String(), domain.HooksDirName), nil
+	}
+	return ExpandPath(path, h.workspace.RepoDir.String())
+}
+
+// Run runs the hook name with args and returns an error wrapping
+// ErrHookFailed when it exits with a non-zero status. A missing hook
+// succeeds; a hook file that is not executable is ignored and recorded for
+// TakeIgnoredHooks.
+func (h *HookRunner) Run(name string, args ...string) error {
+	return h.RunWithInput(name, nil, args...)
+}
+
+// RunWithInput runs the hook name like Run with input as its standard input.
+// A nil input leaves standard input empty.
+func (h *HookRunner) RunWithInput(name string, input io.Reader, args ...string) error {
+	if h == nil {
+		return nil
+	}
+	dir, err := h.Dir()
+	if err != nil {
+		return fmt.Errorf("hook '%s': %w", name, err)
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("hook '%s': %w", name, err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		h.ignoredHooks = append(h.ignoredHooks, name)
+		return nil
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Dir = h.workspace.RepoDir.String()
+	if h.workspace.Bare {
+		cmd.Dir = h.workspace.GelDir.String()
+	}
+	cmd.Env = append(
+		os.Environ(),
+		domain.GelDirEnvVar+"="+h.workspace.GelDir.String(),
+		domain.GelIndexFileEnvVar+"="+h.workspace.IndexPath.String(),
+	)
+	cmd.Stdin = input
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: '%s': %v", ErrHookFailed, name, err)
+	}
+	return nil
+}
+
+// RunPost runs the post-hook name with args. Its failure cannot undo the
+// completed operation, so it is recorded for TakePostHookErrors instead of
+// being returned.
+func (h *HookRunner) RunPost(name string, args ...string) {
+	if err := h.Run(name, args...); err != nil {
+		h.postHookErrors = append(h.postHookErrors, err)
+	}
+}
+
+// TakeIgnoredHooks returns the names of the hooks skipped since the last call
+// because they are not executable.
+func (h *HookRunner) TakeIgnoredHooks() []string {
+	if h == nil {
+		return nil
+	}
+	ignoredHooks := h.ignoredHooks
+	h.ignoredHooks = nil
+	return ignoredHooks
+}
+
+// TakePostHookErrors returns the post-hook failures since the last call.
+func (h *HookRunner) TakePostHookErrors() []error {
+	if h == nil {
+		return nil
+	}
+	postHookErrors := h.postHookErrors
+	h.postHookErrors = nil
+	return postHookErrors
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookRunnerRunPost(t *testing.T) {
+	tests := []struct {
+		name            string
+		script          string
+		perm            os.FileMode
+		wantIgnored     []string
+		wantPostFailure bool
+	}{
+		{name: "missing hook"},
+		{name: "succeeding hook", script: "#!/bin/sh\nexit 0\n", perm: 0o755},
+		{name: "failing hook", script: "#!/bin/sh\nexit 1\n", perm: 0o755, wantPostFailure: true},
+		{
+			name: "not executable", script: "#!/bin/sh\nexit 1\n", perm: 0o644,
+			wantIgnored: []string{HookPostCommit},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+				require.NoError(t, err)
+				workspace, err := domain.NewWorkspace(dir)
+				require.NoError(t, err)
+				hookRunner := NewHookRunner(NewConfigService(storage.NewConfigStorage(workspace)), workspace)
+				if tt.script != "" {
+					hooksDir, err := hookRunner.Dir()
+					require.NoError(t, err)
+					require.NoError(t, os.MkdirAll(hooksDir, 0o755))
+					require.NoError(t, os.WriteFile(filepath.Join(hooksDir, HookPostCommit), []byte(tt.script), tt.perm))
+				}
+
+				hookRunner.RunPost(HookPostCommit)
+
+				assert.Equal(t, tt.wantIgnored, hookRunner.TakeIgnoredHooks())
+				postHookErrors := hookRunner.TakePostHookErrors()
+				if tt.wantPostFailure {
+					require.Len(t, postHookErrors, 1)
+					assert.ErrorIs(t, postHookErrors[0], ErrHookFailed)
+				} else {
+					assert.Empty(t, postHookErrors)
+				}
+				assert.Empty(t, hookRunner.TakeIgnoredHooks())
+				assert.Empty(t, hookRunner.TakePostHookErrors())
+			},
+		)
+	}
+}
+
+func TestNilHookRunner(t *testing.T) {
+	var hookRunner *HookRunner
+
+	hookRunner.RunPost(HookPostCommit)
+
+	assert.NoError(t, hookRunner.Run(HookPreCommit))
+	assert.Empty(t, hookRunner.TakeIgnoredHooks())
+	assert.Empty(t, hookRunner.TakePostHookErrors())
+}
@@ -0,0 +1,327 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreRule is one pattern line read from an ignore file.
+type IgnoreRule struct {
+	// Source is the file the rule was read from, repository-relative when it
+	// lives inside the repository.
+	Source string
+	// Line is the 1-based line number of the rule within Source.
+	Line int
+	// Pattern is the rule as written, including a leading "!".
+	Pattern string
+	// Negate reports whether the rule re-includes paths excluded earlier.
+	Negate bool
+
+	// base is the repository-relative directory the rule applies under; ""
+	// for .gelignore at the root, info/exclude and the global excludes file.
+	base     string
+	segments []string
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnoreRules parses ignore file content. Rules apply to paths below base.
+//
+// Syntax follows Git: blank lines and "#" comments are skipped, "!" negates,
+// a trailing "/" matches directories only, a pattern containing a non-trailing
+// "/" is anchored to base, and "**" matches any number of directories.
+func ParseIgnoreRules(data []byte, source, base string) []IgnoreRule {
+	var rules []IgnoreRule
+	for i, line := range strings.Split(string(data), "\n") {
+		line = trimIgnoreLine(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := IgnoreRule{Source: source, Line: i + 1, Pattern: line, base: base}
+		pattern := line
+		if strings.HasPrefix(pattern, "!") {
+			rule.Negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		if strings.Contains(pattern, "/") {
+			rule.anchored = true
+			pattern = strings.TrimPrefix(pattern, "/")
+		}
+		if pattern == "" {
+			continue
+		}
+		rule.segments = strings.Split(pattern, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// trimIgnoreLine strips a carriage return and unescaped trailing spaces.
+func trimIgnoreLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	trimmed := strings.TrimRight(line, " ")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		return trimmed[:len(trimmed)-1] + " "
+	}
+	return trimmed
+}
+
+// Matches reports whether the rule's pattern matches the repository-relative
+// slash-separated path. Negation is not applied.
+func (r IgnoreRule) Matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		rest, ok := strings.CutPrefix(relPath, r.base+"/")
+		if !ok {
+			return false
+		}
+		relPath = rest
+	}
+
+	pathSegments := strings.Split(relPath, "/")
+	if !r.anchored {
+		matched, _ := path.Match(r.segments[0], pathSegments[len(pathSegments)-1])
+		return matched
+	}
+	return matchIgnoreSegments(r.segments, pathSegments)
+}
+
+// matchIgnoreSegments matches pattern segments against path segments, letting
+// a "**" segment consume any number of path segments. A trailing "**" must
+// consume at least one, so "dir/**" matches the contents of dir but not dir.
+func matchIgnoreSegments(patternSegments, pathSegments []string) bool {
+	if len(patternSegments) == 0 {
+		return len(pathSegments) == 0
+	}
+	if patternSegments[0] == "**" {
+		if len(patternSegments) == 1 {
+			return len(pathSegments) > 0
+		}
+		for i := 0; i <= len(pathSegments); i++ {
+			if matchIgnoreSegments(patternSegments[1:], pathSegments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(pathSegments) == 0 {
+		return false
+	}
+	matched, _ := path.Match(patternSegments[0], pathSegments[0])
+	return matched && matchIgnoreSegments(patternSegments[1:], pathSegments[1:])
+}
+
+// IgnoreMatcher decides which working tree paths are ignored.
+//
+// Rules are read from the global excludes file (core.excludesfile, defaulting
+// to $XDG_CONFIG_HOME/gel/ignore), .gel/info/exclude, and .gelignore files in
+// every directory, in increasing order of precedence. Within the same file the
+// last matching rule wins. As in Git, a path inside an ignored directory
+// cannot be re-included by a negated rule.
+type IgnoreMatcher struct {
+	workspace     *domain.Workspace
+	configService *ConfigService
+
+	loaded       bool
+	excludeRules []IgnoreRule
+	dirRules     map[string][]IgnoreRule
+}
+
+// NewIgnoreMatcher creates an ignore matcher. Ignore files are read lazily on first use.
+func NewIgnoreMatcher(workspace *domain.Workspace, configService *ConfigService) *IgnoreMatcher {
+	return &IgnoreMatcher{
+		workspace:     workspace,
+		configService: configService,
+		dirRules:      make(map[string][]IgnoreRule),
+	}
+}
+
+// IsIgnored reports whether the repository-relative path is ignored.
+func (m *IgnoreMatcher) IsIgnored(relPath string, isDir bool) (bool, error) {
+	rule, err := m.Match(relPath, isDir)
+	if err != nil {
+		return false, err
+	}
+	return rule != nil && !rule.Negate, nil
+}
+
+// Match returns the rule deciding whether relPath is ignored, or nil when no
+// rule matches. A returned negated rule means the path is explicitly not ignored.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) (*IgnoreRule, error) {
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	if relPath == "" {
+		return nil, nil
+	}
+	segments := strings.Split(relPath, "/")
+	for i := 1; i < len(segments); i++ {
+		rule, err := m.matchPath(strings.Join(segments[:i], "/"), true)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil && !rule.Negate {
+			return rule, nil
+		}
+	}
+	return m.matchPath(relPath, isDir)
+}
+
+// matchPath finds the highest-precedence rule matching relPath, ignoring parent directories.
+func (m *IgnoreMatcher) matchPath(relPath string, isDir bool) (*IgnoreRule, error) {
+	dirs := []string{""}
+	if parent := path.Dir(relPath); parent != "." {
+		segments := strings.Split(parent, "/")
+		for i := range segments {
+			dirs = append(dirs, strings.Join(segments[:i+1], "/"))
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules, err := m.rulesForDir(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		if rule := lastMatchingRule(rules, relPath, isDir); rule != nil {
+			return rule, nil
+		}
+	}
+	return lastMatchingRule(m.excludeRules, relPath, isDir), nil
+}
+
+// lastMatchingRule returns the last rule in rules matching relPath.
+func lastMatchingRule(rules []IgnoreRule, relPath string, isDir bool) *IgnoreRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Matches(relPath, isDir) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// rulesForDir returns the rules of the .gelignore file in dir, reading it once.
+func (m *IgnoreMatcher) rulesForDir(dir string) ([]IgnoreRule, error) {
+	if rules, ok := m.dirRules[dir]; ok {
+		return rules, nil
+	}
+
+	source := path.Join(dir, domain.GelIgnoreFileName)
+	rules, err := readIgnoreFile(
+		filepath.Join(m.workspace.RepoDir.String(), filepath.FromSlash(source)), source, dir,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.dirRules[dir] = rules
+	return rules, nil
+}
+
+// load reads the global excludes file and .gel/info/exclude.
+func (m *IgnoreMatcher) load() error {
+	if m.loaded {
+		return nil
+	}
+
+	globalPath, err := m.globalExcludesFile()
+	if err != nil {
+		return err
+	}
+	if globalPath != "" {
+		rules, err := readIgnoreFile(globalPath, globalPath, "")
+		if err != nil {
+			return err
+		}
+		m.excludeRules = append(m.excludeRules, rules...)
+	}
+
+	excludePath := filepath.Join(m.workspace.CommonDir.String(), domain.InfoDirName, domain.ExcludeFileName)
+	source := path.Join(domain.GelDirName, domain.InfoDirName, domain.ExcludeFileName)
+	rules, err := readIgnoreFile(excludePath, source, "")
+	if err != nil {
+		return err
+	}
+	m.excludeRules = append(m.excludeRules, rules...)
+
+	m.loaded = true
+	return nil
+}
+
+// ExcludesFingerprint hashes the location and contents of the global excludes
+// file and .gel/info/exclude, so cached scan results can tell when the
+// repository-wide rules they were computed under have changed.
+func (m *IgnoreMatcher) ExcludesFingerprint() (domain.Hash, error) {
+	globalPath, err := m.globalExcludesFile()
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	excludePath := filepath.Join(m.workspace.CommonDir.String(), domain.InfoDirName, domain.ExcludeFileName)
+
+	var fingerprint []byte
+	for _, filePath := range []string{globalPath, excludePath} {
+		data, err := os.ReadFile(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrPermission) {
+			return domain.Hash{}, fmt.Errorf("ignore: read %s: %w", filePath, err)
+		}
+		fingerprint = append(fingerprint, filePath...)
+		fingerprint = append(fingerprint, 0)
+		fingerprint = append(fingerprint, ComputeSHA256(data)...)
+		fingerprint = append(fingerprint, 0)
+	}
+	return domain.NewHashFromHex(ComputeSHA256(fingerprint))
+}
+
+// globalExcludesFile returns core.excludesfile, or the XDG default location.
+func (m *IgnoreMatcher) globalExcludesFile() (string, error) {
+	value, ok, err := m.configService.GetOptional(ConfigSectionCore, ConfigKeyExcludesFile)
+	if err != nil {
+		return "", err
+	}
+	if ok && value != "" {
+		if rest, found := strings.CutPrefix(value, "~/"); found {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("ignore: resolve %s: %w", value, err)
+			}
+			return filepath.Join(home, rest), nil
+		}
+		return value, nil
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "gel", "ignore"), nil
+}
+
+// readIgnoreFile parses the ignore file at path; a missing file has no rules.
+func readIgnoreFile(filePath, source, base string) ([]IgnoreRule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ignore: read %s: %w", source, err)
+	}
+	return ParseIgnoreRules(data, source, base), nil
+}
@@ -4,12 +4,16 @@ import (
 	"Gel/internal/domain"
 	"Gel/internal/storage"
 	"errors"
+	"fmt"
 	"os"
 )
 
 // IndexService manages loading and saving repository index state.
 type IndexService struct {
-	indexStorage *storage.IndexStorage
+	indexStorage   *storage.IndexStorage
+	configService  *ConfigService
+	fsMonitor      *FSMonitor
+	changeDetector *ChangeDetector
 }
 
 // NewIndexService creates an index service backed by the provided index storage.
@@ -19,26 +23,103 @@ func NewIndexService(indexStorage *storage.IndexStorage) *IndexService {
 	}
 }
 
+// SetConfigService makes Read give an index that has no file yet the format
+// version configured by index.version. An existing index file keeps its
+// version until it is converted with SetVersion.
+func (i *IndexService) SetConfigService(configService *ConfigService) {
+	i.configService = configService
+}
+
+// SetFSMonitor makes Read refresh the fsmonitor state of every index it
+// returns, so FSMonitorValid entry bits can be trusted. Without a monitor the
+// bits are never set on read.
+func (i *IndexService) SetFSMonitor(fsMonitor *FSMonitor) {
+	i.fsMonitor = fsMonitor
+}
+
+// SetChangeDetector makes Write check racily clean entries against the
+// working tree and smudge those whose file was modified, so the change is
+// still detected once the rewritten index is newer than the file.
+func (i *IndexService) SetChangeDetector(changeDetector *ChangeDetector) {
+	i.changeDetector = changeDetector
+}
+
 // Read loads the repository index from storage.
 // If the index file does not exist yet, it returns an empty index.
+// Entries not strictly older than the index file are marked RacilyClean.
 func (i *IndexService) Read() (*domain.Index, error) {
-	data, err := i.indexStorage.Read()
+	data, timestamp, err := i.indexStorage.Read()
 	if errors.Is(err, os.ErrNotExist) {
-		return domain.NewEmptyIndex(), nil
+		data, err = nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return domain.DeserializeIndex(data)
+	index, err := domain.DeserializeIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 && i.configService != nil {
+		version, ok, err := i.configService.IndexVersion()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			index.Header.Version = version
+		}
+	}
+	index.Timestamp = timestamp
+	index.MarkRacilyClean()
+	if i.fsMonitor != nil {
+		if err := i.fsMonitor.Refresh(index); err != nil {
+			return nil, err
+		}
+	} else {
+		index.ApplyFSMonitorChanges(nil, true)
+		index.FSMonitorToken = ""
+	}
+	return index, nil
 }
 
-// Write serializes the given index and persists it to storage.
+// Write serializes the given index and persists it to storage. Racily clean
+// entries are smudged first when their file has changed, and afterwards
+// index.Timestamp is the new write time.
 func (i *IndexService) Write(index *domain.Index) error {
+	if i.changeDetector != nil {
+		if err := i.changeDetector.SmudgeRacilyClean(index); err != nil {
+			return err
+		}
+	}
 	serializedData, err := index.Serialize()
 	if err != nil {
 		return err
 	}
-	return i.indexStorage.Write(serializedData)
+	timestamp, err := i.indexStorage.Write(serializedData)
+	if err != nil {
+		return err
+	}
+	index.Timestamp = timestamp
+	index.MarkRacilyClean()
+	return nil
+}
+
+// SetVersion rewrites the index in the given format version and returns the
+// versions it was stored in before and after. Asking for version 2 or 3 yields
+// whichever of the two the entries need, as decided by domain.Index.Serialize.
+func (i *IndexService) SetVersion(version uint32) (uint32, uint32, error) {
+	if !domain.IsSupportedIndexVersion(version) {
+		return 0, 0, fmt.Errorf("%w: got %d", domain.ErrUnsupportedVersion, version)
+	}
+	index, err := i.Read()
+	if err != nil {
+		return 0, 0, err
+	}
+	previous := index.Header.Version
+	index.Header.Version = version
+	if err := i.Write(index); err != nil {
+		return 0, 0, err
+	}
+	return previous, index.Header.Version, nil
 }
 
 // GetEntries returns the current index entries from storage.
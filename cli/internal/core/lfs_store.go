@@ -0,0 +1,199 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LFSStore is a directory of large file payloads named by their SHA-256 and
+// laid out as <dir>/<oid[0:2]>/<oid[2:4]>/<oid>. It backs both the local
+// .gel/lfs/objects store and directory-based remote stores.
+type LFSStore struct {
+	dir string
+}
+
+// NewLFSStore creates a store rooted at dir. The directory is created on first write.
+func NewLFSStore(dir string) *LFSStore {
+	return &LFSStore{
+		dir: dir,
+	}
+}
+
+// Dir returns the store's root directory.
+func (s *LFSStore) Dir() string {
+	return s.dir
+}
+
+// Path returns the file holding the payload oid.
+func (s *LFSStore) Path(oid domain.Hash) string {
+	hex := oid.Hex()
+	return filepath.Join(s.dir, hex[:2], hex[2:4], hex)
+}
+
+// Has reports whether the payload oid is stored.
+func (s *LFSStore) Has(oid domain.Hash) (bool, error) {
+	return Exists(s.Path(oid))
+}
+
+// Put stores data and returns the pointer describing it. Existing payloads are not rewritten.
+func (s *LFSStore) Put(data []byte) (domain.LFSPointer, error) {
+	oid := domain.Hash(sha256.Sum256(data))
+	pointer := domain.LFSPointer{OID: oid, Size: int64(len(data))}
+
+	exists, err := s.Has(oid)
+	if err != nil || exists {
+		return pointer, err
+	}
+	return pointer, s.write(oid, data)
+}
+
+// Get returns the payload described by pointer after checking its size and hash.
+func (s *LFSStore) Get(pointer domain.LFSPointer) ([]byte, error) {
+	data, err := os.ReadFile(s.Path(pointer.OID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrLFSObjectNotFound, pointer.OID)
+		}
+		return nil, fmt.Errorf("lfs: read %s: %w", pointer.OID, err)
+	}
+
+	if int64(len(data)) != pointer.Size || domain.Hash(sha256.Sum256(data)) != pointer.OID {
+		return nil, fmt.Errorf("%w: %s", ErrLFSObjectCorrupt, pointer.OID)
+	}
+	return data, nil
+}
+
+// CopyFrom copies the payload described by pointer from src into s.
+func (s *LFSStore) CopyFrom(src *LFSStore, pointer domain.LFSPointer) error {
+	data, err := src.Get(pointer)
+	if err != nil {
+		return err
+	}
+	return s.write(pointer.OID, data)
+}
+
+// List returns every payload in the store with its size.
+func (s *LFSStore) List() ([]domain.LFSPointer, error) {
+	var pointers []domain.LFSPointer
+	err := filepath.WalkDir(
+		s.dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) && path == s.dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			oid, err := domain.NewHashFromHex(d.Name())
+			if err != nil {
+				// temporary files and strays are not payloads
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			pointers = append(pointers, domain.LFSPointer{OID: oid, Size: info.Size()})
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: list %s: %w", s.dir, err)
+	}
+	return pointers, nil
+}
+
+// Remove deletes the payload oid. Removing a missing payload is not an error.
+func (s *LFSStore) Remove(oid domain.Hash) error {
+	if err := os.Remove(s.Path(oid)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("lfs: remove %s: %w", oid, err)
+	}
+	return nil
+}
+
+// write stores data under oid through a temporary file so readers never see partial payloads.
+func (s *LFSStore) write(oid domain.Hash, data []byte) error {
+	path := s.Path(oid)
+	if err := os.MkdirAll(filepath.Dir(path), domain.DefaultDirPermission); err != nil {
+		return fmt.Errorf("lfs: write %s: %w", oid, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return fmt.Errorf("lfs: write %s: %w", oid, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("lfs: write %s: %w", oid, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("lfs: write %s: %w", oid, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("lfs: write %s: %w", oid, err)
+	}
+	return nil
+}
+
+// RemoteLFSStore returns the store large files are fetched from and pushed to:
+// the directory named by lfs.url, or else the large file store of the
+// repository at remote.origin.url. It returns ErrNoLFSRemote when neither is usable.
+func RemoteLFSStore(configService *ConfigService) (*LFSStore, error) {
+	url, ok, err := configService.GetOptional(ConfigSectionLFS, ConfigKeyURL)
+	if err != nil {
+		return nil, err
+	}
+	if ok && url != "" {
+		return NewLFSStore(url), nil
+	}
+
+	url, ok, err = configService.GetOptional(ConfigSectionRemote, DefaultLFSRemoteName+"."+ConfigKeyURL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || url == "" {
+		return nil, fmt.Errorf("%w: set lfs.url or remote.%s.url", ErrNoLFSRemote, DefaultLFSRemoteName)
+	}
+	remoteWorkspace, err := domain.NewWorkspace(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: remote '%s': %v", ErrNoLFSRemote, DefaultLFSRemoteName, err)
+	}
+	return NewLFSStore(remoteWorkspace.LFSObjectsDir.String()), nil
+}
+
+// ParseLFSThreshold parses lfs.threshold: a byte count with an optional k, m,
+// or g suffix. Zero disables the threshold.
+func ParseLFSThreshold(text string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(text))
+	if value == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "m"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "g"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		value = value[:len(value)-1]
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("lfs: invalid %s.%s %q", ConfigSectionLFS, ConfigKeyThreshold, text)
+	}
+	return n * multiplier, nil
+}
@@ -5,10 +5,20 @@ import (
 	"Gel/internal/storage"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// PromisorFetcher retrieves objects that a partial clone deliberately omitted.
+type PromisorFetcher interface {
+	// FetchObject copies hash from the promisor remote into the local store.
+	// It reports false when the repository has no promisor remote.
+	FetchObject(hash domain.Hash) (bool, error)
+}
+
 type ObjectService struct {
-	objectStorage *storage.ObjectStorage
+	objectStorage   *storage.ObjectStorage
+	promisorFetcher PromisorFetcher
+	contentFilter   *ContentFilter
 }
 
 func NewObjectService(objectStorage *storage.ObjectStorage) *ObjectService {
@@ -17,18 +27,18 @@ func NewObjectService(objectStorage *storage.ObjectStorage) *ObjectService {
 	}
 }
 
-func (o *ObjectService) GetObjectSize(hash domain.Hash) (uint32, error) {
-	compressedData, err := o.objectStorage.Read(hash)
-	if err != nil {
-		return 0, err
-	}
+// SetPromisorFetcher enables lazy object fetching for partial clones.
+func (o *ObjectService) SetPromisorFetcher(fetcher PromisorFetcher) {
+	o.promisorFetcher = fetcher
+}
 
-	data, err := Decompress(compressedData)
-	if err != nil {
-		return 0, err
-	}
+// SetContentFilter enables attribute-driven clean and smudge conversion of working tree files.
+func (o *ObjectService) SetContentFilter(filter *ContentFilter) {
+	o.contentFilter = filter
+}
 
-	object, err := domain.DeserializeObject(data)
+func (o *ObjectService) GetObjectSize(hash domain.Hash) (uint32, error) {
+	object, err := o.Read(hash)
 	if err != nil {
 		return 0, err
 	}
@@ -43,7 +53,19 @@ func (o *ObjectService) Write(hash domain.Hash, data []byte) error {
 	return o.objectStorage.Write(hash, compressedData)
 }
 
+// Read reads the object stored under hash. When the object is missing
+// locally and a promisor remote is configured, it is fetched on demand first,
+// so every typed reader below works in a partial clone.
 func (o *ObjectService) Read(hash domain.Hash) (domain.Object, error) {
+	object, err := o.readLocal(hash)
+	if err != nil {
+		return o.readPromised(hash, err)
+	}
+	return object, nil
+}
+
+// readLocal reads the object stored under hash from the local object store.
+func (o *ObjectService) readLocal(hash domain.Hash) (domain.Object, error) {
 	compressedData, err := o.objectStorage.Read(hash)
 	if err != nil {
 		return nil, err
@@ -61,6 +83,7 @@ func (o *ObjectService) Read(hash domain.Hash) (domain.Object, error) {
 	return object, nil
 }
 
+// ReadBlob reads a blob object, fetching it from the promisor remote like Read.
 func (o *ObjectService) ReadBlob(hash domain.Hash) (*domain.Blob, error) {
 	object, err := o.Read(hash)
 	if err != nil {
@@ -73,6 +96,30 @@ func (o *ObjectService) ReadBlob(hash domain.Hash) (*domain.Blob, error) {
 	return blob, nil
 }
 
+// readPromised fetches a missing object from the promisor remote and reads it again.
+// readErr is returned unchanged when the object exists locally or no promisor is configured.
+func (o *ObjectService) readPromised(hash domain.Hash, readErr error) (domain.Object, error) {
+	if o.promisorFetcher == nil {
+		return nil, readErr
+	}
+	exists, err := o.Exists(hash)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, readErr
+	}
+
+	fetched, err := o.promisorFetcher.FetchObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch promised object '%s': %w", hash, err)
+	}
+	if !fetched {
+		return nil, readErr
+	}
+	return o.readLocal(hash)
+}
+
 func (o *ObjectService) ReadTree(hash domain.Hash) (*domain.Tree, error) {
 	object, err := o.Read(hash)
 	if err != nil {
@@ -99,12 +146,88 @@ func (o *ObjectService) ReadCommit(hash domain.Hash) (*domain.Commit, error) {
 	return commit, nil
 }
 
+// ReadTag reads the annotated tag object stored under hash.
+func (o *ObjectService) ReadTag(hash domain.Hash) (*domain.Tag, error) {
+	object, err := o.Read(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, ok := object.(*domain.Tag)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %s, got %s", domain.ErrObjectTypeMismatch, domain.ObjectTypeTag, object.Type())
+	}
+	return tag, nil
+}
+
+// WriteBlob stores body as a blob object and returns its hash.
+func (o *ObjectService) WriteBlob(body []byte) (domain.Hash, error) {
+	data := domain.NewBlob(body).Serialize()
+	hash, err := domain.NewHashFromHex(ComputeSHA256(data))
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	return hash, o.Write(hash, data)
+}
+
 func (o *ObjectService) Exists(hash domain.Hash) (bool, error) {
 	return o.objectStorage.Exists(hash)
 }
 
+// ReadWorkingContent returns the repository form of the working tree file at
+// path: its clean-filtered content, or the link target for symlinks.
+func (o *ObjectService) ReadWorkingContent(path domain.AbsolutePath) ([]byte, error) {
+	data, isSymlink, err := readWorkingFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isSymlink || o.contentFilter == nil {
+		return data, nil
+	}
+	return o.contentFilter.Clean(path, data)
+}
+
+// WriteWorkingContent writes repository-form body to path in its working tree
+// form. Regular files are smudge-filtered; FileModeSymlink creates a symlink.
+// Writing a .gelattributes file makes later writes see its rules.
+func (o *ObjectService) WriteWorkingContent(path domain.AbsolutePath, body []byte, mode domain.FileMode) error {
+	if !mode.IsSymlink() && o.contentFilter != nil {
+		smudged, err := o.contentFilter.Smudge(path, body)
+		if err != nil {
+			return err
+		}
+		body = smudged
+	}
+	if err := WriteWorkingFile(path, body, mode); err != nil {
+		return err
+	}
+	if o.contentFilter != nil && filepath.Base(path.String()) == domain.GelAttributesFileName {
+		o.contentFilter.attributeMatcher.Reset()
+	}
+	return nil
+}
+
+// CheckoutBlob writes the blob hash to path via WriteWorkingContent. For a
+// submodule, whose commit lives in another repository, only its directory
+// is created; gel submodule update checks the commit out.
+func (o *ObjectService) CheckoutBlob(hash domain.Hash, path domain.AbsolutePath, mode domain.FileMode) error {
+	if mode.IsGitlink() {
+		if err := os.MkdirAll(path.String(), domain.DefaultDirPermission); err != nil {
+			return fmt.Errorf("failed to create submodule directory '%s': %w", path, err)
+		}
+		return nil
+	}
+	blob, err := o.ReadBlob(hash)
+	if err != nil {
+		return err
+	}
+	return o.WriteWorkingContent(path, blob.Body(), mode)
+}
+
+// ComputeObjectHash hashes the working tree file at path as a blob.
+// Content is clean-filtered; symlinks are hashed by their target path rather than followed.
 func (o *ObjectService) ComputeObjectHash(path domain.AbsolutePath) (domain.Hash, []byte, error) {
-	data, err := os.ReadFile(path.String())
+	data, err := o.ReadWorkingContent(path)
 	if err != nil {
 		return domain.Hash{}, nil, fmt.Errorf("failed to read file at '%s': %w", path, err)
 	}
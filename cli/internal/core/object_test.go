@@ -0,0 +1,80 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storePromisorFetcher serves objects from another object store and records
+// the requested hashes.
+type storePromisorFetcher struct {
+	promisor  *ObjectService
+	local     *ObjectService
+	requested []domain.Hash
+}
+
+// FetchObject copies hash from the promisor store into the local store.
+func (f *storePromisorFetcher) FetchObject(hash domain.Hash) (bool, error) {
+	f.requested = append(f.requested, hash)
+	object, err := f.promisor.Read(hash)
+	if err != nil {
+		return false, err
+	}
+	return true, f.local.Write(hash, object.Serialize())
+}
+
+// newTestObjectService initializes a repository and returns its object service.
+func newTestObjectService(t *testing.T) *ObjectService {
+	t.Helper()
+	dir := t.TempDir()
+	_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+	require.NoError(t, err)
+	workspace, err := domain.NewWorkspace(dir)
+	require.NoError(t, err)
+	return NewObjectService(storage.NewObjectStorage(workspace))
+}
+
+func TestObjectServiceReadFetchesPromisedObjects(t *testing.T) {
+	promisor := newTestObjectService(t)
+	local := newTestObjectService(t)
+	fetcher := &storePromisorFetcher{promisor: promisor, local: local}
+	local.SetPromisorFetcher(fetcher)
+
+	blobHash, err := promisor.WriteBlob([]byte("content"))
+	require.NoError(t, err)
+	treeObject, err := domain.NewTreeFromEntries(
+		[]domain.TreeEntry{domain.NewTreeEntry(domain.FileModeRegular, blobHash, "file")},
+	)
+	require.NoError(t, err)
+	treeHash, err := domain.NewHashFromHex(ComputeSHA256(treeObject.Serialize()))
+	require.NoError(t, err)
+	require.NoError(t, promisor.Write(treeHash, treeObject.Serialize()))
+
+	object, err := local.Read(blobHash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), object.Body())
+
+	readTree, err := local.ReadTree(treeHash)
+	require.NoError(t, err)
+	assert.Equal(t, treeObject.Body(), readTree.Body())
+
+	_, err = local.Read(blobHash)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Hash{blobHash, treeHash}, fetcher.requested)
+}
+
+func TestObjectServiceReadWithoutPromisor(t *testing.T) {
+	local := newTestObjectService(t)
+	missing, err := domain.NewHashFromHex(ComputeSHA256([]byte("missing")))
+	require.NoError(t, err)
+
+	_, err = local.Read(missing)
+
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -40,34 +40,42 @@ type ResolvedPath struct {
 	Type            PathspecType
 	NormalizedScope string
 	NormalizedPaths map[domain.NormalizedPath]bool
+	// IgnoredPaths holds explicitly named files and directories left out of
+	// NormalizedPaths because an ignore rule matches them.
+	IgnoredPaths map[domain.NormalizedPath]bool
+}
+
+// ResolveOptions controls pathspec expansion.
+type ResolveOptions struct {
+	// IncludeIgnored expands paths matched by ignore rules as well.
+	IncludeIgnored bool
+}
+
+// builtinIgnoredNames are metadata directories never reported as working tree content.
+var builtinIgnoredNames = map[string]bool{
+	domain.GelDirName: true,
+	".git":            true,
 }
 
 // PathResolver expands pathspecs to normalized repository-relative paths.
 type PathResolver struct {
-	repoDir         domain.AbsolutePath
-	ignoredPatterns map[string]bool
+	repoDir       domain.AbsolutePath
+	ignoreMatcher *IgnoreMatcher
 }
 
 // NewPathResolver creates a resolver rooted at repoDir.
-// When ignoredPatterns is nil, a default set of VCS/editor directories is used.
-func NewPathResolver(repoDir domain.AbsolutePath, ignoredPatterns map[string]bool) *PathResolver {
-	if ignoredPatterns == nil {
-		// TODO: implement gelignore.
-		ignoredPatterns = map[string]bool{
-			".gel":  true,
-			".git":  true,
-			".idea": true,
-		}
-	}
+// Repository metadata directories are always skipped; other paths are
+// filtered through ignoreMatcher unless it is nil.
+func NewPathResolver(repoDir domain.AbsolutePath, ignoreMatcher *IgnoreMatcher) *PathResolver {
 	return &PathResolver{
-		repoDir:         repoDir,
-		ignoredPatterns: ignoredPatterns,
+		repoDir:       repoDir,
+		ignoreMatcher: ignoreMatcher,
 	}
 }
 
 // Resolve classifies and expands each pathspec and returns normalized results.
 // NormalizedScope is repository-relative and used for scoped index reconciliation.
-func (p *PathResolver) Resolve(pathspecs []string) ([]ResolvedPath, error) {
+func (p *PathResolver) Resolve(pathspecs []string, options ResolveOptions) ([]ResolvedPath, error) {
 	resolvedPaths := make([]ResolvedPath, 0)
 	for _, pathspec := range pathspecs {
 		pathspecType, err := classifyPathspec(pathspec)
@@ -75,7 +83,8 @@ func (p *PathResolver) Resolve(pathspecs []string) ([]ResolvedPath, error) {
 			return nil, err
 		}
 
-		paths, err := p.expandPathspec(pathspec, pathspecType)
+		ignoredPaths := make(map[domain.NormalizedPath]bool)
+		paths, err := p.expandPathspec(pathspec, pathspecType, options, ignoredPaths)
 		if err != nil {
 			return nil, err
 		}
@@ -101,32 +110,100 @@ func (p *PathResolver) Resolve(pathspecs []string) ([]ResolvedPath, error) {
 				Type:            pathspecType,
 				NormalizedScope: normalizedScope,
 				NormalizedPaths: normalizedPaths,
+				IgnoredPaths:    ignoredPaths,
 			},
 		)
 	}
 	return resolvedPaths, nil
 }
 
-// shouldIgnore checks whether any segment of a path matches ignored patterns.
+// IsIgnored reports whether a normalized path is excluded by ignore rules.
+// Repository metadata directories and their contents are always ignored.
+func (p *PathResolver) IsIgnored(path domain.NormalizedPath, isDir bool) (bool, error) {
+	if p.shouldIgnore(path.String()) {
+		return true, nil
+	}
+	if p.ignoreMatcher == nil {
+		return false, nil
+	}
+	return p.ignoreMatcher.IsIgnored(path.String(), isDir)
+}
+
+// shouldIgnore checks whether any segment of a path names a metadata directory.
 func (p *PathResolver) shouldIgnore(path string) bool {
 	segments := strings.Split(filepath.ToSlash(path), "/")
 	for _, segment := range segments {
-		if p.ignoredPatterns[segment] {
+		if builtinIgnoredNames[segment] {
 			return true
 		}
 	}
 	return false
 }
 
+// excluded reports whether the filesystem path is ignored under options.
+// Metadata directories are excluded even with options.IncludeIgnored.
+func (p *PathResolver) excluded(path string, isDir bool, options ResolveOptions) (bool, error) {
+	normalizedPath, err := domain.NewNormalizedPath(path, p.repoDir)
+	if err != nil {
+		return false, err
+	}
+	if normalizedPath.String() == "" {
+		return false, nil
+	}
+	if p.shouldIgnore(normalizedPath.String()) {
+		return true, nil
+	}
+	if options.IncludeIgnored {
+		return false, nil
+	}
+	return p.IsIgnored(normalizedPath, isDir)
+}
+
+// recordIgnored adds path to ignoredPaths when it is ignored under options.
+// It reports whether the path was ignored.
+func (p *PathResolver) recordIgnored(
+	path string,
+	isDir bool,
+	options ResolveOptions,
+	ignoredPaths map[domain.NormalizedPath]bool,
+) (bool, error) {
+	ignored, err := p.excluded(path, isDir, options)
+	if err != nil || !ignored {
+		return false, err
+	}
+	normalizedPath, err := domain.NewNormalizedPath(path, p.repoDir)
+	if err != nil {
+		return false, err
+	}
+	if !p.shouldIgnore(normalizedPath.String()) {
+		ignoredPaths[normalizedPath] = true
+	}
+	return true, nil
+}
+
 // expandPathspec resolves a pathspec according to its classified type.
-func (p *PathResolver) expandPathspec(pathspec string, pathspecType PathspecType) ([]string, error) {
+// Explicitly named paths excluded by ignore rules are added to ignoredPaths.
+func (p *PathResolver) expandPathspec(
+	pathspec string,
+	pathspecType PathspecType,
+	options ResolveOptions,
+	ignoredPaths map[domain.NormalizedPath]bool,
+) ([]string, error) {
 	switch pathspecType {
 	case PathspecTypeFile:
+		ignored, err := p.recordIgnored(pathspec, false, options, ignoredPaths)
+		if err != nil || ignored {
+			return []string{}, err
+		}
 		return []string{pathspec}, nil
 	case PathspecTypeDirectory:
-		return p.expandDirectory(pathspec)
+		ignored, err := p.recordIgnored(pathspec, true, options, ignoredPaths)
+		if err != nil || ignored {
+			return []string{}, err
+		}
+		return p.expandDirectory(pathspec, options)
 	case PathspecTypeGlobPattern:
-		return p.expandGlobPattern(pathspec)
+		return p.expandGlobPattern(pathspec, options, ignoredPaths)
 	case PathspecTypeNonExistent:
 		return []string{}, nil
 	default:
@@ -159,7 +236,7 @@ func classifyPathspec(pathspec string) (PathspecType, error) {
 		return PathspecTypeGlobPattern, nil
 	}
 
-	fileInfo, err := os.Stat(pathspec)
+	fileInfo, err := os.Lstat(pathspec)
 	if errors.Is(err, os.ErrNotExist) {
 		return PathspecTypeNonExistent, nil
 	} else if err != nil {
@@ -172,19 +249,40 @@ func classifyPathspec(pathspec string) (PathspecType, error) {
 }
 
 // expandDirectory walks a directory pathspec and returns all file paths.
-func (p *PathResolver) expandDirectory(path string) ([]string, error) {
+// Ignored directories are pruned and ignored files skipped. Symlinks are
+// returned as files and never followed, even when they point at directories.
+// Nested repositories such as submodules are returned as single paths.
+func (p *PathResolver) expandDirectory(path string, options ResolveOptions) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(
-		path, func(p string, d os.DirEntry, err error) error {
+		path, func(walkPath string, d os.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
-			if !d.IsDir() {
-				info, err := os.Stat(p)
-				if err == nil && info.IsDir() {
+			if walkPath != path {
+				ignored, err := p.excluded(walkPath, d.IsDir(), options)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
 					return nil
 				}
-				files = append(files, p)
+			}
+			if d.IsDir() && IsRepositoryRoot(walkPath) {
+				absWalkPath, err := filepath.Abs(walkPath)
+				if err != nil {
+					return err
+				}
+				if absWalkPath != p.repoDir.String() {
+					files = append(files, walkPath)
+					return filepath.SkipDir
+				}
+			}
+			if !d.IsDir() {
+				files = append(files, walkPath)
 			}
 			return nil
 		},
@@ -196,7 +294,12 @@ func (p *PathResolver) expandDirectory(path string) ([]string, error) {
 }
 
 // expandGlobPattern expands a glob and recursively expands directory matches.
-func (p *PathResolver) expandGlobPattern(pattern string) ([]string, error) {
+// Matches excluded by ignore rules are added to ignoredPaths.
+func (p *PathResolver) expandGlobPattern(
+	pattern string,
+	options ResolveOptions,
+	ignoredPaths map[domain.NormalizedPath]bool,
+) ([]string, error) {
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, err
@@ -204,12 +307,19 @@ func (p *PathResolver) expandGlobPattern(pattern string) ([]string, error) {
 
 	var result []string
 	for _, match := range matches {
-		info, err := os.Stat(match)
+		info, err := os.Lstat(match)
 		if err != nil {
 			continue
 		}
+		ignored, err := p.recordIgnored(match, info.IsDir(), options, ignoredPaths)
+		if err != nil {
+			return nil, err
+		}
+		if ignored {
+			continue
+		}
 		if info.IsDir() {
-			files, err := p.expandDirectory(match)
+			files, err := p.expandDirectory(match, options)
 			if err != nil {
 				return nil, err
 			}
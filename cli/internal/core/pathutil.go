@@ -1,9 +1,12 @@
 package core
 
 import (
+	"Gel/internal/domain"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // Exists reports whether path exists on disk.
@@ -18,3 +21,34 @@ func Exists(path string) (bool, error) {
 	}
 	return true, nil
 }
+
+// NormalizePathspecs converts command-line pathspecs to repository-relative scopes
+// for MatchesPathspecs. The repository root is represented as "".
+func NormalizePathspecs(pathspecs []string, repoDir domain.AbsolutePath) ([]string, error) {
+	scopes := make([]string, 0, len(pathspecs))
+	for _, pathspec := range pathspecs {
+		normalizedPath, err := domain.NewNormalizedPath(pathspec, repoDir)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, normalizedPath.String())
+	}
+	return scopes, nil
+}
+
+// MatchesPathspecs reports whether path equals, lies below, or glob-matches one
+// of scopes. An empty scope list matches every path.
+func MatchesPathspecs(path domain.NormalizedPath, scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == "" || path.String() == scope || strings.HasPrefix(path.String(), scope+"/") {
+			return true
+		}
+		if matched, _ := filepath.Match(scope, path.String()); matched {
+			return true
+		}
+	}
+	return false
+}
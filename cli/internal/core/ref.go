@@ -78,7 +78,7 @@ func (r *RefService) Read(ref string) (domain.Hash, error) {
 		return domain.Hash{}, err
 	}
 
-	absPath := filepath.Join(r.workspace.GelDir.String(), ref)
+	absPath := filepath.Join(r.workspace.CommonDir.String(), ref)
 	contentBytes, err := os.ReadFile(absPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -105,7 +105,7 @@ func (r *RefService) Write(ref string, hash domain.Hash) error {
 		return err
 	}
 
-	absPath := filepath.Join(r.workspace.GelDir.String(), ref)
+	absPath := filepath.Join(r.workspace.CommonDir.String(), ref)
 	dir := filepath.Dir(absPath)
 	if err := os.MkdirAll(dir, domain.DefaultDirPermission); err != nil {
 		return fmt.Errorf("ref: failed to create directory '%s': %w", dir, err)
@@ -124,7 +124,7 @@ func (r *RefService) Delete(ref string) error {
 		return err
 	}
 
-	path := filepath.Join(r.workspace.GelDir.String(), ref)
+	path := filepath.Join(r.workspace.CommonDir.String(), ref)
 	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("ref: failed to delete '%s': %w", ref, err)
 	}
@@ -138,7 +138,7 @@ func (r *RefService) Exists(ref string) (bool, error) {
 		return false, err
 	}
 
-	path := filepath.Join(r.workspace.GelDir.String(), ref)
+	path := filepath.Join(r.workspace.CommonDir.String(), ref)
 	ok, err := Exists(path)
 	if err != nil {
 		return false, fmt.Errorf("ref: %w", err)
@@ -156,13 +156,17 @@ func (r *RefService) Resolve(name string) (domain.Hash, error) {
 }
 
 // symbolicPath sanitizes symbolic-ref file names and maps them under .gel.
-// Absolute and traversal paths are rejected.
+// Names below refs/ are shared by all worktrees; others such as HEAD belong
+// to the current worktree. Absolute and traversal paths are rejected.
 func (r *RefService) symbolicPath(name string) (string, error) {
 	cleanName := filepath.Clean(name)
 	if cleanName == "." || filepath.IsAbs(cleanName) || cleanName == ".." ||
 		strings.HasPrefix(cleanName, ".."+string(filepath.Separator)) {
 		return "", fmt.Errorf("'%s': %w", name, ErrInvalidSymbolicRef)
 	}
+	if strings.HasPrefix(filepath.ToSlash(cleanName), domain.RefsDirName+"/") {
+		return filepath.Join(r.workspace.CommonDir.String(), cleanName), nil
+	}
 	return filepath.Join(r.workspace.GelDir.String(), cleanName), nil
 }
 
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ShallowService reads and writes the .gel/shallow graft file.
+type ShallowService struct {
+	workspace *domain.Workspace
+}
+
+// NewShallowService creates a shallow graft service.
+func NewShallowService(workspace *domain.Workspace) *ShallowService {
+	return &ShallowService{
+		workspace: workspace,
+	}
+}
+
+// Read returns the recorded shallow grafts. A missing file yields an empty set.
+func (s *ShallowService) Read() (domain.ShallowGrafts, error) {
+	data, err := os.ReadFile(s.workspace.ShallowPath.String())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(domain.ShallowGrafts), nil
+		}
+		return nil, fmt.Errorf("shallow: %w", err)
+	}
+	return domain.ParseShallowGrafts(data)
+}
+
+// Write persists grafts, removing the file when the set is empty.
+func (s *ShallowService) Write(grafts domain.ShallowGrafts) error {
+	path := s.workspace.ShallowPath.String()
+	if len(grafts) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("shallow: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, grafts.Serialize(), domain.DefaultFilePermission); err != nil {
+		return fmt.Errorf("shallow: %w", err)
+	}
+	return nil
+}
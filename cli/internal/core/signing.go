@@ -0,0 +1,309 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AllowedSigner is one line of an allowed signers file: the principals a
+// public key may sign for, restricted to namespaces and a validity period.
+type AllowedSigner struct {
+	// Principals is the comma-separated principal list, usually email addresses.
+	Principals string
+	// Namespaces limits the signature namespaces the key is trusted for; empty allows all.
+	Namespaces []string
+	// ValidAfter and ValidBefore bound the signature time when non-zero.
+	ValidAfter  time.Time
+	ValidBefore time.Time
+	// PublicKey is the trusted key.
+	PublicKey ed25519.PublicKey
+}
+
+// ParseAllowedSigners parses an allowed signers file in the format of
+// ssh-keygen: "<principals> [<options>] <key type> <base64 key> [<comment>]"
+// per line. Lines with key types other than ed25519 or with the
+// cert-authority option are skipped, since they cannot match a Gel signature.
+func ParseAllowedSigners(data []byte) ([]AllowedSigner, error) {
+	var signers []AllowedSigner
+	for number, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := splitQuoted(line, func(r rune) bool { return r == ' ' || r == '\t' })
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("allowed signers line %d: %w: missing fields", number+1, ErrInvalidSSHKey)
+		}
+
+		signer := AllowedSigner{Principals: fields[0]}
+		rest := fields[1:]
+		if !isSSHKeyType(rest[0]) {
+			skip, err := signer.applyOptions(rest[0])
+			if err != nil {
+				return nil, fmt.Errorf("allowed signers line %d: %w", number+1, err)
+			}
+			if skip {
+				continue
+			}
+			rest = rest[1:]
+		}
+		if len(rest) < 2 {
+			return nil, fmt.Errorf("allowed signers line %d: %w: missing key", number+1, ErrInvalidSSHKey)
+		}
+		if rest[0] != SSHKeyTypeEd25519 {
+			continue
+		}
+		publicKey, err := ParseSSHPublicKey(rest[0], rest[1])
+		if err != nil {
+			return nil, fmt.Errorf("allowed signers line %d: %w", number+1, err)
+		}
+		signer.PublicKey = publicKey
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// Allows reports whether the signer trusts publicKey for namespace at time at.
+func (a AllowedSigner) Allows(publicKey ed25519.PublicKey, namespace string, at time.Time) bool {
+	if !a.PublicKey.Equal(publicKey) {
+		return false
+	}
+	if len(a.Namespaces) > 0 && !containsString(a.Namespaces, namespace) {
+		return false
+	}
+	if !a.ValidAfter.IsZero() && at.Before(a.ValidAfter) {
+		return false
+	}
+	if !a.ValidBefore.IsZero() && !at.Before(a.ValidBefore) {
+		return false
+	}
+	return true
+}
+
+// applyOptions applies the comma-separated options of an allowed signers
+// line and reports whether the line must be skipped.
+func (a *AllowedSigner) applyOptions(options string) (bool, error) {
+	for _, option := range splitQuoted(options, func(r rune) bool { return r == ',' }) {
+		name, value, _ := strings.Cut(option, "=")
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(name) {
+		case "cert-authority":
+			return true, nil
+		case "namespaces":
+			a.Namespaces = strings.Split(value, ",")
+		case "valid-after", "valid-before":
+			at, err := parseAllowedSignerTime(value)
+			if err != nil {
+				return false, err
+			}
+			if strings.EqualFold(name, "valid-after") {
+				a.ValidAfter = at
+			} else {
+				a.ValidBefore = at
+			}
+		default:
+			return false, fmt.Errorf("%w: unknown option %q", ErrInvalidSSHKey, name)
+		}
+	}
+	return false, nil
+}
+
+// parseAllowedSignerTime parses a YYYYMMDD[HHMM[SS]] time, in UTC when it
+// ends with "Z" and in local time otherwise.
+func parseAllowedSignerTime(value string) (time.Time, error) {
+	location := time.Local
+	if trimmed, ok := strings.CutSuffix(value, "Z"); ok {
+		value, location = trimmed, time.UTC
+	}
+	layouts := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: invalid time %q", ErrInvalidSSHKey, value)
+	}
+	at, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid time %q", ErrInvalidSSHKey, value)
+	}
+	return at, nil
+}
+
+// isSSHKeyType reports whether field names an SSH public key type rather than options.
+func isSSHKeyType(field string) bool {
+	for _, prefix := range []string{"ssh-", "ecdsa-", "sk-"} {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitQuoted splits value at the runes for which separator is true, except
+// inside double quotes. Empty fields are dropped.
+func splitQuoted(value string, separator func(rune) bool) []string {
+	quoted := false
+	return strings.FieldsFunc(
+		value, func(r rune) bool {
+			if r == '"' {
+				quoted = !quoted
+			}
+			return !quoted && separator(r)
+		},
+	)
+}
+
+// containsString reports whether values holds value.
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SignatureVerification describes a commit or tag signature that was checked.
+type SignatureVerification struct {
+	// KeyType is the SSH type of the signing key.
+	KeyType string
+	// Fingerprint is the SHA256 fingerprint of the signing key.
+	Fingerprint string
+	// Principal lists the allowed signer principals of the key, empty when
+	// the key is not in the allowed signers file.
+	Principal string
+}
+
+// SigningService signs commits and tags with the SSH key in user.signingkey
+// and checks their signatures against gpg.ssh.allowedSignersFile.
+type SigningService struct {
+	configService *ConfigService
+	workspace     *domain.Workspace
+}
+
+// NewSigningService creates a signing service.
+func NewSigningService(configService *ConfigService, workspace *domain.Workspace) *SigningService {
+	return &SigningService{
+		configService: configService,
+		workspace:     workspace,
+	}
+}
+
+// Sign signs payload with the private key file named by user.signingkey.
+func (s *SigningService) Sign(payload []byte) (string, error) {
+	path, err := s.configPath(ConfigSectionUser, ConfigKeySigningKey)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", ErrNoSigningKey
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read signing key: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte(SSHKeyTypeEd25519+" ")) {
+		return "", fmt.Errorf("%w: user.signingkey must name a private key", ErrInvalidSSHKey)
+	}
+	key, err := ParseSSHPrivateKey(data)
+	if err != nil {
+		return "", err
+	}
+	return SignSSH(key, SSHSignatureNamespace, payload), nil
+}
+
+// VerifyCommit checks the signature of commit. A good signature by a key
+// missing from the allowed signers file returns the verification together
+// with ErrNoPrincipal. A signature is valid at the committer date.
+func (s *SigningService) VerifyCommit(commit *domain.Commit) (*SignatureVerification, error) {
+	return s.verify(commit.Signature, commit.SignedPayload(), commit.Committer)
+}
+
+// VerifyTag checks the signature of an annotated tag like VerifyCommit. A
+// signature is valid at the tagger date.
+func (s *SigningService) VerifyTag(tag *domain.Tag) (*SignatureVerification, error) {
+	return s.verify(tag.Signature, tag.SignedPayload(), tag.Tagger)
+}
+
+// verify checks signature over payload against the allowed signers at the
+// date of signer.
+func (s *SigningService) verify(
+	signature string,
+	payload []byte,
+	signer domain.Identity,
+) (*SignatureVerification, error) {
+	if signature == "" {
+		return nil, ErrNoSignature
+	}
+	publicKey, err := VerifySSH(signature, SSHSignatureNamespace, payload)
+	if err != nil {
+		return nil, err
+	}
+	verification := &SignatureVerification{
+		KeyType:     SSHKeyTypeEd25519,
+		Fingerprint: SSHFingerprint(publicKey),
+	}
+
+	allowedSigners, err := s.allowedSigners()
+	if err != nil {
+		return verification, err
+	}
+	signedAt, err := domain.ParseCommitTime(signer.Timestamp, signer.Timezone)
+	if err != nil {
+		return verification, err
+	}
+	for _, allowedSigner := range allowedSigners {
+		if allowedSigner.Allows(publicKey, SSHSignatureNamespace, signedAt) {
+			verification.Principal = allowedSigner.Principals
+			return verification, nil
+		}
+	}
+	return verification, ErrNoPrincipal
+}
+
+// allowedSigners reads the file named by gpg.ssh.allowedSignersFile.
+func (s *SigningService) allowedSigners() ([]AllowedSigner, error) {
+	path, err := s.configPath(ConfigSectionGPG, ConfigKeyAllowedSignersFile)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, ErrNoAllowedSigners
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read allowed signers: %w", err)
+	}
+	return ParseAllowedSigners(data)
+}
+
+// configPath returns the file path configured at section.key, or an empty
+// string when unset. A leading "~/" is the home directory and a relative path
+// is taken from the working tree root.
+func (s *SigningService) configPath(section, key string) (string, error) {
+	path, ok, err := s.configService.GetOptional(section, key)
+	if err != nil || !ok || strings.TrimSpace(path) == "" {
+		return "", err
+	}
+	return ExpandPath(path, s.workspace.RepoDir.String())
+}
+
+// ExpandPath resolves a path read from config: a leading "~/" is the home
+// directory and a relative path is taken from base.
+func ExpandPath(path, base string) (string, error) {
+	if rest, found := strings.CutPrefix(path, "~/"); found {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, rest), nil
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(base, path), nil
+	}
+	return path, nil
+}
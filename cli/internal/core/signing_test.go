@@ -0,0 +1,298 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingTestTimestamp is the committer date of signed test commits, 2023-11-14T22:13:20Z.
+const signingTestTimestamp = "1700000000"
+
+func TestParseAllowedSigners(t *testing.T) {
+	key := newTestSSHKey(t)
+	publicKey := key.Public().(ed25519.PublicKey)
+	data := strings.Join(
+		[]string{
+			"# team keys",
+			"",
+			"alice@example.com " + authorizedTestSSHKey(key) + " alice laptop",
+			`bob@example.com,ci@example.com namespaces="git,file",valid-after=20230101,valid-before="20240101Z" ` +
+				authorizedTestSSHKey(key),
+			"ca@example.com cert-authority " + authorizedTestSSHKey(key),
+			"rsa@example.com ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQ",
+		}, "\n",
+	)
+
+	signers, err := ParseAllowedSigners([]byte(data))
+
+	require.NoError(t, err)
+	require.Len(t, signers, 2)
+	assert.Equal(t, "alice@example.com", signers[0].Principals)
+	assert.True(t, publicKey.Equal(signers[0].PublicKey))
+	assert.Empty(t, signers[0].Namespaces)
+	assert.Equal(t, "bob@example.com,ci@example.com", signers[1].Principals)
+	assert.Equal(t, []string{"git", "file"}, signers[1].Namespaces)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local), signers[1].ValidAfter)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), signers[1].ValidBefore)
+}
+
+func TestParseAllowedSignersRejectsMalformedLines(t *testing.T) {
+	key := newTestSSHKey(t)
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "missing key", line: "alice@example.com " + SSHKeyTypeEd25519},
+		{name: "unknown option", line: "alice@example.com no-touch-required " + authorizedTestSSHKey(key)},
+		{name: "invalid time", line: "alice@example.com valid-after=2023 " + authorizedTestSSHKey(key)},
+		{name: "invalid key", line: "alice@example.com " + SSHKeyTypeEd25519 + " AAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := ParseAllowedSigners([]byte(tt.line))
+
+				require.ErrorIs(t, err, ErrInvalidSSHKey)
+			},
+		)
+	}
+}
+
+func TestAllowedSignerAllows(t *testing.T) {
+	key := newTestSSHKey(t)
+	signer := AllowedSigner{
+		Principals:  "alice@example.com",
+		Namespaces:  []string{SSHSignatureNamespace},
+		ValidAfter:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PublicKey:   key.Public().(ed25519.PublicKey),
+	}
+	validTime := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		publicKey ed25519.PublicKey
+		namespace string
+		at        time.Time
+		want      bool
+	}{
+		{name: "trusted", publicKey: signer.PublicKey, namespace: SSHSignatureNamespace, at: validTime, want: true},
+		{
+			name:      "wrong key",
+			publicKey: newTestSSHKey(t).Public().(ed25519.PublicKey),
+			namespace: SSHSignatureNamespace,
+			at:        validTime,
+		},
+		{name: "wrong namespace", publicKey: signer.PublicKey, namespace: "file", at: validTime},
+		{
+			name:      "before valid-after",
+			publicKey: signer.PublicKey,
+			namespace: SSHSignatureNamespace,
+			at:        signer.ValidAfter.Add(-time.Second),
+		},
+		{
+			name:      "at valid-after",
+			publicKey: signer.PublicKey,
+			namespace: SSHSignatureNamespace,
+			at:        signer.ValidAfter,
+			want:      true,
+		},
+		{name: "at valid-before", publicKey: signer.PublicKey, namespace: SSHSignatureNamespace, at: signer.ValidBefore},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, signer.Allows(tt.publicKey, tt.namespace, tt.at))
+			},
+		)
+	}
+}
+
+// newTestSigningService initializes a repository whose user.signingKey is a
+// generated key, and returns the signing service with that key.
+func newTestSigningService(t *testing.T) (*SigningService, *ConfigService, ed25519.PrivateKey) {
+	t.Helper()
+	dir := t.TempDir()
+	_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+	require.NoError(t, err)
+	workspace, err := domain.NewWorkspace(dir)
+	require.NoError(t, err)
+	configService := NewConfigService(storage.NewConfigStorage(workspace))
+
+	key := newTestSSHKey(t)
+	keyPath := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, encodeTestSSHPrivateKey(key), 0o600))
+	require.NoError(t, configService.Set(ConfigSectionUser, "signingKey", keyPath))
+	return NewSigningService(configService, workspace), configService, key
+}
+
+// newTestSignedCommit returns a commit with message signed by signingService.
+func newTestSignedCommit(t *testing.T, signingService *SigningService, message string) *domain.Commit {
+	t.Helper()
+	identity, err := domain.NewIdentity("Alice", "alice@example.com", signingTestTimestamp, "+0000")
+	require.NoError(t, err)
+	treeHash, err := domain.NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	fields := domain.CommitFields{TreeHash: treeHash, Author: identity, Committer: identity, Message: message}
+	unsigned, err := domain.NewCommitFromFields(fields)
+	require.NoError(t, err)
+
+	fields.Signature, err = signingService.Sign(unsigned.Body())
+	require.NoError(t, err)
+	signed, err := domain.NewCommitFromFields(fields)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestSigningServiceVerifyCommit(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowedPrefix string
+		otherKey      bool
+		tamper        bool
+		wantPrincipal string
+		wantErr       error
+	}{
+		{name: "trusted", allowedPrefix: "alice@example.com ", wantPrincipal: "alice@example.com"},
+		{name: "key not allowed", allowedPrefix: "alice@example.com ", otherKey: true, wantErr: ErrNoPrincipal},
+		{name: "tampered", allowedPrefix: "alice@example.com ", tamper: true, wantErr: ErrBadSignature},
+		{
+			name:          "namespace not allowed",
+			allowedPrefix: `alice@example.com namespaces="file" `,
+			wantErr:       ErrNoPrincipal,
+		},
+		{
+			name:          "valid at commit date",
+			allowedPrefix: "alice@example.com valid-after=20231101Z,valid-before=20231201Z ",
+			wantPrincipal: "alice@example.com",
+		},
+		{
+			name:          "expired at commit date",
+			allowedPrefix: "alice@example.com valid-before=20231101Z ",
+			wantErr:       ErrNoPrincipal,
+		},
+		{
+			name:          "not yet valid at commit date",
+			allowedPrefix: "alice@example.com valid-after=20231201Z ",
+			wantErr:       ErrNoPrincipal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				signingService, configService, key := newTestSigningService(t)
+				allowedKey := key
+				if tt.otherKey {
+					allowedKey = newTestSSHKey(t)
+				}
+				allowedSignersPath := filepath.Join(t.TempDir(), "allowed_signers")
+				allowedSigners := tt.allowedPrefix + authorizedTestSSHKey(allowedKey) + "\n"
+				require.NoError(t, os.WriteFile(allowedSignersPath, []byte(allowedSigners), 0o600))
+				require.NoError(t, configService.Set(ConfigSectionGPG, "ssh.allowedSignersFile", allowedSignersPath))
+
+				commit := newTestSignedCommit(t, signingService, "signed\n")
+				if tt.tamper {
+					fields := commit.CommitFields
+					fields.Message = "tampered\n"
+					var err error
+					commit, err = domain.NewCommitFromFields(fields)
+					require.NoError(t, err)
+				}
+
+				verification, err := signingService.VerifyCommit(commit)
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+				if tt.tamper {
+					return
+				}
+				require.NotNil(t, verification)
+				assert.Equal(t, SSHFingerprint(key.Public().(ed25519.PublicKey)), verification.Fingerprint)
+				assert.Equal(t, tt.wantPrincipal, verification.Principal)
+			},
+		)
+	}
+}
+
+func TestSigningServiceVerifyCommitErrors(t *testing.T) {
+	signingService, configService, _ := newTestSigningService(t)
+	signed := newTestSignedCommit(t, signingService, "signed\n")
+	unsignedFields := signed.CommitFields
+	unsignedFields.Signature = ""
+	unsigned, err := domain.NewCommitFromFields(unsignedFields)
+	require.NoError(t, err)
+
+	_, err = signingService.VerifyCommit(unsigned)
+	require.ErrorIs(t, err, ErrNoSignature)
+
+	_, err = signingService.VerifyCommit(signed)
+	require.ErrorIs(t, err, ErrNoAllowedSigners)
+
+	require.NoError(t, configService.Set(ConfigSectionUser, ConfigKeySigningKey, ""))
+	_, err = signingService.Sign(signed.SignedPayload())
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestSigningServiceVerifyTag(t *testing.T) {
+	signingService, configService, key := newTestSigningService(t)
+	allowedSignersPath := filepath.Join(t.TempDir(), "allowed_signers")
+	allowedSigners := "alice@example.com valid-before=20231201Z " + authorizedTestSSHKey(key) + "\n"
+	require.NoError(t, os.WriteFile(allowedSignersPath, []byte(allowedSigners), 0o600))
+	require.NoError(t, configService.Set(ConfigSectionGPG, "ssh.allowedSignersFile", allowedSignersPath))
+
+	tagger, err := domain.NewIdentity("Alice", "alice@example.com", signingTestTimestamp, "+0000")
+	require.NoError(t, err)
+	commitHash, err := domain.NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	fields := domain.TagFields{
+		ObjectHash: commitHash,
+		ObjectType: domain.ObjectTypeCommit,
+		Name:       "v1.0",
+		Tagger:     tagger,
+		Message:    "release\n",
+	}
+	unsigned, err := domain.NewTagFromFields(fields)
+	require.NoError(t, err)
+	fields.Signature, err = signingService.Sign(unsigned.Body())
+	require.NoError(t, err)
+	signed, err := domain.NewTagFromFields(fields)
+	require.NoError(t, err)
+
+	verification, err := signingService.VerifyTag(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", verification.Principal)
+
+	_, err = signingService.VerifyTag(unsigned)
+	require.ErrorIs(t, err, ErrNoSignature)
+
+	fields.Message = "tampered\n"
+	tampered, err := domain.NewTagFromFields(fields)
+	require.NoError(t, err)
+	_, err = signingService.VerifyTag(tampered)
+	require.ErrorIs(t, err, ErrBadSignature)
+
+	fields.Message = "release\n"
+	fields.Signature = ""
+	fields.Tagger.Timestamp = "1710000000"
+	late, err := domain.NewTagFromFields(fields)
+	require.NoError(t, err)
+	fields.Signature, err = signingService.Sign(late.Body())
+	require.NoError(t, err)
+	late, err = domain.NewTagFromFields(fields)
+	require.NoError(t, err)
+	_, err = signingService.VerifyTag(late)
+	require.ErrorIs(t, err, ErrNoPrincipal)
+}
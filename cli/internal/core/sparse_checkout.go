@@ -0,0 +1,90 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SparseCheckout reads and writes the sparse checkout definition: the
+// core.sparsecheckout switch and the cone stored in .gel/info/sparse-checkout.
+type SparseCheckout struct {
+	configService *ConfigService
+	workspace     *domain.Workspace
+}
+
+// NewSparseCheckout creates a sparse checkout accessor.
+func NewSparseCheckout(configService *ConfigService, workspace *domain.Workspace) *SparseCheckout {
+	return &SparseCheckout{
+		configService: configService,
+		workspace:     workspace,
+	}
+}
+
+// Enabled reports whether core.sparsecheckout is true.
+func (s *SparseCheckout) Enabled() (bool, error) {
+	value, ok, err := s.configService.GetOptional(ConfigSectionCore, ConfigKeySparseCheckout)
+	if err != nil || !ok {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("sparse-checkout: invalid %s.%s %q", ConfigSectionCore, ConfigKeySparseCheckout, value)
+	}
+	return enabled, nil
+}
+
+// SetEnabled writes core.sparsecheckout.
+func (s *SparseCheckout) SetEnabled(enabled bool) error {
+	return s.configService.Set(ConfigSectionCore, ConfigKeySparseCheckout, strconv.FormatBool(enabled))
+}
+
+// Cone returns the active cone, or nil when sparse checkout is disabled.
+// An enabled sparse checkout without a definition file keeps only root files.
+func (s *SparseCheckout) Cone() (*domain.SparseCone, error) {
+	enabled, err := s.Enabled()
+	if err != nil || !enabled {
+		return nil, err
+	}
+	cone, err := s.ReadCone()
+	if err != nil {
+		return nil, err
+	}
+	return &cone, nil
+}
+
+// ReadCone parses the definition file whether or not sparse checkout is enabled.
+func (s *SparseCheckout) ReadCone() (domain.SparseCone, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return domain.SparseCone{}, nil
+		}
+		return domain.SparseCone{}, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	cone, err := domain.ParseSparseCone(data)
+	if err != nil {
+		return domain.SparseCone{}, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	return cone, nil
+}
+
+// WriteCone replaces the definition file with cone.
+func (s *SparseCheckout) WriteCone(cone domain.SparseCone) error {
+	path := s.path()
+	if err := os.MkdirAll(filepath.Dir(path), domain.DefaultDirPermission); err != nil {
+		return fmt.Errorf("sparse-checkout: %w", err)
+	}
+	if err := os.WriteFile(path, cone.Serialize(), domain.DefaultFilePermission); err != nil {
+		return fmt.Errorf("sparse-checkout: %w", err)
+	}
+	return nil
+}
+
+// path returns the location of the definition file.
+func (s *SparseCheckout) path() string {
+	return filepath.Join(s.workspace.GelDir.String(), domain.InfoDirName, domain.SparseCheckoutFileName)
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// SSH signatures follow the sshsig format of OpenSSH (PROTOCOL.sshsig), so
+// they can be checked with ssh-keygen -Y verify as well. Only ed25519 keys
+// are supported.
+const (
+	// SSHKeyTypeEd25519 is the SSH name of ed25519 keys.
+	SSHKeyTypeEd25519 = "ssh-ed25519"
+
+	// SSHSignatureNamespace is the sshsig namespace of commit signatures.
+	SSHSignatureNamespace = "git"
+
+	sshSignatureMagic         = "SSHSIG"
+	sshSignatureVersion       = 1
+	sshSignatureHashAlgorithm = "sha512"
+	sshSignatureBegin         = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureEnd           = "-----END SSH SIGNATURE-----"
+	sshSignatureLineLength    = 70
+
+	sshPrivateKeyBlockType = "OPENSSH PRIVATE KEY"
+	sshPrivateKeyMagic     = "openssh-key-v1\x00"
+)
+
+// ParseSSHPrivateKey parses an unencrypted ed25519 key in the OpenSSH private
+// key format written by ssh-keygen.
+func ParseSSHPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != sshPrivateKeyBlockType {
+		return nil, fmt.Errorf("%w: not an OpenSSH private key", ErrInvalidSSHKey)
+	}
+	reader := sshReader{data: block.Bytes}
+	if !bytes.HasPrefix(reader.data, []byte(sshPrivateKeyMagic)) {
+		return nil, fmt.Errorf("%w: not an OpenSSH private key", ErrInvalidSSHKey)
+	}
+	reader.data = reader.data[len(sshPrivateKeyMagic):]
+
+	cipherName := reader.readString()
+	reader.readString() // KDF name
+	reader.readString() // KDF options
+	keyCount := reader.readUint32()
+	reader.readString() // public key
+	private := sshReader{data: reader.readBytes()}
+	if reader.err != nil || keyCount != 1 {
+		return nil, fmt.Errorf("%w: malformed private key", ErrInvalidSSHKey)
+	}
+	if cipherName != "none" {
+		return nil, ErrEncryptedSSHKey
+	}
+
+	check1, check2 := private.readUint32(), private.readUint32()
+	keyType := private.readString()
+	publicKey := private.readBytes()
+	privateKey := private.readBytes()
+	if private.err != nil || check1 != check2 {
+		return nil, fmt.Errorf("%w: malformed private key", ErrInvalidSSHKey)
+	}
+	if keyType != SSHKeyTypeEd25519 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSSHKeyType, keyType)
+	}
+	if len(publicKey) != ed25519.PublicKeySize || len(privateKey) != ed25519.PrivateKeySize ||
+		!bytes.Equal(privateKey[ed25519.SeedSize:], publicKey) {
+		return nil, fmt.Errorf("%w: malformed ed25519 key", ErrInvalidSSHKey)
+	}
+	return ed25519.PrivateKey(privateKey), nil
+}
+
+// ParseSSHPublicKey parses a public key given as "<key type> <base64 key>".
+func ParseSSHPublicKey(keyType, encoded string) (ed25519.PublicKey, error) {
+	if keyType != SSHKeyTypeEd25519 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSSHKeyType, keyType)
+	}
+	wire, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHKey, err)
+	}
+	return parseSSHPublicKeyWire(wire)
+}
+
+// SSHFingerprint returns the SHA256 fingerprint of publicKey as printed by ssh-keygen -l.
+func SSHFingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(marshalSSHPublicKey(publicKey))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// SignSSH signs message for namespace with key and returns the armored signature.
+func SignSSH(key ed25519.PrivateKey, namespace string, message []byte) string {
+	publicKey := key.Public().(ed25519.PublicKey)
+	signature := ed25519.Sign(key, sshSignedData(namespace, message))
+
+	var blob sshWriter
+	blob.writeRaw([]byte(sshSignatureMagic))
+	blob.writeUint32(sshSignatureVersion)
+	blob.writeBytes(marshalSSHPublicKey(publicKey))
+	blob.writeString(namespace)
+	blob.writeString("")
+	blob.writeString(sshSignatureHashAlgorithm)
+	var wireSignature sshWriter
+	wireSignature.writeString(SSHKeyTypeEd25519)
+	wireSignature.writeBytes(signature)
+	blob.writeBytes(wireSignature.buffer.Bytes())
+
+	encoded := base64.StdEncoding.EncodeToString(blob.buffer.Bytes())
+	lines := []string{sshSignatureBegin}
+	for len(encoded) > sshSignatureLineLength {
+		lines = append(lines, encoded[:sshSignatureLineLength])
+		encoded = encoded[sshSignatureLineLength:]
+	}
+	lines = append(lines, encoded, sshSignatureEnd)
+	return strings.Join(lines, "\n")
+}
+
+// VerifySSH checks the armored signature of message for namespace and
+// returns the public key that made it. It fails with ErrBadSignature when the
+// signature does not match message.
+func VerifySSH(armored, namespace string, message []byte) (ed25519.PublicKey, error) {
+	armored = strings.TrimSpace(armored)
+	body, ok := strings.CutPrefix(armored, sshSignatureBegin)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing signature header", ErrInvalidSSHSignature)
+	}
+	body, ok = strings.CutSuffix(body, sshSignatureEnd)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing signature footer", ErrInvalidSSHSignature)
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHSignature, err)
+	}
+
+	reader := sshReader{data: blob}
+	if !bytes.HasPrefix(reader.data, []byte(sshSignatureMagic)) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidSSHSignature)
+	}
+	reader.data = reader.data[len(sshSignatureMagic):]
+	version := reader.readUint32()
+	publicKeyWire := reader.readBytes()
+	signedNamespace := reader.readString()
+	reader.readString() // reserved
+	hashAlgorithm := reader.readString()
+	signatureReader := sshReader{data: reader.readBytes()}
+	signatureType := signatureReader.readString()
+	signature := signatureReader.readBytes()
+	if reader.err != nil || signatureReader.err != nil {
+		return nil, fmt.Errorf("%w: truncated signature", ErrInvalidSSHSignature)
+	}
+	if version != sshSignatureVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSSHSignature, version)
+	}
+	if hashAlgorithm != sshSignatureHashAlgorithm {
+		return nil, fmt.Errorf("%w: unsupported hash algorithm %q", ErrInvalidSSHSignature, hashAlgorithm)
+	}
+	if signatureType != SSHKeyTypeEd25519 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSSHKeyType, signatureType)
+	}
+	publicKey, err := parseSSHPublicKeyWire(publicKeyWire)
+	if err != nil {
+		return nil, err
+	}
+	if signedNamespace != namespace {
+		return nil, fmt.Errorf("%w: signature namespace %q, expected %q", ErrBadSignature, signedNamespace, namespace)
+	}
+	if !ed25519.Verify(publicKey, sshSignedData(namespace, message), signature) {
+		return nil, ErrBadSignature
+	}
+	return publicKey, nil
+}
+
+// sshSignedData builds the blob an sshsig signature actually signs.
+func sshSignedData(namespace string, message []byte) []byte {
+	digest := sha512.Sum512(message)
+	var data sshWriter
+	data.writeRaw([]byte(sshSignatureMagic))
+	data.writeString(namespace)
+	data.writeString("")
+	data.writeString(sshSignatureHashAlgorithm)
+	data.writeBytes(digest[:])
+	return data.buffer.Bytes()
+}
+
+// marshalSSHPublicKey encodes publicKey in the SSH wire format.
+func marshalSSHPublicKey(publicKey ed25519.PublicKey) []byte {
+	var wire sshWriter
+	wire.writeString(SSHKeyTypeEd25519)
+	wire.writeBytes(publicKey)
+	return wire.buffer.Bytes()
+}
+
+// parseSSHPublicKeyWire decodes a public key in the SSH wire format.
+func parseSSHPublicKeyWire(wire []byte) (ed25519.PublicKey, error) {
+	reader := sshReader{data: wire}
+	keyType := reader.readString()
+	key := reader.readBytes()
+	if reader.err != nil || len(reader.data) != 0 {
+		return nil, fmt.Errorf("%w: malformed public key", ErrInvalidSSHKey)
+	}
+	if keyType != SSHKeyTypeEd25519 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSSHKeyType, keyType)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: malformed ed25519 key", ErrInvalidSSHKey)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// sshReader decodes SSH wire format values. The first failure sticks in err
+// and makes later reads return zero values.
+type sshReader struct {
+	data []byte
+	err  error
+}
+
+// readUint32 reads a big-endian uint32.
+func (r *sshReader) readUint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = ErrInvalidSSHSignature
+		return 0
+	}
+	value := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return value
+}
+
+// readBytes reads a length-prefixed byte string.
+func (r *sshReader) readBytes() []byte {
+	length := r.readUint32()
+	if r.err != nil || uint32(len(r.data)) < length {
+		r.err = ErrInvalidSSHSignature
+		return nil
+	}
+	value := r.data[:length]
+	r.data = r.data[length:]
+	return value
+}
+
+// readString reads a length-prefixed string.
+func (r *sshReader) readString() string {
+	return string(r.readBytes())
+}
+
+// sshWriter encodes SSH wire format values.
+type sshWriter struct {
+	buffer bytes.Buffer
+}
+
+// writeRaw appends data without a length prefix.
+func (w *sshWriter) writeRaw(data []byte) {
+	w.buffer.Write(data)
+}
+
+// writeUint32 appends a big-endian uint32.
+func (w *sshWriter) writeUint32(value uint32) {
+	w.buffer.Write(binary.BigEndian.AppendUint32(nil, value))
+}
+
+// writeBytes appends a length-prefixed byte string.
+func (w *sshWriter) writeBytes(data []byte) {
+	w.writeUint32(uint32(len(data)))
+	w.buffer.Write(data)
+}
+
+// writeString appends a length-prefixed string.
+func (w *sshWriter) writeString(value string) {
+	w.writeBytes([]byte(value))
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSSHKey generates an ed25519 key pair.
+func newTestSSHKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return key
+}
+
+// encodeTestSSHPrivateKey writes key in the unencrypted OpenSSH private key
+// format of ssh-keygen.
+func encodeTestSSHPrivateKey(key ed25519.PrivateKey) []byte {
+	publicKey := key.Public().(ed25519.PublicKey)
+	var private sshWriter
+	private.writeUint32(0x5eed)
+	private.writeUint32(0x5eed)
+	private.writeString(SSHKeyTypeEd25519)
+	private.writeBytes(publicKey)
+	private.writeBytes(key)
+	private.writeString("test")
+	for padding := byte(1); private.buffer.Len()%8 != 0; padding++ {
+		private.writeRaw([]byte{padding})
+	}
+
+	var file sshWriter
+	file.writeRaw([]byte(sshPrivateKeyMagic))
+	file.writeString("none")
+	file.writeString("none")
+	file.writeString("")
+	file.writeUint32(1)
+	file.writeBytes(marshalSSHPublicKey(publicKey))
+	file.writeBytes(private.buffer.Bytes())
+	return pem.EncodeToMemory(&pem.Block{Type: sshPrivateKeyBlockType, Bytes: file.buffer.Bytes()})
+}
+
+// authorizedTestSSHKey returns the "<key type> <base64 key>" form of the public half of key.
+func authorizedTestSSHKey(key ed25519.PrivateKey) string {
+	wire := marshalSSHPublicKey(key.Public().(ed25519.PublicKey))
+	return SSHKeyTypeEd25519 + " " + base64.StdEncoding.EncodeToString(wire)
+}
+
+func TestParseSSHPrivateKey(t *testing.T) {
+	key := newTestSSHKey(t)
+
+	parsed, err := ParseSSHPrivateKey(encodeTestSSHPrivateKey(key))
+
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+}
+
+func TestParseSSHPrivateKeyRejectsPublicKey(t *testing.T) {
+	_, err := ParseSSHPrivateKey([]byte(authorizedTestSSHKey(newTestSSHKey(t))))
+
+	require.ErrorIs(t, err, ErrInvalidSSHKey)
+}
+
+func TestVerifySSH(t *testing.T) {
+	key := newTestSSHKey(t)
+	otherKey := newTestSSHKey(t)
+	payload := []byte("tree 1234\n\nmessage\n")
+
+	tests := []struct {
+		name        string
+		key         ed25519.PrivateKey
+		namespace   string
+		payload     []byte
+		wantTrusted bool
+		wantErr     error
+	}{
+		{name: "round trip", key: key, namespace: SSHSignatureNamespace, payload: payload, wantTrusted: true},
+		{name: "other key", key: otherKey, namespace: SSHSignatureNamespace, payload: payload},
+		{name: "wrong namespace", key: key, namespace: "file", payload: payload, wantErr: ErrBadSignature},
+		{
+			name:      "tampered payload",
+			key:       key,
+			namespace: SSHSignatureNamespace,
+			payload:   []byte("tree 1234\n\nMessage\n"),
+			wantErr:   ErrBadSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				signature := SignSSH(tt.key, tt.namespace, payload)
+
+				publicKey, err := VerifySSH(signature, SSHSignatureNamespace, tt.payload)
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.True(t, tt.key.Public().(ed25519.PublicKey).Equal(publicKey))
+				assert.Equal(t, tt.wantTrusted, key.Public().(ed25519.PublicKey).Equal(publicKey))
+			},
+		)
+	}
+}
+
+func TestVerifySSHRejectsMalformedSignature(t *testing.T) {
+	signature := SignSSH(newTestSSHKey(t), SSHSignatureNamespace, []byte("payload"))
+	lines := strings.Split(signature, "\n")
+
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{name: "missing header", signature: strings.Join(lines[1:], "\n")},
+		{name: "missing footer", signature: strings.Join(lines[:len(lines)-1], "\n")},
+		{name: "truncated", signature: strings.Join([]string{lines[0], lines[1][:20], lines[len(lines)-1]}, "\n")},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := VerifySSH(tt.signature, SSHSignatureNamespace, []byte("payload"))
+
+				require.ErrorIs(t, err, ErrInvalidSSHSignature)
+			},
+		)
+	}
+}
+
+// TestSSHSignatureMatchesSSHKeygen checks both directions against ssh-keygen -Y
+// when it is installed.
+func TestSSHSignatureMatchesSSHKeygen(t *testing.T) {
+	sshKeygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	payloadPath := filepath.Join(dir, "payload")
+	payload := []byte("tree 1234\n\nmessage\n")
+	require.NoError(t, os.WriteFile(payloadPath, payload, 0o600))
+	output, err := exec.Command(sshKeygen, "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", keyPath).CombinedOutput()
+	require.NoError(t, err, string(output))
+	keyData, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	key, err := ParseSSHPrivateKey(keyData)
+	require.NoError(t, err)
+
+	output, err = exec.Command(sshKeygen, "-Y", "sign", "-n", SSHSignatureNamespace, "-f", keyPath, payloadPath).
+		CombinedOutput()
+	require.NoError(t, err, string(output))
+	sshKeygenSignature, err := os.ReadFile(payloadPath + ".sig")
+	require.NoError(t, err)
+	publicKey, err := VerifySSH(string(sshKeygenSignature), SSHSignatureNamespace, payload)
+	require.NoError(t, err)
+	assert.True(t, key.Public().(ed25519.PublicKey).Equal(publicKey))
+
+	allowedSignersPath := filepath.Join(dir, "allowed_signers")
+	allowedSigners := "signer@example.com " + authorizedTestSSHKey(key) + "\n"
+	require.NoError(t, os.WriteFile(allowedSignersPath, []byte(allowedSigners), 0o600))
+	require.NoError(t, os.WriteFile(payloadPath+".sig", []byte(SignSSH(key, SSHSignatureNamespace, payload)), 0o600))
+	verify := exec.Command(
+		sshKeygen, "-Y", "verify", "-f", allowedSignersPath, "-I", "signer@example.com",
+		"-n", SSHSignatureNamespace, "-s", payloadPath+".sig",
+	)
+	verify.Stdin = strings.NewReader(string(payload))
+	output, err = verify.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Submodule is a repository embedded in the working tree, as listed in .gelmodules.
+type Submodule struct {
+	// Name identifies the submodule in .gelmodules and under [submodule] in config.
+	Name string
+	// Path is the directory the submodule is checked out in.
+	Path domain.NormalizedPath
+	// URL is the repository the submodule is cloned from. A relative URL is
+	// relative to the superproject's working tree root.
+	URL string
+}
+
+// gelModulesEntry is one [submodule."<name>"] table of .gelmodules.
+type gelModulesEntry struct {
+	Path string `toml:"path"`
+	URL  string `toml:"url"`
+}
+
+// gelModulesFile is the TOML layout of .gelmodules.
+type gelModulesFile struct {
+	Submodule map[string]gelModulesEntry `toml:"submodule"`
+}
+
+// ParseGelModules decodes .gelmodules content into submodules sorted by path.
+func ParseGelModules(data []byte) ([]Submodule, error) {
+	var file gelModulesFile
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", domain.GelModulesFileName, err)
+	}
+
+	submodules := make([]Submodule, 0, len(file.Submodule))
+	for name, entry := range file.Submodule {
+		path, err := domain.ParseNormalizedPath(entry.Path)
+		if err != nil || path.IsRoot() {
+			return nil, fmt.Errorf("invalid %s path %q for '%s'", domain.GelModulesFileName, entry.Path, name)
+		}
+		if entry.URL == "" {
+			return nil, fmt.Errorf("%s has no url for '%s'", domain.GelModulesFileName, name)
+		}
+		submodules = append(submodules, Submodule{Name: name, Path: path, URL: entry.URL})
+	}
+	sort.Slice(
+		submodules, func(i, j int) bool {
+			return submodules[i].Path.String() < submodules[j].Path.String()
+		},
+	)
+	return submodules, nil
+}
+
+// SerializeGelModules encodes submodules in .gelmodules format, one
+// [submodule."<name>"] table per submodule in the given order.
+func SerializeGelModules(submodules []Submodule) ([]byte, error) {
+	var buffer bytes.Buffer
+	for i, submodule := range submodules {
+		if i > 0 {
+			buffer.WriteByte('\n')
+		}
+		fmt.Fprintf(&buffer, "[submodule.%s]\n", strconv.Quote(submodule.Name))
+		entry := gelModulesEntry{Path: submodule.Path.String(), URL: submodule.URL}
+		if err := toml.NewEncoder(&buffer).Encode(entry); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", domain.GelModulesFileName, err)
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// ReadSubmoduleHead returns the commit checked out in the repository whose
+// working tree root is path. It reports false when path holds no repository
+// or its HEAD has no commit yet.
+func ReadSubmoduleHead(path domain.AbsolutePath) (domain.Hash, bool, error) {
+	workspace, ok, err := domain.OpenWorkspace(path.String())
+	if err != nil || !ok {
+		return domain.Hash{}, false, err
+	}
+	hash, err := NewRefService(workspace).Resolve(domain.HeadFileName)
+	if errors.Is(err, ErrRefNotFound) {
+		return domain.Hash{}, false, nil
+	}
+	if err != nil {
+		return domain.Hash{}, false, err
+	}
+	return hash, !hash.IsEmpty(), nil
+}
+
+// IsRepositoryRoot reports whether the directory dir holds a .gel directory
+// or file, making it the root of a nested repository such as a submodule.
+func IsRepositoryRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, domain.GelDirName))
+	return err == nil
+}
@@ -2,6 +2,10 @@ package core
 
 import (
 	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -22,6 +26,10 @@ func (p PathHashes) ExtractPathsSorted() (paths []domain.NormalizedPath) {
 	return
 }
 
+// PathModes maps normalized repository-relative paths to file modes.
+// It accompanies PathHashes where callers must tell symlinks from regular files.
+type PathModes map[domain.NormalizedPath]domain.FileMode
+
 // TreeResolver resolves path->hash snapshots from repository trees, index, and working tree.
 type TreeResolver struct {
 	objectService  *ObjectService
@@ -29,6 +37,7 @@ type TreeResolver struct {
 	refService     *RefService
 	pathResolver   *PathResolver
 	changeDetector *ChangeDetector
+	configService  *ConfigService
 	workspace      *domain.Workspace
 }
 
@@ -39,6 +48,7 @@ func NewTreeResolver(
 	refService *RefService,
 	pathResolver *PathResolver,
 	changeDetector *ChangeDetector,
+	configService *ConfigService,
 	workspace *domain.Workspace,
 ) *TreeResolver {
 	return &TreeResolver{
@@ -47,6 +57,7 @@ func NewTreeResolver(
 		refService:     refService,
 		pathResolver:   pathResolver,
 		changeDetector: changeDetector,
+		configService:  configService,
 		workspace:      workspace,
 	}
 }
@@ -73,21 +84,59 @@ func (t *TreeResolver) ResolveCommit(hash domain.Hash) (PathHashes, error) {
 	}
 
 	pathHashes := make(map[domain.NormalizedPath]domain.Hash)
+	err = t.walkCommitTree(
+		commit.TreeHash, func(path domain.NormalizedPath, e domain.TreeEntry) {
+			pathHashes[path] = e.Hash
+		},
+	)
+	return pathHashes, err
+}
+
+// ResolveHEADModes resolves the file modes of the tree pointed to by HEAD.
+func (t *TreeResolver) ResolveHEADModes() (PathModes, error) {
+	commitHash, err := t.refService.Resolve(domain.HeadFileName)
+	if err != nil {
+		return nil, err
+	}
+	return t.ResolveCommitModes(commitHash)
+}
+
+// ResolveCommitModes walks a commit tree recursively and returns normalized path modes.
+func (t *TreeResolver) ResolveCommitModes(hash domain.Hash) (PathModes, error) {
+	commit, err := t.objectService.ReadCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	pathModes := make(PathModes)
+	err = t.walkCommitTree(
+		commit.TreeHash, func(path domain.NormalizedPath, e domain.TreeEntry) {
+			pathModes[path] = e.Mode
+		},
+	)
+	return pathModes, err
+}
+
+// walkCommitTree calls visit for every blob entry reachable from treeHash.
+func (t *TreeResolver) walkCommitTree(
+	treeHash domain.Hash,
+	visit func(path domain.NormalizedPath, e domain.TreeEntry),
+) error {
 	walker := NewTreeWalker(t.objectService, WalkOptions{Recursive: true})
-	err = walker.Walk(
-		commit.TreeHash, "", func(e domain.TreeEntry, relPath string) error {
+	return walker.Walk(
+		treeHash, "", func(e domain.TreeEntry, relPath string) error {
 			normalizedPath, err := domain.ParseNormalizedPath(relPath)
 			if err != nil {
 				return err
 			}
-			pathHashes[normalizedPath] = e.Hash
+			visit(normalizedPath, e)
 			return nil
 		},
 	)
-	return pathHashes, err
 }
 
 // ResolveIndex returns the current index snapshot as normalized path hashes.
+// Intent-to-add entries record no content yet and are left out.
 func (t *TreeResolver) ResolveIndex() (PathHashes, error) {
 	entries, err := t.indexService.GetEntries()
 	if err != nil {
@@ -96,58 +145,268 @@ func (t *TreeResolver) ResolveIndex() (PathHashes, error) {
 
 	pathHashes := make(PathHashes, len(entries))
 	for _, entry := range entries {
+		if entry.IntentToAdd() {
+			continue
+		}
 		pathHashes[entry.Path] = entry.Hash
 	}
 	return pathHashes, nil
 }
 
+// ResolveIndexModes returns the file modes recorded in the index, leaving out
+// intent-to-add entries like ResolveIndex.
+func (t *TreeResolver) ResolveIndexModes() (PathModes, error) {
+	entries, err := t.indexService.GetEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	pathModes := make(PathModes, len(entries))
+	for _, entry := range entries {
+		if entry.IntentToAdd() {
+			continue
+		}
+		pathModes[entry.Path] = domain.FileMode(entry.Mode)
+	}
+	return pathModes, nil
+}
+
+// ResolveIntentToAdd returns the paths of the intent-to-add index entries:
+// paths to be added whose content is not staged yet.
+func (t *TreeResolver) ResolveIntentToAdd() ([]domain.NormalizedPath, error) {
+	entries, err := t.indexService.GetEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []domain.NormalizedPath
+	for _, entry := range entries {
+		if entry.IntentToAdd() {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
+
+// ResolveWorkingTreeModes returns the modes of paths as they exist on disk.
+// Symlinks are reported as FileModeSymlink rather than followed; missing paths are omitted.
+// A directory in place of a file can only be a submodule, checked out or not,
+// and is reported as FileModeGitlink.
+// Paths are stat'ed by up to core.scanworkers goroutines.
+func (t *TreeResolver) ResolveWorkingTreeModes(paths []domain.NormalizedPath) (PathModes, error) {
+	workers, err := t.scanWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	modes := make([]domain.FileMode, len(paths))
+	present := make([]bool, len(paths))
+	err = RunParallel(
+		len(paths), workers, func(i int) error {
+			absolutePath, err := paths[i].ToAbsolutePath(t.workspace.RepoDir)
+			if err != nil {
+				return err
+			}
+			stat, err := domain.NewFileStatFromPath(absolutePath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			mode, err := domain.NewFileModeFromOSMode(stat.Mode)
+			if err != nil {
+				return err
+			}
+			if mode.IsDirectory() {
+				mode = domain.FileModeGitlink
+			}
+			modes[i], present[i] = mode, true
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pathModes := make(PathModes, len(paths))
+	for i, path := range paths {
+		if present[i] {
+			pathModes[path] = modes[i]
+		}
+	}
+	return pathModes, nil
+}
+
 // ResolveWorkingTree returns repository-wide working tree path hashes.
 // The scan is rooted at repository root so results are independent of current working directory.
+// Untracked paths matched by ignore rules are omitted; tracked paths are always included.
+//
+// Files are stat'ed and hashed by up to core.scanworkers goroutines. With
+// core.untrackedcache enabled, directory listings are kept in the index so
+// unchanged directories are not read again on the next scan. With
+// core.fsmonitor set, only files and directories the monitor reports are
+// checked at all.
 func (t *TreeResolver) ResolveWorkingTree() (PathHashes, error) {
-	resolvedPaths, err := t.pathResolver.Resolve([]string{t.workspace.RepoDir.String()})
+	index, err := t.indexService.Read()
+	if err != nil {
+		return nil, err
+	}
+	useCache, err := t.prepareUntrackedCache(index)
 	if err != nil {
 		return nil, err
 	}
 
-	index, err := t.indexService.Read()
+	paths, cacheChanged, err := t.pathResolver.ScanWorkingTree(index.UntrackedCache)
 	if err != nil {
 		return nil, err
 	}
+	indexChanged := cacheChanged || index.FSMonitorToken != ""
+	if !useCache && index.UntrackedCache != nil {
+		index.UntrackedCache = nil
+		indexChanged = true
+	}
 
-	pathHashes := make(map[domain.NormalizedPath]domain.Hash)
-	for _, resolved := range resolvedPaths {
-		for path := range resolved.NormalizedPaths {
-			entry, _ := index.FindEntry(path)
-			if entry != nil {
-				changeResult, err := t.changeDetector.DetectFileChange(entry)
-				if err != nil {
-					return nil, err
-				}
-				switch changeResult.FileState {
-				case FileStateUnchanged:
-					pathHashes[path] = entry.Hash
-				case FileStateModified:
-					pathHashes[path] = changeResult.NewHash
-				case FileStateDeleted:
-					delete(pathHashes, path)
-				}
-			} else {
-				absolutePath, err := path.ToAbsolutePath(t.workspace.RepoDir)
-				if err != nil {
-					return nil, err
-				}
+	workers, err := t.scanWorkers()
+	if err != nil {
+		return nil, err
+	}
 
-				hash, _, err := t.objectService.ComputeObjectHash(absolutePath)
-				if err != nil {
-					return nil, err
-				}
-				pathHashes[path] = hash
+	scanned := make(map[domain.NormalizedPath]bool, len(paths))
+	for _, path := range paths {
+		scanned[path] = true
+	}
+	// Tracked files pruned by ignore rules were not scanned.
+	var unscanned []*domain.IndexEntry
+	for _, entry := range index.Entries {
+		if !scanned[entry.Path] {
+			unscanned = append(unscanned, entry)
+		}
+	}
+
+	hashes := make([]domain.Hash, len(paths)+len(unscanned))
+	present := make([]bool, len(hashes))
+	err = RunParallel(
+		len(hashes), workers, func(i int) error {
+			var hash domain.Hash
+			var exists bool
+			var err error
+			if i < len(paths) {
+				hash, exists, err = t.hashWorkingTreePath(index, paths[i])
+			} else {
+				hash, exists, err = t.hashTrackedEntry(unscanned[i-len(paths)])
 			}
+			hashes[i], present[i] = hash, exists
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist refreshed listings and the entries the monitor can vouch for from now on.
+	if indexChanged {
+		if err := t.indexService.Write(index); err != nil {
+			return nil, err
+		}
+	}
+
+	pathHashes := make(map[domain.NormalizedPath]domain.Hash, len(hashes))
+	for i, hash := range hashes {
+		if !present[i] {
+			continue
+		}
+		if i < len(paths) {
+			pathHashes[paths[i]] = hash
+		} else {
+			pathHashes[unscanned[i-len(paths)].Path] = hash
 		}
 	}
 	return pathHashes, nil
 }
 
+// hashWorkingTreePath returns the content hash of a scanned path and whether it still exists.
+// Tracked paths reuse the index hash while their stat data matches. An
+// untracked nested repository hashes to the commit it has checked out.
+func (t *TreeResolver) hashWorkingTreePath(index *domain.Index, path domain.NormalizedPath) (domain.Hash, bool, error) {
+	if entry, _ := index.FindEntry(path); entry != nil {
+		return t.hashTrackedEntry(entry)
+	}
+
+	absolutePath, err := path.ToAbsolutePath(t.workspace.RepoDir)
+	if err != nil {
+		return domain.Hash{}, false, err
+	}
+	if IsRepositoryRoot(absolutePath.String()) {
+		return ReadSubmoduleHead(absolutePath)
+	}
+	hash, _, err := t.objectService.ComputeObjectHash(absolutePath)
+	if err != nil {
+		return domain.Hash{}, false, err
+	}
+	return hash, true, nil
+}
+
+// hashTrackedEntry returns the working tree hash of a tracked entry and whether its file exists.
+func (t *TreeResolver) hashTrackedEntry(entry *domain.IndexEntry) (domain.Hash, bool, error) {
+	changeResult, err := t.changeDetector.DetectFileChange(entry)
+	if err != nil {
+		return domain.Hash{}, false, err
+	}
+	switch changeResult.FileState {
+	case FileStateUnchanged:
+		return entry.Hash, true, nil
+	case FileStateModified:
+		return changeResult.NewHash, true, nil
+	}
+	return domain.Hash{}, false, nil
+}
+
+// prepareUntrackedCache reports whether core.untrackedcache is enabled and,
+// if so, makes sure index carries a cache computed under the current exclude
+// rules, starting a fresh one when they changed.
+func (t *TreeResolver) prepareUntrackedCache(index *domain.Index) (bool, error) {
+	value, ok, err := t.configService.GetOptional(ConfigSectionCore, ConfigKeyUntrackedCache)
+	if err != nil || !ok {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s.%s %q", ConfigSectionCore, ConfigKeyUntrackedCache, value)
+	}
+	if !enabled {
+		return false, nil
+	}
+
+	var excludesHash domain.Hash
+	if t.pathResolver.ignoreMatcher != nil {
+		excludesHash, err = t.pathResolver.ignoreMatcher.ExcludesFingerprint()
+		if err != nil {
+			return false, err
+		}
+	}
+	if index.UntrackedCache == nil || index.UntrackedCache.ExcludesHash != excludesHash {
+		index.UntrackedCache = domain.NewUntrackedCache(excludesHash)
+	}
+	return true, nil
+}
+
+// scanWorkers returns core.scanworkers, or DefaultWorkers when unset or zero.
+func (t *TreeResolver) scanWorkers() (int, error) {
+	value, ok, err := t.configService.GetOptional(ConfigSectionCore, ConfigKeyScanWorkers)
+	if err != nil || !ok {
+		return DefaultWorkers(), err
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 0 {
+		return 0, fmt.Errorf("invalid %s.%s %q", ConfigSectionCore, ConfigKeyScanWorkers, value)
+	}
+	if workers == 0 {
+		return DefaultWorkers(), nil
+	}
+	return workers, nil
+}
+
 // LookupPathInTree traverses a tree hierarchy using the given tree hash and path, returning the matching tree entry.
 func (t *TreeResolver) LookupPathInTree(treeHash domain.Hash, path domain.NormalizedPath) (domain.TreeEntry, error) {
 	segments := strings.Split(path.String(), "/")
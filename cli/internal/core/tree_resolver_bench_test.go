@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/staging"
+	"Gel/internal/storage"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The synthetic working tree size can be raised for profiling large
+// repositories, for example:
+//
+//	go test ./internal/core -run '^$' -bench ResolveWorkingTree -args -bench.files=100000
+var (
+	benchFiles     = flag.Int("bench.files", 10000, "number of tracked files in the synthetic working tree")
+	benchUntracked = flag.Int("bench.untracked", 1000, "number of untracked files in the synthetic working tree")
+	benchFanout    = flag.Int("bench.fanout", 100, "files per directory and directories per level")
+)
+
+// BenchmarkResolveWorkingTree measures the working tree scan behind gel status
+// with a serial scan, a parallel scan, and a parallel scan backed by the
+// untracked cache. Each configuration scans once before timing so caches are warm.
+func BenchmarkResolveWorkingTree(b *testing.B) {
+	configService, treeResolver := newBenchRepository(b)
+
+	scenarios := []struct {
+		name           string
+		scanWorkers    string
+		untrackedCache string
+	}{
+		{name: "serial", scanWorkers: "1", untrackedCache: "false"},
+		{name: "parallel", scanWorkers: "0", untrackedCache: "false"},
+		{name: "parallel+untracked-cache", scanWorkers: "0", untrackedCache: "true"},
+	}
+	for _, scenario := range scenarios {
+		b.Run(
+			scenario.name, func(b *testing.B) {
+				err := configService.Set(core.ConfigSectionCore, core.ConfigKeyScanWorkers, scenario.scanWorkers)
+				require.NoError(b, err)
+				err = configService.Set(core.ConfigSectionCore, core.ConfigKeyUntrackedCache, scenario.untrackedCache)
+				require.NoError(b, err)
+				_, err = treeResolver.ResolveWorkingTree()
+				require.NoError(b, err)
+
+				for b.Loop() {
+					if _, err := treeResolver.ResolveWorkingTree(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		)
+	}
+}
+
+// newBenchRepository creates a repository with the synthetic files staged
+// and the untracked ones added, and returns its config and tree resolver.
+func newBenchRepository(b *testing.B) (*core.ConfigService, *core.TreeResolver) {
+	b.Helper()
+	dir := b.TempDir()
+	_, err := setup.NewInitService().Init(dir, setup.InitOptions{})
+	require.NoError(b, err)
+	b.Chdir(dir)
+
+	workspace, err := domain.NewWorkspace(dir)
+	require.NoError(b, err)
+	objectService := core.NewObjectService(storage.NewObjectStorage(workspace))
+	indexService := core.NewIndexService(storage.NewIndexStorage(workspace))
+	configService := core.NewConfigService(storage.NewConfigStorage(workspace))
+	indexService.SetConfigService(configService)
+	changeDetector := core.NewChangeDetector(objectService, workspace.RepoDir)
+	indexService.SetChangeDetector(changeDetector)
+	refService := core.NewRefService(workspace)
+	pathResolver := core.NewPathResolver(workspace.RepoDir, core.NewIgnoreMatcher(workspace, configService))
+	treeResolver := core.NewTreeResolver(
+		objectService, indexService, refService, pathResolver, changeDetector, configService, workspace,
+	)
+	updateIndexService := staging.NewUpdateIndexService(
+		indexService, objectService, core.NewHashObjectService(objectService), changeDetector, workspace,
+	)
+	addService := staging.NewAddService(
+		indexService, objectService, updateIndexService, pathResolver, changeDetector, workspace,
+	)
+
+	writeBenchFiles(b, dir, "tracked", *benchFiles, *benchFanout)
+	require.NoError(b, addService.Add([]string{"."}, staging.AddOptions{}).Error)
+	writeBenchFiles(b, dir, "untracked", *benchUntracked, *benchFanout)
+	return configService, treeResolver
+}
+
+// writeBenchFiles creates count small files under dir/prefix, fanout files per
+// directory in a two-level hierarchy of fanout directories per level.
+func writeBenchFiles(b *testing.B, dir, prefix string, count, fanout int) {
+	b.Helper()
+	for i := range count {
+		leaf := i / fanout
+		parent := filepath.Join(dir, prefix, fmt.Sprintf("d%03d", leaf/fanout), fmt.Sprintf("d%03d", leaf%fanout))
+		if i%fanout == 0 {
+			require.NoError(b, os.MkdirAll(parent, domain.DefaultDirPermission))
+		}
+		name := filepath.Join(parent, fmt.Sprintf("f%03d.txt", i%fanout))
+		content := []byte(fmt.Sprintf("%s file %d\n", prefix, i))
+		require.NoError(b, os.WriteFile(name, content, domain.DefaultFilePermission))
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultWorkers returns the worker count used when none is configured.
+func DefaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// RunParallel calls work for every index in [0, count) on at most workers
+// goroutines. Once a call fails no further indexes are handed out, and the
+// first error is returned after running calls finish. A workers value below
+// two runs everything on the calling goroutine.
+func RunParallel(count, workers int, work func(i int) error) error {
+	if workers > count {
+		workers = count
+	}
+	if workers < 2 {
+		for i := range count {
+			if err := work(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		next      atomic.Int64
+		failed    atomic.Bool
+		firstErr  error
+		errOnce   sync.Once
+		waitGroup sync.WaitGroup
+	)
+	for range workers {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= count {
+					return
+				}
+				if err := work(i); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					failed.Store(true)
+					return
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+	return firstErr
+}
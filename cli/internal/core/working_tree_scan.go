@@ -0,0 +1,149 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ScanWorkingTree lists every non-ignored file below the repository root,
+// like Resolve does for the root itself.
+//
+// When cache is not nil, a directory whose mtime and .gelignore stat match
+// its cached listing is not read again; other directories are read and their
+// listings stored in cache. A changed .gelignore also forces every directory
+// below it to be read, since its rules apply there too. Listings of
+// directories no longer reached are dropped. Listings the filesystem monitor
+// vouches for are used without even a stat. ScanWorkingTree reports whether
+// cache was modified.
+func (p *PathResolver) ScanWorkingTree(cache *domain.UntrackedCache) ([]domain.NormalizedPath, bool, error) {
+	scan := &workingTreeScan{
+		resolver: p,
+		cache:    cache,
+		visited:  make(map[string]bool),
+	}
+	if err := scan.scanDir("", false); err != nil {
+		return nil, false, err
+	}
+	if cache != nil {
+		for dir := range cache.Dirs {
+			if !scan.visited[dir] {
+				delete(cache.Dirs, dir)
+				scan.changed = true
+			}
+		}
+	}
+	return scan.paths, scan.changed, nil
+}
+
+// workingTreeScan holds the state of one ScanWorkingTree call.
+type workingTreeScan struct {
+	resolver *PathResolver
+	cache    *domain.UntrackedCache
+	visited  map[string]bool
+	paths    []domain.NormalizedPath
+	changed  bool
+}
+
+// scanDir collects the files below the repository-relative directory dir.
+// With stale set, a cached listing is never trusted.
+func (s *workingTreeScan) scanDir(dir string, stale bool) error {
+	s.visited[dir] = true
+	if s.cache != nil && !stale {
+		if listing := s.cache.Dirs[dir]; listing != nil && listing.FSMonitorValid {
+			return s.collect(dir, listing, false)
+		}
+	}
+
+	absDir := filepath.Join(s.resolver.repoDir.String(), filepath.FromSlash(dir))
+	info, err := os.Stat(absDir)
+	if err != nil {
+		if dir != "" && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	ignoreModifiedTime, ignoreSize := ignoreFileStat(absDir)
+
+	var listing *domain.UntrackedCacheDir
+	if s.cache != nil {
+		listing = s.cache.Dirs[dir]
+	}
+	ignoreChanged := listing == nil ||
+		listing.IgnoreModifiedTime != ignoreModifiedTime ||
+		listing.IgnoreSize != ignoreSize
+	if stale || ignoreChanged || listing.ModifiedTime != info.ModTime().UnixNano() {
+		listing, err = s.readDir(dir, absDir)
+		if err != nil {
+			return err
+		}
+		listing.ModifiedTime = info.ModTime().UnixNano()
+		listing.IgnoreModifiedTime = ignoreModifiedTime
+		listing.IgnoreSize = ignoreSize
+		if s.cache != nil {
+			s.cache.Dirs[dir] = listing
+			s.changed = true
+		}
+	}
+	return s.collect(dir, listing, stale || ignoreChanged)
+}
+
+// collect records the files of listing and scans its subdirectories.
+func (s *workingTreeScan) collect(dir string, listing *domain.UntrackedCacheDir, stale bool) error {
+	for _, name := range listing.Files {
+		normalizedPath, err := domain.ParseNormalizedPath(path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		s.paths = append(s.paths, normalizedPath)
+	}
+	for _, name := range listing.Dirs {
+		if err := s.scanDir(path.Join(dir, name), stale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir lists the non-ignored entries of dir, located at absDir.
+// Symlinks are listed as files and never followed, and so are nested
+// repositories such as submodules, whose content is not ours.
+func (s *workingTreeScan) readDir(dir, absDir string) (*domain.UntrackedCacheDir, error) {
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &domain.UntrackedCacheDir{}
+	for _, entry := range entries {
+		normalizedPath, err := domain.ParseNormalizedPath(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		ignored, err := s.resolver.IsIgnored(normalizedPath, entry.IsDir())
+		if err != nil {
+			return nil, err
+		}
+		if ignored {
+			continue
+		}
+		if entry.IsDir() && !IsRepositoryRoot(filepath.Join(absDir, entry.Name())) {
+			listing.Dirs = append(listing.Dirs, entry.Name())
+		} else {
+			listing.Files = append(listing.Files, entry.Name())
+		}
+	}
+	return listing, nil
+}
+
+// ignoreFileStat returns the mtime in nanoseconds and the size of the
+// .gelignore file in absDir, or zeros when there is none.
+func ignoreFileStat(absDir string) (int64, int64) {
+	info, err := os.Lstat(filepath.Join(absDir, domain.GelIgnoreFileName))
+	if err != nil {
+		return 0, 0
+	}
+	return info.ModTime().UnixNano(), info.Size()
+}
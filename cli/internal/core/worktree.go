@@ -0,0 +1,134 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Worktree describes one working tree attached to the repository.
+type Worktree struct {
+	// Name is the directory below .gel/worktrees holding a linked worktree's
+	// metadata; it is empty for the main worktree.
+	Name string
+	// Dir is the working tree root; it is empty for a bare main repository.
+	Dir string
+	// GelDir is the worktree's own metadata directory holding HEAD and the index.
+	GelDir string
+	// HeadRef is the ref HEAD points at, or empty when HEAD cannot be read.
+	HeadRef string
+	// Bare reports whether this is the main worktree of a bare repository.
+	Bare bool
+	// Current reports whether this is the worktree of the running command.
+	Current bool
+	// Prunable reports that a linked worktree's directory no longer exists,
+	// so its metadata can be pruned.
+	Prunable bool
+}
+
+// WorktreeRegistry lists the worktrees sharing the repository of a workspace.
+type WorktreeRegistry struct {
+	workspace *domain.Workspace
+}
+
+// NewWorktreeRegistry creates a registry for the repository of workspace.
+func NewWorktreeRegistry(workspace *domain.Workspace) *WorktreeRegistry {
+	return &WorktreeRegistry{
+		workspace: workspace,
+	}
+}
+
+// List returns the main worktree followed by the linked worktrees sorted by name.
+func (r *WorktreeRegistry) List() ([]Worktree, error) {
+	commonDir := r.workspace.CommonDir.String()
+	main := Worktree{
+		GelDir:  commonDir,
+		HeadRef: readHeadRef(commonDir),
+		Bare:    filepath.Base(commonDir) != domain.GelDirName,
+	}
+	if !main.Bare {
+		main.Dir = filepath.Dir(commonDir)
+	}
+	main.Current = main.GelDir == r.workspace.GelDir.String()
+	worktrees := []Worktree{main}
+
+	dirEntries, err := os.ReadDir(r.workspace.WorktreesDir.String())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	var linked []Worktree
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		worktree, err := r.readLinked(dirEntry.Name())
+		if err != nil {
+			return nil, err
+		}
+		linked = append(linked, worktree)
+	}
+	sort.Slice(
+		linked, func(i, j int) bool {
+			return linked[i].Name < linked[j].Name
+		},
+	)
+	return append(worktrees, linked...), nil
+}
+
+// CheckedOutElsewhere returns the other worktree whose HEAD points at ref, if any.
+func (r *WorktreeRegistry) CheckedOutElsewhere(ref string) (*Worktree, error) {
+	worktrees, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, worktree := range worktrees {
+		if !worktree.Current && !worktree.Bare && worktree.HeadRef == ref {
+			return &worktree, nil
+		}
+	}
+	return nil, nil
+}
+
+// readLinked describes the linked worktree whose metadata is worktrees/<name>.
+func (r *WorktreeRegistry) readLinked(name string) (Worktree, error) {
+	gelDir := filepath.Join(r.workspace.WorktreesDir.String(), name)
+	worktree := Worktree{
+		Name:    name,
+		GelDir:  gelDir,
+		HeadRef: readHeadRef(gelDir),
+		Current: gelDir == r.workspace.GelDir.String(),
+	}
+
+	data, err := os.ReadFile(filepath.Join(gelDir, domain.GelDirFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		worktree.Prunable = true
+		return worktree, nil
+	case err != nil:
+		return Worktree{}, fmt.Errorf("worktree: %w", err)
+	}
+	gelFile := strings.TrimSpace(string(data))
+	worktree.Dir = filepath.Dir(gelFile)
+	if _, err := os.Stat(gelFile); errors.Is(err, os.ErrNotExist) {
+		worktree.Prunable = true
+	}
+	return worktree, nil
+}
+
+// readHeadRef returns the ref named by the HEAD file in gelDir, or "" when
+// it cannot be read.
+func readHeadRef(gelDir string) string {
+	data, err := os.ReadFile(filepath.Join(gelDir, domain.HeadFileName))
+	if err != nil {
+		return ""
+	}
+	content := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(content, "ref: ") {
+		return ""
+	}
+	return strings.TrimPrefix(content, "ref: ")
+}
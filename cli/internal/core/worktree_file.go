@@ -0,0 +1,77 @@
+package core
+
+import (
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ReadWorkingFile returns the blob body for a working tree path without following symlinks.
+// A symlink yields its target path; any other file yields its contents.
+func ReadWorkingFile(path domain.AbsolutePath) ([]byte, error) {
+	data, _, err := readWorkingFile(path)
+	return data, err
+}
+
+// readWorkingFile is ReadWorkingFile that also reports whether path is a symlink.
+func readWorkingFile(path domain.AbsolutePath) ([]byte, bool, error) {
+	info, err := os.Lstat(path.String())
+	if err != nil {
+		return nil, false, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path.String())
+		if err != nil {
+			return nil, false, err
+		}
+		return []byte(target), true, nil
+	}
+	data, err := os.ReadFile(path.String())
+	return data, false, err
+}
+
+// WriteWorkingFile replaces the working tree path with body according to mode.
+// FileModeSymlink creates a symlink pointing at body; other modes write a regular file.
+// Whatever currently occupies path, including a symlink, is replaced rather than written through.
+func WriteWorkingFile(path domain.AbsolutePath, body []byte, mode domain.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path.String()), domain.DefaultDirPermission); err != nil {
+		return fmt.Errorf("failed to create parent dir for '%s': %w", path, err)
+	}
+	if err := os.Remove(path.String()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to replace '%s': %w", path, err)
+	}
+	if mode.IsSymlink() {
+		if err := os.Symlink(string(body), path.String()); err != nil {
+			return fmt.Errorf("failed to create symlink '%s': %w", path, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path.String(), body, domain.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	return nil
+}
+
+// CheckoutOrder returns paths sorted for writing, with .gelattributes files
+// first so the files they describe are smudged according to their rules.
+func CheckoutOrder(paths []domain.NormalizedPath) []domain.NormalizedPath {
+	ordered := slices.Clone(paths)
+	domain.SortPaths(ordered)
+	slices.SortStableFunc(
+		ordered, func(a, b domain.NormalizedPath) int {
+			return compareAttributesFirst(a) - compareAttributesFirst(b)
+		},
+	)
+	return ordered
+}
+
+// compareAttributesFirst ranks .gelattributes files ahead of every other path.
+func compareAttributesFirst(path domain.NormalizedPath) int {
+	if filepath.Base(path.String()) == domain.GelAttributesFileName {
+		return 0
+	}
+	return 1
+}
@@ -4,7 +4,6 @@ import (
 	"Gel/internal/core"
 	"Gel/internal/domain"
 	"fmt"
-	"os"
 	"slices"
 	"strings"
 )
@@ -34,12 +33,22 @@ type DiffOptions struct {
 // ContentLoaderFunc loads textual content for a path/hash pair in a snapshot.
 type ContentLoaderFunc func(path domain.NormalizedPath, hash domain.Hash) (string, error)
 
-// Snapshot bundles path hashes with a matching content loader.
+// Snapshot bundles path hashes and modes with a matching content loader.
 type Snapshot struct {
 	PathHashes    core.PathHashes
+	PathModes     core.PathModes
 	ContentLoader ContentLoaderFunc
 }
 
+// loadContent loads the content of path. A submodule's content lives in
+// another repository, so it is shown as the commit it records.
+func (s *Snapshot) loadContent(path domain.NormalizedPath, hash domain.Hash) (string, error) {
+	if s.PathModes[path].IsGitlink() {
+		return fmt.Sprintf("Subproject commit %s\n", hash), nil
+	}
+	return s.ContentLoader(path, hash)
+}
+
 // DiffStatus classifies a file-level diff result.
 type DiffStatus int
 
@@ -50,6 +59,8 @@ const (
 	DiffStatusAdded
 	// DiffStatusDeleted indicates a path exists only in the old snapshot.
 	DiffStatusDeleted
+	// DiffStatusTypeChanged indicates a path switched between a symlink and a regular file.
+	DiffStatusTypeChanged
 )
 
 // DiffResult represents the patch hunks and metadata for one changed path.
@@ -60,6 +71,10 @@ type DiffResult struct {
 	NewPath domain.NormalizedPath
 	OldHash domain.Hash
 	NewHash domain.Hash
+	OldMode domain.FileMode
+	NewMode domain.FileMode
+	// Binary reports that content was not diffed line by line and Hunks is empty.
+	Binary bool
 }
 
 // DiffService resolves snapshots and computes unified-style line diffs.
@@ -67,6 +82,7 @@ type DiffService struct {
 	objectService *core.ObjectService
 	treeResolver  *core.TreeResolver
 	diffAlgorithm *MyersDiffAlgorithm
+	contentFilter *core.ContentFilter
 	workspace     *domain.Workspace
 }
 
@@ -75,12 +91,14 @@ func NewDiffService(
 	objectService *core.ObjectService,
 	treeResolver *core.TreeResolver,
 	diffAlgorithm *MyersDiffAlgorithm,
+	contentFilter *core.ContentFilter,
 	workspace *domain.Workspace,
 ) *DiffService {
 	return &DiffService{
 		objectService: objectService,
 		treeResolver:  treeResolver,
 		diffAlgorithm: diffAlgorithm,
+		contentFilter: contentFilter,
 		workspace:     workspace,
 	}
 }
@@ -98,57 +116,65 @@ func (d *DiffService) Diff(options DiffOptions) ([]*DiffResult, error) {
 func (d *DiffService) computeDiffResults(oldSnapshot *Snapshot, newSnapshot *Snapshot) ([]*DiffResult, error) {
 	var results []*DiffResult
 	for newPath, newHash := range newSnapshot.PathHashes {
-		newContent, err := newSnapshot.ContentLoader(newPath, newHash)
+		newMode := newSnapshot.PathModes[newPath]
+		oldMode := oldSnapshot.PathModes[newPath]
+		oldHash, ok := oldSnapshot.PathHashes[newPath]
+		if ok && newHash == oldHash && newMode.SameType(oldMode) {
+			continue
+		}
+
+		newContent, err := newSnapshot.loadContent(newPath, newHash)
 		if err != nil {
 			return nil, err
 		}
-
-		newLines := strings.Split(strings.TrimSuffix(newContent, "\n"), "\n")
-		oldHash, ok := oldSnapshot.PathHashes[newPath]
 		if !ok {
-			lineDiffs := d.diffAlgorithm.ComputeLineDiffs(nil, newLines)
-			regions := FindRegions(lineDiffs)
-			hunks := BuildHunks(lineDiffs, regions)
-			results = append(
-				results, &DiffResult{
-					hunks, DiffStatusAdded, newPath,
-					newPath, domain.Hash{}, newHash,
-				},
-			)
-		} else if newHash != oldHash {
-			oldContent, err := oldSnapshot.ContentLoader(newPath, oldHash)
+			hunks, binary, err := d.computeHunks(newPath, nil, &newContent)
 			if err != nil {
 				return nil, err
 			}
-
-			oldLines := strings.Split(strings.TrimSuffix(oldContent, "\n"), "\n")
-			lineDiffs := d.diffAlgorithm.ComputeLineDiffs(oldLines, newLines)
-			regions := FindRegions(lineDiffs)
-			hunks := BuildHunks(lineDiffs, regions)
 			results = append(
 				results, &DiffResult{
-					hunks, DiffStatusModified, newPath,
-					newPath, oldHash, newHash,
+					hunks, DiffStatusAdded, newPath,
+					newPath, domain.Hash{}, newHash, 0, newMode, binary,
 				},
 			)
+			continue
+		}
+
+		status := DiffStatusModified
+		if !newMode.SameType(oldMode) {
+			status = DiffStatusTypeChanged
 		}
+		oldContent, err := oldSnapshot.loadContent(newPath, oldHash)
+		if err != nil {
+			return nil, err
+		}
+		hunks, binary, err := d.computeHunks(newPath, &oldContent, &newContent)
+		if err != nil {
+			return nil, err
+		}
+		results = append(
+			results, &DiffResult{
+				hunks, status, newPath,
+				newPath, oldHash, newHash, oldMode, newMode, binary,
+			},
+		)
 	}
 
 	for oldPath, oldHash := range oldSnapshot.PathHashes {
 		if _, ok := newSnapshot.PathHashes[oldPath]; !ok {
-			oldContent, err := oldSnapshot.ContentLoader(oldPath, oldHash)
+			oldContent, err := oldSnapshot.loadContent(oldPath, oldHash)
+			if err != nil {
+				return nil, err
+			}
+			hunks, binary, err := d.computeHunks(oldPath, &oldContent, nil)
 			if err != nil {
 				return nil, err
 			}
-
-			oldLines := strings.Split(strings.TrimSuffix(oldContent, "\n"), "\n")
-			lineDiffs := d.diffAlgorithm.ComputeLineDiffs(oldLines, nil)
-			regions := FindRegions(lineDiffs)
-			hunks := BuildHunks(lineDiffs, regions)
 			results = append(
 				results, &DiffResult{
 					hunks, DiffStatusDeleted, oldPath,
-					domain.NormalizedPath{}, oldHash, domain.Hash{},
+					domain.NormalizedPath{}, oldHash, domain.Hash{}, oldSnapshot.PathModes[oldPath], 0, binary,
 				},
 			)
 		}
@@ -156,86 +182,178 @@ func (d *DiffService) computeDiffResults(oldSnapshot *Snapshot, newSnapshot *Sna
 	return sortDiffResults(results), nil
 }
 
+// computeHunks diffs the old and new content of path; a nil side is absent.
+// The path's diff attribute is honored: an unset attribute, or content that
+// looks binary, yields no hunks and reports binary, and a textconv driver
+// converts both sides before they are compared.
+func (d *DiffService) computeHunks(path domain.NormalizedPath, oldContent, newContent *string) ([]*Hunk, bool, error) {
+	driver, err := d.contentFilter.DiffDriver(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if driver.Binary {
+		return nil, true, nil
+	}
+
+	var sides [2][]string
+	for i, content := range []*string{oldContent, newContent} {
+		if content == nil {
+			continue
+		}
+		data := []byte(*content)
+		if driver.TextConv != "" {
+			data, err = d.contentFilter.TextConv(driver.TextConv, data)
+			if err != nil {
+				return nil, false, err
+			}
+		} else if core.IsBinary(data) {
+			return nil, true, nil
+		}
+		sides[i] = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	lineDiffs := d.diffAlgorithm.ComputeLineDiffs(sides[0], sides[1])
+	regions := FindRegions(lineDiffs)
+	return BuildHunks(lineDiffs, regions), false, nil
+}
+
 // LoadSnapshots resolves old/new snapshots and matching content loaders for a diff mode.
 func (d *DiffService) LoadSnapshots(options DiffOptions) (*Snapshot, *Snapshot, error) {
 	switch options.Mode {
 	case DiffModeHeadVsWorkingTree:
-		headPathHashes, err := d.treeResolver.ResolveHEAD()
+		headSnapshot, err := d.loadHEADSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		workingTreePathHashes, err := d.treeResolver.ResolveWorkingTree()
+		workingTreeSnapshot, err := d.loadWorkingTreeSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
-		return &Snapshot{headPathHashes, d.LoadBlobContent},
-			&Snapshot{workingTreePathHashes, d.LoadFileContent},
-			nil
+		return headSnapshot, workingTreeSnapshot, nil
 	case DiffModeHEADVsIndex:
-		headPathHashes, err := d.treeResolver.ResolveHEAD()
+		headSnapshot, err := d.loadHEADSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		indexPathHashes, err := d.treeResolver.ResolveIndex()
+		indexSnapshot, err := d.loadIndexSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
-		return &Snapshot{headPathHashes, d.LoadBlobContent},
-			&Snapshot{indexPathHashes, d.LoadBlobContent},
-			nil
+		return headSnapshot, indexSnapshot, nil
 
 	case DiffModeCommitVsCommit:
-		baseCommitPathHashes := make(core.PathHashes)
+		baseSnapshot := &Snapshot{make(core.PathHashes), make(core.PathModes), d.LoadBlobContent}
 		if !options.BaseCommitHash.IsEmpty() {
 			var err error
-			baseCommitPathHashes, err = d.treeResolver.ResolveCommit(options.BaseCommitHash)
+			baseSnapshot, err = d.loadCommitSnapshot(options.BaseCommitHash)
 			if err != nil {
 				return nil, nil, err
 			}
 		}
 
-		targetCommitPathHashes, err := d.treeResolver.ResolveCommit(options.TargetCommitHash)
+		targetSnapshot, err := d.loadCommitSnapshot(options.TargetCommitHash)
 		if err != nil {
 			return nil, nil, err
 		}
-		return &Snapshot{baseCommitPathHashes, d.LoadBlobContent},
-			&Snapshot{targetCommitPathHashes, d.LoadBlobContent},
-			nil
+		return baseSnapshot, targetSnapshot, nil
 
 	case DiffModeCommitVsWorkingTree:
-		commitPathHashes, err := d.treeResolver.ResolveCommit(options.BaseCommitHash)
+		commitSnapshot, err := d.loadCommitSnapshot(options.BaseCommitHash)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		workingTreePathHashes, err := d.treeResolver.ResolveWorkingTree()
+		workingTreeSnapshot, err := d.loadWorkingTreeSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
-		return &Snapshot{commitPathHashes, d.LoadBlobContent},
-			&Snapshot{workingTreePathHashes, d.LoadFileContent},
-			nil
+		return commitSnapshot, workingTreeSnapshot, nil
 
 	case DiffModeIndexVsWorkingTree:
-		indexPathHashes, err := d.treeResolver.ResolveIndex()
+		indexSnapshot, err := d.loadIndexSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		workingTreePathHashes, err := d.treeResolver.ResolveWorkingTree()
+		workingTreeSnapshot, err := d.loadWorkingTreeSnapshot()
 		if err != nil {
 			return nil, nil, err
 		}
-		return &Snapshot{indexPathHashes, d.LoadBlobContent},
-			&Snapshot{workingTreePathHashes, d.LoadFileContent},
-			nil
+		return indexSnapshot, workingTreeSnapshot, nil
 	default:
 		return nil, nil, fmt.Errorf("%d': %w", options.Mode, ErrUnsupportedDiffMode)
 	}
 }
 
+// loadHEADSnapshot resolves the HEAD tree snapshot backed by object storage.
+func (d *DiffService) loadHEADSnapshot() (*Snapshot, error) {
+	pathHashes, err := d.treeResolver.ResolveHEAD()
+	if err != nil {
+		return nil, err
+	}
+	pathModes, err := d.treeResolver.ResolveHEADModes()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{pathHashes, pathModes, d.LoadBlobContent}, nil
+}
+
+// loadCommitSnapshot resolves a commit tree snapshot backed by object storage.
+func (d *DiffService) loadCommitSnapshot(commitHash domain.Hash) (*Snapshot, error) {
+	pathHashes, err := d.treeResolver.ResolveCommit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	pathModes, err := d.treeResolver.ResolveCommitModes(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{pathHashes, pathModes, d.LoadBlobContent}, nil
+}
+
+// loadIndexSnapshot resolves the index snapshot backed by object storage.
+func (d *DiffService) loadIndexSnapshot() (*Snapshot, error) {
+	pathHashes, err := d.treeResolver.ResolveIndex()
+	if err != nil {
+		return nil, err
+	}
+	pathModes, err := d.treeResolver.ResolveIndexModes()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{pathHashes, pathModes, d.LoadBlobContent}, nil
+}
+
+// loadWorkingTreeSnapshot resolves the working tree snapshot backed by files on disk.
+// Only tracked paths are included: untracked files are not diffed unless
+// they are recorded as intent-to-add, in which case they show up as new.
+func (d *DiffService) loadWorkingTreeSnapshot() (*Snapshot, error) {
+	pathHashes, err := d.treeResolver.ResolveWorkingTree()
+	if err != nil {
+		return nil, err
+	}
+	indexPathHashes, err := d.treeResolver.ResolveIndex()
+	if err != nil {
+		return nil, err
+	}
+	intentToAddPaths, err := d.treeResolver.ResolveIntentToAdd()
+	if err != nil {
+		return nil, err
+	}
+	for path := range pathHashes {
+		if _, tracked := indexPathHashes[path]; !tracked && !slices.Contains(intentToAddPaths, path) {
+			delete(pathHashes, path)
+		}
+	}
+	pathModes, err := d.treeResolver.ResolveWorkingTreeModes(pathHashes.ExtractPaths())
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{pathHashes, pathModes, d.LoadFileContent}, nil
+}
+
 // LoadBlobContent loads blob text from object storage.
 func (d *DiffService) LoadBlobContent(_ domain.NormalizedPath, hash domain.Hash) (string, error) {
 	blob, err := d.objectService.ReadBlob(hash)
@@ -246,13 +364,14 @@ func (d *DiffService) LoadBlobContent(_ domain.NormalizedPath, hash domain.Hash)
 }
 
 // LoadFileContent loads file text from the working tree using repository-root resolution.
+// Content is clean-filtered so it compares with blobs; symlinks load as their target path.
 func (d *DiffService) LoadFileContent(path domain.NormalizedPath, _ domain.Hash) (string, error) {
 	absolutePath, err := path.ToAbsolutePath(d.workspace.RepoDir)
 	if err != nil {
 		return "", err
 	}
 
-	content, err := os.ReadFile(absolutePath.String())
+	content, err := d.objectService.ReadWorkingContent(absolutePath)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,430 @@
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// PatchDirection selects what selecting a hunk does to the content written back.
+type PatchDirection int
+
+const (
+	// PatchDirectionForward applies selected hunks on top of the old content (add -p).
+	PatchDirectionForward PatchDirection = iota
+	// PatchDirectionReverse reverts selected hunks out of the new content (restore -p, reset -p).
+	PatchDirectionReverse
+)
+
+const (
+	patchPromptChoices = "[y,n,q,a,d,s,e,?]"
+	noNewlineMarker    = `\ No newline at end of file`
+)
+
+// EditFunc opens path in an editor and returns once the user is done.
+type EditFunc func(path string) error
+
+// PatchSelector drives interactive hunk selection for patch mode commands.
+//
+// Answers are read one per line from input, so a script can drive a session
+// by piping answers on stdin; reaching the end of input behaves like "q".
+type PatchSelector struct {
+	input     *bufio.Reader
+	output    io.Writer
+	action    string
+	direction PatchDirection
+	edit      EditFunc
+	algorithm *MyersDiffAlgorithm
+	quit      bool
+}
+
+// NewPatchSelector creates a selector that asks "<action> this hunk" for each
+// hunk. edit may be nil, in which case the "e" answer is unavailable.
+func NewPatchSelector(
+	input io.Reader,
+	output io.Writer,
+	action string,
+	direction PatchDirection,
+	edit EditFunc,
+) *PatchSelector {
+	return &PatchSelector{
+		input:     bufio.NewReader(input),
+		output:    output,
+		action:    action,
+		direction: direction,
+		edit:      edit,
+		algorithm: NewMyersDiffAlgorithm(),
+	}
+}
+
+// Done reports whether the user quit; callers skip the remaining files.
+func (s *PatchSelector) Done() bool {
+	return s.quit
+}
+
+// patchHunk is one selectable hunk over a shared line diff.
+type patchHunk struct {
+	// start and end bound the displayed lines, context included.
+	start, end int
+	// first and last bound the changed lines this hunk decides.
+	first, last int
+	decided     bool
+	selected    bool
+	// replacement, when non-nil, replaces lines [start, end) of the written content.
+	replacement []string
+}
+
+// Select shows the change from oldContent to newContent for path hunk by hunk.
+//
+// It returns the content to write back: oldContent with the selected hunks
+// applied for PatchDirectionForward, or newContent with the selected hunks
+// reverted for PatchDirectionReverse. The boolean reports whether any hunk was
+// selected. Binary content is skipped.
+func (s *PatchSelector) Select(path, oldContent, newContent string) (string, bool, error) {
+	base := newContent
+	if s.direction == PatchDirectionForward {
+		base = oldContent
+	}
+	if s.quit {
+		return base, false, nil
+	}
+	if strings.ContainsRune(oldContent, 0) || strings.ContainsRune(newContent, 0) {
+		fmt.Fprintf(s.output, "Skipping binary file %s\n", path)
+		return base, false, nil
+	}
+
+	lineDiffs := s.algorithm.ComputeLineDiffs(splitPatchLines(oldContent), splitPatchLines(newContent))
+	var hunks []*patchHunk
+	for _, region := range FindRegions(lineDiffs) {
+		hunks = append(hunks, newPatchHunk(lineDiffs, region.Start, region.End))
+	}
+	if len(hunks) == 0 {
+		return base, false, nil
+	}
+
+	fmt.Fprintf(s.output, "diff --gel a/%s b/%s\n", path, path)
+	for i := 0; i < len(hunks) && !s.quit; {
+		hunk := hunks[i]
+		if hunk.decided {
+			i++
+			continue
+		}
+
+		s.printHunk(lineDiffs, hunk)
+		fmt.Fprintf(s.output, "(%d/%d) %s this hunk %s? ", i+1, len(hunks), s.action, patchPromptChoices)
+		answer, err := s.readAnswer()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch answer {
+		case "y":
+			hunk.decided, hunk.selected = true, true
+		case "n":
+			hunk.decided = true
+		case "q":
+			s.quit = true
+		case "a", "d":
+			for _, remaining := range hunks[i:] {
+				if !remaining.decided {
+					remaining.decided, remaining.selected = true, answer == "a"
+				}
+			}
+		case "s":
+			split := splitPatchHunk(lineDiffs, hunk)
+			if len(split) == 1 {
+				fmt.Fprintf(s.output, "Sorry, cannot split this hunk\n")
+				continue
+			}
+			fmt.Fprintf(s.output, "Split into %d hunks.\n", len(split))
+			hunks = slices.Replace(hunks, i, i+1, split...)
+			continue
+		case "e":
+			if err := s.editHunk(path, lineDiffs, hunk); err != nil {
+				fmt.Fprintf(s.output, "%v\n", err)
+				continue
+			}
+			hunk.decided, hunk.selected = true, true
+		default:
+			s.printHelp()
+			continue
+		}
+		i++
+	}
+	return s.build(lineDiffs, hunks)
+}
+
+// newPatchHunk creates a hunk displaying lineDiffs[start:end] and deciding every change in it.
+func newPatchHunk(lineDiffs []LineDiff, start, end int) *patchHunk {
+	hunk := &patchHunk{start: start, end: end, first: -1}
+	for i := start; i < end; i++ {
+		if lineDiffs[i].OperationType == OpTypeMatch {
+			continue
+		}
+		if hunk.first < 0 {
+			hunk.first = i
+		}
+		hunk.last = i
+	}
+	return hunk
+}
+
+// splitPatchHunk splits hunk at unchanged lines between its changes. Each part
+// keeps up to three context lines without reaching into a neighbouring part.
+func splitPatchHunk(lineDiffs []LineDiff, hunk *patchHunk) []*patchHunk {
+	if hunk.replacement != nil {
+		return []*patchHunk{hunk}
+	}
+
+	type run struct{ first, last int }
+	var runs []run
+	for i := hunk.first; i <= hunk.last; i++ {
+		if lineDiffs[i].OperationType == OpTypeMatch {
+			continue
+		}
+		if len(runs) > 0 && runs[len(runs)-1].last == i-1 {
+			runs[len(runs)-1].last = i
+			continue
+		}
+		runs = append(runs, run{i, i})
+	}
+	if len(runs) <= 1 {
+		return []*patchHunk{hunk}
+	}
+
+	parts := make([]*patchHunk, len(runs))
+	for i, r := range runs {
+		start := max(hunk.start, r.first-3)
+		if i > 0 {
+			start = max(start, runs[i-1].last+1)
+		}
+		end := min(hunk.end, r.last+4)
+		if i < len(runs)-1 {
+			end = min(end, runs[i+1].first)
+		}
+		parts[i] = &patchHunk{start: start, end: end, first: r.first, last: r.last}
+	}
+	return parts
+}
+
+// build assembles the content to write back from the hunk decisions.
+func (s *PatchSelector) build(lineDiffs []LineDiff, hunks []*patchHunk) (string, bool, error) {
+	owner := make(map[int]*patchHunk)
+	edited := make(map[int]*patchHunk)
+	selectedAny := false
+	for _, hunk := range hunks {
+		if !hunk.selected {
+			continue
+		}
+		selectedAny = true
+		if hunk.replacement != nil {
+			edited[hunk.start] = hunk
+			continue
+		}
+		for i := hunk.first; i <= hunk.last; i++ {
+			owner[i] = hunk
+		}
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(lineDiffs); i++ {
+		if hunk, ok := edited[i]; ok {
+			for _, line := range hunk.replacement {
+				builder.WriteString(line)
+			}
+			i = hunk.end - 1
+			continue
+		}
+
+		line := lineDiffs[i]
+		takeNew := (owner[i] != nil) == (s.direction == PatchDirectionForward)
+		switch {
+		case line.OperationType == OpTypeMatch,
+			line.OperationType == OpTypeInsertion && takeNew,
+			line.OperationType == OpTypeDeletion && !takeNew:
+			builder.WriteString(line.Content)
+		}
+	}
+	return builder.String(), selectedAny, nil
+}
+
+// printHunk writes the hunk header and its lines in unified diff format.
+func (s *PatchSelector) printHunk(lineDiffs []LineDiff, hunk *patchHunk) {
+	if hunk.replacement != nil {
+		return
+	}
+	header := BuildHunks(lineDiffs, []Region{{hunk.start, hunk.end}})[0]
+	fmt.Fprintf(
+		s.output, "@@ -%d,%d +%d,%d @@\n",
+		header.OldStart, header.OldLength, header.NewStart, header.NewLength,
+	)
+	for _, line := range lineDiffs[hunk.start:hunk.end] {
+		writePatchLine(s.output, patchLinePrefix(line.OperationType), line.Content)
+	}
+}
+
+// editHunk lets the user rewrite hunk in an editor and stores the result as
+// its replacement. The edited hunk must still match the content it is applied to.
+func (s *PatchSelector) editHunk(path string, lineDiffs []LineDiff, hunk *patchHunk) error {
+	if s.edit == nil {
+		return errors.New("editing hunks is not supported here")
+	}
+
+	file, err := os.CreateTemp("", "gel-hunk-*.diff")
+	if err != nil {
+		return fmt.Errorf("edit hunk: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	header := BuildHunks(lineDiffs, []Region{{hunk.start, hunk.end}})[0]
+	fmt.Fprintf(file, "# Manual hunk edit mode for %s -- see bottom for a quick guide.\n", path)
+	fmt.Fprintf(file, "@@ -%d,%d +%d,%d @@\n", header.OldStart, header.OldLength, header.NewStart, header.NewLength)
+	for _, line := range lineDiffs[hunk.start:hunk.end] {
+		writePatchLine(file, patchLinePrefix(line.OperationType), line.Content)
+	}
+	removedPrefix, addedPrefix := "-", "+"
+	if s.direction == PatchDirectionReverse {
+		removedPrefix, addedPrefix = "+", "-"
+	}
+	fmt.Fprintf(file, "# ---\n")
+	fmt.Fprintf(file, "# To remove '%s' lines, make them ' ' lines (context).\n", removedPrefix)
+	fmt.Fprintf(file, "# To remove '%s' lines, delete them.\n", addedPrefix)
+	fmt.Fprintf(file, "# Lines starting with # will be removed.\n")
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("edit hunk: %w", err)
+	}
+
+	if err := s.edit(file.Name()); err != nil {
+		return fmt.Errorf("edit hunk: %w", err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return fmt.Errorf("edit hunk: %w", err)
+	}
+
+	oldSide, newSide := parseEditedHunk(string(data))
+	var expected []string
+	for _, line := range lineDiffs[hunk.start:hunk.end] {
+		if line.OperationType == OpTypeMatch ||
+			(line.OperationType == OpTypeDeletion) == (s.direction == PatchDirectionForward) {
+			expected = append(expected, line.Content)
+		}
+	}
+
+	base, replacement := oldSide, newSide
+	if s.direction == PatchDirectionReverse {
+		base, replacement = newSide, oldSide
+	}
+	if !slices.Equal(base, expected) {
+		return errors.New("your edited hunk does not apply")
+	}
+	hunk.replacement = replacement
+	if hunk.replacement == nil {
+		hunk.replacement = []string{}
+	}
+	return nil
+}
+
+// parseEditedHunk returns the old and new sides of an edited hunk.
+// Comment and header lines are dropped; a blank line counts as empty context.
+func parseEditedHunk(text string) (oldSide, newSide []string) {
+	// previous lists the sides the previous line was appended to, so a
+	// following "no newline" marker can strip its line ending.
+	var previous []*[]string
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		if line == noNewlineMarker {
+			for _, side := range previous {
+				n := len(*side) - 1
+				(*side)[n] = strings.TrimSuffix((*side)[n], "\n")
+			}
+			previous = nil
+			continue
+		}
+
+		prefix, content := " ", "\n"
+		if line != "" {
+			prefix, content = line[:1], line[1:]+"\n"
+		}
+		switch prefix {
+		case "-":
+			previous = []*[]string{&oldSide}
+		case "+":
+			previous = []*[]string{&newSide}
+		default:
+			previous = []*[]string{&oldSide, &newSide}
+		}
+		for _, side := range previous {
+			*side = append(*side, content)
+		}
+	}
+	return oldSide, newSide
+}
+
+// readAnswer reads the next answer; end of input is treated as "q".
+func (s *PatchSelector) readAnswer() (string, error) {
+	line, err := s.input.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" && errors.Is(err, io.EOF) {
+		fmt.Fprintln(s.output)
+		return "q", nil
+	}
+	if answer == "" {
+		return "?", nil
+	}
+	return strings.ToLower(answer[:1]), nil
+}
+
+// printHelp lists the accepted answers.
+func (s *PatchSelector) printHelp() {
+	action := strings.ToLower(s.action)
+	fmt.Fprintf(s.output, "y - %s this hunk\n", action)
+	fmt.Fprintf(s.output, "n - do not %s this hunk\n", action)
+	fmt.Fprintf(s.output, "q - quit; do not %s this hunk or any of the remaining ones\n", action)
+	fmt.Fprintf(s.output, "a - %s this hunk and all later hunks in the file\n", action)
+	fmt.Fprintf(s.output, "d - do not %s this hunk or any of the later hunks in the file\n", action)
+	fmt.Fprintf(s.output, "s - split the current hunk into smaller hunks\n")
+	fmt.Fprintf(s.output, "e - manually edit the current hunk\n")
+	fmt.Fprintf(s.output, "? - print help\n")
+}
+
+// splitPatchLines splits content into lines that keep their trailing newline.
+func splitPatchLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// patchLinePrefix returns the unified diff prefix for an operation.
+func patchLinePrefix(operationType OperationType) string {
+	switch operationType {
+	case OpTypeInsertion:
+		return "+"
+	case OpTypeDeletion:
+		return "-"
+	}
+	return " "
+}
+
+// writePatchLine writes one diff line, marking content without a trailing newline.
+func writePatchLine(writer io.Writer, prefix, content string) {
+	if strings.HasSuffix(content, "\n") {
+		fmt.Fprintf(writer, "%s%s", prefix, content)
+		return
+	}
+	fmt.Fprintf(writer, "%s%s\n%s\n", prefix, content, noNewlineMarker)
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	patchTestOld = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	// patchTestNew changes lines 2 and 6, close enough to share one hunk.
+	patchTestNew = "one\nTWO\nthree\nfour\nfive\nSIX\nseven\neight\n"
+	// patchTestFirstOnly and patchTestSecondOnly carry one of the two changes.
+	patchTestFirstOnly  = "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\n"
+	patchTestSecondOnly = "one\ntwo\nthree\nfour\nfive\nSIX\nseven\neight\n"
+)
+
+func TestPatchSelectorSelect(t *testing.T) {
+	tests := []struct {
+		name         string
+		direction    PatchDirection
+		answers      string
+		wantContent  string
+		wantSelected bool
+	}{
+		{"forward yes", PatchDirectionForward, "y\n", patchTestNew, true},
+		{"forward no", PatchDirectionForward, "n\n", patchTestOld, false},
+		{"forward quit", PatchDirectionForward, "q\n", patchTestOld, false},
+		{"forward end of input", PatchDirectionForward, "", patchTestOld, false},
+		{"forward all", PatchDirectionForward, "a\n", patchTestNew, true},
+		{"forward none", PatchDirectionForward, "d\n", patchTestOld, false},
+		{"forward split first", PatchDirectionForward, "s\ny\nn\n", patchTestFirstOnly, true},
+		{"forward split second", PatchDirectionForward, "s\nn\ny\n", patchTestSecondOnly, true},
+		{"forward split then quit", PatchDirectionForward, "s\ny\nq\n", patchTestFirstOnly, true},
+		{"forward help then yes", PatchDirectionForward, "?\ny\n", patchTestNew, true},
+		{"reverse yes", PatchDirectionReverse, "y\n", patchTestOld, true},
+		{"reverse no", PatchDirectionReverse, "n\n", patchTestNew, false},
+		{"reverse quit", PatchDirectionReverse, "q\n", patchTestNew, false},
+		{"reverse split first", PatchDirectionReverse, "s\ny\nn\n", patchTestSecondOnly, true},
+		{"reverse split second", PatchDirectionReverse, "s\nn\ny\n", patchTestFirstOnly, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				selector := NewPatchSelector(strings.NewReader(tt.answers), io.Discard, "Stage", tt.direction, nil)
+
+				content, selected, err := selector.Select("file.txt", patchTestOld, patchTestNew)
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantContent, content)
+				assert.Equal(t, tt.wantSelected, selected)
+			},
+		)
+	}
+}
+
+func TestPatchSelectorQuitSkipsLaterFiles(t *testing.T) {
+	var output strings.Builder
+	selector := NewPatchSelector(strings.NewReader("q\ny\n"), &output, "Stage", PatchDirectionForward, nil)
+
+	_, _, err := selector.Select("first.txt", patchTestOld, patchTestNew)
+	require.NoError(t, err)
+	require.True(t, selector.Done())
+
+	content, selected, err := selector.Select("second.txt", patchTestOld, patchTestNew)
+	require.NoError(t, err)
+	assert.Equal(t, patchTestOld, content)
+	assert.False(t, selected)
+	assert.NotContains(t, output.String(), "second.txt")
+}
+
+func TestPatchSelectorCannotSplitSingleChange(t *testing.T) {
+	var output strings.Builder
+	selector := NewPatchSelector(strings.NewReader("s\ny\n"), &output, "Stage", PatchDirectionForward, nil)
+
+	content, selected, err := selector.Select("file.txt", "a\nb\n", "a\nB\n")
+
+	require.NoError(t, err)
+	assert.Equal(t, "a\nB\n", content)
+	assert.True(t, selected)
+	assert.Contains(t, output.String(), "Sorry, cannot split this hunk")
+}
+
+func TestPatchSelectorSkipsBinaryContent(t *testing.T) {
+	selector := NewPatchSelector(strings.NewReader("y\n"), io.Discard, "Stage", PatchDirectionForward, nil)
+
+	content, selected, err := selector.Select("file.bin", "a\x00", "b\x00")
+
+	require.NoError(t, err)
+	assert.Equal(t, "a\x00", content)
+	assert.False(t, selected)
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidCacheTree is returned when the cache-tree index extension cannot be parsed.
+	ErrInvalidCacheTree = errors.New("invalid cache-tree extension")
+)
+
+const (
+	// CacheTreeSignature identifies the cache-tree extension in the index file.
+	CacheTreeSignature = "TREE"
+)
+
+// CacheTree caches the tree object written for one index directory and,
+// recursively, for its subdirectories. A node is valid while EntryCount is
+// not negative; index mutations invalidate every directory above the changed path.
+type CacheTree struct {
+	// EntryCount is the number of index entries below the directory, or -1 when invalid.
+	EntryCount int
+	// Hash is the tree object of the directory; meaningful only while valid.
+	Hash Hash
+	// Children maps subdirectory names to their caches.
+	Children map[string]*CacheTree
+}
+
+// NewCacheTree returns an invalid cache node without children.
+func NewCacheTree() *CacheTree {
+	return &CacheTree{
+		EntryCount: -1,
+		Children:   make(map[string]*CacheTree),
+	}
+}
+
+// Valid reports whether Hash describes the directory's current entries.
+func (c *CacheTree) Valid() bool {
+	return c != nil && c.EntryCount >= 0
+}
+
+// Child returns the cache of the subdirectory name, or nil when absent.
+func (c *CacheTree) Child(name string) *CacheTree {
+	if c == nil {
+		return nil
+	}
+	return c.Children[name]
+}
+
+// Invalidate marks every directory on the way to the file path as invalid.
+func (c *CacheTree) Invalidate(path NormalizedPath) {
+	node := c
+	segments := strings.Split(path.String(), "/")
+	for _, name := range segments[:len(segments)-1] {
+		if node == nil {
+			return
+		}
+		node.EntryCount = -1
+		node = node.Children[name]
+	}
+	if node != nil {
+		node.EntryCount = -1
+	}
+}
+
+// Clone returns a deep copy of the cache.
+func (c *CacheTree) Clone() *CacheTree {
+	if c == nil {
+		return nil
+	}
+	cloned := &CacheTree{
+		EntryCount: c.EntryCount,
+		Hash:       c.Hash,
+		Children:   make(map[string]*CacheTree, len(c.Children)),
+	}
+	for name, child := range c.Children {
+		cloned.Children[name] = child.Clone()
+	}
+	return cloned
+}
+
+// Serialize encodes the cache in pre-order. Each node is written as
+//
+//	<name> NUL <entry count> SP <subtree count> LF [<hash> when valid]
+//
+// with the root named "" and children sorted by name.
+func (c *CacheTree) Serialize() []byte {
+	var buffer bytes.Buffer
+	c.serialize(&buffer, "")
+	return buffer.Bytes()
+}
+
+// serialize appends the node named name and its children to buffer.
+func (c *CacheTree) serialize(buffer *bytes.Buffer, name string) {
+	names := make([]string, 0, len(c.Children))
+	for childName := range c.Children {
+		names = append(names, childName)
+	}
+	slices.Sort(names)
+
+	fmt.Fprintf(buffer, "%s\x00%d %d\n", name, c.EntryCount, len(names))
+	if c.Valid() {
+		buffer.Write(c.Hash[:])
+	}
+	for _, childName := range names {
+		c.Children[childName].serialize(buffer, childName)
+	}
+}
+
+// DeserializeCacheTree parses data produced by Serialize.
+func DeserializeCacheTree(data []byte) (*CacheTree, error) {
+	root, _, rest, err := deserializeCacheTreeNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidCacheTree, len(rest))
+	}
+	return root, nil
+}
+
+// deserializeCacheTreeNode parses one node and its subtrees, returning its name and the unread data.
+func deserializeCacheTreeNode(data []byte) (*CacheTree, string, []byte, error) {
+	nameEnd := bytes.IndexByte(data, 0)
+	if nameEnd < 0 {
+		return nil, "", nil, fmt.Errorf("%w: unterminated name", ErrInvalidCacheTree)
+	}
+	name := string(data[:nameEnd])
+	data = data[nameEnd+1:]
+
+	lineEnd := bytes.IndexByte(data, '\n')
+	if lineEnd < 0 {
+		return nil, "", nil, fmt.Errorf("%w: unterminated counts for '%s'", ErrInvalidCacheTree, name)
+	}
+	counts := strings.Fields(string(data[:lineEnd]))
+	data = data[lineEnd+1:]
+	if len(counts) != 2 {
+		return nil, "", nil, fmt.Errorf("%w: malformed counts for '%s'", ErrInvalidCacheTree, name)
+	}
+	entryCount, err := strconv.Atoi(counts[0])
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: entry count for '%s': %v", ErrInvalidCacheTree, name, err)
+	}
+	subtreeCount, err := strconv.Atoi(counts[1])
+	if err != nil || subtreeCount < 0 {
+		return nil, "", nil, fmt.Errorf("%w: subtree count for '%s'", ErrInvalidCacheTree, name)
+	}
+
+	node := NewCacheTree()
+	node.EntryCount = entryCount
+	if node.Valid() {
+		if len(data) < SHA256ByteLength {
+			return nil, "", nil, fmt.Errorf("%w: truncated hash for '%s'", ErrInvalidCacheTree, name)
+		}
+		copy(node.Hash[:], data[:SHA256ByteLength])
+		data = data[SHA256ByteLength:]
+	}
+	for range subtreeCount {
+		child, childName, rest, err := deserializeCacheTreeNode(data)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		node.Children[childName] = child
+		data = rest
+	}
+	return node, name, data, nil
+}
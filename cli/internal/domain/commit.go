@@ -3,12 +3,14 @@ package domain
 import (
 	"bytes"
 	"errors"
+	"strings"
 )
 
 // ErrInvalidCommitFormat is returned when commit body parsing fails.
 var ErrInvalidCommitFormat = errors.New("invalid commit format")
 
-// ErrInvalidCommitField is returned when an unknown commit header is encountered.
+// ErrInvalidCommitField is returned when an extra commit header has an empty
+// key, a key with a space, or the key of a header Gel parses itself.
 var ErrInvalidCommitField = errors.New("invalid commit field")
 
 // CommitFieldTree is the commit header key that stores the root tree hash.
@@ -24,6 +26,22 @@ const CommitFieldAuthor string = "author"
 // CommitFieldCommitter is the commit header key for committer identity metadata.
 const CommitFieldCommitter string = "committer"
 
+// CommitFieldGPGSig is the commit header key for the signature of the commit.
+// Its value spans several lines, each continuation line starting with a space.
+const CommitFieldGPGSig string = "gpgsig"
+
+// CommitHeader is a commit header Gel stores without interpreting it, such as
+// encoding or mergetag in a commit imported from Git.
+type CommitHeader struct {
+	// Key is the header name.
+	Key string
+
+	// Value is the header value. Continuation lines are joined with "\n"
+	// without their leading space, so an empty continuation line keeps a
+	// blank line such as the one inside a mergetag.
+	Value string
+}
+
 // CommitFields contains the semantic fields represented by a commit object body.
 // It is the normalized in-memory form used by serialization and parsing code.
 type CommitFields struct {
@@ -40,8 +58,17 @@ type CommitFields struct {
 	// Committer describes who created this commit object and when.
 	Committer Identity
 
+	// ExtraHeaders are the other headers, in the order they appear after the
+	// committer. Gel writes none itself; they are kept so that commits imported
+	// from Git export byte for byte.
+	ExtraHeaders []CommitHeader
+
 	// Message is the full commit message body and may contain multiple lines.
 	Message string
+
+	// Signature is the armored signature over the commit body without the
+	// signature header, or empty for an unsigned commit.
+	Signature string
 }
 
 // Commit represents a parsed commit domain object.
@@ -57,6 +84,43 @@ func (commit *Commit) Body() []byte {
 	return append([]byte(nil), commit.body...)
 }
 
+// Trailers returns the trailers at the end of the commit message.
+func (commit *Commit) Trailers() []Trailer {
+	return ParseTrailers(commit.Message)
+}
+
+// SignedPayload returns the bytes covered by the commit signature: the raw
+// commit body with the gpgsig header and its continuation lines removed.
+func (commit *Commit) SignedPayload() []byte {
+	return StripCommitSignature(commit.body)
+}
+
+// StripCommitSignature removes the gpgsig header and its continuation lines
+// from the raw commit body.
+func StripCommitSignature(body []byte) []byte {
+	var payload bytes.Buffer
+	inSignature := false
+	for len(body) > 0 {
+		line := body
+		if newline := bytes.IndexByte(body, '\n'); newline != -1 {
+			line = body[:newline+1]
+		}
+		if len(line) == 1 && line[0] == '\n' {
+			payload.Write(body)
+			break
+		}
+		body = body[len(line):]
+		if inSignature && line[0] == ' ' {
+			continue
+		}
+		inSignature = bytes.HasPrefix(line, []byte(CommitFieldGPGSig+" "))
+		if !inSignature {
+			payload.Write(line)
+		}
+	}
+	return payload.Bytes()
+}
+
 // Type returns the domain object type for Commit.
 func (commit *Commit) Type() ObjectType {
 	return ObjectTypeCommit
@@ -131,6 +195,14 @@ func validateCommitFields(fields CommitFields) error {
 	); err != nil {
 		return err
 	}
+	if strings.Contains(fields.Signature, "\n\n") || strings.HasSuffix(fields.Signature, "\n") {
+		return ErrInvalidCommitFormat
+	}
+	for _, header := range fields.ExtraHeaders {
+		if header.Key == "" || strings.ContainsAny(header.Key, " \n") || isValidCommitField(header.Key) {
+			return ErrInvalidCommitField
+		}
+	}
 	return nil
 }
 
@@ -140,6 +212,9 @@ func validateCommitFields(fields CommitFields) error {
 //	parent <hash>\n (zero or more)
 //	author <name> <email> <timestamp> <timezone>\n
 //	committer <name> <email> <timestamp> <timezone>\n
+//	<key> <value line>\n (zero or more extra headers, continued like gpgsig)
+//	gpgsig <signature line>\n (when signed)
+//	 <signature line>\n (zero or more)
 //	\n
 //	<message>
 func serializeBody(fields CommitFields) []byte {
@@ -163,21 +238,38 @@ func serializeBody(fields CommitFields) []byte {
 	buffer.WriteString(" ")
 	buffer.Write(fields.Committer.Serialize())
 	buffer.WriteString("\n")
+	for _, header := range fields.ExtraHeaders {
+		writeMultilineHeader(&buffer, header.Key, header.Value)
+	}
+	if fields.Signature != "" {
+		writeMultilineHeader(&buffer, CommitFieldGPGSig, fields.Signature)
+	}
 	buffer.WriteString("\n")
 	buffer.WriteString(fields.Message)
 
 	return buffer.Bytes()
 }
 
+// writeMultilineHeader writes a header whose value may span several lines,
+// each continuation line starting with a space.
+func writeMultilineHeader(buffer *bytes.Buffer, key, value string) {
+	buffer.WriteString(key)
+	buffer.WriteString(" ")
+	buffer.WriteString(strings.ReplaceAll(value, "\n", "\n "))
+	buffer.WriteString("\n")
+}
+
 // deserializeFields parses a raw commit body into CommitFields.
 // It requires exactly one tree, one author, one committer header, and a message section.
-// Duplicate tree/author/committer headers are rejected.
+// Duplicate tree/author/committer/gpgsig headers are rejected. Other headers
+// are kept in ExtraHeaders.
 func deserializeFields(data []byte) (CommitFields, error) {
 	var fields CommitFields
 	i := 0
 	hasTree := false
 	hasAuthor := false
 	hasCommitter := false
+	hasSignature := false
 	hasMessage := false
 
 	for i < len(data) {
@@ -238,6 +330,26 @@ func deserializeFields(data []byte) (CommitFields, error) {
 			fields.Committer = committer
 			hasCommitter = true
 			i = nextI
+
+		case CommitFieldGPGSig:
+			if hasSignature {
+				return fields, ErrInvalidCommitFormat
+			}
+			signature, nextI, err := deserializeMultilineValue(data, i)
+			if err != nil {
+				return fields, err
+			}
+			fields.Signature = signature
+			hasSignature = true
+			i = nextI
+
+		default:
+			value, nextI, err := deserializeMultilineValue(data, i)
+			if err != nil {
+				return fields, err
+			}
+			fields.ExtraHeaders = append(fields.ExtraHeaders, CommitHeader{Key: fieldStr, Value: value})
+			i = nextI
 		}
 	}
 
@@ -248,7 +360,8 @@ func deserializeFields(data []byte) (CommitFields, error) {
 }
 
 // deserializeFieldStr parses a commit header key from data[start:] and returns
-// the field name and the next index after the separating space.
+// the field name and the next index after the separating space. The key must
+// be non-empty and end on the same line.
 func deserializeFieldStr(data []byte, start int) (string, int, error) {
 	i := start
 	for i < len(data) && data[i] != ' ' {
@@ -260,7 +373,7 @@ func deserializeFieldStr(data []byte, start int) (string, int, error) {
 	}
 
 	fieldStr := string(data[start:i])
-	if ok := isValidCommitField(fieldStr); !ok {
+	if fieldStr == "" || strings.Contains(fieldStr, "\n") {
 		return "", i, ErrInvalidCommitField
 	}
 	return fieldStr, i + 1, nil
@@ -284,6 +397,29 @@ func deserializeTreeOrParent(data []byte, start int) (Hash, int, error) {
 	return hash, i + 1, nil
 }
 
+// deserializeMultilineValue parses a header value from data[start:] that
+// continues over the following lines starting with a space, and returns it
+// with the continuation spaces removed.
+func deserializeMultilineValue(data []byte, start int) (string, int, error) {
+	var lines []string
+	i := start
+	for {
+		lineEnd := i
+		for lineEnd < len(data) && data[lineEnd] != '\n' {
+			lineEnd++
+		}
+		if lineEnd >= len(data) {
+			return "", i, ErrInvalidCommitFormat
+		}
+		lines = append(lines, string(data[i:lineEnd]))
+		i = lineEnd + 1
+		if i >= len(data) || data[i] != ' ' {
+			return strings.Join(lines, "\n"), i, nil
+		}
+		i++
+	}
+}
+
 // deserializeIdentity parses an identity line segment in
 // "<name> <email> <timestamp> <timezone>" form and returns a validated Identity.
 func deserializeIdentity(data []byte, start int) (Identity, int, error) {
@@ -348,16 +484,19 @@ func deserializeIdentity(data []byte, start int) (Identity, int, error) {
 func cloneCommitFields(fields CommitFields) CommitFields {
 	cloned := fields
 	cloned.ParentHashes = append([]Hash(nil), fields.ParentHashes...)
+	cloned.ExtraHeaders = append([]CommitHeader(nil), fields.ExtraHeaders...)
 	return cloned
 }
 
-// isValidCommitField reports whether field is a supported commit header key.
+// isValidCommitField reports whether field is a commit header key Gel parses
+// into its own field.
 func isValidCommitField(field string) bool {
 	switch field {
 	case CommitFieldTree,
 		CommitFieldParent,
 		CommitFieldAuthor,
-		CommitFieldCommitter:
+		CommitFieldCommitter,
+		CommitFieldGPGSig:
 		return true
 	}
 	return false
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCommitFields returns the fields of a two-parent commit with signature.
+func newTestCommitFields(t *testing.T, signature string) CommitFields {
+	t.Helper()
+	identity, err := NewIdentity("Alice", "alice@example.com", "1700000000", "+0000")
+	require.NoError(t, err)
+	treeHash, err := NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	firstParent, err := NewHashFromHex(strings.Repeat("cd", 32))
+	require.NoError(t, err)
+	secondParent, err := NewHashFromHex(strings.Repeat("ef", 32))
+	require.NoError(t, err)
+	return CommitFields{
+		TreeHash:     treeHash,
+		ParentHashes: []Hash{firstParent, secondParent},
+		Author:       identity,
+		Committer:    identity,
+		// The message mentions gpgsig at the start of a line, which is not a header.
+		Message:   "subject\n\ngpgsig is not a header here\n",
+		Signature: signature,
+	}
+}
+
+func TestStripCommitSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{name: "unsigned"},
+		{name: "single line", signature: "-----BEGIN SSH SIGNATURE-----"},
+		{
+			name:      "multi line",
+			signature: "-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\nAAAA\n-----END SSH SIGNATURE-----",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				unsigned, err := NewCommitFromFields(newTestCommitFields(t, ""))
+				require.NoError(t, err)
+				signed, err := NewCommitFromFields(newTestCommitFields(t, tt.signature))
+				require.NoError(t, err)
+
+				assert.Equal(t, string(unsigned.Body()), string(StripCommitSignature(signed.Body())))
+				assert.Equal(t, string(unsigned.Body()), string(signed.SignedPayload()))
+			},
+		)
+	}
+}
+
+func TestCommitSignatureRoundTrip(t *testing.T) {
+	signature := "-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----"
+	signed, err := NewCommitFromFields(newTestCommitFields(t, signature))
+	require.NoError(t, err)
+	assert.Contains(t, string(signed.Body()), "\n"+CommitFieldGPGSig+" -----BEGIN SSH SIGNATURE-----\n U1NIU0lH\n")
+
+	parsed, err := NewCommit(signed.Body())
+
+	require.NoError(t, err)
+	assert.Equal(t, signature, parsed.Signature)
+	assert.Equal(t, signed.Message, parsed.Message)
+	assert.Equal(t, signed.ParentHashes, parsed.ParentHashes)
+}
+
+func TestNewCommitRejectsDuplicateSignature(t *testing.T) {
+	signed, err := NewCommitFromFields(newTestCommitFields(t, "first"))
+	require.NoError(t, err)
+	body := strings.Replace(
+		string(signed.Body()), CommitFieldGPGSig+" first\n", CommitFieldGPGSig+" first\n"+CommitFieldGPGSig+" second\n", 1,
+	)
+
+	_, err = NewCommit([]byte(body))
+
+	require.ErrorIs(t, err, ErrInvalidCommitFormat)
+}
+
+func TestCommitExtraHeadersRoundTrip(t *testing.T) {
+	fields := newTestCommitFields(t, "signature")
+	fields.ExtraHeaders = []CommitHeader{
+		{Key: "encoding", Value: "ISO-8859-1"},
+		{Key: "mergetag", Value: "object 1234\ntype commit\n\ntag message"},
+	}
+	commit, err := NewCommitFromFields(fields)
+	require.NoError(t, err)
+	assert.Contains(
+		t, string(commit.Body()),
+		"encoding ISO-8859-1\nmergetag object 1234\n type commit\n \n tag message\n"+CommitFieldGPGSig+" signature\n",
+	)
+
+	parsed, err := NewCommit(commit.Body())
+
+	require.NoError(t, err)
+	assert.Equal(t, fields.ExtraHeaders, parsed.ExtraHeaders)
+	assert.Equal(t, fields.Signature, parsed.Signature)
+	assert.Equal(t, fields.Message, parsed.Message)
+}
+
+func TestNewCommitFromFieldsRejectsInvalidExtraHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty key", key: ""},
+		{name: "key with space", key: "merge tag"},
+		{name: "key with newline", key: "merge\ntag"},
+		{name: "parsed key", key: CommitFieldParent},
+		{name: "signature key", key: CommitFieldGPGSig},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				fields := newTestCommitFields(t, "")
+				fields.ExtraHeaders = []CommitHeader{{Key: tt.key, Value: "value"}}
+
+				_, err := NewCommitFromFields(fields)
+
+				require.ErrorIs(t, err, ErrInvalidCommitField)
+			},
+		)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -21,6 +22,9 @@ type ConfigEntry struct {
 }
 
 // Config stores repository configuration grouped by TOML section.
+// Section names and the last dot-separated part of keys are case-insensitive
+// and stored lowercased, so "clean.requireForce" and "clean.requireforce" name
+// the same key. Subsection parts such as remote and branch names keep their case.
 type Config struct {
 	// sections maps section names to their key/value pairs.
 	sections map[string]ConfigSection
@@ -56,6 +60,7 @@ func (c *Config) Get(section, key string) (string, bool) {
 		return "", false
 	}
 
+	section, key = NormalizeConfigKey(section, key)
 	sec, ok := c.sections[section]
 	if !ok {
 		return "", false
@@ -114,12 +119,24 @@ func (c *Config) Sections() map[string]ConfigSection {
 }
 
 func (c *Config) setUnchecked(section, key, value string) {
+	section, key = NormalizeConfigKey(section, key)
 	if _, ok := c.sections[section]; !ok {
 		c.sections[section] = make(ConfigSection)
 	}
 	c.sections[section][key] = value
 }
 
+// NormalizeConfigKey returns section and key in the case they are stored in:
+// the section and the variable name after the last dot of key are lowercased,
+// while a subsection before it, such as "origin" in "origin.url", is kept.
+func NormalizeConfigKey(section, key string) (string, string) {
+	section = strings.ToLower(section)
+	if dot := strings.LastIndex(key, "."); dot >= 0 {
+		return section, key[:dot+1] + strings.ToLower(key[dot+1:])
+	}
+	return section, strings.ToLower(key)
+}
+
 func validateConfigName(kind, value string) error {
 	if value == "" {
 		return fmt.Errorf("%w: %s is empty", ErrInvalidConfigKey, kind)
@@ -26,11 +26,96 @@ const (
 	// HeadsDirName is the refs/heads directory name.
 	HeadsDirName string = "heads"
 
+	// TagsDirName is the refs/tags directory name.
+	TagsDirName string = "tags"
+
+	// RemotesDirName is the refs/remotes directory name for remote-tracking refs.
+	RemotesDirName string = "remotes"
+
 	// IndexFileName is the index file name.
 	IndexFileName string = "index"
 
 	// ConfigFileName is the repository config file name.
 	ConfigFileName string = "config.toml"
+
+	// ShallowFileName is the file listing shallow clone boundary commits.
+	ShallowFileName string = "shallow"
+
+	// LFSDirName is the large file storage directory name.
+	LFSDirName string = "lfs"
+
+	// InfoDirName is the repository-local auxiliary files directory name.
+	InfoDirName string = "info"
+
+	// ExcludeFileName is the repository-local ignore file under info/.
+	ExcludeFileName string = "exclude"
+
+	// GelIgnoreFileName is the per-directory ignore file name.
+	GelIgnoreFileName string = ".gelignore"
+
+	// AttributesFileName is the repository-local attributes file under info/.
+	AttributesFileName string = "attributes"
+
+	// GelAttributesFileName is the per-directory attributes file name.
+	GelAttributesFileName string = ".gelattributes"
+
+	// GelModulesFileName is the file at the repository root mapping submodule paths to URLs.
+	GelModulesFileName string = ".gelmodules"
+
+	// SparseCheckoutFileName is the cone definition file under info/.
+	SparseCheckoutFileName string = "sparse-checkout"
+
+	// WorktreesDirName is the directory holding the metadata of linked worktrees.
+	WorktreesDirName string = "worktrees"
+
+	// CommonDirFileName names, inside a linked worktree's metadata directory,
+	// the file holding the path of the shared metadata directory.
+	CommonDirFileName string = "commondir"
+
+	// GelDirFileName names, inside a linked worktree's metadata directory, the
+	// file holding the path of the worktree's .gel file.
+	GelDirFileName string = "geldir"
+
+	// GelDirFilePrefix starts the content of a linked worktree's .gel file,
+	// followed by the path of its metadata directory.
+	GelDirFilePrefix string = "geldir: "
+
+	// HooksDirName is the directory under the shared metadata directory holding hook executables.
+	HooksDirName string = "hooks"
+
+	// CommitEditMsgFileName is the per-worktree file holding the commit message being edited.
+	CommitEditMsgFileName string = "COMMIT_EDITMSG"
+)
+
+const (
+	// GelDirEnvVar names the environment variable that overrides repository
+	// discovery with an explicit metadata directory.
+	GelDirEnvVar string = "GEL_DIR"
+
+	// GelIndexFileEnvVar names the environment variable that tells hooks the
+	// path of the index file.
+	GelIndexFileEnvVar string = "GEL_INDEX_FILE"
+
+	// GelEditorEnvVar names the environment variable that overrides the editor command.
+	GelEditorEnvVar string = "GEL_EDITOR"
+
+	// GelAuthorNameEnvVar names the environment variable that overrides the author name of new commits.
+	GelAuthorNameEnvVar string = "GEL_AUTHOR_NAME"
+
+	// GelAuthorEmailEnvVar names the environment variable that overrides the author email of new commits.
+	GelAuthorEmailEnvVar string = "GEL_AUTHOR_EMAIL"
+
+	// GelAuthorDateEnvVar names the environment variable that overrides the author date of new commits.
+	GelAuthorDateEnvVar string = "GEL_AUTHOR_DATE"
+
+	// GelCommitterNameEnvVar names the environment variable that overrides the committer name of new commits.
+	GelCommitterNameEnvVar string = "GEL_COMMITTER_NAME"
+
+	// GelCommitterEmailEnvVar names the environment variable that overrides the committer email of new commits.
+	GelCommitterEmailEnvVar string = "GEL_COMMITTER_EMAIL"
+
+	// GelCommitterDateEnvVar names the environment variable that overrides the committer date of new commits.
+	GelCommitterDateEnvVar string = "GEL_COMMITTER_DATE"
 )
 
 const (
@@ -34,10 +34,11 @@ type FileStat struct {
 }
 
 // NewFileStatFromPath retrieves file metadata for the given absolute path.
+// Symbolic links are not followed: the stat describes the link itself.
 func NewFileStatFromPath(path AbsolutePath) (*FileStat, error) {
 	var stat syscall.Stat_t
-	if err := syscall.Stat(path.String(), &stat); err != nil {
-		return nil, fmt.Errorf("file stat: stat %q: %w", path, err)
+	if err := syscall.Lstat(path.String(), &stat); err != nil {
+		return nil, fmt.Errorf("file stat: lstat %q: %w", path, err)
 	}
 	return newFileStatFromSyscall(&stat), nil
 }
@@ -51,7 +52,7 @@ func newFileStatFromSyscall(stat *syscall.Stat_t) *FileStat {
 		GroupID:      stat.Gid,
 		Mode:         uint32(stat.Mode),
 		Size:         uint64(stat.Size),
-		ChangedTime:  time.Unix(stat.Ctimespec.Sec, stat.Ctimespec.Nsec),
-		ModifiedTime: time.Unix(stat.Mtimespec.Sec, stat.Mtimespec.Nsec),
+		ChangedTime:  statChangedTime(stat),
+		ModifiedTime: statModifiedTime(stat),
 	}
 }
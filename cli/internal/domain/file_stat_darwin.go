@@ -0,0 +1,18 @@
+//go:build darwin
+
+package domain
+
+import (
+	"syscall"
+	"time"
+)
+
+// statChangedTime returns the ctime of stat.
+func statChangedTime(stat *syscall.Stat_t) time.Time {
+	return time.Unix(stat.Ctimespec.Sec, stat.Ctimespec.Nsec)
+}
+
+// statModifiedTime returns the mtime of stat.
+func statModifiedTime(stat *syscall.Stat_t) time.Time {
+	return time.Unix(stat.Mtimespec.Sec, stat.Mtimespec.Nsec)
+}
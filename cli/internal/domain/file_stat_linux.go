@@ -0,0 +1,18 @@
+//go:build linux
+
+package domain
+
+import (
+	"syscall"
+	"time"
+)
+
+// statChangedTime returns the ctime of stat.
+func statChangedTime(stat *syscall.Stat_t) time.Time {
+	return time.Unix(stat.Ctim.Sec, stat.Ctim.Nsec)
+}
+
+// statModifiedTime returns the mtime of stat.
+func statModifiedTime(stat *syscall.Stat_t) time.Time {
+	return time.Unix(stat.Mtim.Sec, stat.Mtim.Nsec)
+}
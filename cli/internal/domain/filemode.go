@@ -24,18 +24,27 @@ const (
 	// FileModeExecutable is an executable file (100755).
 	FileModeExecutable FileMode = 0o100755
 
+	// FileModeSymlink is a symbolic link whose blob holds the link target (120000).
+	FileModeSymlink FileMode = 0o120000
+
 	// FileModeDirectory is a directory (040000).
 	FileModeDirectory FileMode = 0o040000
+
+	// FileModeGitlink is a submodule whose hash names a commit in another repository (160000).
+	FileModeGitlink FileMode = 0o160000
 )
 
 const (
 	treeModeRegular             = "100644"
 	treeModeExecutable          = "100755"
+	treeModeSymlink             = "120000"
 	treeModeDirectory           = "40000"
-	expectedStoredModes         = "100644, 100755, or 040000"
-	expectedTreeModes           = `"100644", "100755", or "40000"`
+	treeModeGitlink             = "160000"
+	expectedStoredModes         = "100644, 100755, 120000, 040000, or 160000"
+	expectedTreeModes           = `"100644", "100755", "120000", "40000", or "160000"`
 	osModeTypeMask       uint32 = 0o170000
 	osModeTypeRegular    uint32 = 0o100000
+	osModeTypeSymlink    uint32 = 0o120000
 	osModeTypeDirectory  uint32 = 0o040000
 	osModeExecutableMask uint32 = 0o111
 )
@@ -61,8 +70,12 @@ func NewFileModeFromTreeMode(mode string) (FileMode, error) {
 		return FileModeRegular, nil
 	case treeModeExecutable:
 		return FileModeExecutable, nil
+	case treeModeSymlink:
+		return FileModeSymlink, nil
 	case treeModeDirectory:
 		return FileModeDirectory, nil
+	case treeModeGitlink:
+		return FileModeGitlink, nil
 	default:
 		return 0, fmt.Errorf(
 			"%w: %q (expected %s)",
@@ -78,6 +91,8 @@ func NewFileModeFromOSMode(mode uint32) (FileMode, error) {
 	switch mode & osModeTypeMask {
 	case osModeTypeDirectory:
 		return FileModeDirectory, nil
+	case osModeTypeSymlink:
+		return FileModeSymlink, nil
 	case osModeTypeRegular:
 		if mode&osModeExecutableMask != 0 {
 			return FileModeExecutable, nil
@@ -101,8 +116,12 @@ func (f FileMode) String() string {
 		return treeModeRegular
 	case FileModeExecutable:
 		return treeModeExecutable
+	case FileModeSymlink:
+		return treeModeSymlink
 	case FileModeDirectory:
 		return treeModeDirectory
+	case FileModeGitlink:
+		return treeModeGitlink
 	default:
 		return ""
 	}
@@ -116,7 +135,7 @@ func (f FileMode) Uint32() uint32 {
 // IsValid reports whether mode is one of Gel's supported file modes.
 func (f FileMode) IsValid() bool {
 	switch f {
-	case FileModeRegular, FileModeExecutable, FileModeDirectory:
+	case FileModeRegular, FileModeExecutable, FileModeSymlink, FileModeDirectory, FileModeGitlink:
 		return true
 	default:
 		return false
@@ -128,13 +147,32 @@ func (f FileMode) IsDirectory() bool {
 	return f == FileModeDirectory
 }
 
+// IsSymlink reports whether the mode represents a symbolic link.
+func (f FileMode) IsSymlink() bool {
+	return f == FileModeSymlink
+}
+
+// IsGitlink reports whether the mode represents a submodule commit.
+func (f FileMode) IsGitlink() bool {
+	return f == FileModeGitlink
+}
+
+// SameType reports whether two modes describe the same kind of object.
+// Regular and executable files are the same type; a symlink, a directory,
+// a submodule, and a file are all distinct types.
+func (f FileMode) SameType(o FileMode) bool {
+	return f.IsSymlink() == o.IsSymlink() && f.IsDirectory() == o.IsDirectory() && f.IsGitlink() == o.IsGitlink()
+}
+
 // ObjectType returns the domain object type corresponding to this mode.
 func (f FileMode) ObjectType() (ObjectType, error) {
 	switch f {
-	case FileModeRegular, FileModeExecutable:
+	case FileModeRegular, FileModeExecutable, FileModeSymlink:
 		return ObjectTypeBlob, nil
 	case FileModeDirectory:
 		return ObjectTypeTree, nil
+	case FileModeGitlink:
+		return ObjectTypeCommit, nil
 	default:
 		return "", fmt.Errorf(
 			"%w: %s (expected %s)",
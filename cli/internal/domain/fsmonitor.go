@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidFSMonitor is returned when the fsmonitor index extension cannot be parsed.
+	ErrInvalidFSMonitor = errors.New("invalid fsmonitor extension")
+)
+
+const (
+	// FSMonitorSignature identifies the fsmonitor extension in the index file.
+	FSMonitorSignature = "FSMN"
+)
+
+// serializeFSMonitor encodes token followed by a bitmap with one bit per
+// entry, in entry order, set when the entry is FSMonitorValid:
+//
+//	<token> NUL <bitmap>
+func serializeFSMonitor(token string, entries []*IndexEntry) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(token)
+	buffer.WriteByte(0)
+
+	bitmap := make([]byte, (len(entries)+7)/8)
+	for i, entry := range entries {
+		if entry.FSMonitorValid {
+			bitmap[i/8] |= 1 << (i % 8)
+		}
+	}
+	buffer.Write(bitmap)
+	return buffer.Bytes()
+}
+
+// deserializeFSMonitor parses data produced by serializeFSMonitor, marks the
+// entries whose bit is set, and returns the token.
+func deserializeFSMonitor(data []byte, entries []*IndexEntry) (string, error) {
+	tokenEnd := bytes.IndexByte(data, 0)
+	if tokenEnd < 0 {
+		return "", fmt.Errorf("%w: unterminated token", ErrInvalidFSMonitor)
+	}
+	token := string(data[:tokenEnd])
+	bitmap := data[tokenEnd+1:]
+	if len(bitmap) != (len(entries)+7)/8 {
+		return "", fmt.Errorf(
+			"%w: bitmap covers %d entries, index has %d", ErrInvalidFSMonitor, len(bitmap)*8, len(entries),
+		)
+	}
+	for i, entry := range entries {
+		entry.FSMonitorValid = bitmap[i/8]&(1<<(i%8)) != 0
+	}
+	return token, nil
+}
+
+// ApplyFSMonitorChanges updates the fsmonitor state for a monitor answer.
+// Entries at or below any of paths lose FSMonitorValid, as do all entries
+// when all is set. Cached directory listings become FSMonitorValid unless
+// all is set or a reported path is the directory, lies directly in it, or
+// covers it.
+func (idx *Index) ApplyFSMonitorChanges(paths []string, all bool) {
+	reported := make(map[string]bool, len(paths))
+	parents := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		reported[path] = true
+		parents[parentDir(path)] = true
+	}
+
+	for _, entry := range idx.Entries {
+		if all || coveredBy(entry.Path.String(), reported) {
+			entry.FSMonitorValid = false
+		}
+	}
+	if idx.UntrackedCache == nil {
+		return
+	}
+	for dir, listing := range idx.UntrackedCache.Dirs {
+		listing.FSMonitorValid = !all && !parents[dir] && !coveredBy(dir, reported)
+	}
+}
+
+// coveredBy reports whether path or one of its ancestor directories is in reported.
+func coveredBy(path string, reported map[string]bool) bool {
+	if len(reported) == 0 {
+		return false
+	}
+	for {
+		if reported[path] {
+			return true
+		}
+		if path == "" {
+			return false
+		}
+		path = parentDir(path)
+	}
+}
+
+// parentDir returns the directory containing the slash-separated path, "" at the root.
+func parentDir(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
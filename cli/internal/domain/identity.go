@@ -66,6 +66,23 @@ func (i Identity) Serialize() []byte {
 	))
 }
 
+// ParseIdentityPerson splits a "Name <email>" string into its name and email.
+func ParseIdentityPerson(value string) (string, string, error) {
+	open := strings.LastIndexByte(value, '<')
+	if open == -1 || !strings.HasSuffix(value, ">") {
+		return "", "", fmt.Errorf("%w: %q is not in 'Name <email>' format", ErrInvalidIdentity, value)
+	}
+	name := strings.TrimSpace(value[:open])
+	email := strings.TrimSpace(value[open+1 : len(value)-1])
+	if err := validateIdentityName(name); err != nil {
+		return "", "", err
+	}
+	if err := validateIdentityEmail(email); err != nil {
+		return "", "", err
+	}
+	return name, email, nil
+}
+
 func validateIdentityName(name string) error {
 	if name == "" {
 		return fmt.Errorf("%w: name is empty", ErrInvalidIdentity)
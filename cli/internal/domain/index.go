@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -18,8 +19,16 @@ const (
 	// IndexSignature is the 4-byte signature stored at the start of index files.
 	IndexSignature = "DIRC"
 
-	// IndexVersion is the supported on-disk index format version.
+	// IndexVersion is the default on-disk index format version.
 	IndexVersion = 2
+
+	// IndexVersionExtended is the index format version written when some entry carries extended flags.
+	IndexVersionExtended = 3
+
+	// IndexVersionPathCompressed is the index format version that stores each
+	// entry path as a prefix shared with the previous entry plus a suffix, and
+	// drops the entry padding.
+	IndexVersionPathCompressed = 4
 )
 
 // Index-specific errors.
@@ -31,6 +40,12 @@ var (
 	ErrChecksumMismatch      = errors.New("index checksum verification failed: file may be corrupted")
 	ErrEntryDataTooShort     = errors.New("index entry is incomplete: minimum 74 bytes required")
 	ErrPathNotNullTerminated = errors.New("index entry path is malformed: missing null terminator")
+	ErrTruncatedExtension    = errors.New("index extension truncated: not enough data to read its body")
+	ErrUnsupportedExtension  = errors.New("index extension is required but not supported")
+	ErrUnsupportedVersion    = errors.New("unsupported index version: expected 2, 3 or 4")
+	ErrUnexpectedExtended    = errors.New("index entry has extended flags, which version 2 does not support")
+	ErrUnknownExtendedFlags  = errors.New("index entry has unknown extended flags set")
+	ErrInvalidPathPrefix     = errors.New("index entry path is malformed: prefix exceeds the previous path")
 )
 
 // Index file header and entry size constants.
@@ -47,6 +62,14 @@ const (
 	PaddingAlignment  = 8
 )
 
+// Index extension header constants. Each extension after the entries is a
+// 4-byte signature, a 4-byte body size, and the body.
+const (
+	IndexExtensionSignatureSize = 4
+	IndexExtensionSizeFieldSize = 4
+	IndexExtensionHeaderSize    = IndexExtensionSignatureSize + IndexExtensionSizeFieldSize
+)
+
 // Index entry field size constants (in bytes).
 // Note: Device, Inode, and Size use uint64 (8 bytes) to support modern filesystems.
 // Time fields use int64 (8 bytes) for seconds plus uint32 (4 bytes) for nanoseconds.
@@ -60,6 +83,7 @@ const (
 	IndexEntrySizeFieldSize         = 8  // uint64
 	IndexEntryHashSize              = SHA256ByteLength
 	IndexEntryFlagsSize             = 2
+	IndexEntryExtendedFlagsSize     = 2
 	IndexEntryPathNullTerminateSize = 1
 	IndexEntryHashOffset            = 2*IndexEntryTimeSize + IndexEntryDeviceSize + IndexEntryInodeSize + IndexEntryModeSize + IndexEntryUserIDSize + IndexEntryGroupIDSize + IndexEntrySizeFieldSize
 	IndexEntryFlagsOffset           = IndexEntryHashOffset + IndexEntryHashSize
@@ -68,9 +92,18 @@ const (
 
 // Index flags constants.
 const (
-	MaxPathLength = 0xFFF // maximum path length encoded in flags (12 bits)
-	StageMask     = 0x3   // mask for stage bits in flags
-	StageShift    = 12    // bit offset for stage in flags
+	MaxPathLength   = 0xFFF  // maximum path length encoded in flags (12 bits)
+	StageMask       = 0x3    // mask for stage bits in flags
+	StageShift      = 12     // bit offset for stage in flags
+	ExtendedFlag    = 0x4000 // flags bit announcing a 16-bit extended flags field (version 3+)
+	AssumeValidFlag = 0x8000 // entry is assumed unchanged: its file is not checked for changes
+)
+
+// Index extended flags constants.
+const (
+	IntentToAddFlag   = 0x2000                             // entry records a path to be added, without content yet
+	SkipWorktreeFlag  = 0x4000                             // entry is outside the sparse checkout and absent from the working tree
+	ExtendedFlagsMask = IntentToAddFlag | SkipWorktreeFlag // extended flags this implementation understands
 )
 
 // IndexHeader stores the on-disk index header.
@@ -110,12 +143,24 @@ type IndexEntry struct {
 	UserID uint32
 	// GroupID is the file owner's group ID.
 	GroupID uint32
-	// Flags contains path length (lower 12 bits) and stage (upper 4 bits).
+	// Flags contains path length (lower 12 bits), stage (bits 12-13) and AssumeValidFlag.
 	Flags uint16
+	// ExtendedFlags contains the version 3 extended flags, IntentToAddFlag and
+	// SkipWorktreeFlag. It is written only when non-zero.
+	ExtendedFlags uint16
 	// ChangedTime is the last metadata change time (ctime).
 	ChangedTime time.Time
 	// ModifiedTime is the last content modification time (mtime).
 	ModifiedTime time.Time
+	// FSMonitorValid records that the file matched the entry's stat data and
+	// the filesystem monitor has not reported the path since Index.FSMonitorToken.
+	// It is persisted in the fsmonitor extension, not in the entry itself.
+	FSMonitorValid bool
+	// RacilyClean records that the entry's mtime is not strictly older than the
+	// index file it was read from, so matching stat data cannot prove the file
+	// unchanged: it may have been modified again within the same timestamp
+	// granularity. It is derived on read and never persisted.
+	RacilyClean bool
 }
 
 // NewEmptyIndexEntry creates an index entry with zero values for the given path, hash, and mode.
@@ -170,12 +215,77 @@ func (e *IndexEntry) GetStage() uint16 {
 	return (e.Flags >> StageShift) & StageMask
 }
 
-// serialize converts the index entry to its binary representation.
-func (e *IndexEntry) serialize() ([]byte, error) {
-	pathLen := len(e.Path.String())
-	totalBytes := IndexEntryFixedSize + pathLen + IndexEntryPathNullTerminateSize
-	padding := (PaddingAlignment - (totalBytes % PaddingAlignment)) % PaddingAlignment
-	totalBytes += padding
+// SkipWorktree reports whether the entry is excluded from the working tree by sparse checkout.
+func (e *IndexEntry) SkipWorktree() bool {
+	return e.ExtendedFlags&SkipWorktreeFlag != 0
+}
+
+// SetSkipWorktree sets or clears the skip-worktree bit.
+func (e *IndexEntry) SetSkipWorktree(skip bool) {
+	if skip {
+		e.ExtendedFlags |= SkipWorktreeFlag
+	} else {
+		e.ExtendedFlags &^= SkipWorktreeFlag
+	}
+}
+
+// AssumeUnchanged reports whether the entry's file is assumed to match it
+// and is never checked for changes.
+func (e *IndexEntry) AssumeUnchanged() bool {
+	return e.Flags&AssumeValidFlag != 0
+}
+
+// SetAssumeUnchanged sets or clears the assume-unchanged bit.
+func (e *IndexEntry) SetAssumeUnchanged(assumeUnchanged bool) {
+	if assumeUnchanged {
+		e.Flags |= AssumeValidFlag
+	} else {
+		e.Flags &^= AssumeValidFlag
+	}
+}
+
+// IntentToAdd reports whether the entry only records that its path is to be added.
+func (e *IndexEntry) IntentToAdd() bool {
+	return e.ExtendedFlags&IntentToAddFlag != 0
+}
+
+// SetIntentToAdd sets or clears the intent-to-add bit.
+func (e *IndexEntry) SetIntentToAdd(intentToAdd bool) {
+	if intentToAdd {
+		e.ExtendedFlags |= IntentToAddFlag
+	} else {
+		e.ExtendedFlags &^= IntentToAddFlag
+	}
+}
+
+// hasExtendedFlags reports whether the entry needs the extended flags field,
+// which only versions IndexVersionExtended and later can store.
+func (e *IndexEntry) hasExtendedFlags() bool {
+	return e.ExtendedFlags != 0
+}
+
+// serialize converts the index entry to its binary representation in the
+// given index version. Version 4 stores the path relative to previousPath,
+// the path of the entry written before it, and adds no padding.
+func (e *IndexEntry) serialize(version uint32, previousPath string) ([]byte, error) {
+	path := e.Path.String()
+	var pathPrefix []byte
+	if version == IndexVersionPathCompressed {
+		common := commonPrefixLength(previousPath, path)
+		pathPrefix = appendIndexVarint(nil, uint64(len(previousPath)-common))
+		path = path[common:]
+	}
+	totalBytes := IndexEntryFixedSize + len(pathPrefix) + len(path) + IndexEntryPathNullTerminateSize
+	flags := e.Flags &^ ExtendedFlag
+	if e.hasExtendedFlags() {
+		flags |= ExtendedFlag
+		totalBytes += IndexEntryExtendedFlagsSize
+	}
+	padding := 0
+	if version != IndexVersionPathCompressed {
+		padding = (PaddingAlignment - (totalBytes % PaddingAlignment)) % PaddingAlignment
+		totalBytes += padding
+	}
 	var buffer bytes.Buffer
 	buffer.Grow(totalBytes)
 	if err := writeIndexEntryFields(&buffer, e); err != nil {
@@ -184,10 +294,18 @@ func (e *IndexEntry) serialize() ([]byte, error) {
 	if _, err := buffer.Write(e.Hash[:]); err != nil {
 		return nil, err
 	}
-	if err := binary.Write(&buffer, binary.BigEndian, e.Flags); err != nil {
+	if err := binary.Write(&buffer, binary.BigEndian, flags); err != nil {
 		return nil, err
 	}
-	if _, err := buffer.WriteString(e.Path.String()); err != nil {
+	if e.hasExtendedFlags() {
+		if err := binary.Write(&buffer, binary.BigEndian, e.ExtendedFlags); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := buffer.Write(pathPrefix); err != nil {
+		return nil, err
+	}
+	if _, err := buffer.WriteString(path); err != nil {
 		return nil, err
 	}
 	buffer.WriteByte(0)
@@ -199,12 +317,13 @@ func (e *IndexEntry) serialize() ([]byte, error) {
 }
 
 // MatchesStat compares the entry's stored metadata against a fresh stat call.
-// Returns true if the file appears unchanged (same device, inode, times, size, mode).
+// Returns true if the file appears unchanged (same device, inode, owner,
+// nanosecond-precise times, size, and file type).
 func (e *IndexEntry) MatchesStat(stat *FileStat) bool {
-	if e.ChangedTime != stat.ChangedTime {
+	if !e.ChangedTime.Equal(stat.ChangedTime) {
 		return false
 	}
-	if e.ModifiedTime != stat.ModifiedTime {
+	if !e.ModifiedTime.Equal(stat.ModifiedTime) {
 		return false
 	}
 	if e.Size != stat.Size {
@@ -213,16 +332,54 @@ func (e *IndexEntry) MatchesStat(stat *FileStat) bool {
 	if e.Device != stat.Device || e.Inode != stat.Inode {
 		return false
 	}
-	// TODO: compare filemode vs os stat mode
+	if e.UserID != stat.UserID || e.GroupID != stat.GroupID {
+		return false
+	}
+	// TODO: compare executable bits vs os stat mode
+	if statMode, err := NewFileModeFromOSMode(stat.Mode); err != nil || !FileMode(e.Mode).SameType(statMode) {
+		return false
+	}
 	return true
 }
 
+// RefreshStat replaces the entry's stat data with stat, leaving its content
+// hash and mode alone. It is used once the file content is known to match.
+func (e *IndexEntry) RefreshStat(stat *FileStat) {
+	e.Size = stat.Size
+	e.Device = stat.Device
+	e.Inode = stat.Inode
+	e.UserID = stat.UserID
+	e.GroupID = stat.GroupID
+	e.ChangedTime = stat.ChangedTime
+	e.ModifiedTime = stat.ModifiedTime
+	e.RacilyClean = false
+}
+
+// Smudge zeroes the recorded size so the stat data of a non-empty file never
+// matches again, forcing a content check until the entry is refreshed. It is
+// applied to racily clean entries whose file turns out to be modified.
+func (e *IndexEntry) Smudge() {
+	e.Size = 0
+}
+
 // Index stores the staging area entries and checksum.
 type Index struct {
 	// Header stores the parsed index header.
 	Header IndexHeader
 	// Entries stores the tracked paths in sorted order.
 	Entries []*IndexEntry
+	// CacheTree caches the tree objects of the entries' directories, or is nil
+	// when no tree has been written since the entries were last replaced.
+	CacheTree *CacheTree
+	// UntrackedCache caches working tree directory listings, or is nil when
+	// the untracked cache is disabled.
+	UntrackedCache *UntrackedCache
+	// FSMonitorToken is the filesystem monitor token the FSMonitorValid
+	// entry bits are relative to, or empty when no monitor is in use.
+	FSMonitorToken string
+	// Timestamp is the write time of the index file the index was read from,
+	// or zero for an index never written.
+	Timestamp time.Time
 	// Checksum stores the hex-encoded SHA-256 checksum of the serialized data.
 	Checksum string
 }
@@ -249,9 +406,13 @@ func (idx *Index) Clone() *Index {
 	}
 
 	cloned := &Index{
-		Header:   idx.Header,
-		Checksum: idx.Checksum,
-		Entries:  make([]*IndexEntry, len(idx.Entries)),
+		Header:         idx.Header,
+		Checksum:       idx.Checksum,
+		Entries:        make([]*IndexEntry, len(idx.Entries)),
+		CacheTree:      idx.CacheTree.Clone(),
+		UntrackedCache: idx.UntrackedCache.Clone(),
+		FSMonitorToken: idx.FSMonitorToken,
+		Timestamp:      idx.Timestamp,
 	}
 	for i, entry := range idx.Entries {
 		if entry == nil {
@@ -263,9 +424,18 @@ func (idx *Index) Clone() *Index {
 	return cloned
 }
 
+// MarkRacilyClean sets RacilyClean on every entry whose mtime is not
+// strictly older than Timestamp and clears it on the others.
+func (idx *Index) MarkRacilyClean() {
+	for _, entry := range idx.Entries {
+		entry.RacilyClean = !idx.Timestamp.IsZero() && !entry.ModifiedTime.Before(idx.Timestamp)
+	}
+}
+
 // AddEntry inserts an entry into the index, maintaining sorted order.
 // If an entry with the same path exists, it is replaced.
 func (idx *Index) AddEntry(entry *IndexEntry) {
+	idx.CacheTree.Invalidate(entry.Path)
 	i := sort.Search(
 		len(idx.Entries), func(i int) bool {
 			return idx.Entries[i].Path.String() >= entry.Path.String()
@@ -282,7 +452,9 @@ func (idx *Index) AddEntry(entry *IndexEntry) {
 	idx.Header.NumEntries = uint32(len(idx.Entries))
 }
 
+// ReplaceEntries swaps in a new entry list and drops the cache tree.
 func (idx *Index) ReplaceEntries(entries []*IndexEntry) {
+	idx.CacheTree = nil
 	idx.Entries = entries
 	idx.Header.NumEntries = uint32(len(entries))
 }
@@ -293,6 +465,7 @@ func (idx *Index) UpdateEntry(entry *IndexEntry) bool {
 	if prevEntry == nil {
 		return false
 	}
+	idx.CacheTree.Invalidate(entry.Path)
 	idx.Entries[i] = entry
 	return true
 }
@@ -307,6 +480,7 @@ func (idx *Index) SetEntry(entry *IndexEntry) {
 // RemoveEntry removes the entry with the given path if it exists.
 func (idx *Index) RemoveEntry(path NormalizedPath) {
 	if entry, i := idx.FindEntry(path); entry != nil {
+		idx.CacheTree.Invalidate(path)
 		idx.Entries = append(idx.Entries[:i], idx.Entries[i+1:]...)
 		idx.Header.NumEntries = uint32(len(idx.Entries))
 	}
@@ -354,7 +528,16 @@ func (idx *Index) HasEntry(path NormalizedPath) bool {
 }
 
 // Serialize serializes the entire index to bytes, including header, entries, and checksum.
+// It writes the version in the header, defaulting to IndexVersion when that is
+// unsupported. Versions 2 and 3 are interchangeable: IndexVersionExtended is
+// written exactly while some entry has extended flags.
 func (idx *Index) Serialize() ([]byte, error) {
+	if !IsSupportedIndexVersion(idx.Header.Version) {
+		idx.Header.Version = IndexVersion
+	}
+	if idx.Header.Version != IndexVersionPathCompressed {
+		idx.Header.Version = idx.flagsVersion()
+	}
 	serializedHeader := idx.serializeHeader()
 
 	slices.SortFunc(
@@ -369,6 +552,15 @@ func (idx *Index) Serialize() ([]byte, error) {
 	}
 
 	data := append(serializedHeader, serializedEntries...)
+	if idx.CacheTree != nil {
+		data = appendIndexExtension(data, CacheTreeSignature, idx.CacheTree.Serialize())
+	}
+	if idx.UntrackedCache != nil {
+		data = appendIndexExtension(data, UntrackedCacheSignature, idx.UntrackedCache.Serialize())
+	}
+	if idx.FSMonitorToken != "" {
+		data = appendIndexExtension(data, FSMonitorSignature, serializeFSMonitor(idx.FSMonitorToken, idx.Entries))
+	}
 	checksum := ComputeSHA256(data)
 	checksumBytes, err := hex.DecodeString(checksum)
 	if err != nil {
@@ -382,6 +574,18 @@ func (idx *Index) Serialize() ([]byte, error) {
 	return result, nil
 }
 
+// flagsVersion returns the version among IndexVersion and IndexVersionExtended
+// that the entries need: IndexVersionExtended exactly while some entry has
+// extended flags.
+func (idx *Index) flagsVersion() uint32 {
+	for _, entry := range idx.Entries {
+		if entry.hasExtendedFlags() {
+			return IndexVersionExtended
+		}
+	}
+	return IndexVersion
+}
+
 // serializeHeader converts the index header to bytes.
 func (idx *Index) serializeHeader() []byte {
 	serializedHeader := make([]byte, IndexHeaderSize)
@@ -399,17 +603,25 @@ func (idx *Index) serializeHeader() []byte {
 // serializeEntries converts all index entries to bytes.
 func (idx *Index) serializeEntries() ([]byte, error) {
 	var serializedEntries []byte
+	previousPath := ""
 	for _, entry := range idx.Entries {
-		serializedEntry, err := entry.serialize()
+		serializedEntry, err := entry.serialize(idx.Header.Version, previousPath)
 		if err != nil {
 			return nil, err
 		}
 		serializedEntries = append(serializedEntries, serializedEntry...)
+		previousPath = entry.Path.String()
 	}
 	return serializedEntries, nil
 }
 
-// DeserializeIndex parses index data from bytes.
+// IsSupportedIndexVersion reports whether version is an index format version
+// that can be read and written.
+func IsSupportedIndexVersion(version uint32) bool {
+	return version >= IndexVersion && version <= IndexVersionPathCompressed
+}
+
+// DeserializeIndex parses index data from bytes in any supported version.
 // It validates the header signature, version, entry count, and checksum.
 func DeserializeIndex(data []byte) (*Index, error) {
 	if len(data) == 0 {
@@ -431,18 +643,53 @@ func DeserializeIndex(data []byte) (*Index, error) {
 	numEntries := header.NumEntries
 	offset += IndexHeaderSize
 
+	previousPath := ""
 	for i := uint32(0); i < numEntries; i++ {
 		if offset >= len(data)-IndexChecksumSize {
 			return nil, ErrTruncatedEntryData
 		}
 
-		entry, entrySize, err := deserializeIndexEntry(data[offset:])
+		entry, entrySize, err := deserializeIndexEntry(
+			data[offset:len(data)-IndexChecksumSize], header.Version, previousPath,
+		)
 		if err != nil {
 			return nil, err
 		}
 
 		index.AddEntry(entry)
 		offset += entrySize
+		previousPath = entry.Path.String()
+	}
+
+	for len(data)-offset > IndexChecksumSize {
+		signature, body, size, err := readIndexExtension(data[offset : len(data)-IndexChecksumSize])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case signature == CacheTreeSignature:
+			cacheTree, err := DeserializeCacheTree(body)
+			if err != nil {
+				return nil, err
+			}
+			index.CacheTree = cacheTree
+		case signature == UntrackedCacheSignature:
+			untrackedCache, err := DeserializeUntrackedCache(body)
+			if err != nil {
+				return nil, err
+			}
+			index.UntrackedCache = untrackedCache
+		case signature == FSMonitorSignature:
+			token, err := deserializeFSMonitor(body, index.Entries)
+			if err != nil {
+				return nil, err
+			}
+			index.FSMonitorToken = token
+		case signature[0] < 'A' || signature[0] > 'Z':
+			// Extensions whose signature starts with an uppercase letter are optional.
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedExtension, signature)
+		}
+		offset += size
 	}
 
 	if len(data)-offset != IndexChecksumSize {
@@ -464,6 +711,28 @@ func DeserializeIndex(data []byte) (*Index, error) {
 	return &index, nil
 }
 
+// appendIndexExtension appends an extension with signature and body to data.
+func appendIndexExtension(data []byte, signature string, body []byte) []byte {
+	data = append(data, signature...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(body)))
+	return append(data, body...)
+}
+
+// readIndexExtension parses the extension at the start of data and returns its
+// signature, its body, and the number of bytes it occupies.
+func readIndexExtension(data []byte) (string, []byte, int, error) {
+	if len(data) < IndexExtensionHeaderSize {
+		return "", nil, 0, ErrTruncatedExtension
+	}
+	signature := string(data[:IndexExtensionSignatureSize])
+	size := int(binary.BigEndian.Uint32(data[IndexExtensionSignatureSize:IndexExtensionHeaderSize]))
+	if len(data)-IndexExtensionHeaderSize < size {
+		return "", nil, 0, ErrTruncatedExtension
+	}
+	body := data[IndexExtensionHeaderSize : IndexExtensionHeaderSize+size]
+	return signature, body, IndexExtensionHeaderSize + size, nil
+}
+
 // ComputeIndexFlags encodes the path length and stage into a 16-bit flags field.
 func ComputeIndexFlags(path string, stage uint16) uint16 {
 	pathLength := min(len(path), MaxPathLength)
@@ -481,14 +750,18 @@ func deserializeHeader(data []byte) (IndexHeader, error) {
 	}
 
 	header.Version = binary.BigEndian.Uint32(data[IndexHeaderSignatureSize : IndexHeaderSignatureSize+IndexHeaderVersionSize])
+	if !IsSupportedIndexVersion(header.Version) {
+		return header, fmt.Errorf("%w: got %d", ErrUnsupportedVersion, header.Version)
+	}
 	header.NumEntries = binary.BigEndian.Uint32(data[IndexHeaderSignatureSize+IndexHeaderVersionSize:])
 
 	return header, nil
 }
 
-// deserializeIndexEntry parses a single index entry from bytes.
+// deserializeIndexEntry parses a single index entry from bytes in the given
+// index version; previousPath is the path of the entry read before it.
 // Returns the entry and the total number of bytes consumed (including padding).
-func deserializeIndexEntry(data []byte) (*IndexEntry, int, error) {
+func deserializeIndexEntry(data []byte, version uint32, previousPath string) (*IndexEntry, int, error) {
 	if len(data) < IndexEntryFixedSize {
 		return nil, 0, ErrEntryDataTooShort
 	}
@@ -516,24 +789,103 @@ func deserializeIndexEntry(data []byte) (*IndexEntry, int, error) {
 	}
 
 	offset := IndexEntryFixedSize
+	if entry.Flags&ExtendedFlag != 0 {
+		if version == IndexVersion {
+			return nil, 0, ErrUnexpectedExtended
+		}
+		if err := binary.Read(reader, binary.BigEndian, &entry.ExtendedFlags); err != nil {
+			return nil, 0, ErrEntryDataTooShort
+		}
+		if entry.ExtendedFlags&^ExtendedFlagsMask != 0 {
+			return nil, 0, fmt.Errorf("%w: %#04x", ErrUnknownExtendedFlags, entry.ExtendedFlags)
+		}
+		entry.Flags &^= ExtendedFlag
+		offset += IndexEntryExtendedFlagsSize
+	}
+
+	pathPrefix := ""
+	if version == IndexVersionPathCompressed {
+		strip, size, err := readIndexVarint(data[offset:])
+		if err != nil {
+			return nil, 0, err
+		}
+		if strip > uint64(len(previousPath)) {
+			return nil, 0, ErrInvalidPathPrefix
+		}
+		pathPrefix = previousPath[:len(previousPath)-int(strip)]
+		offset += size
+	}
 	pathEnd := bytes.IndexByte(data[offset:], 0)
 	if pathEnd == -1 {
 		return nil, 0, ErrPathNotNullTerminated
 	}
 
-	normPath, err := ParseNormalizedPath(string(data[offset : offset+pathEnd]))
+	normPath, err := ParseNormalizedPath(pathPrefix + string(data[offset:offset+pathEnd]))
 	if err != nil {
 		return nil, 0, fmt.Errorf("invalid path in index entry: %w", err)
 	}
 
 	entry.Path = normPath
 	offset += pathEnd + IndexEntryPathNullTerminateSize
+	if version == IndexVersionPathCompressed {
+		return &entry, offset, nil
+	}
 	padding := (PaddingAlignment - (offset % PaddingAlignment)) % PaddingAlignment
 	totalSize := offset + padding
 
 	return &entry, totalSize, nil
 }
 
+// appendIndexVarint appends value to data in the variable-length encoding of
+// version 4 path prefixes: big-endian groups of 7 bits, each but the last
+// with the high bit set and each continuation adding one to the value, so
+// that every number has exactly one encoding.
+func appendIndexVarint(data []byte, value uint64) []byte {
+	var encoded [10]byte
+	pos := len(encoded) - 1
+	encoded[pos] = byte(value & 0x7F)
+	for value >>= 7; value != 0; value >>= 7 {
+		value--
+		pos--
+		encoded[pos] = 0x80 | byte(value&0x7F)
+	}
+	return append(data, encoded[pos:]...)
+}
+
+// readIndexVarint decodes a value written by appendIndexVarint at the start
+// of data and returns it with the number of bytes it occupies.
+func readIndexVarint(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrEntryDataTooShort
+	}
+	c := data[0]
+	value := uint64(c & 0x7F)
+	size := 1
+	for c&0x80 != 0 {
+		if size == len(data) {
+			return 0, 0, ErrEntryDataTooShort
+		}
+		if value+1 > math.MaxUint64>>7 {
+			return 0, 0, ErrInvalidPathPrefix
+		}
+		c = data[size]
+		size++
+		value = (value+1)<<7 | uint64(c&0x7F)
+	}
+	return value, size, nil
+}
+
+// commonPrefixLength returns the length of the longest common prefix of a and b.
+func commonPrefixLength(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
 // writeIndexEntryFields writes the fixed-size fields of an index entry to the buffer.
 func writeIndexEntryFields(buffer *bytes.Buffer, entry *IndexEntry) error {
 	if err := binary.Write(buffer, binary.BigEndian, entry.ChangedTime.Unix()); err != nil {
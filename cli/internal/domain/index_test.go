@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestIndex returns an index in version with one regular file entry per path.
+func newTestIndex(t *testing.T, version uint32, paths ...string) *Index {
+	t.Helper()
+	index := NewEmptyIndex()
+	index.Header.Version = version
+	hash, err := NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	for i, path := range paths {
+		normalizedPath, err := ParseNormalizedPath(path)
+		require.NoError(t, err)
+		entry := NewIndexEntry(
+			normalizedPath, hash, uint64(i), 0o100644, 1, uint64(i+1), 1000, 1000,
+			ComputeIndexFlags(path, 0), time.Unix(1700000000, 1), time.Unix(1700000000, 2),
+		)
+		index.AddEntry(entry)
+	}
+	return index
+}
+
+func TestIndexVarintRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   uint64
+		encoded []byte
+	}{
+		{name: "zero", value: 0, encoded: []byte{0x00}},
+		{name: "one byte", value: 127, encoded: []byte{0x7f}},
+		{name: "two bytes", value: 128, encoded: []byte{0x80, 0x00}},
+		{name: "largest two bytes", value: 16511, encoded: []byte{0xff, 0x7f}},
+		{name: "three bytes", value: 16512, encoded: []byte{0x80, 0x80, 0x00}},
+		{name: "largest value", value: math.MaxUint64},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				encoded := appendIndexVarint(nil, tt.value)
+				if tt.encoded != nil {
+					assert.Equal(t, tt.encoded, encoded)
+				}
+
+				value, size, err := readIndexVarint(append(encoded, 'x'))
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.value, value)
+				assert.Equal(t, len(encoded), size)
+			},
+		)
+	}
+}
+
+func TestReadIndexVarintRejectsCorruptData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "empty", data: nil, wantErr: ErrEntryDataTooShort},
+		{name: "truncated continuation", data: []byte{0x80}, wantErr: ErrEntryDataTooShort},
+		{name: "overflow", data: bytes.Repeat([]byte{0xff}, 11), wantErr: ErrInvalidPathPrefix},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, _, err := readIndexVarint(tt.data)
+
+				require.ErrorIs(t, err, tt.wantErr)
+			},
+		)
+	}
+}
+
+func TestIndexPathCompressedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{name: "no entries"},
+		{name: "single entry", paths: []string{"file.txt"}},
+		{
+			name:  "shared prefixes",
+			paths: []string{"dir/sub/a.txt", "dir/sub/b.txt", "dir/c.txt", "dir2/a.txt", "z.txt"},
+		},
+		{name: "prefix of the next path", paths: []string{"a", "a/b", "a/b/c", "ab"}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				index := newTestIndex(t, IndexVersionPathCompressed, tt.paths...)
+				if len(index.Entries) > 0 {
+					index.Entries[0].SetSkipWorktree(true)
+				}
+				data, err := index.Serialize()
+				require.NoError(t, err)
+
+				parsed, err := DeserializeIndex(data)
+
+				require.NoError(t, err)
+				assert.Equal(t, uint32(IndexVersionPathCompressed), parsed.Header.Version)
+				require.Len(t, parsed.Entries, len(index.Entries))
+				for i, entry := range index.Entries {
+					assert.Equal(t, entry.Path, parsed.Entries[i].Path)
+					assert.Equal(t, entry.Hash, parsed.Entries[i].Hash)
+					assert.Equal(t, entry.Flags, parsed.Entries[i].Flags)
+					assert.Equal(t, entry.ExtendedFlags, parsed.Entries[i].ExtendedFlags)
+					assert.True(t, entry.ModifiedTime.Equal(parsed.Entries[i].ModifiedTime))
+				}
+				reserialized, err := parsed.Serialize()
+				require.NoError(t, err)
+				assert.Equal(t, data, reserialized)
+			},
+		)
+	}
+}
+
+func TestIndexPathCompressedStoresSuffixes(t *testing.T) {
+	index := newTestIndex(t, IndexVersionPathCompressed, "dir/sub/a.txt", "dir/sub/b.txt")
+
+	data, err := index.Serialize()
+
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "dir/sub/a.txt\x00")
+	assert.NotContains(t, string(data), "dir/sub/b.txt")
+	// The second path strips "a.txt" from the first and appends "b.txt".
+	assert.Contains(t, string(data), "\x05b.txt\x00")
+}
+
+func TestDeserializeIndexRejectsCorruptPathPrefix(t *testing.T) {
+	checksum := make([]byte, IndexChecksumSize)
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		wantErr error
+	}{
+		{
+			name: "strip exceeds empty previous path",
+			corrupt: func(data []byte) []byte {
+				data[IndexHeaderSize+IndexEntryFixedSize] = 1
+				return data
+			},
+			wantErr: ErrInvalidPathPrefix,
+		},
+		{
+			name: "strip exceeds previous path",
+			corrupt: func(data []byte) []byte {
+				// The first entry is its varint, "a" and NUL.
+				data[IndexHeaderSize+2*IndexEntryFixedSize+3] = 2
+				return data
+			},
+			wantErr: ErrInvalidPathPrefix,
+		},
+		{
+			name: "truncated strip",
+			corrupt: func(data []byte) []byte {
+				entry := data[:IndexHeaderSize+IndexEntryFixedSize]
+				return append(append(entry, 0x80), checksum...)
+			},
+			wantErr: ErrEntryDataTooShort,
+		},
+		{
+			name: "unterminated path",
+			corrupt: func(data []byte) []byte {
+				entry := data[:IndexHeaderSize+IndexEntryFixedSize+2]
+				return append(entry, checksum...)
+			},
+			wantErr: ErrPathNotNullTerminated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				data, err := newTestIndex(t, IndexVersionPathCompressed, "a", "b").Serialize()
+				require.NoError(t, err)
+
+				_, err = DeserializeIndex(tt.corrupt(data))
+
+				require.ErrorIs(t, err, tt.wantErr)
+			},
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidLFSPointer is returned when blob content is not a large file pointer.
+	ErrInvalidLFSPointer = errors.New("invalid lfs pointer")
+)
+
+const (
+	// LFSPointerVersion is the first line of every large file pointer.
+	LFSPointerVersion = "version gel-lfs/v1"
+	// lfsPointerOIDPrefix precedes the hex SHA-256 of the payload.
+	lfsPointerOIDPrefix = "oid sha256:"
+	// lfsPointerSizePrefix precedes the decimal payload size.
+	lfsPointerSizePrefix = "size "
+	// LFSPointerMaxSize bounds the size of pointer content; larger blobs are never parsed.
+	LFSPointerMaxSize = 256
+)
+
+// LFSPointer is the small blob stored in trees in place of a large file.
+// The payload itself lives in the large file store under its OID.
+type LFSPointer struct {
+	// OID is the SHA-256 of the raw payload.
+	OID Hash
+	// Size is the payload length in bytes.
+	Size int64
+}
+
+// Serialize renders the pointer as blob content:
+//
+//	version gel-lfs/v1
+//	oid sha256:<hex>
+//	size <bytes>
+func (p LFSPointer) Serialize() []byte {
+	return []byte(fmt.Sprintf(
+		"%s\n%s%s\n%s%d\n", LFSPointerVersion, lfsPointerOIDPrefix, p.OID, lfsPointerSizePrefix, p.Size,
+	))
+}
+
+// ParseLFSPointer parses blob content produced by Serialize.
+func ParseLFSPointer(data []byte) (LFSPointer, error) {
+	if len(data) > LFSPointerMaxSize {
+		return LFSPointer{}, fmt.Errorf("%w: %d bytes is too large", ErrInvalidLFSPointer, len(data))
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != LFSPointerVersion {
+		return LFSPointer{}, fmt.Errorf("%w: missing %q header", ErrInvalidLFSPointer, LFSPointerVersion)
+	}
+
+	oidHex, ok := strings.CutPrefix(lines[1], lfsPointerOIDPrefix)
+	if !ok {
+		return LFSPointer{}, fmt.Errorf("%w: malformed oid line %q", ErrInvalidLFSPointer, lines[1])
+	}
+	oid, err := NewHashFromHex(oidHex)
+	if err != nil {
+		return LFSPointer{}, fmt.Errorf("%w: %v", ErrInvalidLFSPointer, err)
+	}
+
+	sizeText, ok := strings.CutPrefix(lines[2], lfsPointerSizePrefix)
+	if !ok {
+		return LFSPointer{}, fmt.Errorf("%w: malformed size line %q", ErrInvalidLFSPointer, lines[2])
+	}
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if err != nil || size < 0 {
+		return LFSPointer{}, fmt.Errorf("%w: malformed size %q", ErrInvalidLFSPointer, sizeText)
+	}
+	return LFSPointer{OID: oid, Size: size}, nil
+}
+
+// IsLFSPointer reports whether data is large file pointer content.
+func IsLFSPointer(data []byte) bool {
+	_, err := ParseLFSPointer(data)
+	return err == nil
+}
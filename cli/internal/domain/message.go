@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidCleanupMode is returned for an unknown commit message cleanup mode.
+	ErrInvalidCleanupMode = errors.New("invalid cleanup mode")
+
+	// ErrInvalidTrailer is returned for a trailer that is not "<key>=<value>" or "<key>: <value>".
+	ErrInvalidTrailer = errors.New("invalid trailer")
+)
+
+// CommentPrefix starts the lines of an edited commit message that are
+// removed by CleanupStrip.
+const CommentPrefix = "#"
+
+// ScissorsLine marks the end of the message in an edited commit message
+// cleaned up with CleanupScissors. Everything from it on is removed.
+const ScissorsLine = "# ------------------------ >8 ------------------------"
+
+// CleanupMode selects how a commit message is tidied up before it is stored.
+type CleanupMode string
+
+const (
+	// CleanupDefault is CleanupStrip for an edited message and CleanupWhitespace otherwise.
+	CleanupDefault CleanupMode = "default"
+	// CleanupStrip is CleanupWhitespace that also removes comment lines.
+	CleanupStrip CleanupMode = "strip"
+	// CleanupWhitespace removes trailing whitespace, leading and trailing blank
+	// lines, and collapses runs of blank lines.
+	CleanupWhitespace CleanupMode = "whitespace"
+	// CleanupVerbatim keeps the message as it is.
+	CleanupVerbatim CleanupMode = "verbatim"
+	// CleanupScissors is CleanupWhitespace that also cuts an edited message at
+	// the scissors line.
+	CleanupScissors CleanupMode = "scissors"
+)
+
+// ParseCleanupMode validates a cleanup mode name.
+func ParseCleanupMode(value string) (CleanupMode, error) {
+	switch mode := CleanupMode(value); mode {
+	case CleanupDefault, CleanupStrip, CleanupWhitespace, CleanupVerbatim, CleanupScissors:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidCleanupMode, value)
+}
+
+// CleanupMessage tidies message according to mode. edited reports whether the
+// message went through the editor, which decides what CleanupDefault and
+// CleanupScissors do. Apart from CleanupVerbatim the result has no trailing newline.
+func CleanupMessage(message string, mode CleanupMode, edited bool) string {
+	if mode == CleanupDefault {
+		mode = CleanupWhitespace
+		if edited {
+			mode = CleanupStrip
+		}
+	}
+	switch mode {
+	case CleanupVerbatim:
+		return message
+	case CleanupScissors:
+		if edited {
+			if before, _, ok := cutLine(message, ScissorsLine); ok {
+				message = before
+			}
+		}
+	}
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(message, "\n") {
+		if mode == CleanupStrip && strings.HasPrefix(line, CommentPrefix) {
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cutLine splits message around the first line equal to line.
+func cutLine(message, line string) (string, string, bool) {
+	if strings.HasPrefix(message, line+"\n") || message == line {
+		return "", strings.TrimPrefix(message, line), true
+	}
+	before, after, ok := strings.Cut(message, "\n"+line+"\n")
+	if !ok && strings.HasSuffix(message, "\n"+line) {
+		return strings.TrimSuffix(message, "\n"+line), "", true
+	}
+	return before, after, ok
+}
+
+// Trailer is a "<key>: <value>" line in the last paragraph of a commit
+// message, such as Signed-off-by or Co-authored-by.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// String renders the trailer as a message line.
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// ParseTrailer parses a trailer given as "<key>=<value>" or "<key>: <value>".
+func ParseTrailer(value string) (Trailer, error) {
+	separator := strings.IndexAny(value, "=:")
+	if separator == -1 {
+		return Trailer{}, fmt.Errorf("%w: %q", ErrInvalidTrailer, value)
+	}
+	trailer := Trailer{
+		Key:   strings.TrimSpace(value[:separator]),
+		Value: strings.TrimSpace(value[separator+1:]),
+	}
+	if !isTrailerKey(trailer.Key) || trailer.Value == "" || strings.Contains(trailer.Value, "\n") {
+		return Trailer{}, fmt.Errorf("%w: %q", ErrInvalidTrailer, value)
+	}
+	return trailer, nil
+}
+
+// ParseTrailers returns the trailers of message: the lines of its last
+// paragraph when every one of them is a trailer or continues the previous
+// trailer with indentation. The first paragraph is the subject and never
+// holds trailers. Comment lines are ignored.
+func ParseTrailers(message string) []Trailer {
+	_, block := splitTrailerBlock(message)
+	var trailers []Trailer
+	for _, line := range block {
+		if line[0] == ' ' || line[0] == '\t' {
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		key, value, _ := strings.Cut(line, ":")
+		trailers = append(trailers, Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
+
+// AppendTrailers adds trailers to the trailer block of message, starting a
+// new paragraph when message has none. A trailer already in the block is not
+// added again.
+func AppendTrailers(message string, trailers []Trailer) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	existing := ParseTrailers(message)
+	body := strings.TrimRight(message, "\n")
+
+	var added []string
+	for _, trailer := range trailers {
+		duplicate := false
+		for _, other := range existing {
+			if strings.EqualFold(other.Key, trailer.Key) && other.Value == trailer.Value {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			existing = append(existing, trailer)
+			added = append(added, trailer.String())
+		}
+	}
+	if len(added) == 0 {
+		return message
+	}
+
+	if body == "" {
+		return "\n" + strings.Join(added, "\n") + "\n"
+	}
+	if hasTrailers, _ := splitTrailerBlock(message); !hasTrailers {
+		body += "\n"
+	}
+	return body + "\n" + strings.Join(added, "\n") + "\n"
+}
+
+// splitTrailerBlock reports whether message ends in a trailer block and
+// returns the block's non-comment lines.
+func splitTrailerBlock(message string) (bool, []string) {
+	var paragraphs [][]string
+	var current []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, CommentPrefix) {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, strings.TrimRight(line, " \t\r"))
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	if len(paragraphs) < 2 {
+		return false, nil
+	}
+
+	block := paragraphs[len(paragraphs)-1]
+	for i, line := range block {
+		if line[0] == ' ' || line[0] == '\t' {
+			if i == 0 {
+				return false, nil
+			}
+			continue
+		}
+		key, _, ok := strings.Cut(line, ":")
+		if !ok || !isTrailerKey(strings.TrimSpace(key)) {
+			return false, nil
+		}
+	}
+	return true, block
+}
+
+// isTrailerKey reports whether key is a valid trailer key: letters, digits and dashes.
+func isTrailerKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -27,9 +27,9 @@ var (
 	ErrObjectSizeInvalid = errors.New("invalid object header: size must be a non-negative integer")
 )
 
-// Object is implemented by Blob, Tree, and Commit.
+// Object is implemented by Blob, Tree, Commit, and Tag.
 type Object interface {
-	// Type returns the object type (blob, tree, or commit).
+	// Type returns the object type (blob, tree, commit, or tag).
 	Type() ObjectType
 
 	// Size returns the byte length of the object's body.
@@ -68,6 +68,8 @@ func DeserializeObject(data []byte) (Object, error) {
 		return NewTree(body)
 	case ObjectTypeCommit:
 		return NewCommit(body)
+	case ObjectTypeTag:
+		return NewTag(body)
 	default:
 		return nil, fmt.Errorf("%w: %q", ErrObjectTypeUnknown, objectType)
 	}
@@ -10,12 +10,14 @@ const (
 	ObjectTypeTree ObjectType = "tree"
 	// ObjectTypeCommit represents a commit snapshot object.
 	ObjectTypeCommit ObjectType = "commit"
+	// ObjectTypeTag represents an annotated tag object.
+	ObjectTypeTag ObjectType = "tag"
 )
 
 // IsValid reports whether objectType is one of the supported values.
 func (objectType ObjectType) IsValid() bool {
 	switch objectType {
-	case ObjectTypeBlob, ObjectTypeTree, ObjectTypeCommit:
+	case ObjectTypeBlob, ObjectTypeTree, ObjectTypeCommit, ObjectTypeTag:
 		return true
 	default:
 		return false
@@ -31,7 +33,7 @@ func (objectType ObjectType) String() string {
 func ParseObjectType(s string) (ObjectType, bool) {
 	objectType := ObjectType(s)
 	switch objectType {
-	case ObjectTypeBlob, ObjectTypeTree, ObjectTypeCommit:
+	case ObjectTypeBlob, ObjectTypeTree, ObjectTypeCommit, ObjectTypeTag:
 		return objectType, true
 	default:
 		return "", false
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ShallowGrafts is the set of commits recorded in .gel/shallow.
+//
+// A shallow commit is a history boundary created by a depth-limited clone: its
+// parents were intentionally not fetched, so history walks must treat it as a
+// root commit instead of failing on the missing parents.
+type ShallowGrafts map[Hash]struct{}
+
+// ParseShallowGrafts parses one hexadecimal commit hash per line.
+// Blank lines are ignored.
+func ParseShallowGrafts(data []byte) (ShallowGrafts, error) {
+	grafts := make(ShallowGrafts)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		hash, err := NewHashFromHex(line)
+		if err != nil {
+			return nil, fmt.Errorf("shallow: %w", err)
+		}
+		grafts[hash] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("shallow: %w", err)
+	}
+	return grafts, nil
+}
+
+// Contains reports whether hash is a shallow boundary commit.
+func (g ShallowGrafts) Contains(hash Hash) bool {
+	_, ok := g[hash]
+	return ok
+}
+
+// Parents returns commit's parents as seen through the grafts: none for a
+// shallow boundary commit, otherwise the parents recorded in the commit.
+func (g ShallowGrafts) Parents(hash Hash, commit *Commit) []Hash {
+	if g.Contains(hash) {
+		return nil
+	}
+	return commit.ParentHashes
+}
+
+// Serialize encodes the grafts as sorted hexadecimal hashes, one per line.
+func (g ShallowGrafts) Serialize() []byte {
+	lines := make([]string, 0, len(g))
+	for hash := range g {
+		lines = append(lines, hash.Hex())
+	}
+	slices.Sort(lines)
+
+	var buffer bytes.Buffer
+	for _, line := range lines {
+		buffer.WriteString(line)
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes()
+}
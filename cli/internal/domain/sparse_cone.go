@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInvalidSparsePattern is returned when a sparse-checkout file holds a pattern outside cone mode.
+	ErrInvalidSparsePattern = errors.New("invalid sparse-checkout pattern")
+)
+
+const (
+	// sparseConeRootPatterns include the files at the repository root and exclude every top-level directory.
+	sparseConeRootPatterns = "/*\n!/*/\n"
+)
+
+// SparseCone is a cone-mode sparse checkout definition. A path is in the cone
+// when it lies below one of Dirs, or directly inside the repository root or an
+// ancestor directory of one of Dirs.
+type SparseCone struct {
+	// Dirs are the recursively included directories, sorted and free of nesting.
+	Dirs []string
+}
+
+// NewSparseCone builds a cone from repository-relative directories. Trailing
+// slashes and duplicates are dropped, as are directories inside another one.
+func NewSparseCone(dirs []string) SparseCone {
+	var cleaned []string
+	for _, dir := range dirs {
+		dir = strings.Trim(path.Clean("/"+filepath.ToSlash(dir)), "/")
+		if dir != "" {
+			cleaned = append(cleaned, dir)
+		}
+	}
+	slices.Sort(cleaned)
+	cleaned = slices.Compact(cleaned)
+
+	var result []string
+	for _, dir := range cleaned {
+		if len(result) > 0 && strings.HasPrefix(dir, result[len(result)-1]+"/") {
+			continue
+		}
+		result = append(result, dir)
+	}
+	return SparseCone{Dirs: result}
+}
+
+// Includes reports whether the repository-relative file path is in the cone.
+func (c SparseCone) Includes(filePath NormalizedPath) bool {
+	dir := path.Dir(filePath.String())
+	if dir == "." {
+		return true
+	}
+	for _, included := range c.Dirs {
+		if strings.HasPrefix(filePath.String(), included+"/") {
+			return true
+		}
+		if strings.HasPrefix(included, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Serialize renders the cone as sparse-checkout file content: the root
+// patterns, then each ancestor of an included directory with its
+// subdirectories excluded, then each included directory.
+func (c SparseCone) Serialize() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(sparseConeRootPatterns)
+	for _, parent := range c.parents() {
+		fmt.Fprintf(&buffer, "/%s/\n!/%s/*/\n", parent, parent)
+	}
+	for _, dir := range c.Dirs {
+		fmt.Fprintf(&buffer, "/%s/\n", dir)
+	}
+	return buffer.Bytes()
+}
+
+// parents returns the sorted ancestor directories of Dirs, excluding the root.
+func (c SparseCone) parents() []string {
+	var parents []string
+	for _, dir := range c.Dirs {
+		for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+			parents = append(parents, parent)
+		}
+	}
+	slices.Sort(parents)
+	return slices.Compact(parents)
+}
+
+// ParseSparseCone parses sparse-checkout file content written by Serialize.
+// Blank lines and comments are ignored; any other non-cone pattern is rejected.
+func ParseSparseCone(data []byte) (SparseCone, error) {
+	var dirs []string
+	parents := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case line == "/*" || line == "!/*/":
+			continue
+		case strings.HasPrefix(line, "!/") && strings.HasSuffix(line, "/*/"):
+			parents[strings.TrimSuffix(strings.TrimPrefix(line, "!/"), "/*/")] = true
+		case strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/") && len(line) > 2 && !strings.Contains(line, "*"):
+			dirs = append(dirs, strings.Trim(line, "/"))
+		default:
+			return SparseCone{}, fmt.Errorf("%w: %q", ErrInvalidSparsePattern, line)
+		}
+	}
+
+	var included []string
+	for _, dir := range dirs {
+		if !parents[dir] {
+			included = append(included, dir)
+		}
+	}
+	return NewSparseCone(included), nil
+}
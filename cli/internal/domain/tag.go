@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTagFormat is returned when tag body parsing fails.
+var ErrInvalidTagFormat = errors.New("invalid tag format")
+
+// TagFieldObject is the tag header key that stores the tagged object hash.
+const TagFieldObject string = "object"
+
+// TagFieldType is the tag header key that stores the tagged object type.
+const TagFieldType string = "type"
+
+// TagFieldTag is the tag header key that stores the tag name.
+const TagFieldTag string = "tag"
+
+// TagFieldTagger is the tag header key for tagger identity metadata.
+const TagFieldTagger string = "tagger"
+
+// TagSignatureBegin starts the armored signature appended to a signed tag
+// message.
+const TagSignatureBegin string = "-----BEGIN SSH SIGNATURE-----"
+
+// TagFields contains the semantic fields represented by a tag object body.
+type TagFields struct {
+	// ObjectHash points to the tagged object.
+	ObjectHash Hash
+
+	// ObjectType is the type of the tagged object.
+	ObjectType ObjectType
+
+	// Name is the tag name, without the refs/tags/ prefix.
+	Name string
+
+	// Tagger describes who created the tag and when.
+	Tagger Identity
+
+	// Message is the tag message without the signature.
+	Message string
+
+	// Signature is the armored signature over the tag body without the
+	// signature, or empty for an unsigned tag.
+	Signature string
+}
+
+// Tag represents a parsed annotated tag domain object.
+// body stores the raw tag payload (without object header), while TagFields
+// stores parsed structured fields from the same payload.
+//
+// As in Git, the signature of a signed tag is not a header: it is appended
+// to the message, and the signed payload is the body up to it.
+type Tag struct {
+	body []byte
+	TagFields
+}
+
+// Body returns a defensive copy of the raw tag body bytes.
+func (tag *Tag) Body() []byte {
+	return append([]byte(nil), tag.body...)
+}
+
+// SignedPayload returns the bytes covered by the tag signature: the raw tag
+// body without the appended signature.
+func (tag *Tag) SignedPayload() []byte {
+	if tag.Signature == "" {
+		return tag.Body()
+	}
+	return append([]byte(nil), tag.body[:len(tag.body)-len(tag.Signature)-1]...)
+}
+
+// Type returns the domain object type for Tag.
+func (tag *Tag) Type() ObjectType {
+	return ObjectTypeTag
+}
+
+// Size returns the byte length of the raw tag body.
+func (tag *Tag) Size() int {
+	return len(tag.body)
+}
+
+// Serialize returns the full object serialization in "<type> <size>\\x00<body>" format.
+func (tag *Tag) Serialize() []byte {
+	return SerializeObject(ObjectTypeTag, tag.body)
+}
+
+// NewTag parses a raw tag body and returns a Tag.
+// The input bytes are copied so subsequent caller mutations do not affect the Tag.
+func NewTag(body []byte) (*Tag, error) {
+	bodyCopy := append([]byte(nil), body...)
+	fields, err := deserializeTagFields(bodyCopy)
+	if err != nil {
+		return nil, err
+	}
+	return &Tag{body: bodyCopy, TagFields: fields}, nil
+}
+
+// NewTagFromFields validates tag fields, serializes them into canonical
+// tag-body format, and returns a new Tag value.
+func NewTagFromFields(tagFields TagFields) (*Tag, error) {
+	if err := validateTagFields(tagFields); err != nil {
+		return nil, err
+	}
+	return &Tag{body: serializeTagBody(tagFields), TagFields: tagFields}, nil
+}
+
+// validateTagFields validates logical tag invariants before serialization:
+// a non-empty object hash, a valid object type, a single-line name, a valid
+// tagger identity, and a signature that parses back out of the message.
+func validateTagFields(fields TagFields) error {
+	if fields.ObjectHash.IsEmpty() || !fields.ObjectType.IsValid() {
+		return ErrInvalidTagFormat
+	}
+	if fields.Name == "" || strings.ContainsAny(fields.Name, " \n") {
+		return ErrInvalidTagFormat
+	}
+	if _, err := NewIdentity(
+		fields.Tagger.Name,
+		fields.Tagger.Email,
+		fields.Tagger.Timestamp,
+		fields.Tagger.Timezone,
+	); err != nil {
+		return err
+	}
+	if strings.Contains(fields.Message, TagSignatureBegin) {
+		return ErrInvalidTagFormat
+	}
+	if fields.Signature != "" {
+		if !strings.HasPrefix(fields.Signature, TagSignatureBegin) || strings.HasSuffix(fields.Signature, "\n") {
+			return ErrInvalidTagFormat
+		}
+		if fields.Message != "" && !strings.HasSuffix(fields.Message, "\n") {
+			return ErrInvalidTagFormat
+		}
+	}
+	return nil
+}
+
+// serializeTagBody serializes tag fields into raw tag-body format:
+//
+//	object <hash>\n
+//	type <type>\n
+//	tag <name>\n
+//	tagger <name> <email> <timestamp> <timezone>\n
+//	\n
+//	<message>
+//	<signature>\n (when signed)
+func serializeTagBody(fields TagFields) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(TagFieldObject + " " + fields.ObjectHash.Hex() + "\n")
+	buffer.WriteString(TagFieldType + " " + fields.ObjectType.String() + "\n")
+	buffer.WriteString(TagFieldTag + " " + fields.Name + "\n")
+	buffer.WriteString(TagFieldTagger + " ")
+	buffer.Write(fields.Tagger.Serialize())
+	buffer.WriteString("\n\n")
+	buffer.WriteString(fields.Message)
+	if fields.Signature != "" {
+		buffer.WriteString(fields.Signature)
+		buffer.WriteString("\n")
+	}
+	return buffer.Bytes()
+}
+
+// deserializeTagFields parses a raw tag body into TagFields. It requires the
+// object, type, tag and tagger headers in that order, followed by a message
+// section. A message line starting TagSignatureBegin starts the signature.
+func deserializeTagFields(data []byte) (TagFields, error) {
+	var fields TagFields
+	i := 0
+	for _, key := range []string{TagFieldObject, TagFieldType, TagFieldTag, TagFieldTagger} {
+		if !bytes.HasPrefix(data[i:], []byte(key+" ")) {
+			return fields, ErrInvalidTagFormat
+		}
+		i += len(key) + 1
+		if key == TagFieldTagger {
+			tagger, nextI, err := deserializeIdentity(data, i)
+			if err != nil {
+				return fields, ErrInvalidTagFormat
+			}
+			fields.Tagger = tagger
+			i = nextI
+			continue
+		}
+		lineEnd := bytes.IndexByte(data[i:], '\n')
+		if lineEnd == -1 {
+			return fields, ErrInvalidTagFormat
+		}
+		value := string(data[i : i+lineEnd])
+		i += lineEnd + 1
+		switch key {
+		case TagFieldObject:
+			hash, err := NewHashFromHex(value)
+			if err != nil {
+				return fields, ErrInvalidTagFormat
+			}
+			fields.ObjectHash = hash
+		case TagFieldType:
+			objectType, ok := ParseObjectType(value)
+			if !ok {
+				return fields, ErrInvalidTagFormat
+			}
+			fields.ObjectType = objectType
+		case TagFieldTag:
+			fields.Name = value
+		}
+	}
+	if i >= len(data) || data[i] != '\n' {
+		return fields, ErrInvalidTagFormat
+	}
+	message := string(data[i+1:])
+
+	signatureStart := -1
+	if strings.HasPrefix(message, TagSignatureBegin) {
+		signatureStart = 0
+	} else if index := strings.Index(message, "\n"+TagSignatureBegin); index != -1 {
+		signatureStart = index + 1
+	}
+	if signatureStart == -1 {
+		fields.Message = message
+		return fields, nil
+	}
+	if !strings.HasSuffix(message, "\n") {
+		return fields, ErrInvalidTagFormat
+	}
+	fields.Message = message[:signatureStart]
+	fields.Signature = strings.TrimSuffix(message[signatureStart:], "\n")
+	return fields, nil
+}
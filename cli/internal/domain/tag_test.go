@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTagFields returns the fields of a tag of a commit with signature.
+func newTestTagFields(t *testing.T, signature string) TagFields {
+	t.Helper()
+	tagger, err := NewIdentity("Alice", "alice@example.com", "1700000000", "+0000")
+	require.NoError(t, err)
+	commitHash, err := NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	return TagFields{
+		ObjectHash: commitHash,
+		ObjectType: ObjectTypeCommit,
+		Name:       "v1.0",
+		Tagger:     tagger,
+		Message:    "release\n\nnotes\n",
+		Signature:  signature,
+	}
+}
+
+func TestTagRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{name: "unsigned"},
+		{name: "signed", signature: TagSignatureBegin + "\nU1NIU0lH\n-----END SSH SIGNATURE-----"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				unsigned, err := NewTagFromFields(newTestTagFields(t, ""))
+				require.NoError(t, err)
+				tag, err := NewTagFromFields(newTestTagFields(t, tt.signature))
+				require.NoError(t, err)
+
+				object, err := DeserializeObject(tag.Serialize())
+
+				require.NoError(t, err)
+				parsed, ok := object.(*Tag)
+				require.True(t, ok)
+				assert.Equal(t, tag.TagFields, parsed.TagFields)
+				assert.Equal(t, string(unsigned.Body()), string(parsed.SignedPayload()))
+			},
+		)
+	}
+}
+
+func TestNewTagFromFieldsRejectsInvalidFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(fields *TagFields)
+	}{
+		{name: "missing object", mutate: func(fields *TagFields) { fields.ObjectHash = Hash{} }},
+		{name: "invalid type", mutate: func(fields *TagFields) { fields.ObjectType = "branch" }},
+		{name: "name with space", mutate: func(fields *TagFields) { fields.Name = "v1 0" }},
+		{name: "signature in message", mutate: func(fields *TagFields) { fields.Message = TagSignatureBegin + "\n" }},
+		{name: "unarmored signature", mutate: func(fields *TagFields) { fields.Signature = "signature" }},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				fields := newTestTagFields(t, "")
+				tt.mutate(&fields)
+
+				_, err := NewTagFromFields(fields)
+
+				require.ErrorIs(t, err, ErrInvalidTagFormat)
+			},
+		)
+	}
+}
+
+func TestNewTagRejectsMissingHeaders(t *testing.T) {
+	tag, err := NewTagFromFields(newTestTagFields(t, ""))
+	require.NoError(t, err)
+	body := strings.Replace(string(tag.Body()), TagFieldType+" "+ObjectTypeCommit.String()+"\n", "", 1)
+
+	_, err = NewTag([]byte(body))
+
+	require.ErrorIs(t, err, ErrInvalidTagFormat)
+}
@@ -4,14 +4,31 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var (
 	ErrInvalidCommitTimestamp = errors.New("invalid commit timestamp")
 	ErrInvalidCommitTimezone  = errors.New("invalid commit timezone")
+	ErrInvalidCommitDate      = errors.New("invalid commit date")
 )
 
+// commitDateLayouts are the date formats ParseCommitDate accepts besides
+// "[@]<unix timestamp> <±HHMM>" and "@<unix timestamp>". Layouts without a zone
+// are read in the local time zone.
+var commitDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon Jan 2 15:04:05 2006 -0700",
+	"2006-01-02",
+}
+
 // FormatCommitTimestamp formats a time as Unix timestamp string for commit objects.
 func FormatCommitTimestamp(t time.Time) string {
 	return strconv.FormatInt(t.Unix(), 10)
@@ -26,18 +43,57 @@ func FormatCommitTimezone(t time.Time) string {
 // formatted date string in "2006-01-02 15:04:05 -0700" layout.
 // It validates that timestamp is non-empty and timezone is in ±HHMM format.
 func FormatCommitDate(timestamp, timezone string) (string, error) {
-	unix, err := parseCommitTimestamp(timestamp)
+	t, err := ParseCommitTime(timestamp, timezone)
 	if err != nil {
 		return "", err
 	}
+	return t.Format("2006-01-02 15:04:05 -0700"), nil
+}
+
+// ParseCommitTime converts a commit timestamp and timezone into a time in
+// that timezone.
+func ParseCommitTime(timestamp, timezone string) (time.Time, error) {
+	unix, err := parseCommitTimestamp(timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
 
 	offset, err := parseCommitTimezoneOffset(timezone)
 	if err != nil {
-		return "", err
+		return time.Time{}, err
 	}
 
 	loc := time.FixedZone(timezone, offset)
-	return time.Unix(unix, 0).In(loc).Format("2006-01-02 15:04:05 -0700"), nil
+	return time.Unix(unix, 0).In(loc), nil
+}
+
+// ParseCommitDate parses a user-supplied date into a commit timestamp and
+// timezone. It accepts the commit object form "<unix timestamp> <±HHMM>",
+// optionally prefixed with "@", "@<unix timestamp>" in UTC, ISO 8601 and
+// RFC 2822 dates, and the "2006-01-02 15:04:05 -0700" form printed by log.
+func ParseCommitDate(value string) (string, string, error) {
+	value = strings.TrimSpace(value)
+	raw, isRaw := strings.CutPrefix(value, "@")
+	if timestamp, timezone, ok := strings.Cut(raw, " "); ok {
+		if _, err := parseCommitTimestamp(timestamp); err == nil {
+			if _, err := parseCommitTimezoneOffset(timezone); err != nil {
+				return "", "", err
+			}
+			return timestamp, timezone, nil
+		}
+	}
+	if isRaw {
+		if _, err := parseCommitTimestamp(raw); err != nil {
+			return "", "", err
+		}
+		return raw, "+0000", nil
+	}
+	for _, layout := range commitDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return FormatCommitTimestamp(t), FormatCommitTimezone(t), nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %q", ErrInvalidCommitDate, value)
 }
 
 func parseCommitTimestamp(timestamp string) (int64, error) {
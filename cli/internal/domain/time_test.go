@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommitDate(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("", 2*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	tests := []struct {
+		name          string
+		value         string
+		wantTimestamp string
+		wantTimezone  string
+		wantErr       error
+	}{
+		{name: "raw", value: "1700000000 +0530", wantTimestamp: "1700000000", wantTimezone: "+0530"},
+		{name: "raw with at", value: "@1700000000 -0700", wantTimestamp: "1700000000", wantTimezone: "-0700"},
+		{name: "raw with spaces", value: "  1700000000 +0000 ", wantTimestamp: "1700000000", wantTimezone: "+0000"},
+		{name: "negative timestamp", value: "-1 +0000", wantTimestamp: "-1", wantTimezone: "+0000"},
+		{name: "at", value: "@1700000000", wantTimestamp: "1700000000", wantTimezone: "+0000"},
+		{name: "RFC 3339", value: "2023-11-14T22:13:20Z", wantTimestamp: "1700000000", wantTimezone: "+0000"},
+		{
+			name: "RFC 3339 with offset", value: "2023-11-14T23:13:20+01:00",
+			wantTimestamp: "1700000000", wantTimezone: "+0100",
+		},
+		{
+			name: "ISO 8601 basic offset", value: "2023-11-14T23:13:20+0100",
+			wantTimestamp: "1700000000", wantTimezone: "+0100",
+		},
+		{
+			name: "log form", value: "2023-11-14 17:13:20 -0500",
+			wantTimestamp: "1700000000", wantTimezone: "-0500",
+		},
+		{name: "ISO 8601 local", value: "2023-11-15T00:13:20", wantTimestamp: "1700000000", wantTimezone: "+0200"},
+		{name: "log form local", value: "2023-11-15 00:13:20", wantTimestamp: "1700000000", wantTimezone: "+0200"},
+		{
+			name: "RFC 2822", value: "Tue, 14 Nov 2023 22:13:20 +0000",
+			wantTimestamp: "1700000000", wantTimezone: "+0000",
+		},
+		{
+			name: "RFC 2822 single digit day", value: "Thu, 2 Nov 2023 10:00:00 +0000",
+			wantTimestamp: "1698919200", wantTimezone: "+0000",
+		},
+		{
+			name: "git default form", value: "Tue Nov 14 22:13:20 2023 +0000",
+			wantTimestamp: "1700000000", wantTimezone: "+0000",
+		},
+		{name: "date only", value: "2023-11-15", wantTimestamp: "1699999200", wantTimezone: "+0200"},
+		{name: "empty", value: "", wantErr: ErrInvalidCommitDate},
+		{name: "garbage", value: "yesterday", wantErr: ErrInvalidCommitDate},
+		{name: "raw without timezone", value: "1700000000", wantErr: ErrInvalidCommitDate},
+		{name: "raw with bad timezone", value: "1700000000 +2500", wantErr: ErrInvalidCommitTimezone},
+		{name: "raw with short timezone", value: "1700000000 +05", wantErr: ErrInvalidCommitTimezone},
+		{name: "at with bad timezone", value: "@1700000000 0530", wantErr: ErrInvalidCommitTimezone},
+		{name: "at without timestamp", value: "@", wantErr: ErrInvalidCommitTimestamp},
+		{name: "at with garbage", value: "@noon", wantErr: ErrInvalidCommitTimestamp},
+		{name: "at with garbage and timezone", value: "@noon +0000", wantErr: ErrInvalidCommitTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				timestamp, timezone, err := ParseCommitDate(tt.value)
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantTimestamp, timestamp)
+				assert.Equal(t, tt.wantTimezone, timezone)
+			},
+		)
+	}
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidUntrackedCache is returned when the untracked-cache index extension cannot be parsed.
+	ErrInvalidUntrackedCache = errors.New("invalid untracked-cache extension")
+)
+
+const (
+	// UntrackedCacheSignature identifies the untracked-cache extension in the index file.
+	UntrackedCacheSignature = "UNTR"
+)
+
+// UntrackedCache remembers the filtered listing of every working tree
+// directory so a scan can skip reading directories whose modification time
+// has not changed since they were last listed.
+//
+// Listings depend on ignore rules as well as directory contents, so each
+// directory also records the stat of its own .gelignore file and the whole
+// cache is tied to a fingerprint of the repository-wide exclude sources.
+type UntrackedCache struct {
+	// ExcludesHash fingerprints the global excludes file and .gel/info/exclude.
+	ExcludesHash Hash
+	// Dirs maps repository-relative directory paths, "" for the root, to their listings.
+	Dirs map[string]*UntrackedCacheDir
+}
+
+// UntrackedCacheDir is the cached listing of one directory.
+type UntrackedCacheDir struct {
+	// ModifiedTime is the directory mtime, in nanoseconds, observed before it was listed.
+	ModifiedTime int64
+	// IgnoreModifiedTime is the mtime of the directory's .gelignore in nanoseconds, or 0 when absent.
+	IgnoreModifiedTime int64
+	// IgnoreSize is the size of the directory's .gelignore, or 0 when absent.
+	IgnoreSize int64
+	// Files lists the names of non-ignored entries that are not directories.
+	Files []string
+	// Dirs lists the names of non-ignored subdirectories.
+	Dirs []string
+	// FSMonitorValid records that the filesystem monitor vouches for the
+	// listing, so a scan may use it without checking the directory mtime.
+	// It is derived from the monitor on every index read and never persisted.
+	FSMonitorValid bool
+}
+
+// NewUntrackedCache returns an empty cache tied to excludesHash.
+func NewUntrackedCache(excludesHash Hash) *UntrackedCache {
+	return &UntrackedCache{
+		ExcludesHash: excludesHash,
+		Dirs:         make(map[string]*UntrackedCacheDir),
+	}
+}
+
+// Clone returns a deep copy of the cache.
+func (u *UntrackedCache) Clone() *UntrackedCache {
+	if u == nil {
+		return nil
+	}
+	cloned := NewUntrackedCache(u.ExcludesHash)
+	for path, dir := range u.Dirs {
+		cloned.Dirs[path] = &UntrackedCacheDir{
+			ModifiedTime:       dir.ModifiedTime,
+			IgnoreModifiedTime: dir.IgnoreModifiedTime,
+			IgnoreSize:         dir.IgnoreSize,
+			Files:              slices.Clone(dir.Files),
+			Dirs:               slices.Clone(dir.Dirs),
+			FSMonitorValid:     dir.FSMonitorValid,
+		}
+	}
+	return cloned
+}
+
+// Serialize encodes the cache as the excludes hash followed by one record per
+// directory, sorted by path:
+//
+//	<path> NUL <mtime> SP <ignore mtime> SP <ignore size> SP <file count> SP <dir count> LF
+//	<file name> NUL ... <dir name> NUL ...
+func (u *UntrackedCache) Serialize() []byte {
+	var buffer bytes.Buffer
+	buffer.Write(u.ExcludesHash[:])
+
+	paths := make([]string, 0, len(u.Dirs))
+	for path := range u.Dirs {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		dir := u.Dirs[path]
+		fmt.Fprintf(
+			&buffer, "%s\x00%d %d %d %d %d\n",
+			path, dir.ModifiedTime, dir.IgnoreModifiedTime, dir.IgnoreSize, len(dir.Files), len(dir.Dirs),
+		)
+		for _, name := range dir.Files {
+			buffer.WriteString(name)
+			buffer.WriteByte(0)
+		}
+		for _, name := range dir.Dirs {
+			buffer.WriteString(name)
+			buffer.WriteByte(0)
+		}
+	}
+	return buffer.Bytes()
+}
+
+// DeserializeUntrackedCache parses data produced by Serialize.
+func DeserializeUntrackedCache(data []byte) (*UntrackedCache, error) {
+	if len(data) < SHA256ByteLength {
+		return nil, fmt.Errorf("%w: truncated excludes hash", ErrInvalidUntrackedCache)
+	}
+	var excludesHash Hash
+	copy(excludesHash[:], data[:SHA256ByteLength])
+	data = data[SHA256ByteLength:]
+
+	cache := NewUntrackedCache(excludesHash)
+	for len(data) > 0 {
+		pathEnd := bytes.IndexByte(data, 0)
+		if pathEnd < 0 {
+			return nil, fmt.Errorf("%w: unterminated path", ErrInvalidUntrackedCache)
+		}
+		path := string(data[:pathEnd])
+		data = data[pathEnd+1:]
+
+		lineEnd := bytes.IndexByte(data, '\n')
+		if lineEnd < 0 {
+			return nil, fmt.Errorf("%w: unterminated fields for '%s'", ErrInvalidUntrackedCache, path)
+		}
+		fields := strings.Fields(string(data[:lineEnd]))
+		data = data[lineEnd+1:]
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("%w: malformed fields for '%s'", ErrInvalidUntrackedCache, path)
+		}
+		values := make([]int64, len(fields))
+		for i, field := range fields {
+			value, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: field for '%s': %v", ErrInvalidUntrackedCache, path, err)
+			}
+			values[i] = value
+		}
+		if values[3] < 0 || values[4] < 0 {
+			return nil, fmt.Errorf("%w: negative count for '%s'", ErrInvalidUntrackedCache, path)
+		}
+
+		dir := &UntrackedCacheDir{
+			ModifiedTime:       values[0],
+			IgnoreModifiedTime: values[1],
+			IgnoreSize:         values[2],
+		}
+		var err error
+		if dir.Files, data, err = readUntrackedCacheNames(data, values[3], path); err != nil {
+			return nil, err
+		}
+		if dir.Dirs, data, err = readUntrackedCacheNames(data, values[4], path); err != nil {
+			return nil, err
+		}
+		cache.Dirs[path] = dir
+	}
+	return cache, nil
+}
+
+// readUntrackedCacheNames reads count NUL-terminated names of directory path and returns the unread data.
+func readUntrackedCacheNames(data []byte, count int64, path string) ([]string, []byte, error) {
+	names := make([]string, 0, min(count, int64(len(data))))
+	for range count {
+		nameEnd := bytes.IndexByte(data, 0)
+		if nameEnd < 0 {
+			return nil, nil, fmt.Errorf("%w: truncated listing of '%s'", ErrInvalidUntrackedCache, path)
+		}
+		names = append(names, string(data[:nameEnd]))
+		data = data[nameEnd+1:]
+	}
+	return names, data, nil
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUntrackedCache returns a cache with the root, a nested directory
+// with a .gelignore, and an empty directory.
+func newTestUntrackedCache(t *testing.T) *UntrackedCache {
+	t.Helper()
+	excludesHash, err := NewHashFromHex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	cache := NewUntrackedCache(excludesHash)
+	cache.Dirs[""] = &UntrackedCacheDir{
+		ModifiedTime: 1700000000000000000,
+		Files:        []string{"a.txt", "with space.txt"},
+		Dirs:         []string{"src", "empty"},
+	}
+	cache.Dirs["src"] = &UntrackedCacheDir{
+		ModifiedTime:       1700000000000000001,
+		IgnoreModifiedTime: 1690000000000000000,
+		IgnoreSize:         12,
+		Files:              []string{"main.go"},
+		Dirs:               []string{},
+	}
+	cache.Dirs["empty"] = &UntrackedCacheDir{ModifiedTime: -1, Files: []string{}, Dirs: []string{}}
+	return cache
+}
+
+func TestUntrackedCacheRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache func(t *testing.T) *UntrackedCache
+	}{
+		{
+			name: "no directories",
+			cache: func(t *testing.T) *UntrackedCache {
+				return NewUntrackedCache(Hash{})
+			},
+		},
+		{name: "directories", cache: newTestUntrackedCache},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				cache := tt.cache(t)
+
+				parsed, err := DeserializeUntrackedCache(cache.Serialize())
+
+				require.NoError(t, err)
+				assert.Equal(t, cache, parsed)
+				assert.Equal(t, cache.Serialize(), parsed.Serialize())
+			},
+		)
+	}
+}
+
+func TestUntrackedCacheSerializeDropsFSMonitorValid(t *testing.T) {
+	cache := newTestUntrackedCache(t)
+	cache.Dirs["src"].FSMonitorValid = true
+
+	parsed, err := DeserializeUntrackedCache(cache.Serialize())
+
+	require.NoError(t, err)
+	assert.False(t, parsed.Dirs["src"].FSMonitorValid)
+}
+
+func TestDeserializeUntrackedCacheRejectsCorruptData(t *testing.T) {
+	hash := strings.Repeat("\x01", SHA256ByteLength)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "truncated excludes hash", data: hash[:SHA256ByteLength-1]},
+		{name: "unterminated path", data: hash + "src"},
+		{name: "unterminated fields", data: hash + "src\x001 0 0 0 0"},
+		{name: "missing field", data: hash + "src\x001 0 0 0\n"},
+		{name: "extra field", data: hash + "src\x001 0 0 0 0 0\n"},
+		{name: "non-numeric field", data: hash + "src\x00mtime 0 0 0 0\n"},
+		{name: "overflowing field", data: hash + "src\x0099999999999999999999 0 0 0 0\n"},
+		{name: "negative file count", data: hash + "src\x001 0 0 -1 0\n"},
+		{name: "negative dir count", data: hash + "src\x001 0 0 0 -1\n"},
+		{name: "truncated files", data: hash + "src\x001 0 0 2 0\na.txt\x00"},
+		{name: "unterminated file name", data: hash + "src\x001 0 0 1 0\na.txt"},
+		{name: "truncated dirs", data: hash + "src\x001 0 0 0 1\n"},
+		{name: "huge count", data: hash + "src\x001 0 0 9223372036854775807 0\na\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := DeserializeUntrackedCache([]byte(tt.data))
+
+				require.ErrorIs(t, err, ErrInvalidUntrackedCache)
+			},
+		)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var (
@@ -19,6 +20,10 @@ var (
 	// ErrInvalidGelRepository is returned when .gel exists but cannot be used as
 	// a repository metadata directory.
 	ErrInvalidGelRepository = errors.New("invalid Gel repository")
+
+	// ErrBareRepository is returned when an operation that needs a working tree
+	// runs in a bare repository.
+	ErrBareRepository = errors.New("this operation must be run in a work tree")
 )
 
 // Workspace represents the absolute, OS-native paths for a Gel repository.
@@ -26,13 +31,34 @@ var (
 // It is a layout descriptor only: constructing a Workspace discovers the
 // repository root and derives standard metadata paths, but it does not verify
 // that every metadata file exists or is readable.
+//
+// A bare repository has no working tree: its directory is itself the metadata
+// directory, Bare is true, and RepoDir is the zero AbsolutePath.
+//
+// A linked worktree (gel worktree add) has a .gel file instead of a directory,
+// naming its own metadata directory below .gel/worktrees of the repository.
+// That directory holds the worktree's HEAD and index; objects, refs and
+// config live in the shared CommonDir.
 type Workspace struct {
-	// RepoDir is the repository root directory, which is the parent of GelDir.
+	// RepoDir is the working tree root directory, which holds .gel. It is
+	// empty for bare repositories.
 	RepoDir AbsolutePath
 
-	// GelDir is the .gel repository metadata directory.
+	// GelDir is the per-worktree metadata directory holding HEAD and the
+	// index: .gel for the main working tree, .gel/worktrees/<name> for a
+	// linked worktree, or the repository directory itself when Bare is true.
 	GelDir AbsolutePath
 
+	// CommonDir is the metadata directory shared by all worktrees, holding
+	// objects, refs and config. It equals GelDir outside linked worktrees.
+	CommonDir AbsolutePath
+
+	// Bare reports whether the repository has no working tree.
+	Bare bool
+
+	// WorktreesDir is the .gel/worktrees directory of linked worktree metadata.
+	WorktreesDir AbsolutePath
+
 	// ObjectsDir is the .gel/objects object storage directory.
 	ObjectsDir AbsolutePath
 
@@ -50,15 +76,24 @@ type Workspace struct {
 
 	// ConfigPath is the .gel/config.toml repository config file path.
 	ConfigPath AbsolutePath
+
+	// ShallowPath is the .gel/shallow graft file path.
+	ShallowPath AbsolutePath
+
+	// LFSObjectsDir is the .gel/lfs/objects large file content store.
+	LFSObjectsDir AbsolutePath
 }
 
 // NewWorkspace searches upward from startPath for .gel and returns a Workspace
 // with absolute repository paths.
 //
 // startPath may be relative or absolute. The returned Workspace contains paths
-// derived from the first .gel directory found at startPath or one of its
-// parents. If a .gel path exists but is not a directory, discovery fails with
-// ErrInvalidGelRepository instead of continuing to a parent repository.
+// derived from the first .gel found at startPath or one of its parents. A .gel
+// file marks a linked worktree and names its metadata directory. A directory
+// that has no .gel but itself contains HEAD, objects, and refs is discovered
+// as a bare repository. If a .gel path exists but is neither a directory nor a
+// valid .gel file, discovery fails with ErrInvalidGelRepository instead of
+// continuing to a parent repository.
 func NewWorkspace(startPath string) (*Workspace, error) {
 	if startPath == "" {
 		return nil, fmt.Errorf("%w: empty path", ErrInvalidWorkspacePath)
@@ -69,19 +104,101 @@ func NewWorkspace(startPath string) (*Workspace, error) {
 		return nil, fmt.Errorf("workspace: resolve start path %q: %w", startPath, err)
 	}
 
-	gelDir, err := findGelDir(absStartPath)
+	repoDir, gelDir, err := findGelDir(absStartPath)
 	if err != nil {
 		return nil, err
 	}
-	return newWorkspaceFromGelDir(gelDir)
+	return newWorkspaceFromGelDir(repoDir, gelDir)
 }
 
-// newWorkspaceFromGelDir derives all standard repository paths from gelDir.
-// gelDir must be an absolute path to an existing .gel directory.
-func newWorkspaceFromGelDir(gelDir string) (*Workspace, error) {
-	repoDir, err := newWorkspaceAbsolutePath(filepath.Dir(gelDir))
+// NewWorkspaceFromGelDir returns a Workspace for an explicit metadata directory,
+// as named by the GEL_DIR environment variable, without upward discovery.
+//
+// When the directory is named .gel, its parent is the working tree. The
+// metadata directory of a linked worktree names its working tree in its
+// geldir file. Any other directory is treated as a bare repository. The
+// directory must exist.
+func NewWorkspaceFromGelDir(gelDir string) (*Workspace, error) {
+	if gelDir == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidWorkspacePath)
+	}
+
+	absGelDir, err := filepath.Abs(gelDir)
+	if err != nil {
+		return nil, fmt.Errorf("workspace: resolve %s %q: %w", GelDirEnvVar, gelDir, err)
+	}
+
+	info, err := os.Stat(absGelDir)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s=%q does not exist", ErrNotAGelRepository, GelDirEnvVar, gelDir)
+		}
+		return nil, fmt.Errorf("workspace: stat %q: %w", absGelDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %q is not a directory", ErrInvalidGelRepository, absGelDir)
+	}
+	repoDir := ""
+	if filepath.Base(absGelDir) == GelDirName {
+		repoDir = filepath.Dir(absGelDir)
+	} else if gelFile, ok, err := readLinkFile(filepath.Join(absGelDir, GelDirFileName), ""); err != nil {
 		return nil, err
+	} else if ok {
+		repoDir = filepath.Dir(gelFile)
+	}
+	return newWorkspaceFromGelDir(repoDir, absGelDir)
+}
+
+// OpenWorkspace returns the Workspace whose working tree root is exactly dir,
+// without upward discovery, and reports false when dir holds no .gel. It is
+// used to look into the repository of a submodule.
+func OpenWorkspace(dir string) (*Workspace, bool, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false, fmt.Errorf("workspace: resolve path %q: %w", dir, err)
+	}
+	gelPath := filepath.Join(absDir, GelDirName)
+	info, err := os.Stat(gelPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("workspace: stat %q: %w", gelPath, err)
+	}
+	gelDir := gelPath
+	if !info.IsDir() {
+		gelDir, err = readGelFile(gelPath)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	workspace, err := newWorkspaceFromGelDir(absDir, gelDir)
+	if err != nil {
+		return nil, false, err
+	}
+	return workspace, true, nil
+}
+
+// RequireWorkTree returns ErrBareRepository when the repository has no working tree.
+func (w *Workspace) RequireWorkTree() error {
+	if w.Bare {
+		return ErrBareRepository
+	}
+	return nil
+}
+
+// newWorkspaceFromGelDir derives all standard repository paths from gelDir.
+// gelDir must be an absolute path to an existing metadata directory; repoDir
+// is its working tree, or empty for bare repositories. Shared paths come from
+// the directory named by gelDir's commondir file when there is one.
+func newWorkspaceFromGelDir(repoDir string, gelDir string) (*Workspace, error) {
+	var repoPath AbsolutePath
+	if repoDir != "" {
+		var err error
+		repoPath, err = newWorkspaceAbsolutePath(repoDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	gelPath, err := newWorkspaceAbsolutePath(gelDir)
@@ -89,17 +206,34 @@ func newWorkspaceFromGelDir(gelDir string) (*Workspace, error) {
 		return nil, err
 	}
 
-	objectsDir, err := newWorkspaceAbsolutePath(filepath.Join(gelDir, ObjectsDirName))
+	commonDir, ok, err := readLinkFile(filepath.Join(gelDir, CommonDirFileName), gelDir)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		commonDir = gelDir
+	}
+	commonPath, err := newWorkspaceAbsolutePath(commonDir)
 	if err != nil {
 		return nil, err
 	}
 
-	refsDir, err := newWorkspaceAbsolutePath(filepath.Join(gelDir, RefsDirName))
+	worktreesDir, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, WorktreesDirName))
 	if err != nil {
 		return nil, err
 	}
 
-	headsDir, err := newWorkspaceAbsolutePath(filepath.Join(gelDir, RefsDirName, HeadsDirName))
+	objectsDir, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, ObjectsDirName))
+	if err != nil {
+		return nil, err
+	}
+
+	refsDir, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, RefsDirName))
+	if err != nil {
+		return nil, err
+	}
+
+	headsDir, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, RefsDirName, HeadsDirName))
 	if err != nil {
 		return nil, err
 	}
@@ -114,19 +248,34 @@ func newWorkspaceFromGelDir(gelDir string) (*Workspace, error) {
 		return nil, err
 	}
 
-	configPath, err := newWorkspaceAbsolutePath(filepath.Join(gelDir, ConfigFileName))
+	configPath, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, ConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	shallowPath, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, ShallowFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	lfsObjectsDir, err := newWorkspaceAbsolutePath(filepath.Join(commonDir, LFSDirName, ObjectsDirName))
 	if err != nil {
 		return nil, err
 	}
 	return &Workspace{
-		RepoDir:    repoDir,
-		GelDir:     gelPath,
-		ObjectsDir: objectsDir,
-		RefsDir:    refsDir,
-		HeadsDir:   headsDir,
-		HeadPath:   headPath,
-		IndexPath:  indexPath,
-		ConfigPath: configPath,
+		RepoDir:       repoPath,
+		GelDir:        gelPath,
+		CommonDir:     commonPath,
+		WorktreesDir:  worktreesDir,
+		Bare:          repoDir == "",
+		ObjectsDir:    objectsDir,
+		RefsDir:       refsDir,
+		HeadsDir:      headsDir,
+		HeadPath:      headPath,
+		IndexPath:     indexPath,
+		ConfigPath:    configPath,
+		ShallowPath:   shallowPath,
+		LFSObjectsDir: lfsObjectsDir,
 	}, nil
 }
 
@@ -138,28 +287,110 @@ func newWorkspaceAbsolutePath(path string) (AbsolutePath, error) {
 	return absPath, nil
 }
 
-// findGelDir walks upward from startPath and returns the first .gel directory.
-// startPath must be absolute. If no .gel directory exists before the filesystem
-// root, it returns ErrNotAGelRepository.
-func findGelDir(startPath string) (string, error) {
+// findGelDir walks upward from startPath and returns the working tree and
+// metadata directory of the first .gel found, or of the first directory laid
+// out as a bare repository, whose working tree is empty. startPath must be
+// absolute. If neither exists before the filesystem root, it returns
+// ErrNotAGelRepository.
+func findGelDir(startPath string) (string, string, error) {
 	currentPath := startPath
 	for {
 		gelPath := filepath.Join(currentPath, GelDirName)
 		info, err := os.Stat(gelPath)
 		if err == nil {
-			if !info.IsDir() {
-				return "", fmt.Errorf("%w: %q is not a directory", ErrInvalidGelRepository, gelPath)
+			if info.IsDir() {
+				return currentPath, gelPath, nil
 			}
-			return gelPath, nil
+			gelDir, err := readGelFile(gelPath)
+			if err != nil {
+				return "", "", err
+			}
+			return currentPath, gelDir, nil
 		}
 		if !errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("workspace: stat %q: %w", gelPath, err)
+			return "", "", fmt.Errorf("workspace: stat %q: %w", gelPath, err)
+		}
+
+		bare, err := isBareRepository(currentPath)
+		if err != nil {
+			return "", "", err
+		}
+		if bare {
+			// Discovery from inside a non-bare .gel directory still belongs to
+			// the working tree that owns it.
+			if filepath.Base(currentPath) == GelDirName {
+				return filepath.Dir(currentPath), currentPath, nil
+			}
+			return "", currentPath, nil
 		}
 
 		parentPath := filepath.Dir(currentPath)
 		if parentPath == currentPath {
-			return "", ErrNotAGelRepository
+			return "", "", ErrNotAGelRepository
 		}
 		currentPath = parentPath
 	}
 }
+
+// readGelFile resolves the .gel file of a linked worktree to its metadata directory.
+func readGelFile(gelPath string) (string, error) {
+	gelDir, ok, err := readLinkFile(gelPath, filepath.Dir(gelPath))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %q is not a directory", ErrInvalidGelRepository, gelPath)
+	}
+	info, err := os.Stat(gelDir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: %q points to missing %q", ErrInvalidGelRepository, gelPath, gelDir)
+	}
+	return gelDir, nil
+}
+
+// readLinkFile reads a file holding one path, optionally after
+// GelDirFilePrefix, and resolves it against relativeTo. It reports false when
+// the file does not exist.
+func readLinkFile(path string, relativeTo string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("workspace: read %q: %w", path, err)
+	}
+	target := strings.TrimPrefix(strings.TrimSpace(string(data)), GelDirFilePrefix)
+	if target == "" {
+		return "", false, fmt.Errorf("%w: %q is empty", ErrInvalidGelRepository, path)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(relativeTo, target)
+	}
+	return filepath.Clean(target), true, nil
+}
+
+// isBareRepository reports whether path directly contains a HEAD file and the
+// objects and refs directories.
+func isBareRepository(path string) (bool, error) {
+	required := []struct {
+		name  string
+		isDir bool
+	}{
+		{name: HeadFileName, isDir: false},
+		{name: ObjectsDirName, isDir: true},
+		{name: RefsDirName, isDir: true},
+	}
+	for _, entry := range required {
+		info, err := os.Stat(filepath.Join(path, entry.name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return false, fmt.Errorf("workspace: stat %q: %w", filepath.Join(path, entry.name), err)
+		}
+		if info.IsDir() != entry.isDir {
+			return false, nil
+		}
+	}
+	return true, nil
+}
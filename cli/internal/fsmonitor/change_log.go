@@ -0,0 +1,102 @@
+package fsmonitor
+
+import (
+	"Gel/internal/core"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxChangeLogEvents bounds the events kept in memory; older tokens get a full rescan.
+	maxChangeLogEvents = 1 << 16
+	// tokenPrefix starts every token issued by the built-in daemon.
+	tokenPrefix = "gel"
+)
+
+// change is one path reported by the watcher.
+type change struct {
+	seq  uint64
+	path string
+}
+
+// changeLog numbers the changes the watcher reports and answers which paths
+// changed since a token. Tokens name the daemon instance and a sequence
+// number, so tokens of an earlier daemon are answered with a full rescan.
+type changeLog struct {
+	mutex   sync.Mutex
+	id      string
+	seq     uint64
+	floor   uint64
+	changes []change
+}
+
+// newChangeLog creates an empty log with a fresh instance id.
+func newChangeLog() *changeLog {
+	return &changeLog{id: strconv.FormatInt(time.Now().UnixNano(), 10)}
+}
+
+// record appends a changed path. core.FSMonitorAllPaths forgets every change,
+// so all earlier tokens are answered with a full rescan.
+func (l *changeLog) record(path string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.seq++
+	if path == core.FSMonitorAllPaths {
+		l.changes = nil
+		l.floor = l.seq
+		return
+	}
+	l.changes = append(l.changes, change{seq: l.seq, path: path})
+	if len(l.changes) > maxChangeLogEvents {
+		dropped := len(l.changes) / 2
+		l.floor = l.changes[dropped-1].seq
+		l.changes = slices.Clone(l.changes[dropped:])
+	}
+}
+
+// since returns the paths changed after token together with a new token.
+func (l *changeLog) since(token string) core.FSMonitorResult {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	result := core.FSMonitorResult{Token: l.token()}
+	seq, ok := l.parseToken(token)
+	if !ok || seq < l.floor || seq > l.seq {
+		result.All = true
+		return result
+	}
+
+	seen := make(map[string]bool)
+	for i := len(l.changes) - 1; i >= 0 && l.changes[i].seq > seq; i-- {
+		path := l.changes[i].path
+		if !seen[path] {
+			seen[path] = true
+			result.Paths = append(result.Paths, path)
+		}
+	}
+	slices.Sort(result.Paths)
+	return result
+}
+
+// token returns the token for the current sequence number.
+func (l *changeLog) token() string {
+	return fmt.Sprintf("%s:%s:%d", tokenPrefix, l.id, l.seq)
+}
+
+// parseToken returns the sequence number of a token issued by this instance.
+func (l *changeLog) parseToken(token string) (uint64, bool) {
+	fields := strings.Split(token, ":")
+	if len(fields) != 3 || fields[0] != tokenPrefix || fields[1] != l.id {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
@@ -0,0 +1,82 @@
+package fsmonitor
+
+import (
+	"Gel/internal/core"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenAt returns the token log issues at sequence number seq.
+func tokenAt(log *changeLog, seq uint64) string {
+	return fmt.Sprintf("%s:%s:%d", tokenPrefix, log.id, seq)
+}
+
+func TestChangeLogSince(t *testing.T) {
+	log := newChangeLog()
+	log.record("a.txt")
+	log.record("dir/b.txt")
+	log.record("a.txt")
+
+	tests := []struct {
+		name      string
+		token     string
+		wantAll   bool
+		wantPaths []string
+	}{
+		{name: "empty token", token: "", wantAll: true},
+		{name: "other daemon", token: tokenPrefix + ":other:1", wantAll: true},
+		{name: "other prefix", token: "git:" + log.id + ":1", wantAll: true},
+		{name: "missing field", token: tokenPrefix + ":" + log.id, wantAll: true},
+		{name: "extra field", token: tokenAt(log, 1) + ":1", wantAll: true},
+		{name: "non-numeric sequence", token: tokenPrefix + ":" + log.id + ":x", wantAll: true},
+		{name: "negative sequence", token: tokenPrefix + ":" + log.id + ":-1", wantAll: true},
+		{name: "sequence ahead", token: tokenAt(log, 4), wantAll: true},
+		{name: "start", token: tokenAt(log, 0), wantPaths: []string{"a.txt", "dir/b.txt"}},
+		{name: "repeated path", token: tokenAt(log, 1), wantPaths: []string{"a.txt", "dir/b.txt"}},
+		{name: "last change", token: tokenAt(log, 2), wantPaths: []string{"a.txt"}},
+		{name: "current", token: tokenAt(log, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				result := log.since(tt.token)
+
+				assert.Equal(t, tokenAt(log, 3), result.Token)
+				assert.Equal(t, tt.wantAll, result.All)
+				assert.Equal(t, tt.wantPaths, result.Paths)
+			},
+		)
+	}
+}
+
+func TestChangeLogAllPathsRaisesFloor(t *testing.T) {
+	log := newChangeLog()
+	log.record("a.txt")
+	log.record(core.FSMonitorAllPaths)
+	log.record("b.txt")
+
+	assert.True(t, log.since(tokenAt(log, 1)).All)
+	result := log.since(tokenAt(log, 2))
+	assert.False(t, result.All)
+	assert.Equal(t, []string{"b.txt"}, result.Paths)
+}
+
+func TestChangeLogOverflowRaisesFloor(t *testing.T) {
+	log := newChangeLog()
+	for i := range maxChangeLogEvents + 1 {
+		log.record(fmt.Sprintf("file%d", i))
+	}
+	require.Len(t, log.changes, maxChangeLogEvents+1-maxChangeLogEvents/2)
+	floor := uint64(maxChangeLogEvents / 2)
+	require.Equal(t, floor, log.floor)
+
+	assert.True(t, log.since(tokenAt(log, 0)).All)
+	assert.True(t, log.since(tokenAt(log, floor-1)).All)
+	result := log.since(tokenAt(log, floor))
+	assert.False(t, result.All)
+	assert.Len(t, result.Paths, len(log.changes))
+	assert.Equal(t, tokenAt(log, maxChangeLogEvents+1), result.Token)
+}
@@ -0,0 +1,195 @@
+package fsmonitor
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DaemonCommandName is the command that runs the built-in daemon.
+	DaemonCommandName = "fsmonitor--daemon"
+	// DaemonRunCommandName is the subcommand that runs the daemon in the foreground.
+	DaemonRunCommandName = "run"
+	// daemonLogFileName receives the output of a daemon started in the background.
+	daemonLogFileName = "fsmonitor--daemon.log"
+
+	// daemonStartTimeout bounds how long Start and Stop wait for the daemon.
+	daemonStartTimeout = 5 * time.Second
+	// daemonPollInterval is how often Start and Stop check on the daemon.
+	daemonPollInterval = 50 * time.Millisecond
+	// requestTimeout bounds one request from a client.
+	requestTimeout = 5 * time.Second
+)
+
+// DaemonService runs the built-in filesystem monitor: a background process
+// that watches the working tree and answers "what changed since token X" on a
+// socket in the metadata directory. Enable it for status and friends with
+// core.fsmonitor = true.
+type DaemonService struct {
+	fsMonitor *core.FSMonitor
+	workspace *domain.Workspace
+}
+
+// NewDaemonService creates a daemon service.
+func NewDaemonService(fsMonitor *core.FSMonitor, workspace *domain.Workspace) *DaemonService {
+	return &DaemonService{
+		fsMonitor: fsMonitor,
+		workspace: workspace,
+	}
+}
+
+// Running reports whether a daemon answers on the repository's socket.
+func (d *DaemonService) Running() bool {
+	conn, err := net.DialTimeout("unix", d.fsMonitor.SocketPath(), daemonPollInterval)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Start launches the daemon in the background and waits until it listens.
+func (d *DaemonService) Start() error {
+	if err := d.workspace.RequireWorkTree(); err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	if d.Running() {
+		return fmt.Errorf("fsmonitor--daemon: %w", ErrDaemonRunning)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	logPath := filepath.Join(d.workspace.GelDir.String(), daemonLogFileName)
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, DaemonCommandName, DaemonRunCommandName)
+	cmd.Dir = d.workspace.RepoDir.String()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		if d.Running() {
+			return nil
+		}
+		select {
+		case <-exited:
+			output, _ := os.ReadFile(logPath)
+			return fmt.Errorf(
+				"fsmonitor--daemon: %w: %s", ErrDaemonStartFailed, strings.TrimSpace(string(output)),
+			)
+		case <-time.After(daemonPollInterval):
+		}
+	}
+	return fmt.Errorf("fsmonitor--daemon: %w: not listening after %s", ErrDaemonStartFailed, daemonStartTimeout)
+}
+
+// Stop asks the running daemon to exit and waits until it has.
+func (d *DaemonService) Stop() error {
+	conn, err := net.DialTimeout("unix", d.fsMonitor.SocketPath(), daemonPollInterval)
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", ErrDaemonNotRunning)
+	}
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout))
+	_, err = fmt.Fprintf(conn, "%s\n", core.FSMonitorStopCommand)
+	if err == nil {
+		_, err = io.ReadAll(conn)
+	}
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for d.Running() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("fsmonitor--daemon: daemon did not stop after %s", daemonStartTimeout)
+		}
+		time.Sleep(daemonPollInterval)
+	}
+	return nil
+}
+
+// Run watches the working tree and serves queries until asked to stop.
+func (d *DaemonService) Run() error {
+	if err := d.workspace.RequireWorkTree(); err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	if d.Running() {
+		return fmt.Errorf("fsmonitor--daemon: %w", ErrDaemonRunning)
+	}
+
+	// Watch before listening so no answered token predates the watches.
+	changes := newChangeLog()
+	stopWatching, err := watchRepository(d.workspace.RepoDir.String(), changes.record)
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	defer stopWatching()
+
+	socketPath := d.fsMonitor.SocketPath()
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("fsmonitor--daemon: %w", err)
+	}
+	defer os.Remove(socketPath)
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return fmt.Errorf("fsmonitor--daemon: %w", err)
+		}
+		if stop := d.serve(conn, changes); stop {
+			return nil
+		}
+	}
+}
+
+// serve answers one request and reports whether the daemon should stop.
+func (d *DaemonService) serve(conn net.Conn, changes *changeLog) bool {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	command, argument, _ := strings.Cut(strings.TrimSuffix(line, "\n"), " ")
+	switch command {
+	case core.FSMonitorQueryCommand:
+		_, _ = conn.Write(core.FormatFSMonitorResponse(changes.since(argument)))
+	case core.FSMonitorStopCommand:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package fsmonitor
+
+import "errors"
+
+var (
+	// ErrDaemonRunning is returned when a daemon already watches the repository.
+	ErrDaemonRunning = errors.New("fsmonitor daemon is already running")
+
+	// ErrDaemonNotRunning is returned when no daemon watches the repository.
+	ErrDaemonNotRunning = errors.New("fsmonitor daemon is not running")
+
+	// ErrDaemonStartFailed is returned when a started daemon exits or never starts listening.
+	ErrDaemonStartFailed = errors.New("fsmonitor daemon failed to start")
+
+	// ErrUnsupportedPlatform is returned when the built-in daemon cannot watch files on this platform.
+	ErrUnsupportedPlatform = errors.New("fsmonitor daemon is not supported on this platform")
+)
@@ -0,0 +1,170 @@
+//go:build linux
+
+package fsmonitor
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// inotifyMask selects the events that can change a path's content, metadata or presence.
+	inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+	// inotifyBufferSize holds many events per read.
+	inotifyBufferSize = 64 * 1024
+)
+
+// metadataDirNames are never watched; changes inside them are not working tree changes.
+var metadataDirNames = map[string]bool{
+	domain.GelDirName: true,
+	".git":            true,
+}
+
+// inotifyWatcher reports changes below repoDir through inotify watches on every directory.
+type inotifyWatcher struct {
+	fd      int
+	repoDir string
+	record  func(path string)
+
+	mutex   sync.Mutex
+	watches map[int32]string
+}
+
+// watchRepository watches every directory below repoDir and calls record with
+// the repository-relative path of each change, or core.FSMonitorAllPaths when
+// events were lost. The returned function stops watching.
+func watchRepository(repoDir string, record func(path string)) (func(), error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotifyWatcher{
+		fd:      fd,
+		repoDir: repoDir,
+		record:  record,
+		watches: make(map[int32]string),
+	}
+	if err := w.addTree("", false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	go w.run()
+	return func() { syscall.Close(fd) }, nil
+}
+
+// addTree watches dir and every directory below it. With report set, every
+// path found is recorded, since files created before the watch existed
+// produced no events.
+func (w *inotifyWatcher) addTree(dir string, report bool) error {
+	root := filepath.Join(w.repoDir, filepath.FromSlash(dir))
+	return filepath.WalkDir(
+		root, func(walkPath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && walkPath != w.repoDir {
+					return nil
+				}
+				return err
+			}
+			relPath, err := filepath.Rel(w.repoDir, walkPath)
+			if err != nil {
+				return err
+			}
+			relPath = filepath.ToSlash(relPath)
+			if relPath == "." {
+				relPath = ""
+			}
+			if metadataDirNames[d.Name()] && relPath != "" {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if report && relPath != dir {
+				w.record(relPath)
+			}
+			if !d.IsDir() {
+				return nil
+			}
+
+			wd, err := syscall.InotifyAddWatch(w.fd, walkPath, inotifyMask|syscall.IN_ONLYDIR)
+			if err != nil {
+				if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENOTDIR) {
+					return nil
+				}
+				return err
+			}
+			w.mutex.Lock()
+			w.watches[int32(wd)] = relPath
+			w.mutex.Unlock()
+			return nil
+		},
+	)
+}
+
+// run reads events until the inotify descriptor is closed.
+func (w *inotifyWatcher) run() {
+	buffer := make([]byte, inotifyBufferSize)
+	for {
+		n, err := syscall.Read(w.fd, buffer)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil || n <= 0 {
+			return
+		}
+
+		offset := 0
+		for offset+syscall.SizeofInotifyEvent <= n {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if nameEnd > n {
+				break
+			}
+			name := strings.TrimRight(string(buffer[nameStart:nameEnd]), "\x00")
+			w.handle(event, name)
+			offset = nameEnd
+		}
+	}
+}
+
+// handle records the path of one event and watches directories that appear.
+func (w *inotifyWatcher) handle(event *syscall.InotifyEvent, name string) {
+	if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+		w.record(core.FSMonitorAllPaths)
+		return
+	}
+
+	w.mutex.Lock()
+	dir, ok := w.watches[event.Wd]
+	if event.Mask&syscall.IN_IGNORED != 0 {
+		delete(w.watches, event.Wd)
+	}
+	w.mutex.Unlock()
+	if !ok || event.Mask&syscall.IN_IGNORED != 0 || metadataDirNames[name] {
+		return
+	}
+
+	relPath := path.Join(dir, name)
+	if relPath == "" {
+		// The repository root itself was deleted or moved.
+		w.record(core.FSMonitorAllPaths)
+		return
+	}
+	w.record(relPath)
+
+	if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		if err := w.addTree(relPath, true); err != nil {
+			w.record(core.FSMonitorAllPaths)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package fsmonitor
+
+// watchRepository reports that the built-in daemon needs inotify.
+func watchRepository(_ string, _ func(path string)) (func(), error) {
+	return nil, ErrUnsupportedPlatform
+}
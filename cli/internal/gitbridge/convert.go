@@ -0,0 +1,249 @@
+package gitbridge
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gitLegacyRegularMode is a group-writable file mode written by very old Git versions.
+const gitLegacyRegularMode = "100664"
+
+// gelModeFromGit maps a Git tree entry mode to a Gel FileMode. Gitlinks are
+// refused: the submodule commit they name has no known Gel hash.
+func gelModeFromGit(mode string, name string) (domain.FileMode, error) {
+	if mode == gitLegacyRegularMode {
+		return domain.FileModeRegular, nil
+	}
+	fileMode, err := domain.NewFileModeFromTreeMode(mode)
+	if err != nil || fileMode.IsGitlink() {
+		return 0, fmt.Errorf("%w: %s %q", ErrUnsupportedGitMode, mode, name)
+	}
+	return fileMode, nil
+}
+
+// gitDependencies returns the object IDs a Git object refers to.
+func gitDependencies(object *GitObject) ([]GitHash, error) {
+	switch object.Type {
+	case GitObjectTypeTree:
+		entries, err := parseGitTree(object.Body)
+		if err != nil {
+			return nil, err
+		}
+		dependencies := make([]GitHash, 0, len(entries))
+		for _, entry := range entries {
+			if _, err := gelModeFromGit(entry.mode, entry.name); err != nil {
+				return nil, err
+			}
+			dependencies = append(dependencies, entry.hash)
+		}
+		return dependencies, nil
+	case GitObjectTypeCommit:
+		headers, _, err := parseGitCommit(object.Body)
+		if err != nil {
+			return nil, err
+		}
+		var dependencies []GitHash
+		for _, header := range headers {
+			if header.key != domain.CommitFieldTree && header.key != domain.CommitFieldParent {
+				continue
+			}
+			hash, err := NewGitHashFromHex(header.value)
+			if err != nil {
+				return nil, err
+			}
+			dependencies = append(dependencies, hash)
+		}
+		return dependencies, nil
+	case GitObjectTypeBlob:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot import %s objects", ErrInvalidGitObject, object.Type)
+	}
+}
+
+// gitToGel translates a Git object whose dependencies are already mapped.
+//
+// Commit headers other than tree and parent are copied verbatim and in order,
+// so that gelToGit reproduces the Git commit byte for byte. A gpgsig header
+// becomes the commit signature. It was made over the Git commit body, which
+// names Git hashes, so it no longer verifies against the rehashed Gel commit.
+// Headers Gel does not parse, such as encoding and mergetag, become
+// ExtraHeaders.
+func gitToGel(object *GitObject, objectMap *ObjectMap) (domain.Object, error) {
+	switch object.Type {
+	case GitObjectTypeBlob:
+		return domain.NewBlob(object.Body), nil
+
+	case GitObjectTypeTree:
+		entries, err := parseGitTree(object.Body)
+		if err != nil {
+			return nil, err
+		}
+		gelEntries := make([]domain.TreeEntry, 0, len(entries))
+		for _, entry := range entries {
+			mode, err := gelModeFromGit(entry.mode, entry.name)
+			if err != nil {
+				return nil, err
+			}
+			gelHash, err := mappedGelHash(objectMap, entry.hash)
+			if err != nil {
+				return nil, err
+			}
+			gelEntries = append(gelEntries, domain.NewTreeEntry(mode, gelHash, entry.name))
+		}
+		return domain.NewTreeFromEntries(gelEntries)
+
+	case GitObjectTypeCommit:
+		headers, message, err := parseGitCommit(object.Body)
+		if err != nil {
+			return nil, err
+		}
+		var body strings.Builder
+		for _, header := range headers {
+			switch header.key {
+			case domain.CommitFieldTree, domain.CommitFieldParent:
+				gitHash, err := NewGitHashFromHex(header.value)
+				if err != nil {
+					return nil, err
+				}
+				gelHash, err := mappedGelHash(objectMap, gitHash)
+				if err != nil {
+					return nil, err
+				}
+				fmt.Fprintf(&body, "%s %s\n", header.key, gelHash.Hex())
+			default:
+				writeMultilineHeader(&body, header.key, header.value)
+			}
+		}
+		body.WriteString("\n")
+		body.WriteString(message)
+		commit, err := domain.NewCommit([]byte(body.String()))
+		if err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+		return commit, nil
+
+	default:
+		return nil, fmt.Errorf("%w: cannot import %s objects", ErrInvalidGitObject, object.Type)
+	}
+}
+
+// gelDependencies returns the object hashes a Gel object refers to.
+func gelDependencies(object domain.Object) []domain.Hash {
+	switch obj := object.(type) {
+	case *domain.Tree:
+		entries := obj.Entries()
+		dependencies := make([]domain.Hash, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.Mode.IsGitlink() {
+				dependencies = append(dependencies, entry.Hash)
+			}
+		}
+		return dependencies
+	case *domain.Commit:
+		return append([]domain.Hash{obj.TreeHash}, obj.ParentHashes...)
+	default:
+		return nil
+	}
+}
+
+// gelToGit translates a Gel object whose dependencies are already mapped.
+//
+// Commit headers are written in the order of the Gel commit body, with tree
+// and parent hashes mapped and every other header copied verbatim. A commit
+// signature is written as the gpgsig header. Only signatures that came from
+// Git verify there; one made by Gel covers the Gel commit body.
+func gelToGit(object domain.Object, objectMap *ObjectMap) (*GitObject, error) {
+	switch obj := object.(type) {
+	case *domain.Blob:
+		return &GitObject{Type: GitObjectTypeBlob, Body: obj.Body()}, nil
+
+	case *domain.Tree:
+		var body bytes.Buffer
+		for _, entry := range obj.Entries() {
+			if entry.Mode.IsGitlink() {
+				return nil, fmt.Errorf("%w: %s %q", ErrUnsupportedGitMode, entry.Mode, entry.Name)
+			}
+			gitHash, err := mappedGitHash(objectMap, entry.Hash)
+			if err != nil {
+				return nil, err
+			}
+			body.WriteString(entry.Mode.String())
+			body.WriteByte(' ')
+			body.WriteString(entry.Name)
+			body.WriteByte(0)
+			body.Write(gitHash[:])
+		}
+		return &GitObject{Type: GitObjectTypeTree, Body: body.Bytes()}, nil
+
+	case *domain.Commit:
+		headers, message, err := parseGitCommit(obj.Body())
+		if err != nil {
+			return nil, err
+		}
+		var body bytes.Buffer
+		for _, header := range headers {
+			switch header.key {
+			case domain.CommitFieldTree, domain.CommitFieldParent:
+				gelHash, err := domain.NewHashFromHex(header.value)
+				if err != nil {
+					return nil, err
+				}
+				gitHash, err := mappedGitHash(objectMap, gelHash)
+				if err != nil {
+					return nil, err
+				}
+				fmt.Fprintf(&body, "%s %s\n", header.key, gitHash.Hex())
+			default:
+				writeMultilineHeader(&body, header.key, header.value)
+			}
+		}
+		body.WriteString("\n")
+		body.WriteString(message)
+		return &GitObject{Type: GitObjectTypeCommit, Body: body.Bytes()}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: cannot export %s objects", ErrInvalidGitObject, object.Type())
+	}
+}
+
+// writeMultilineHeader writes a commit header whose value may span several
+// lines, each continuation line starting with a space.
+func writeMultilineHeader(w io.Writer, key, value string) {
+	fmt.Fprintf(w, "%s %s\n", key, strings.ReplaceAll(value, "\n", "\n "))
+}
+
+// writeGelObject stores object in the Gel object database and returns its SHA-256.
+func writeGelObject(objectService *core.ObjectService, object domain.Object) (domain.Hash, error) {
+	data := object.Serialize()
+	hash, err := domain.NewHashFromHex(core.ComputeSHA256(data))
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	if err := objectService.Write(hash, data); err != nil {
+		return domain.Hash{}, err
+	}
+	return hash, nil
+}
+
+// mappedGelHash looks up a dependency that must already have been imported.
+func mappedGelHash(objectMap *ObjectMap, gitHash GitHash) (domain.Hash, error) {
+	gelHash, ok := objectMap.GelHash(gitHash)
+	if !ok {
+		return domain.Hash{}, fmt.Errorf("%w: %s has no Gel mapping", ErrGitObjectNotFound, gitHash)
+	}
+	return gelHash, nil
+}
+
+// mappedGitHash looks up a dependency that must already have been exported.
+func mappedGitHash(objectMap *ObjectMap, gelHash domain.Hash) (GitHash, error) {
+	gitHash, ok := objectMap.GitHash(gelHash)
+	if !ok {
+		return GitHash{}, fmt.Errorf("%w: %s has no Git mapping", ErrGitObjectNotFound, gelHash)
+	}
+	return gitHash, nil
+}
@@ -0,0 +1,23 @@
+package gitbridge
+
+import "errors"
+
+var (
+	// ErrNotAGitRepository is returned when a path has neither a .git directory nor a bare Git layout.
+	ErrNotAGitRepository = errors.New("not a Git repository")
+
+	// ErrGitObjectNotFound is returned when an object is neither loose nor in any pack.
+	ErrGitObjectNotFound = errors.New("git object not found")
+
+	// ErrInvalidGitObject is returned when a Git object or pack entry cannot be parsed.
+	ErrInvalidGitObject = errors.New("invalid git object")
+
+	// ErrInvalidPack is returned when a pack or pack index file is malformed.
+	ErrInvalidPack = errors.New("invalid git pack")
+
+	// ErrUnsupportedGitMode is returned for tree entries Gel cannot translate, such as gitlinks.
+	ErrUnsupportedGitMode = errors.New("unsupported git tree entry mode")
+
+	// ErrInvalidObjectMap is returned when the SHA-1/SHA-256 mapping file is malformed.
+	ErrInvalidObjectMap = errors.New("invalid git object map")
+)
@@ -0,0 +1,218 @@
+package gitbridge
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"fmt"
+	"path/filepath"
+)
+
+// ExportOptions controls how exported objects are stored in the Git repository.
+type ExportOptions struct {
+	// Loose writes one loose object per file instead of a single new pack.
+	Loose bool
+}
+
+// ExportedBranch reports one Git branch moved by export-git.
+type ExportedBranch struct {
+	Name    string
+	OldHash GitHash
+	NewHash GitHash
+}
+
+// ExportResult reports the outcome of an export-git invocation.
+type ExportResult struct {
+	// ObjectsExported is the number of Gel objects translated in this run.
+	ObjectsExported int
+	// Updated lists Git branches that were created or moved, sorted by name.
+	Updated []ExportedBranch
+	// Skipped lists branches left alone because they are checked out in a non-bare Git repository.
+	Skipped []string
+}
+
+// ExportService translates Gel branches into a Git repository.
+//
+// Translation is incremental: Gel objects already present in the mapping
+// table whose Git counterpart exists in the target are skipped together with
+// their history.
+type ExportService struct {
+	objectService *core.ObjectService
+	refService    *core.RefService
+	branchService *branch.BranchService
+	workspace     *domain.Workspace
+}
+
+// NewExportService creates an export service.
+func NewExportService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	branchService *branch.BranchService,
+	workspace *domain.Workspace,
+) *ExportService {
+	return &ExportService{
+		objectService: objectService,
+		refService:    refService,
+		branchService: branchService,
+		workspace:     workspace,
+	}
+}
+
+// Export writes every Gel branch to refs/heads/<branch> of the Git repository
+// at path. Objects are written to one new pack unless options.Loose is set.
+// A branch checked out in a non-bare Git repository is only created, never moved.
+func (e *ExportService) Export(path string, options ExportOptions) (result *ExportResult, err error) {
+	repository, err := OpenGitRepository(path)
+	if err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+	objectMap, err := LoadObjectMap(e.workspace)
+	if err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+
+	branches, err := e.branchService.List()
+	if err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+
+	result = &ExportResult{}
+	written := make(map[GitHash]bool)
+	var pending []*GitObject
+	tips := make(map[string]GitHash, len(branches))
+	for _, item := range branches {
+		ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, item.Name)
+		gelHash, err := e.refService.Read(ref)
+		if err != nil {
+			return nil, fmt.Errorf("export-git: %w", err)
+		}
+		gitHash, err := e.exportObject(repository, objectMap, gelHash, written, &pending, options, result)
+		if err != nil {
+			return nil, fmt.Errorf("export-git: branch '%s': %w", item.Name, err)
+		}
+		tips[item.Name] = gitHash
+	}
+
+	if !options.Loose {
+		if err := repository.WritePack(pending); err != nil {
+			return nil, fmt.Errorf("export-git: %w", err)
+		}
+	}
+	if err := objectMap.Save(); err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+
+	gitBranches, err := repository.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+	currentBranch, hasCurrent, err := repository.CurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("export-git: %w", err)
+	}
+	for _, item := range branches {
+		newHash := tips[item.Name]
+		oldHash := gitBranches[item.Name]
+		if oldHash == newHash {
+			continue
+		}
+		if !repository.IsBare() && hasCurrent && currentBranch == item.Name && !oldHash.IsEmpty() {
+			result.Skipped = append(result.Skipped, item.Name)
+			continue
+		}
+		if err := repository.WriteBranch(item.Name, newHash); err != nil {
+			return nil, fmt.Errorf("export-git: %w", err)
+		}
+		result.Updated = append(result.Updated, ExportedBranch{Name: item.Name, OldHash: oldHash, NewHash: newHash})
+	}
+	return result, nil
+}
+
+// exportFrame is one Gel object on the post-order translation stack.
+type exportFrame struct {
+	hash   domain.Hash
+	object domain.Object
+}
+
+// exportObject translates root and everything it reaches that is not yet in
+// the target repository, dependencies first, and returns the Git ID of root.
+// Packed objects are appended to pending; loose objects are written immediately.
+func (e *ExportService) exportObject(
+	repository *GitRepository,
+	objectMap *ObjectMap,
+	root domain.Hash,
+	written map[GitHash]bool,
+	pending *[]*GitObject,
+	options ExportOptions,
+	result *ExportResult,
+) (GitHash, error) {
+	stack := []exportFrame{{hash: root}}
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		frame := stack[top]
+
+		if frame.object == nil {
+			done, err := isExported(repository, objectMap, written, frame.hash)
+			if err != nil {
+				return GitHash{}, err
+			}
+			if done {
+				stack = stack[:top]
+				continue
+			}
+
+			object, err := e.objectService.Read(frame.hash)
+			if err != nil {
+				return GitHash{}, err
+			}
+			stack[top].object = object
+			for _, dependency := range gelDependencies(object) {
+				stack = append(stack, exportFrame{hash: dependency})
+			}
+			continue
+		}
+
+		stack = stack[:top]
+		if done, err := isExported(repository, objectMap, written, frame.hash); err != nil || done {
+			if err != nil {
+				return GitHash{}, err
+			}
+			continue
+		}
+		gitObject, err := gelToGit(frame.object, objectMap)
+		if err != nil {
+			return GitHash{}, fmt.Errorf("%s: %w", frame.hash, err)
+		}
+
+		gitHash := gitObject.Hash()
+		if options.Loose {
+			if _, err := repository.WriteLoose(gitObject); err != nil {
+				return GitHash{}, err
+			}
+		} else {
+			*pending = append(*pending, gitObject)
+		}
+		written[gitHash] = true
+		objectMap.Add(frame.hash, gitHash)
+		result.ObjectsExported++
+	}
+	return mappedGitHash(objectMap, root)
+}
+
+// isExported reports whether gelHash is mapped and its Git object is stored
+// in the target or queued in this run.
+func isExported(
+	repository *GitRepository,
+	objectMap *ObjectMap,
+	written map[GitHash]bool,
+	gelHash domain.Hash,
+) (bool, error) {
+	gitHash, ok := objectMap.GitHash(gelHash)
+	if !ok {
+		return false, nil
+	}
+	if written[gitHash] {
+		return true, nil
+	}
+	return repository.Exists(gitHash)
+}
@@ -0,0 +1,257 @@
+package gitbridge
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/core"
+	"Gel/internal/setup"
+	"Gel/internal/testutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs git in dir with a fixed identity and clock and no user or
+// system configuration, and returns its trimmed standard output.
+func runGit(t *testing.T, dir, stdin string, args ...string) string {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not found")
+	}
+	cmd := exec.Command(gitPath, append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_NAME=Alice",
+		"GIT_AUTHOR_EMAIL=alice@example.com",
+		"GIT_AUTHOR_DATE=1700000000 +0100",
+		"GIT_COMMITTER_NAME=Bob",
+		"GIT_COMMITTER_EMAIL=bob@example.com",
+		"GIT_COMMITTER_DATE=1700000000 +0100",
+	)
+	cmd.Stdin = strings.NewReader(stdin)
+	output, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), exitErr.Stderr)
+	}
+	require.NoError(t, err)
+	return strings.TrimSpace(string(output))
+}
+
+// writeGitCommit writes a commit with the given extra headers between the
+// committer and the message, and returns its ID.
+func writeGitCommit(t *testing.T, dir, tree, parent string, headers []string, message string) string {
+	t.Helper()
+	body := "tree " + tree + "\n" +
+		"parent " + parent + "\n" +
+		"author Alice <alice@example.com> 1700000000 +0100\n" +
+		"committer Bob <bob@example.com> 1700000000 +0100\n"
+	for _, header := range headers {
+		body += header + "\n"
+	}
+	body += "\n" + message
+	return runGit(t, dir, body, "hash-object", "-t", "commit", "-w", "--stdin")
+}
+
+// newGitFixture creates a Git repository whose branches cover merges, file
+// modes, and commits with encoding, mergetag, and gpgsig headers. It returns
+// the repository path and the commit ID of every branch.
+func newGitFixture(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "source")
+	runGit(t, t.TempDir(), "", "init", "-q", "-b", "main", dir)
+
+	// A large file edited slightly in each commit lets repack store deltas.
+	var large strings.Builder
+	for i := 0; i < 2000; i++ {
+		large.WriteString("line of a large file that repack stores as a delta\n")
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "large.txt"), []byte(large.String()), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dir", "nested.txt"), []byte("nested\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.Symlink("large.txt", filepath.Join(dir, "link")))
+	runGit(t, dir, "", "add", "-A")
+	runGit(t, dir, "", "commit", "-q", "-m", "initial")
+
+	runGit(t, dir, "", "switch", "-q", "-c", "feature")
+	large.WriteString("feature line\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "large.txt"), []byte(large.String()), 0o644))
+	runGit(t, dir, "", "commit", "-q", "-am", "feature")
+
+	runGit(t, dir, "", "switch", "-q", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dir", "nested.txt"), []byte("changed\n"), 0o644))
+	runGit(t, dir, "", "commit", "-q", "-am", "main")
+	runGit(t, dir, "", "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	tree := runGit(t, dir, "", "rev-parse", "main^{tree}")
+	parent := runGit(t, dir, "", "rev-parse", "main")
+	feature := runGit(t, dir, "", "rev-parse", "feature")
+	branches := map[string]string{
+		"main":    parent,
+		"feature": feature,
+		"plain":   writeGitCommit(t, dir, tree, parent, nil, "same message\n"),
+		"encoded": writeGitCommit(t, dir, tree, parent, []string{"encoding ISO-8859-1"}, "same message\n"),
+		"mergetag": writeGitCommit(
+			t, dir, tree, parent, []string{
+				"mergetag object " + feature,
+				" type commit",
+				" tag v1",
+				" tagger Alice <alice@example.com> 1700000000 +0100",
+				" ",
+				" release v1",
+			}, "merge tag v1\n",
+		),
+		"signed": writeGitCommit(
+			t, dir, tree, parent, []string{
+				"gpgsig -----BEGIN SSH SIGNATURE-----",
+				" U1NIU0lH",
+				" -----END SSH SIGNATURE-----",
+			}, "signed\n",
+		),
+	}
+	for name, hash := range branches {
+		runGit(t, dir, "", "update-ref", "refs/heads/"+name, hash)
+	}
+	return dir, branches
+}
+
+// newGelBridge initializes a bare Gel repository and returns its import and
+// export services and its ref service.
+func newGelBridge(t *testing.T) (*ImportService, *ExportService, *core.RefService) {
+	t.Helper()
+	repository := testutil.InitRepository(t, filepath.Join(t.TempDir(), "gel"), setup.InitOptions{Bare: true})
+	objectService, refService, workspace := repository.ObjectService, repository.RefService, repository.Workspace
+	branchService := branch.NewBranchService(refService, objectService, core.NewWorktreeRegistry(workspace), workspace)
+	return NewImportService(objectService, refService, workspace),
+		NewExportService(objectService, refService, branchService, workspace),
+		refService
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		repack bool
+		loose  bool
+	}{
+		{name: "loose source, packed export"},
+		{name: "packed source, packed export", repack: true},
+		{name: "packed source, loose export", repack: true, loose: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				source, branches := newGitFixture(t)
+				if tt.repack {
+					runGit(t, source, "", "repack", "-q", "-a", "-d", "-f")
+					packs, err := filepath.Glob(filepath.Join(source, ".git", "objects", "pack", "*.pack"))
+					require.NoError(t, err)
+					require.Len(t, packs, 1)
+					runGit(t, source, "", "prune-packed")
+				}
+				importService, exportService, refService := newGelBridge(t)
+
+				imported, err := importService.Import(source)
+				require.NoError(t, err)
+				require.Len(t, imported.Updated, len(branches))
+
+				target := filepath.Join(t.TempDir(), "target.git")
+				runGit(t, t.TempDir(), "", "init", "-q", "--bare", target)
+				exported, err := exportService.Export(target, ExportOptions{Loose: tt.loose})
+
+				require.NoError(t, err)
+				assert.Len(t, exported.Updated, len(branches))
+				for name, hash := range branches {
+					assert.Equal(t, hash, runGit(t, target, "", "rev-parse", "refs/heads/"+name), name)
+				}
+				runGit(t, target, "", "fsck", "--no-progress")
+				for _, name := range []string{"encoded", "mergetag", "signed"} {
+					assert.Equal(
+						t, runGit(t, source, "", "cat-file", "commit", name),
+						runGit(t, target, "", "cat-file", "commit", name), name,
+					)
+				}
+
+				plain, err := refService.Read("refs/heads/plain")
+				require.NoError(t, err)
+				encoded, err := refService.Read("refs/heads/encoded")
+				require.NoError(t, err)
+				assert.NotEqual(t, plain, encoded)
+			},
+		)
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("0123456789")
+	tests := []struct {
+		name    string
+		delta   []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "copy and insert", delta: []byte{10, 6, 0x91, 2, 3, 3, 'a', 'b', 'c'}, want: "234abc"},
+		{name: "insert only", delta: []byte{10, 2, 2, 'x', 'y'}, want: "xy"},
+		{name: "base size mismatch", delta: []byte{9, 0}, wantErr: true},
+		{name: "truncated size", delta: []byte{0x8a}, wantErr: true},
+		{name: "zero insert", delta: []byte{10, 1, 0}, wantErr: true},
+		{name: "insert past end", delta: []byte{10, 3, 3, 'a'}, wantErr: true},
+		{name: "truncated copy", delta: []byte{10, 2, 0x91, 2}, wantErr: true},
+		{name: "copy out of range", delta: []byte{10, 4, 0x91, 8, 4}, wantErr: true},
+		{name: "target size mismatch", delta: []byte{10, 5, 2, 'x', 'y'}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				target, err := applyDelta(base, tt.delta)
+
+				if tt.wantErr {
+					require.ErrorIs(t, err, ErrInvalidPack)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, string(target))
+			},
+		)
+	}
+}
+
+func TestParseGitCommit(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantHeaders []gitCommitHeader
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name:        "continuation lines",
+			body:        "tree t\nmergetag object o\n type commit\n \n message\nencoding UTF-8\n\nsubject\n",
+			wantHeaders: []gitCommitHeader{{"tree", "t"}, {"mergetag", "object o\ntype commit\n\nmessage"}, {"encoding", "UTF-8"}},
+			wantMessage: "subject\n",
+		},
+		{name: "no separator", body: "tree t\n", wantErr: true},
+		{name: "leading continuation", body: " tree t\n\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				headers, message, err := parseGitCommit([]byte(tt.body))
+
+				if tt.wantErr {
+					require.ErrorIs(t, err, ErrInvalidGitObject)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantHeaders, headers)
+				assert.Equal(t, tt.wantMessage, message)
+			},
+		)
+	}
+}
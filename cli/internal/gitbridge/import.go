@@ -0,0 +1,231 @@
+package gitbridge
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// ImportedBranch reports one Gel branch moved by import-git.
+type ImportedBranch struct {
+	Name    string
+	OldHash domain.Hash
+	NewHash domain.Hash
+}
+
+// ImportResult reports the outcome of an import-git invocation.
+type ImportResult struct {
+	// ObjectsImported is the number of Git objects translated in this run.
+	ObjectsImported int
+	// Updated lists branches that were created or moved, sorted by name.
+	Updated []ImportedBranch
+	// Skipped lists branches left alone because they are checked out with local history.
+	Skipped []string
+	// HeadBranch is set when HEAD was unborn and now points at the branch
+	// checked out in the Git repository.
+	HeadBranch string
+	// CheckoutHash is set when the checked-out branch was unborn and has just
+	// been created, so the caller should populate the index and working tree.
+	CheckoutHash domain.Hash
+}
+
+// ImportService translates the branches of a Git repository into Gel objects and refs.
+//
+// Translation is incremental: Git objects already present in the mapping table
+// with their Gel counterpart stored are skipped together with their history.
+type ImportService struct {
+	objectService *core.ObjectService
+	refService    *core.RefService
+	workspace     *domain.Workspace
+}
+
+// NewImportService creates an import service.
+func NewImportService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	workspace *domain.Workspace,
+) *ImportService {
+	return &ImportService{
+		objectService: objectService,
+		refService:    refService,
+		workspace:     workspace,
+	}
+}
+
+// Import reads every refs/heads branch of the Git repository at path and writes
+// refs/heads/<branch> in Gel. A checked-out branch that already has commits is
+// never moved, so the working tree stays consistent with HEAD. When HEAD is
+// unborn and none of the imported branches is its branch, HEAD is pointed at
+// the branch checked out in the Git repository.
+func (i *ImportService) Import(path string) (result *ImportResult, err error) {
+	repository, err := OpenGitRepository(path)
+	if err != nil {
+		return nil, fmt.Errorf("import-git: %w", err)
+	}
+	objectMap, err := LoadObjectMap(i.workspace)
+	if err != nil {
+		return nil, fmt.Errorf("import-git: %w", err)
+	}
+	defer func() {
+		if saveErr := objectMap.Save(); saveErr != nil && err == nil {
+			err = fmt.Errorf("import-git: %w", saveErr)
+		}
+	}()
+
+	branches, err := repository.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("import-git: %w", err)
+	}
+	currentRef, err := i.refService.ReadSymbolic(domain.HeadFileName)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) && !errors.Is(err, core.ErrInvalidSymbolicRef) {
+		return nil, fmt.Errorf("import-git: %w", err)
+	}
+
+	result = &ImportResult{}
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		newHash, err := i.importObject(repository, objectMap, branches[name], result)
+		if err != nil {
+			return nil, fmt.Errorf("import-git: branch '%s': %w", name, err)
+		}
+
+		ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, name)
+		oldHash, err := i.refService.Read(ref)
+		if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+			return nil, fmt.Errorf("import-git: %w", err)
+		}
+		if oldHash == newHash {
+			continue
+		}
+
+		isCurrent := ref == currentRef && !i.workspace.Bare
+		if isCurrent && !oldHash.IsEmpty() {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+		if err := i.refService.Write(ref, newHash); err != nil {
+			return nil, fmt.Errorf("import-git: %w", err)
+		}
+		if isCurrent {
+			result.CheckoutHash = newHash
+		}
+		result.Updated = append(result.Updated, ImportedBranch{Name: name, OldHash: oldHash, NewHash: newHash})
+	}
+	if err := i.adoptGitHead(repository, currentRef, result); err != nil {
+		return nil, fmt.Errorf("import-git: %w", err)
+	}
+	return result, nil
+}
+
+// adoptGitHead points an unborn HEAD at the branch checked out in repository,
+// so that a fresh repository ends up on the imported default branch.
+func (i *ImportService) adoptGitHead(repository *GitRepository, currentRef string, result *ImportResult) error {
+	if currentRef == "" || !result.CheckoutHash.IsEmpty() {
+		return nil
+	}
+	if exists, err := i.refService.Exists(currentRef); err != nil || exists {
+		return err
+	}
+	branch, ok, err := repository.CurrentBranch()
+	if err != nil || !ok {
+		return err
+	}
+	ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branch)
+	hash, err := i.refService.Read(ref)
+	if errors.Is(err, core.ErrRefNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := i.refService.WriteSymbolic(domain.HeadFileName, ref); err != nil {
+		return err
+	}
+	result.HeadBranch = branch
+	if !i.workspace.Bare {
+		result.CheckoutHash = hash
+	}
+	return nil
+}
+
+// importFrame is one Git object on the post-order translation stack.
+type importFrame struct {
+	hash   GitHash
+	object *GitObject
+}
+
+// importObject translates root and everything it reaches that is not yet mapped,
+// dependencies first, and returns the Gel hash of root.
+func (i *ImportService) importObject(
+	repository *GitRepository,
+	objectMap *ObjectMap,
+	root GitHash,
+	result *ImportResult,
+) (domain.Hash, error) {
+	stack := []importFrame{{hash: root}}
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		frame := stack[top]
+
+		if frame.object == nil {
+			done, err := i.isImported(objectMap, frame.hash)
+			if err != nil {
+				return domain.Hash{}, err
+			}
+			if done {
+				stack = stack[:top]
+				continue
+			}
+
+			object, err := repository.Read(frame.hash)
+			if err != nil {
+				return domain.Hash{}, err
+			}
+			stack[top].object = object
+			dependencies, err := gitDependencies(object)
+			if err != nil {
+				return domain.Hash{}, fmt.Errorf("%s: %w", frame.hash, err)
+			}
+			for _, dependency := range dependencies {
+				stack = append(stack, importFrame{hash: dependency})
+			}
+			continue
+		}
+
+		stack = stack[:top]
+		if done, err := i.isImported(objectMap, frame.hash); err != nil || done {
+			if err != nil {
+				return domain.Hash{}, err
+			}
+			continue
+		}
+		gelObject, err := gitToGel(frame.object, objectMap)
+		if err != nil {
+			return domain.Hash{}, fmt.Errorf("%s: %w", frame.hash, err)
+		}
+		gelHash, err := writeGelObject(i.objectService, gelObject)
+		if err != nil {
+			return domain.Hash{}, err
+		}
+		objectMap.Add(gelHash, frame.hash)
+		result.ObjectsImported++
+	}
+	return mappedGelHash(objectMap, root)
+}
+
+// isImported reports whether gitHash is mapped and its Gel object is stored.
+func (i *ImportService) isImported(objectMap *ObjectMap, gitHash GitHash) (bool, error) {
+	gelHash, ok := objectMap.GelHash(gitHash)
+	if !ok {
+		return false, nil
+	}
+	return i.objectService.Exists(gelHash)
+}
@@ -0,0 +1,161 @@
+package gitbridge
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// GitHashByteLength is the length of a SHA-1 object ID in raw bytes.
+const GitHashByteLength = sha1.Size
+
+// GitHash is a Git SHA-1 object ID.
+type GitHash [GitHashByteLength]byte
+
+// NewGitHashFromHex parses a 40-character hexadecimal SHA-1.
+func NewGitHashFromHex(hexHash string) (GitHash, error) {
+	decoded, err := hex.DecodeString(hexHash)
+	if err != nil || len(decoded) != GitHashByteLength {
+		return GitHash{}, fmt.Errorf("%w: invalid SHA-1 %q", ErrInvalidGitObject, hexHash)
+	}
+	var hash GitHash
+	copy(hash[:], decoded)
+	return hash, nil
+}
+
+// Hex returns the hex-encoded form of h.
+func (h GitHash) Hex() string {
+	return hex.EncodeToString(h[:])
+}
+
+// String returns the hex-encoded form of h.
+func (h GitHash) String() string {
+	return h.Hex()
+}
+
+// IsEmpty reports whether h is the zero GitHash.
+func (h GitHash) IsEmpty() bool {
+	return h == GitHash{}
+}
+
+// GitObjectType is a Git object type name.
+type GitObjectType string
+
+const (
+	GitObjectTypeCommit GitObjectType = "commit"
+	GitObjectTypeTree   GitObjectType = "tree"
+	GitObjectTypeBlob   GitObjectType = "blob"
+	GitObjectTypeTag    GitObjectType = "tag"
+)
+
+// GitObject is a decoded Git object: its type and raw body without header.
+type GitObject struct {
+	Type GitObjectType
+	Body []byte
+}
+
+// Serialize returns the object in "<type> <size>\x00<body>" form, which is what Git hashes.
+func (o *GitObject) Serialize() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(string(o.Type))
+	buffer.WriteByte(' ')
+	buffer.WriteString(strconv.Itoa(len(o.Body)))
+	buffer.WriteByte(0)
+	buffer.Write(o.Body)
+	return buffer.Bytes()
+}
+
+// Hash returns the SHA-1 object ID of o.
+func (o *GitObject) Hash() GitHash {
+	return sha1.Sum(o.Serialize())
+}
+
+// parseGitObject splits serialized loose object content into type and body.
+func parseGitObject(data []byte) (*GitObject, error) {
+	nullIndex := bytes.IndexByte(data, 0)
+	if nullIndex == -1 {
+		return nil, fmt.Errorf("%w: missing header terminator", ErrInvalidGitObject)
+	}
+	typeName, sizeText, ok := bytes.Cut(data[:nullIndex], []byte(" "))
+	if !ok {
+		return nil, fmt.Errorf("%w: missing header separator", ErrInvalidGitObject)
+	}
+	size, err := strconv.Atoi(string(sizeText))
+	if err != nil || size != len(data)-nullIndex-1 {
+		return nil, fmt.Errorf("%w: bad size %q", ErrInvalidGitObject, sizeText)
+	}
+	objectType := GitObjectType(typeName)
+	switch objectType {
+	case GitObjectTypeCommit, GitObjectTypeTree, GitObjectTypeBlob, GitObjectTypeTag:
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidGitObject, typeName)
+	}
+	return &GitObject{Type: objectType, Body: data[nullIndex+1:]}, nil
+}
+
+// gitTreeEntry is one parsed entry of a Git tree body.
+type gitTreeEntry struct {
+	mode string
+	name string
+	hash GitHash
+}
+
+// parseGitTree parses a Git tree body ("<mode> <name>\x00<20-byte id>" repeated).
+func parseGitTree(body []byte) ([]gitTreeEntry, error) {
+	var entries []gitTreeEntry
+	for offset := 0; offset < len(body); {
+		spaceOffset := bytes.IndexByte(body[offset:], ' ')
+		if spaceOffset == -1 {
+			return nil, fmt.Errorf("%w: tree entry missing mode at offset %d", ErrInvalidGitObject, offset)
+		}
+		mode := string(body[offset : offset+spaceOffset])
+		offset += spaceOffset + 1
+
+		nullOffset := bytes.IndexByte(body[offset:], 0)
+		if nullOffset == -1 || offset+nullOffset+1+GitHashByteLength > len(body) {
+			return nil, fmt.Errorf("%w: truncated tree entry at offset %d", ErrInvalidGitObject, offset)
+		}
+		name := string(body[offset : offset+nullOffset])
+		offset += nullOffset + 1
+
+		var hash GitHash
+		copy(hash[:], body[offset:offset+GitHashByteLength])
+		offset += GitHashByteLength
+		entries = append(entries, gitTreeEntry{mode: mode, name: name, hash: hash})
+	}
+	return entries, nil
+}
+
+// gitCommitHeader is one header of a Git commit, with continuation lines joined by "\n".
+type gitCommitHeader struct {
+	key   string
+	value string
+}
+
+// parseGitCommit splits a Git commit body into headers and the message.
+// Multi-line headers such as gpgsig and mergetag use leading-space continuation lines.
+func parseGitCommit(body []byte) ([]gitCommitHeader, string, error) {
+	var headers []gitCommitHeader
+	rest := body
+	for {
+		line, next, found := bytes.Cut(rest, []byte("\n"))
+		if !found {
+			return nil, "", fmt.Errorf("%w: commit has no message separator", ErrInvalidGitObject)
+		}
+		rest = next
+		if len(line) == 0 {
+			return headers, string(rest), nil
+		}
+		if line[0] == ' ' {
+			if len(headers) == 0 {
+				return nil, "", fmt.Errorf("%w: continuation line before any header", ErrInvalidGitObject)
+			}
+			headers[len(headers)-1].value += "\n" + string(line[1:])
+			continue
+		}
+		key, value, _ := bytes.Cut(line, []byte(" "))
+		headers = append(headers, gitCommitHeader{key: string(key), value: string(value)})
+	}
+}
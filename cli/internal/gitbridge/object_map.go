@@ -0,0 +1,111 @@
+package gitbridge
+
+import (
+	"Gel/internal/domain"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ObjectMapFileName is the mapping table file inside the Gel metadata directory.
+const ObjectMapFileName = "git-map"
+
+// ObjectMap is the persistent bidirectional SHA-256 <-> SHA-1 translation table.
+//
+// Each line of .gel/git-map holds "<sha256> <sha1>". New pairs are appended by
+// Save, so repeated imports and exports only translate objects not yet mapped.
+type ObjectMap struct {
+	path    string
+	toGit   map[domain.Hash]GitHash
+	toGel   map[GitHash]domain.Hash
+	pending [][2]string
+}
+
+// LoadObjectMap reads the mapping table of workspace, or returns an empty map when none exists.
+func LoadObjectMap(workspace *domain.Workspace) (*ObjectMap, error) {
+	objectMap := &ObjectMap{
+		path:  filepath.Join(workspace.CommonDir.String(), ObjectMapFileName),
+		toGit: make(map[domain.Hash]GitHash),
+		toGel: make(map[GitHash]domain.Hash),
+	}
+
+	file, err := os.Open(objectMap.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return objectMap, nil
+		}
+		return nil, fmt.Errorf("git-map: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		gelHex, gitHex, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidObjectMap, lineNumber)
+		}
+		gelHash, err := domain.NewHashFromHex(gelHex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidObjectMap, lineNumber, err)
+		}
+		gitHash, err := NewGitHashFromHex(gitHex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidObjectMap, lineNumber, err)
+		}
+		objectMap.toGit[gelHash] = gitHash
+		objectMap.toGel[gitHash] = gelHash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("git-map: %w", err)
+	}
+	return objectMap, nil
+}
+
+// GitHash returns the SHA-1 mapped to gelHash.
+func (m *ObjectMap) GitHash(gelHash domain.Hash) (GitHash, bool) {
+	gitHash, ok := m.toGit[gelHash]
+	return gitHash, ok
+}
+
+// GelHash returns the SHA-256 mapped to gitHash.
+func (m *ObjectMap) GelHash(gitHash GitHash) (domain.Hash, bool) {
+	gelHash, ok := m.toGel[gitHash]
+	return gelHash, ok
+}
+
+// Add records a pair in memory. It is persisted by the next Save.
+func (m *ObjectMap) Add(gelHash domain.Hash, gitHash GitHash) {
+	if existing, ok := m.toGit[gelHash]; ok && existing == gitHash {
+		return
+	}
+	m.toGit[gelHash] = gitHash
+	m.toGel[gitHash] = gelHash
+	m.pending = append(m.pending, [2]string{gelHash.Hex(), gitHash.Hex()})
+}
+
+// Save appends pairs added since the last Save to the mapping file.
+func (m *ObjectMap) Save() error {
+	if len(m.pending) == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, domain.DefaultFilePermission)
+	if err != nil {
+		return fmt.Errorf("git-map: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	for _, pair := range m.pending {
+		fmt.Fprintf(writer, "%s %s\n", pair[0], pair[1])
+	}
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("git-map: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("git-map: %w", err)
+	}
+	m.pending = nil
+	return nil
+}
@@ -0,0 +1,389 @@
+package gitbridge
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	packSignature       = "PACK"
+	packVersion         = 2
+	packIndexVersion    = 2
+	packHeaderLength    = 12
+	packLargeOffsetFlag = 0x80000000
+)
+
+var packIndexSignature = []byte{0xff, 't', 'O', 'c'}
+
+// Pack entry type codes from the pack format.
+const (
+	packTypeCommit   = 1
+	packTypeTree     = 2
+	packTypeBlob     = 3
+	packTypeTag      = 4
+	packTypeOfsDelta = 6
+	packTypeRefDelta = 7
+)
+
+var packTypeNames = map[int]GitObjectType{
+	packTypeCommit: GitObjectTypeCommit,
+	packTypeTree:   GitObjectTypeTree,
+	packTypeBlob:   GitObjectTypeBlob,
+	packTypeTag:    GitObjectTypeTag,
+}
+
+// packFile is an opened pack with its index loaded into memory.
+type packFile struct {
+	path    string
+	offsets map[GitHash]int64
+}
+
+// openPackFile loads the .idx that accompanies packPath.
+func openPackFile(packPath, indexPath string) (*packFile, error) {
+	offsets, err := readPackIndex(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	return &packFile{path: packPath, offsets: offsets}, nil
+}
+
+// readPackIndex parses a version 2 pack index and returns object offsets by ID.
+func readPackIndex(indexPath string) (map[GitHash]int64, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("read pack index %q: %w", indexPath, err)
+	}
+
+	const fanoutEnd = 8 + 256*4
+	if len(data) < fanoutEnd || !bytes.Equal(data[:4], packIndexSignature) ||
+		binary.BigEndian.Uint32(data[4:8]) != packIndexVersion {
+		return nil, fmt.Errorf("%w: %q is not a version 2 index", ErrInvalidPack, indexPath)
+	}
+
+	count := int(binary.BigEndian.Uint32(data[fanoutEnd-4 : fanoutEnd]))
+	namesStart := fanoutEnd
+	crcStart := namesStart + count*GitHashByteLength
+	offsetsStart := crcStart + count*4
+	largeStart := offsetsStart + count*4
+	if len(data) < largeStart {
+		return nil, fmt.Errorf("%w: %q is truncated", ErrInvalidPack, indexPath)
+	}
+
+	offsets := make(map[GitHash]int64, count)
+	for i := 0; i < count; i++ {
+		var hash GitHash
+		copy(hash[:], data[namesStart+i*GitHashByteLength:])
+
+		offset := binary.BigEndian.Uint32(data[offsetsStart+i*4:])
+		if offset&packLargeOffsetFlag == 0 {
+			offsets[hash] = int64(offset)
+			continue
+		}
+		largeIndex := int(offset &^ packLargeOffsetFlag)
+		position := largeStart + largeIndex*8
+		if position+8 > len(data) {
+			return nil, fmt.Errorf("%w: %q large offset out of range", ErrInvalidPack, indexPath)
+		}
+		offsets[hash] = int64(binary.BigEndian.Uint64(data[position:]))
+	}
+	return offsets, nil
+}
+
+// packReader reads entries from one pack file, resolving deltas.
+type packReader struct {
+	pack       *packFile
+	file       *os.File
+	size       int64
+	resolveRef func(GitHash) (*GitObject, error)
+}
+
+// read returns the object at offset, applying delta chains.
+func (r *packReader) read(offset int64) (*GitObject, error) {
+	entryType, data, baseOffset, baseHash, err := r.readEntry(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	switch entryType {
+	case packTypeOfsDelta:
+		base, err := r.read(baseOffset)
+		if err != nil {
+			return nil, err
+		}
+		body, err := applyDelta(base.Body, data)
+		if err != nil {
+			return nil, err
+		}
+		return &GitObject{Type: base.Type, Body: body}, nil
+	case packTypeRefDelta:
+		var base *GitObject
+		if baseOffset, ok := r.pack.offsets[baseHash]; ok {
+			base, err = r.read(baseOffset)
+		} else {
+			base, err = r.resolveRef(baseHash)
+		}
+		if err != nil {
+			return nil, err
+		}
+		body, err := applyDelta(base.Body, data)
+		if err != nil {
+			return nil, err
+		}
+		return &GitObject{Type: base.Type, Body: body}, nil
+	default:
+		objectType, ok := packTypeNames[entryType]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown entry type %d at offset %d", ErrInvalidPack, entryType, offset)
+		}
+		return &GitObject{Type: objectType, Body: data}, nil
+	}
+}
+
+// readEntry decodes the entry header at offset and inflates its data.
+// For delta entries it also returns the base offset or base object ID.
+func (r *packReader) readEntry(offset int64) (int, []byte, int64, GitHash, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r.file, offset, r.size-offset))
+
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, 0, GitHash{}, fmt.Errorf("%w: read entry at %d: %v", ErrInvalidPack, offset, err)
+	}
+	entryType := int(header>>4) & 0x7
+	size := uint64(header & 0x0f)
+	for shift := 4; header&0x80 != 0; shift += 7 {
+		if header, err = reader.ReadByte(); err != nil {
+			return 0, nil, 0, GitHash{}, fmt.Errorf("%w: read entry size at %d: %v", ErrInvalidPack, offset, err)
+		}
+		size |= uint64(header&0x7f) << shift
+	}
+
+	var baseOffset int64
+	var baseHash GitHash
+	switch entryType {
+	case packTypeOfsDelta:
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, 0, GitHash{}, fmt.Errorf("%w: read delta offset at %d: %v", ErrInvalidPack, offset, err)
+		}
+		distance := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = reader.ReadByte(); err != nil {
+				return 0, nil, 0, GitHash{}, fmt.Errorf("%w: read delta offset at %d: %v", ErrInvalidPack, offset, err)
+			}
+			distance = ((distance + 1) << 7) | int64(b&0x7f)
+		}
+		baseOffset = offset - distance
+	case packTypeRefDelta:
+		if _, err := io.ReadFull(reader, baseHash[:]); err != nil {
+			return 0, nil, 0, GitHash{}, fmt.Errorf("%w: read delta base at %d: %v", ErrInvalidPack, offset, err)
+		}
+	}
+
+	inflater, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, 0, GitHash{}, fmt.Errorf("%w: inflate entry at %d: %v", ErrInvalidPack, offset, err)
+	}
+	defer inflater.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(inflater, data); err != nil {
+		return 0, nil, 0, GitHash{}, fmt.Errorf("%w: inflate entry at %d: %v", ErrInvalidPack, offset, err)
+	}
+	return entryType, data, baseOffset, baseHash, nil
+}
+
+// applyDelta reconstructs a target object from base and a Git delta stream.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	sourceSize, rest, err := readDeltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSize != uint64(len(base)) {
+		return nil, fmt.Errorf("%w: delta base size %d, have %d", ErrInvalidPack, sourceSize, len(base))
+	}
+	targetSize, rest, err := readDeltaSize(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	target := make([]byte, 0, targetSize)
+	for len(rest) > 0 {
+		op := rest[0]
+		rest = rest[1:]
+		if op&0x80 == 0 {
+			if op == 0 || int(op) > len(rest) {
+				return nil, fmt.Errorf("%w: invalid delta insert", ErrInvalidPack)
+			}
+			target = append(target, rest[:op]...)
+			rest = rest[op:]
+			continue
+		}
+
+		var copyOffset, copySize uint64
+		for i := 0; i < 4; i++ {
+			if op&(1<<i) != 0 {
+				if len(rest) == 0 {
+					return nil, fmt.Errorf("%w: truncated delta copy", ErrInvalidPack)
+				}
+				copyOffset |= uint64(rest[0]) << (8 * i)
+				rest = rest[1:]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if op&(1<<(4+i)) != 0 {
+				if len(rest) == 0 {
+					return nil, fmt.Errorf("%w: truncated delta copy", ErrInvalidPack)
+				}
+				copySize |= uint64(rest[0]) << (8 * i)
+				rest = rest[1:]
+			}
+		}
+		if copySize == 0 {
+			copySize = 0x10000
+		}
+		if copyOffset+copySize > uint64(len(base)) {
+			return nil, fmt.Errorf("%w: delta copy out of range", ErrInvalidPack)
+		}
+		target = append(target, base[copyOffset:copyOffset+copySize]...)
+	}
+	if uint64(len(target)) != targetSize {
+		return nil, fmt.Errorf("%w: delta produced %d bytes, want %d", ErrInvalidPack, len(target), targetSize)
+	}
+	return target, nil
+}
+
+// readDeltaSize decodes a little-endian base-128 size from the start of data.
+func readDeltaSize(data []byte) (uint64, []byte, error) {
+	var size uint64
+	for i, shift := 0, 0; i < len(data); i, shift = i+1, shift+7 {
+		size |= uint64(data[i]&0x7f) << shift
+		if data[i]&0x80 == 0 {
+			return size, data[i+1:], nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: truncated delta size", ErrInvalidPack)
+}
+
+// packEntry records where an object was written in a new pack.
+type packEntry struct {
+	hash   GitHash
+	offset int64
+	crc    uint32
+}
+
+// writePack writes objects as undeltified entries to a new pack and index
+// under packDir, named after the pack checksum. It returns the pack checksum.
+func writePack(packDir string, objects []*GitObject) (GitHash, error) {
+	var pack bytes.Buffer
+	pack.WriteString(packSignature)
+	_ = binary.Write(&pack, binary.BigEndian, uint32(packVersion))
+	_ = binary.Write(&pack, binary.BigEndian, uint32(len(objects)))
+
+	typeCodes := map[GitObjectType]int{
+		GitObjectTypeCommit: packTypeCommit,
+		GitObjectTypeTree:   packTypeTree,
+		GitObjectTypeBlob:   packTypeBlob,
+		GitObjectTypeTag:    packTypeTag,
+	}
+
+	entries := make([]packEntry, 0, len(objects))
+	for _, object := range objects {
+		var entry bytes.Buffer
+		size := uint64(len(object.Body))
+		header := byte(typeCodes[object.Type]<<4) | byte(size&0x0f)
+		size >>= 4
+		for size > 0 {
+			entry.WriteByte(header | 0x80)
+			header = byte(size & 0x7f)
+			size >>= 7
+		}
+		entry.WriteByte(header)
+
+		deflater := zlib.NewWriter(&entry)
+		if _, err := deflater.Write(object.Body); err != nil {
+			return GitHash{}, err
+		}
+		if err := deflater.Close(); err != nil {
+			return GitHash{}, err
+		}
+
+		entries = append(
+			entries, packEntry{
+				hash:   object.Hash(),
+				offset: int64(pack.Len()),
+				crc:    crc32.ChecksumIEEE(entry.Bytes()),
+			},
+		)
+		pack.Write(entry.Bytes())
+	}
+	packChecksum := GitHash(sha1.Sum(pack.Bytes()))
+	pack.Write(packChecksum[:])
+
+	slices.SortFunc(
+		entries, func(a, b packEntry) int {
+			return bytes.Compare(a.hash[:], b.hash[:])
+		},
+	)
+	index := buildPackIndex(entries, packChecksum)
+
+	if err := os.MkdirAll(packDir, gitDirPermission); err != nil {
+		return GitHash{}, fmt.Errorf("create pack directory %q: %w", packDir, err)
+	}
+	baseName := filepath.Join(packDir, "pack-"+packChecksum.Hex())
+	if err := os.WriteFile(baseName+".pack", pack.Bytes(), gitObjectFileMode); err != nil {
+		return GitHash{}, fmt.Errorf("write pack: %w", err)
+	}
+	if err := os.WriteFile(baseName+".idx", index, gitObjectFileMode); err != nil {
+		return GitHash{}, fmt.Errorf("write pack index: %w", err)
+	}
+	return packChecksum, nil
+}
+
+// buildPackIndex serializes a version 2 pack index for entries sorted by ID.
+func buildPackIndex(entries []packEntry, packChecksum GitHash) []byte {
+	var index bytes.Buffer
+	index.Write(packIndexSignature)
+	_ = binary.Write(&index, binary.BigEndian, uint32(packIndexVersion))
+
+	var fanout [256]uint32
+	for _, entry := range entries {
+		fanout[entry.hash[0]]++
+	}
+	var cumulative uint32
+	for i := range fanout {
+		cumulative += fanout[i]
+		_ = binary.Write(&index, binary.BigEndian, cumulative)
+	}
+	for _, entry := range entries {
+		index.Write(entry.hash[:])
+	}
+	for _, entry := range entries {
+		_ = binary.Write(&index, binary.BigEndian, entry.crc)
+	}
+
+	var largeOffsets []uint64
+	for _, entry := range entries {
+		if entry.offset < packLargeOffsetFlag {
+			_ = binary.Write(&index, binary.BigEndian, uint32(entry.offset))
+			continue
+		}
+		_ = binary.Write(&index, binary.BigEndian, uint32(packLargeOffsetFlag|len(largeOffsets)))
+		largeOffsets = append(largeOffsets, uint64(entry.offset))
+	}
+	for _, offset := range largeOffsets {
+		_ = binary.Write(&index, binary.BigEndian, offset)
+	}
+
+	index.Write(packChecksum[:])
+	indexChecksum := sha1.Sum(index.Bytes())
+	index.Write(indexChecksum[:])
+	return index.Bytes()
+}
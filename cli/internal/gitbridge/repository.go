@@ -0,0 +1,297 @@
+package gitbridge
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	gitDirName         = ".git"
+	gitObjectsDirName  = "objects"
+	gitPackDirName     = "pack"
+	gitRefsDirName     = "refs"
+	gitHeadsDirName    = "heads"
+	gitHeadFileName    = "HEAD"
+	gitPackedRefsName  = "packed-refs"
+	gitSymbolicPrefix  = "ref: "
+	gitFilePermission  = 0o644
+	gitDirPermission   = 0o755
+	gitObjectFileMode  = 0o444
+	gitPackFileSuffix  = ".pack"
+	gitIndexFileSuffix = ".idx"
+)
+
+// GitRepository reads and writes objects and branch refs of an on-disk Git repository.
+//
+// Objects are looked up as loose files first and then in every pack under
+// objects/pack. Only SHA-1 repositories are supported.
+type GitRepository struct {
+	gitDir string
+	bare   bool
+	packs  []*packFile
+}
+
+// OpenGitRepository opens path as a Git working tree (path/.git) or a bare repository.
+func OpenGitRepository(path string) (*GitRepository, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", path, err)
+	}
+
+	gitDir := filepath.Join(absPath, gitDirName)
+	bare := false
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		gitDir = absPath
+		bare = true
+	}
+	for _, required := range []string{gitHeadFileName, gitObjectsDirName, gitRefsDirName} {
+		if _, err := os.Stat(filepath.Join(gitDir, required)); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrNotAGitRepository, path)
+		}
+	}
+
+	repository := &GitRepository{gitDir: gitDir, bare: bare}
+	if err := repository.loadPacks(); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+// loadPacks opens the index of every pack in objects/pack.
+func (g *GitRepository) loadPacks() error {
+	packDir := filepath.Join(g.gitDir, gitObjectsDirName, gitPackDirName)
+	indexPaths, err := filepath.Glob(filepath.Join(packDir, "*"+gitIndexFileSuffix))
+	if err != nil {
+		return err
+	}
+	slices.Sort(indexPaths)
+
+	g.packs = nil
+	for _, indexPath := range indexPaths {
+		packPath := strings.TrimSuffix(indexPath, gitIndexFileSuffix) + gitPackFileSuffix
+		pack, err := openPackFile(packPath, indexPath)
+		if err != nil {
+			return err
+		}
+		g.packs = append(g.packs, pack)
+	}
+	return nil
+}
+
+// Read returns the object with the given ID from loose storage or a pack.
+func (g *GitRepository) Read(hash GitHash) (*GitObject, error) {
+	data, err := os.ReadFile(g.loosePath(hash))
+	if err == nil {
+		inflated, err := inflate(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidGitObject, hash, err)
+		}
+		return parseGitObject(inflated)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read git object %s: %w", hash, err)
+	}
+
+	for _, pack := range g.packs {
+		offset, ok := pack.offsets[hash]
+		if !ok {
+			continue
+		}
+		return g.readFromPack(pack, offset)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrGitObjectNotFound, hash)
+}
+
+// readFromPack opens pack and reads the entry at offset.
+func (g *GitRepository) readFromPack(pack *packFile, offset int64) (*GitObject, error) {
+	file, err := os.Open(pack.path)
+	if err != nil {
+		return nil, fmt.Errorf("open pack %q: %w", pack.path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	reader := &packReader{pack: pack, file: file, size: info.Size(), resolveRef: g.Read}
+	return reader.read(offset)
+}
+
+// Exists reports whether hash is stored loose or in a pack.
+func (g *GitRepository) Exists(hash GitHash) (bool, error) {
+	if _, err := os.Stat(g.loosePath(hash)); err == nil {
+		return true, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	for _, pack := range g.packs {
+		if _, ok := pack.offsets[hash]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WriteLoose stores object as a zlib-compressed loose object and returns its ID.
+func (g *GitRepository) WriteLoose(object *GitObject) (GitHash, error) {
+	hash := object.Hash()
+	path := g.loosePath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	var buffer bytes.Buffer
+	deflater := zlib.NewWriter(&buffer)
+	if _, err := deflater.Write(object.Serialize()); err != nil {
+		return GitHash{}, err
+	}
+	if err := deflater.Close(); err != nil {
+		return GitHash{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), gitDirPermission); err != nil {
+		return GitHash{}, fmt.Errorf("create object directory: %w", err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), gitObjectFileMode); err != nil {
+		return GitHash{}, fmt.Errorf("write git object %s: %w", hash, err)
+	}
+	return hash, nil
+}
+
+// WritePack stores objects in one new pack with an index and makes them readable.
+func (g *GitRepository) WritePack(objects []*GitObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	packDir := filepath.Join(g.gitDir, gitObjectsDirName, gitPackDirName)
+	if _, err := writePack(packDir, objects); err != nil {
+		return err
+	}
+	return g.loadPacks()
+}
+
+// Branches returns branch names and IDs from loose refs/heads and packed-refs.
+// Loose refs take precedence over packed entries.
+func (g *GitRepository) Branches() (map[string]GitHash, error) {
+	branches, err := g.readPackedBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	headsDir := filepath.Join(g.gitDir, gitRefsDirName, gitHeadsDirName)
+	err = filepath.WalkDir(
+		headsDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			name, err := filepath.Rel(headsDir, path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			hash, err := NewGitHashFromHex(strings.TrimSpace(string(content)))
+			if err != nil {
+				return fmt.Errorf("ref %q: %w", name, err)
+			}
+			branches[filepath.ToSlash(name)] = hash
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read git branches: %w", err)
+	}
+	return branches, nil
+}
+
+// readPackedBranches parses refs/heads entries from packed-refs.
+func (g *GitRepository) readPackedBranches() (map[string]GitHash, error) {
+	branches := make(map[string]GitHash)
+	file, err := os.Open(filepath.Join(g.gitDir, gitPackedRefsName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return branches, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	headsPrefix := gitRefsDirName + "/" + gitHeadsDirName + "/"
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		hexHash, ref, ok := strings.Cut(line, " ")
+		if !ok || !strings.HasPrefix(ref, headsPrefix) {
+			continue
+		}
+		hash, err := NewGitHashFromHex(hexHash)
+		if err != nil {
+			return nil, fmt.Errorf("packed-refs: %w", err)
+		}
+		branches[strings.TrimPrefix(ref, headsPrefix)] = hash
+	}
+	return branches, scanner.Err()
+}
+
+// WriteBranch writes refs/heads/<name> as a loose ref, which overrides packed-refs.
+func (g *GitRepository) WriteBranch(name string, hash GitHash) error {
+	path := filepath.Join(g.gitDir, gitRefsDirName, gitHeadsDirName, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), gitDirPermission); err != nil {
+		return fmt.Errorf("create ref directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hash.Hex()+"\n"), gitFilePermission); err != nil {
+		return fmt.Errorf("write git ref %q: %w", name, err)
+	}
+	return nil
+}
+
+// CurrentBranch returns the branch HEAD points at, or false when HEAD is detached.
+func (g *GitRepository) CurrentBranch() (string, bool, error) {
+	content, err := os.ReadFile(filepath.Join(g.gitDir, gitHeadFileName))
+	if err != nil {
+		return "", false, fmt.Errorf("read git HEAD: %w", err)
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(content)), gitSymbolicPrefix)
+	if !ok {
+		return "", false, nil
+	}
+	name, ok := strings.CutPrefix(ref, gitRefsDirName+"/"+gitHeadsDirName+"/")
+	return name, ok, nil
+}
+
+// IsBare reports whether the repository has no working tree.
+func (g *GitRepository) IsBare() bool {
+	return g.bare
+}
+
+// loosePath returns objects/<2 hex>/<38 hex> for hash.
+func (g *GitRepository) loosePath(hash GitHash) string {
+	hexHash := hash.Hex()
+	return filepath.Join(g.gitDir, gitObjectsDirName, hexHash[:2], hexHash[2:])
+}
+
+// inflate decompresses zlib data.
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
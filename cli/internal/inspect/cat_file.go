@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 )
 
 // CatFileOptions controls which cat-file outputs are produced.
@@ -133,8 +134,7 @@ func (c *CatFileService) catFileWithPretty(writer io.Writer, object domain.Objec
 		if _, err := fmt.Fprintf(
 			writer,
 			"%s %s <%s> %s %s\n"+
-				"%s %s <%s> %s %s\n"+
-				"\n%s\n",
+				"%s %s <%s> %s %s\n",
 			domain.CommitFieldAuthor,
 			commit.Author.Name,
 			commit.Author.Email,
@@ -145,10 +145,36 @@ func (c *CatFileService) catFileWithPretty(writer io.Writer, object domain.Objec
 			commit.Committer.Email,
 			commit.Committer.Timestamp,
 			commit.Committer.Timezone,
-			commit.Message,
 		); err != nil {
 			return fmt.Errorf("cat file: %w", err)
 		}
+		for _, header := range commit.ExtraHeaders {
+			if _, err := fmt.Fprintf(
+				writer,
+				"%s %s\n",
+				header.Key,
+				strings.ReplaceAll(header.Value, "\n", "\n "),
+			); err != nil {
+				return fmt.Errorf("cat file: %w", err)
+			}
+		}
+		if commit.Signature != "" {
+			if _, err := fmt.Fprintf(
+				writer,
+				"%s %s\n",
+				domain.CommitFieldGPGSig,
+				strings.ReplaceAll(commit.Signature, "\n", "\n "),
+			); err != nil {
+				return fmt.Errorf("cat file: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintf(writer, "\n%s\n", commit.Message); err != nil {
+			return fmt.Errorf("cat file: %w", err)
+		}
+	case domain.ObjectTypeTag:
+		if _, err := writer.Write(object.Body()); err != nil {
+			return fmt.Errorf("cat file: %w", err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,83 @@
+package inspect
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CheckIgnoreOptions controls check-ignore evaluation.
+type CheckIgnoreOptions struct {
+	// NoIndex also evaluates tracked paths, which are otherwise never ignored.
+	NoIndex bool
+}
+
+// CheckIgnoreResult reports the ignore decision for one path.
+type CheckIgnoreResult struct {
+	// Path is the path as given on the command line.
+	Path string
+	// Ignored reports whether the path is excluded.
+	Ignored bool
+	// Rule is the deciding rule, or nil when no rule matches. A negated rule
+	// matched but re-included the path.
+	Rule *core.IgnoreRule
+}
+
+// CheckIgnoreService explains which ignore rule applies to a path.
+type CheckIgnoreService struct {
+	indexService  *core.IndexService
+	ignoreMatcher *core.IgnoreMatcher
+	workspace     *domain.Workspace
+}
+
+// NewCheckIgnoreService creates a check-ignore service.
+func NewCheckIgnoreService(
+	indexService *core.IndexService,
+	ignoreMatcher *core.IgnoreMatcher,
+	workspace *domain.Workspace,
+) *CheckIgnoreService {
+	return &CheckIgnoreService{
+		indexService:  indexService,
+		ignoreMatcher: ignoreMatcher,
+		workspace:     workspace,
+	}
+}
+
+// CheckIgnore evaluates each path against the ignore rules.
+// Paths are resolved relative to the current directory; a trailing "/" or an
+// existing directory is matched as a directory.
+func (c *CheckIgnoreService) CheckIgnore(paths []string, options CheckIgnoreOptions) ([]CheckIgnoreResult, error) {
+	index, err := c.indexService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("check-ignore: %w", err)
+	}
+
+	results := make([]CheckIgnoreResult, 0, len(paths))
+	for _, path := range paths {
+		normalizedPath, err := domain.NewNormalizedPath(path, c.workspace.RepoDir)
+		if err != nil {
+			return nil, fmt.Errorf("check-ignore: %w", err)
+		}
+
+		result := CheckIgnoreResult{Path: path}
+		if !options.NoIndex && index.HasEntry(normalizedPath) {
+			results = append(results, result)
+			continue
+		}
+
+		isDir := strings.HasSuffix(path, "/")
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			isDir = true
+		}
+		rule, err := c.ignoreMatcher.Match(normalizedPath.String(), isDir)
+		if err != nil {
+			return nil, fmt.Errorf("check-ignore: %w", err)
+		}
+		result.Rule = rule
+		result.Ignored = rule != nil && !rule.Negate
+		results = append(results, result)
+	}
+	return results, nil
+}
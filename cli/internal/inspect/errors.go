@@ -11,4 +11,7 @@ var (
 
 	// ErrInvalidRestoreMode is returned when an unknown RestoreMode is passed.
 	ErrInvalidRestoreMode = errors.New("invalid restore mode")
+
+	// ErrRepositoryCorrupt is returned when fsck finds missing or corrupt objects.
+	ErrRepositoryCorrupt = errors.New("repository is corrupt")
 )
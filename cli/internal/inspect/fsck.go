@@ -0,0 +1,225 @@
+package inspect
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FsckResult reports the outcome of a connectivity and integrity check.
+type FsckResult struct {
+	// ObjectsChecked is the number of distinct reachable objects read and verified.
+	ObjectsChecked int
+	// PromisedMissing is the number of missing blobs excused by a promisor remote.
+	PromisedMissing int
+	// Problems lists human-readable descriptions of missing or corrupt objects.
+	Problems []string
+}
+
+// FsckService verifies that every object reachable from HEAD and refs exists
+// and hashes to its name.
+//
+// Parents of shallow boundary commits are not required. In a partial clone,
+// missing blobs are promised by the promisor remote and are not problems.
+type FsckService struct {
+	objectService  *core.ObjectService
+	refService     *core.RefService
+	shallowService *core.ShallowService
+	configService  *core.ConfigService
+	workspace      *domain.Workspace
+}
+
+// NewFsckService creates an fsck service.
+func NewFsckService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	shallowService *core.ShallowService,
+	configService *core.ConfigService,
+	workspace *domain.Workspace,
+) *FsckService {
+	return &FsckService{
+		objectService:  objectService,
+		refService:     refService,
+		shallowService: shallowService,
+		configService:  configService,
+		workspace:      workspace,
+	}
+}
+
+// fsckTarget is one object queued for verification with the type its referrer expects.
+type fsckTarget struct {
+	hash       domain.Hash
+	objectType domain.ObjectType
+	referrer   string
+}
+
+// Fsck walks all reachable objects and returns the problems it found.
+func (f *FsckService) Fsck() (*FsckResult, error) {
+	grafts, err := f.shallowService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("fsck: %w", err)
+	}
+	_, promisor, err := f.configService.PromisorRemote()
+	if err != nil {
+		return nil, fmt.Errorf("fsck: %w", err)
+	}
+	roots, err := f.collectRoots()
+	if err != nil {
+		return nil, fmt.Errorf("fsck: %w", err)
+	}
+
+	result := &FsckResult{}
+	visited := make(map[domain.Hash]bool)
+	stack := roots
+	for len(stack) > 0 {
+		target := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[target.hash] {
+			continue
+		}
+		visited[target.hash] = true
+
+		exists, err := f.objectService.Exists(target.hash)
+		if err != nil {
+			return nil, fmt.Errorf("fsck: %w", err)
+		}
+		if !exists {
+			if promisor && target.objectType == domain.ObjectTypeBlob {
+				result.PromisedMissing++
+				continue
+			}
+			result.Problems = append(
+				result.Problems,
+				fmt.Sprintf("missing %s %s (referenced by %s)", target.objectType, target.hash, target.referrer),
+			)
+			continue
+		}
+
+		object, err := f.objectService.Read(target.hash)
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("corrupt object %s: %v", target.hash, err))
+			continue
+		}
+		result.ObjectsChecked++
+
+		actualHash, err := domain.NewHashFromHex(core.ComputeSHA256(object.Serialize()))
+		if err != nil {
+			return nil, fmt.Errorf("fsck: %w", err)
+		}
+		if actualHash != target.hash {
+			result.Problems = append(
+				result.Problems,
+				fmt.Sprintf("hash mismatch for %s (content hashes to %s)", target.hash, actualHash),
+			)
+		}
+		if object.Type() != target.objectType {
+			result.Problems = append(
+				result.Problems,
+				fmt.Sprintf("object %s is a %s, expected %s", target.hash, object.Type(), target.objectType),
+			)
+			continue
+		}
+
+		referrer := fmt.Sprintf("%s %s", object.Type(), target.hash)
+		switch obj := object.(type) {
+		case *domain.Commit:
+			stack = append(stack, fsckTarget{obj.TreeHash, domain.ObjectTypeTree, referrer})
+			for _, parentHash := range grafts.Parents(target.hash, obj) {
+				stack = append(stack, fsckTarget{parentHash, domain.ObjectTypeCommit, referrer})
+			}
+		case *domain.Tag:
+			stack = append(stack, fsckTarget{obj.ObjectHash, obj.ObjectType, referrer})
+		case *domain.Tree:
+			for _, entry := range obj.Entries() {
+				// Submodule commits belong to another repository.
+				if entry.Mode.IsGitlink() {
+					continue
+				}
+				entryType, err := entry.Mode.ObjectType()
+				if err != nil {
+					result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", referrer, err))
+					continue
+				}
+				stack = append(stack, fsckTarget{entry.Hash, entryType, referrer})
+			}
+		}
+	}
+	return result, nil
+}
+
+// collectRoots returns HEAD and every ref under .gel/refs as commit targets,
+// except annotated tags under refs/tags, which are tag targets.
+func (f *FsckService) collectRoots() ([]fsckTarget, error) {
+	var roots []fsckTarget
+	headHash, err := f.refService.Resolve(domain.HeadFileName)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, err
+	}
+	if err == nil && !headHash.IsEmpty() {
+		roots = append(roots, fsckTarget{headHash, domain.ObjectTypeCommit, domain.HeadFileName})
+	}
+
+	var refs []string
+	refsDir := f.workspace.RefsDir.String()
+	err = filepath.WalkDir(
+		refsDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(f.workspace.CommonDir.String(), path)
+			if err != nil {
+				return err
+			}
+			refs = append(refs, filepath.ToSlash(rel))
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	slices.Sort(refs)
+
+	tagsPrefix := domain.RefsDirName + "/" + domain.TagsDirName + "/"
+
+	for _, ref := range refs {
+		hash, err := f.refService.Read(ref)
+		if err != nil {
+			return nil, err
+		}
+		if hash.IsEmpty() {
+			continue
+		}
+		objectType := domain.ObjectTypeCommit
+		if strings.HasPrefix(ref, tagsPrefix) {
+			objectType, err = f.tagTargetType(hash)
+			if err != nil {
+				return nil, err
+			}
+		}
+		roots = append(roots, fsckTarget{hash, objectType, ref})
+	}
+	return roots, nil
+}
+
+// tagTargetType returns the type a tag ref pointing at hash must have: tag
+// for an annotated tag, and commit for a lightweight tag or a missing object,
+// which the walk then reports.
+func (f *FsckService) tagTargetType(hash domain.Hash) (domain.ObjectType, error) {
+	exists, err := f.objectService.Exists(hash)
+	if err != nil || !exists {
+		return domain.ObjectTypeCommit, err
+	}
+	object, err := f.objectService.Read(hash)
+	if err != nil || object.Type() != domain.ObjectTypeTag {
+		return domain.ObjectTypeCommit, nil
+	}
+	return domain.ObjectTypeTag, nil
+}
@@ -2,6 +2,7 @@ package inspect
 
 import (
 	"Gel/internal/core"
+	"Gel/internal/diff"
 	"Gel/internal/domain"
 	"errors"
 	"fmt"
@@ -90,6 +91,179 @@ func (r *RestoreService) Restore(paths []domain.AbsolutePath, options RestoreOpt
 	return nil
 }
 
+// RestorePatch interactively restores hunks of the given paths.
+//
+// For each path present on both sides of options.Mode with differing content,
+// the source-to-target diff is shown and selector decides which hunks to
+// revert in the target. Whole-file additions and deletions are not offered.
+// With no paths every differing tracked path is considered.
+func (r *RestoreService) RestorePatch(
+	paths []domain.AbsolutePath,
+	options RestoreOptions,
+	selector *diff.PatchSelector,
+) error {
+	scopes := make([]string, 0, len(paths))
+	for _, path := range paths {
+		normalizedPath, err := path.ToNormalizedPath(r.workspace.RepoDir)
+		if err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+		scopes = append(scopes, normalizedPath.String())
+	}
+
+	var err error
+	switch options.Mode {
+	case RestoreModeIndexVsWorkingTree:
+		err = r.restorePatchWorkingTree(nil, scopes, selector)
+	case RestoreModeCommitVsWorkingTree:
+		commitHash, resolveErr := r.resolveSource(options.Source)
+		if resolveErr != nil {
+			return fmt.Errorf("restore: %w", resolveErr)
+		}
+		err = r.restorePatchWorkingTree(&commitHash, scopes, selector)
+	case RestoreModeHEADVsIndex:
+		commitHash, resolveErr := r.refService.Resolve(domain.HeadFileName)
+		if resolveErr != nil {
+			return fmt.Errorf("restore: %w", resolveErr)
+		}
+		err = r.restorePatchIndex(commitHash, scopes, selector)
+	case RestoreModeCommitVsIndex:
+		commitHash, resolveErr := r.resolveSource(options.Source)
+		if resolveErr != nil {
+			return fmt.Errorf("restore: %w", resolveErr)
+		}
+		err = r.restorePatchIndex(commitHash, scopes, selector)
+	default:
+		err = ErrInvalidRestoreMode
+	}
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	return nil
+}
+
+// restorePatchWorkingTree reverts selected hunks of working tree files to the
+// index, or to commitHash when it is non-nil. Paths outside the sparse checkout are skipped.
+func (r *RestoreService) restorePatchWorkingTree(
+	commitHash *domain.Hash,
+	scopes []string,
+	selector *diff.PatchSelector,
+) error {
+	skipped, err := r.skipWorktreePaths()
+	if err != nil {
+		return err
+	}
+	sourcePathHashes, err := r.treeResolver.ResolveIndex()
+	if commitHash != nil {
+		sourcePathHashes, err = r.treeResolver.ResolveCommit(*commitHash)
+	}
+	if err != nil {
+		return err
+	}
+	sourcePathModes, err := r.treeResolver.ResolveIndexModes()
+	if commitHash != nil {
+		sourcePathModes, err = r.treeResolver.ResolveCommitModes(*commitHash)
+	}
+	if err != nil {
+		return err
+	}
+	workingTreePathHashes, err := r.treeResolver.ResolveWorkingTree()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range sourcePathHashes.ExtractPathsSorted() {
+		workingTreeHash, ok := workingTreePathHashes[path]
+		if selector.Done() {
+			break
+		}
+		if !ok || workingTreeHash == sourcePathHashes[path] || skipped[path] || !core.MatchesPathspecs(path, scopes) {
+			continue
+		}
+		// A submodule has no content to split into hunks.
+		if sourcePathModes[path].IsGitlink() {
+			continue
+		}
+
+		blob, err := r.objectService.ReadBlob(sourcePathHashes[path])
+		if err != nil {
+			return err
+		}
+		absolutePath, err := path.ToAbsolutePath(r.workspace.RepoDir)
+		if err != nil {
+			return err
+		}
+		content, err := r.objectService.ReadWorkingContent(absolutePath)
+		if err != nil {
+			return err
+		}
+
+		result, selected, err := selector.Select(path.String(), string(blob.Body()), string(content))
+		if err != nil {
+			return err
+		}
+		if !selected {
+			continue
+		}
+		if err := r.objectService.WriteWorkingContent(absolutePath, []byte(result), sourcePathModes[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePatchIndex reverts selected hunks of index entries to commitHash.
+func (r *RestoreService) restorePatchIndex(
+	commitHash domain.Hash,
+	scopes []string,
+	selector *diff.PatchSelector,
+) error {
+	commitPathHashes, err := r.treeResolver.ResolveCommit(commitHash)
+	if err != nil {
+		return err
+	}
+	index, err := r.indexService.Read()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range index.Entries {
+		commitBlobHash, ok := commitPathHashes[entry.Path]
+		if selector.Done() {
+			break
+		}
+		if !ok || commitBlobHash == entry.Hash || !core.MatchesPathspecs(entry.Path, scopes) ||
+			domain.FileMode(entry.Mode).IsGitlink() {
+			continue
+		}
+
+		commitBlob, err := r.objectService.ReadBlob(commitBlobHash)
+		if err != nil {
+			return err
+		}
+		indexBlob, err := r.objectService.ReadBlob(entry.Hash)
+		if err != nil {
+			return err
+		}
+
+		result, selected, err := selector.Select(
+			entry.Path.String(), string(commitBlob.Body()), string(indexBlob.Body()),
+		)
+		if err != nil {
+			return err
+		}
+		if !selected {
+			continue
+		}
+		hash, err := r.objectService.WriteBlob([]byte(result))
+		if err != nil {
+			return err
+		}
+		index.SetEntry(domain.NewEmptyIndexEntry(entry.Path, hash, entry.Mode))
+	}
+	return r.indexService.Write(index)
+}
+
 // restoreIndexVsWorkingTree updates working tree files from index blob snapshots.
 func (r *RestoreService) restoreIndexVsWorkingTree(paths []domain.AbsolutePath) error {
 	index, err := r.indexService.Read()
@@ -103,7 +277,8 @@ func (r *RestoreService) restoreIndexVsWorkingTree(paths []domain.AbsolutePath)
 		}
 
 		entry, _ := index.FindEntry(normalizedPath)
-		if entry == nil {
+		if entry == nil || entry.IntentToAdd() {
+			// An intent-to-add entry has no content to restore.
 			continue
 		}
 
@@ -115,16 +290,7 @@ func (r *RestoreService) restoreIndexVsWorkingTree(paths []domain.AbsolutePath)
 			continue
 		}
 
-		blob, err := r.objectService.ReadBlob(entry.Hash)
-		if err != nil {
-			return err
-		}
-
-		dir := filepath.Dir(path.String())
-		if err := os.MkdirAll(dir, domain.DefaultDirPermission); err != nil {
-			return err
-		}
-		if err := os.WriteFile(path.String(), blob.Body(), domain.DefaultFilePermission); err != nil {
+		if err := r.objectService.CheckoutBlob(entry.Hash, path, domain.FileMode(entry.Mode)); err != nil {
 			return err
 		}
 	}
@@ -142,22 +308,41 @@ func (r *RestoreService) restoreHEADVsIndex(paths []domain.AbsolutePath) error {
 
 // restoreCommitVsWorkingTree updates working tree files to match the source commit.
 // Paths missing in the source commit are removed from the working tree.
+// Paths outside the sparse checkout are skipped.
 func (r *RestoreService) restoreCommitVsWorkingTree(commitHash domain.Hash, paths []domain.AbsolutePath) error {
+	skipped, err := r.skipWorktreePaths()
+	if err != nil {
+		return err
+	}
+
 	commitPathHashes, err := r.treeResolver.ResolveCommit(commitHash)
 	if err != nil {
 		return err
 	}
 
+	commitPathModes, err := r.treeResolver.ResolveCommitModes(commitHash)
+	if err != nil {
+		return err
+	}
+
 	workingTreePathHashes, err := r.treeResolver.ResolveWorkingTree()
 	if err != nil {
 		return err
 	}
 
+	workingTreePathModes, err := r.treeResolver.ResolveWorkingTreeModes(workingTreePathHashes.ExtractPaths())
+	if err != nil {
+		return err
+	}
+
 	for _, path := range paths {
 		normalizedPath, err := path.ToNormalizedPath(r.workspace.RepoDir)
 		if err != nil {
 			return err
 		}
+		if skipped[normalizedPath] {
+			continue
+		}
 
 		commitHash, inCommit := commitPathHashes[normalizedPath]
 		if !inCommit {
@@ -169,17 +354,9 @@ func (r *RestoreService) restoreCommitVsWorkingTree(commitHash domain.Hash, path
 		}
 
 		workingTreeHash, inWorkingTree := workingTreePathHashes[normalizedPath]
-		if !inWorkingTree || commitHash != workingTreeHash {
-			blob, err := r.objectService.ReadBlob(commitHash)
-			if err != nil {
-				return err
-			}
-
-			dir := filepath.Dir(path.String())
-			if err := os.MkdirAll(dir, domain.DefaultDirPermission); err != nil {
-				return err
-			}
-			if err := os.WriteFile(path.String(), blob.Body(), domain.DefaultFilePermission); err != nil {
+		commitMode := commitPathModes[normalizedPath]
+		if !inWorkingTree || commitHash != workingTreeHash || !commitMode.SameType(workingTreePathModes[normalizedPath]) {
+			if err := r.objectService.CheckoutBlob(commitHash, path, commitMode); err != nil {
 				return err
 			}
 		}
@@ -217,10 +394,13 @@ func (r *RestoreService) restoreCommitVsIndex(commitHash domain.Hash, paths []do
 		switch {
 		case !inCommit && !inIndex:
 			continue
-		case inCommit && inIndex && indexEntry.Hash == treeEntry.Hash:
+		case inCommit && inIndex && indexEntry.Hash == treeEntry.Hash && treeEntry.Mode.Equals(domain.FileMode(indexEntry.Mode)):
 			continue
 		case inCommit:
 			newIndexEntry := domain.NewEmptyIndexEntry(normalizedPath, treeEntry.Hash, treeEntry.Mode.Uint32())
+			if inIndex {
+				newIndexEntry.SetSkipWorktree(indexEntry.SkipWorktree())
+			}
 			index.SetEntry(newIndexEntry)
 		default:
 			index.RemoveEntry(normalizedPath)
@@ -229,6 +409,22 @@ func (r *RestoreService) restoreCommitVsIndex(commitHash domain.Hash, paths []do
 	return r.indexService.Write(index)
 }
 
+// skipWorktreePaths returns the index paths marked skip-worktree by sparse checkout.
+func (r *RestoreService) skipWorktreePaths() (map[domain.NormalizedPath]bool, error) {
+	entries, err := r.indexService.GetEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[domain.NormalizedPath]bool)
+	for _, entry := range entries {
+		if entry.SkipWorktree() {
+			skipped[entry.Path] = true
+		}
+	}
+	return skipped, nil
+}
+
 // resolveSource resolves a restore source string to a commit hash.
 // Supported inputs are HEAD, main, full refs/* paths, local branch names, and commit hashes.
 func (r *RestoreService) resolveSource(source string) (domain.Hash, error) {
@@ -0,0 +1,124 @@
+package inspect_test
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/diff"
+	"Gel/internal/inspect"
+	"Gel/internal/staging"
+	"Gel/internal/testutil"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	restorePatchOld = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	restorePatchNew = "one\nTWO\nthree\nfour\nfive\nSIX\nseven\neight\n"
+	// restorePatchFirst and restorePatchSecond carry one of the two changes.
+	restorePatchFirst  = "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\n"
+	restorePatchSecond = "one\ntwo\nthree\nfour\nfive\nSIX\nseven\neight\n"
+)
+
+// restorePatchRepository is a repository with the services restore -p and reset -p need.
+type restorePatchRepository struct {
+	*testutil.Repository
+	addService     *staging.AddService
+	restoreService *inspect.RestoreService
+}
+
+// newRestorePatchRepository initializes a repository in a temporary directory
+// and makes it the working directory of the test.
+func newRestorePatchRepository(t *testing.T) *restorePatchRepository {
+	t.Helper()
+	repository := testutil.NewRepository(t)
+	updateIndexService := staging.NewUpdateIndexService(
+		repository.IndexService, repository.ObjectService, core.NewHashObjectService(repository.ObjectService),
+		repository.ChangeDetector, repository.Workspace,
+	)
+	return &restorePatchRepository{
+		Repository: repository,
+		addService: staging.NewAddService(
+			repository.IndexService, repository.ObjectService, updateIndexService, repository.PathResolver,
+			repository.ChangeDetector, repository.Workspace,
+		),
+		restoreService: inspect.NewRestoreService(
+			repository.IndexService, repository.ObjectService, repository.RefService, repository.TreeResolver,
+			repository.ChangeDetector, repository.Workspace,
+		),
+	}
+}
+
+// stage adds every working tree file to the index.
+func (r *restorePatchRepository) stage(t *testing.T) {
+	t.Helper()
+	require.NoError(t, r.addService.Add([]string{"."}, staging.AddOptions{}).Error)
+}
+
+// restorePatchCases are answer sequences shared by restore -p and reset -p,
+// with the content the reverted side ends up with.
+var restorePatchCases = []struct {
+	name    string
+	answers string
+	want    string
+}{
+	{name: "revert hunk", answers: "y\n", want: restorePatchOld},
+	{name: "keep hunk", answers: "n\n", want: restorePatchNew},
+	{name: "quit", answers: "q\n", want: restorePatchNew},
+	{name: "end of input", answers: "", want: restorePatchNew},
+	{name: "split and revert first", answers: "s\ny\nn\n", want: restorePatchSecond},
+	{name: "split and revert second", answers: "s\nn\ny\n", want: restorePatchFirst},
+	{name: "split then quit", answers: "s\ny\nq\n", want: restorePatchSecond},
+}
+
+func TestRestorePatchWorkingTree(t *testing.T) {
+	for _, tt := range restorePatchCases {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repository := newRestorePatchRepository(t)
+				repository.WriteFile(t, "a.txt", restorePatchOld)
+				repository.stage(t)
+				repository.WriteFile(t, "a.txt", restorePatchNew)
+
+				selector := diff.NewPatchSelector(
+					strings.NewReader(tt.answers), io.Discard, "Discard", diff.PatchDirectionReverse, nil,
+				)
+				err := repository.restoreService.RestorePatch(
+					nil, inspect.RestoreOptions{Mode: inspect.RestoreModeIndexVsWorkingTree}, selector,
+				)
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, repository.ReadFile(t, "a.txt"))
+				assert.Equal(t, restorePatchOld, repository.StagedContent(t, "a.txt"))
+			},
+		)
+	}
+}
+
+func TestResetPatchIndex(t *testing.T) {
+	for _, tt := range restorePatchCases {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repository := newRestorePatchRepository(t)
+				repository.WriteFile(t, "a.txt", restorePatchOld)
+				repository.stage(t)
+				repository.Commit(t, "initial")
+				repository.WriteFile(t, "a.txt", restorePatchNew)
+				repository.stage(t)
+
+				selector := diff.NewPatchSelector(
+					strings.NewReader(tt.answers), io.Discard, "Unstage", diff.PatchDirectionReverse, nil,
+				)
+				err := repository.restoreService.RestorePatch(
+					nil, inspect.RestoreOptions{Mode: inspect.RestoreModeHEADVsIndex}, selector,
+				)
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, repository.StagedContent(t, "a.txt"))
+				assert.Equal(t, restorePatchNew, repository.ReadFile(t, "a.txt"))
+			},
+		)
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"Gel/internal/domain"
 	"errors"
 	"fmt"
+	"slices"
 )
 
 // FileStatus describes one path and its status label.
 type FileStatus struct {
 	Path   domain.NormalizedPath
 	Status string
+	// Note qualifies Status, such as "new commits" for a submodule whose
+	// recorded commit changed.
+	Note string
 }
 
 // StatusResult contains categorized repository changes for status output.
@@ -54,9 +58,23 @@ func (s *StatusService) Status() (*StatusResult, error) {
 		return nil, fmt.Errorf("status: %w", err)
 	}
 
-	result.Staged = collectStaged(indexPathHashes, headTreePathHashes)
-	result.Unstaged = collectUnstaged(indexPathHashes, workingTreePathHashes)
+	indexPathModes, headTreePathModes, workingTreePathModes, err := s.loadPathModes(indexPathHashes)
+	if err != nil {
+		return nil, fmt.Errorf("status: %w", err)
+	}
+
+	intentToAddPaths, err := s.treeResolver.ResolveIntentToAdd()
+	if err != nil {
+		return nil, fmt.Errorf("status: %w", err)
+	}
+
+	result.Staged = collectStaged(indexPathHashes, headTreePathHashes, indexPathModes, headTreePathModes)
+	result.Unstaged = collectUnstaged(indexPathHashes, workingTreePathHashes, indexPathModes, workingTreePathModes)
+	result.Unstaged = append(result.Unstaged, collectIntentToAdd(intentToAddPaths, workingTreePathHashes)...)
 	result.Untracked = collectUntracked(indexPathHashes, workingTreePathHashes)
+	result.Untracked = slices.DeleteFunc(result.Untracked, func(path domain.NormalizedPath) bool {
+		return slices.Contains(intentToAddPaths, path)
+	})
 
 	currentBranch, err := s.branchService.Current()
 	if err != nil {
@@ -91,34 +109,84 @@ func (s *StatusService) loadPathHashes() (
 	return
 }
 
-// collectStaged compares index against HEAD to find staged additions, modifications, and deletions.
-func collectStaged(indexPathHashes, headTreePathHashes core.PathHashes) (staged []FileStatus) {
+// loadPathModes resolves file modes from index, HEAD tree, and the working tree copies of tracked paths.
+func (s *StatusService) loadPathModes(indexPathHashes core.PathHashes) (
+	indexPathModes,
+	headTreePathModes,
+	workingTreePathModes core.PathModes,
+	err error,
+) {
+	indexPathModes, err = s.treeResolver.ResolveIndexModes()
+	if err != nil {
+		return
+	}
+
+	headTreePathModes, err = s.treeResolver.ResolveHEADModes()
+	if errors.Is(err, core.ErrRefNotFound) {
+		headTreePathModes, err = make(core.PathModes), nil
+	}
+	if err != nil {
+		return
+	}
+
+	workingTreePathModes, err = s.treeResolver.ResolveWorkingTreeModes(indexPathHashes.ExtractPaths())
+	return
+}
+
+// collectStaged compares index against HEAD to find staged additions, modifications, type changes, and deletions.
+func collectStaged(
+	indexPathHashes, headTreePathHashes core.PathHashes,
+	indexPathModes, headTreePathModes core.PathModes,
+) (staged []FileStatus) {
 	for indexPath, indexHash := range indexPathHashes {
 		headHash, inHead := headTreePathHashes[indexPath]
 		if !inHead {
-			staged = append(staged, FileStatus{indexPath, "New File"})
+			staged = append(staged, FileStatus{indexPath, "New File", ""})
+		} else if !indexPathModes[indexPath].SameType(headTreePathModes[indexPath]) {
+			staged = append(staged, FileStatus{indexPath, "Type Changed", ""})
 		} else if headHash != indexHash {
-			staged = append(staged, FileStatus{indexPath, "Modified"})
+			staged = append(staged, FileStatus{indexPath, "Modified", submoduleNote(indexPathModes[indexPath])})
 		}
 	}
 	for path := range headTreePathHashes {
 		if _, inIndex := indexPathHashes[path]; !inIndex {
-			staged = append(staged, FileStatus{path, "Deleted"})
+			staged = append(staged, FileStatus{path, "Deleted", ""})
 		}
 	}
 	return
 }
 
-// collectUnstaged compares working tree against index to find unstaged modifications and deletions.
-func collectUnstaged(indexPathHashes, workingTreePathHashes core.PathHashes) (unstaged []FileStatus) {
+// collectUnstaged compares working tree against index to find unstaged modifications, type changes, and deletions.
+func collectUnstaged(
+	indexPathHashes, workingTreePathHashes core.PathHashes,
+	indexPathModes, workingTreePathModes core.PathModes,
+) (unstaged []FileStatus) {
 	for indexPath, indexHash := range indexPathHashes {
 		workingTreeHash, inWorkingDir := workingTreePathHashes[indexPath]
 		if !inWorkingDir {
 			// in Index but not in Working Dir
-			unstaged = append(unstaged, FileStatus{indexPath, "Deleted"})
+			unstaged = append(unstaged, FileStatus{indexPath, "Deleted", ""})
+		} else if !indexPathModes[indexPath].SameType(workingTreePathModes[indexPath]) {
+			// a file replaced by a symlink or vice versa
+			unstaged = append(unstaged, FileStatus{indexPath, "Type Changed", ""})
 		} else if workingTreeHash != indexHash {
 			// in Index and Working Dir but different
-			unstaged = append(unstaged, FileStatus{indexPath, "Modified"})
+			unstaged = append(unstaged, FileStatus{indexPath, "Modified", submoduleNote(indexPathModes[indexPath])})
+		}
+	}
+	return
+}
+
+// collectIntentToAdd reports intent-to-add paths as unstaged new files, or as
+// deleted when their file is gone: their content is not staged yet.
+func collectIntentToAdd(
+	intentToAddPaths []domain.NormalizedPath, workingTreePathHashes core.PathHashes,
+) (unstaged []FileStatus) {
+	for _, path := range intentToAddPaths {
+		if _, inWorkingDir := workingTreePathHashes[path]; inWorkingDir {
+			unstaged = append(unstaged, FileStatus{path, "New File", ""})
+		} else {
+			unstaged = append(unstaged, FileStatus{path, "Deleted", ""})
 		}
 	}
 	return
@@ -133,3 +201,12 @@ func collectUntracked(indexPathHashes, workingTreePathHashes core.PathHashes) (u
 	}
 	return
 }
+
+// submoduleNote returns the note of a modified path: a submodule modified
+// means it records or has checked out a different commit.
+func submoduleNote(mode domain.FileMode) string {
+	if mode.IsGitlink() {
+		return "new commits"
+	}
+	return ""
+}
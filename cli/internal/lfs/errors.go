@@ -0,0 +1,8 @@
+package lfs
+
+import "errors"
+
+var (
+	// ErrMissingLFSObjects is returned when some referenced payloads could not be transferred.
+	ErrMissingLFSObjects = errors.New("lfs objects are missing")
+)
@@ -0,0 +1,527 @@
+package lfs
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// File is a tracked path whose blob is a large file pointer.
+type File struct {
+	Path    domain.NormalizedPath
+	Pointer domain.LFSPointer
+	// Present reports whether the payload is in the local store.
+	Present bool
+}
+
+// FetchOptions controls which commits Fetch downloads payloads for.
+type FetchOptions struct {
+	// All fetches payloads for every local branch tip instead of only HEAD.
+	All bool
+}
+
+// FetchResult reports the payloads downloaded by Fetch.
+type FetchResult struct {
+	Fetched []domain.LFSPointer
+	// Missing lists payloads the remote store does not have.
+	Missing []domain.LFSPointer
+	// Updated lists working tree files replaced by their payload.
+	Updated []domain.NormalizedPath
+}
+
+// PushOptions controls which history Push uploads payloads for.
+type PushOptions struct {
+	// All pushes payloads for the history of every local branch instead of only HEAD.
+	All bool
+}
+
+// PushResult reports the payloads uploaded by Push.
+type PushResult struct {
+	Pushed []domain.LFSPointer
+	// Missing lists referenced payloads absent from the local store.
+	Missing []domain.LFSPointer
+}
+
+// PruneOptions controls Prune.
+type PruneOptions struct {
+	// DryRun reports what would be deleted without deleting anything.
+	DryRun bool
+}
+
+// PruneResult reports the payloads removed by Prune.
+type PruneResult struct {
+	Pruned []domain.LFSPointer
+	// Retained counts payloads kept in the store.
+	Retained int
+}
+
+// LFSService lists, transfers, and prunes large file payloads.
+//
+// The remote store is a plain directory: lfs.url when set, otherwise the
+// large file store of the repository at remote.origin.url.
+type LFSService struct {
+	objectService  *core.ObjectService
+	refService     *core.RefService
+	branchService  *branch.BranchService
+	treeResolver   *core.TreeResolver
+	commitResolver *core.CommitResolver
+	shallowService *core.ShallowService
+	configService  *core.ConfigService
+	store          *core.LFSStore
+	workspace      *domain.Workspace
+}
+
+// NewLFSService creates a large file service over the local store.
+func NewLFSService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	branchService *branch.BranchService,
+	treeResolver *core.TreeResolver,
+	commitResolver *core.CommitResolver,
+	shallowService *core.ShallowService,
+	configService *core.ConfigService,
+	store *core.LFSStore,
+	workspace *domain.Workspace,
+) *LFSService {
+	return &LFSService{
+		objectService:  objectService,
+		refService:     refService,
+		branchService:  branchService,
+		treeResolver:   treeResolver,
+		commitResolver: commitResolver,
+		shallowService: shallowService,
+		configService:  configService,
+		store:          store,
+		workspace:      workspace,
+	}
+}
+
+// LsFiles returns the large files in the index, or in the tree of revision when given.
+func (l *LFSService) LsFiles(revision string) ([]File, error) {
+	var pathHashes core.PathHashes
+	var pathModes core.PathModes
+	var err error
+	if revision == "" {
+		pathHashes, err = l.treeResolver.ResolveIndex()
+		if err == nil {
+			pathModes, err = l.treeResolver.ResolveIndexModes()
+		}
+	} else {
+		var commitHash domain.Hash
+		commitHash, err = l.commitResolver.Resolve(revision)
+		if err == nil {
+			pathHashes, err = l.treeResolver.ResolveCommit(commitHash)
+		}
+		if err == nil {
+			pathModes, err = l.treeResolver.ResolveCommitModes(commitHash)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+
+	files, err := l.files(pathHashes, pathModes, make(map[domain.Hash]*domain.LFSPointer))
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	return files, nil
+}
+
+// Fetch downloads the payloads referenced by HEAD, or by every branch tip with
+// options.All, that are missing locally. Working tree files still holding the
+// pointer of a now available payload are replaced by the payload.
+func (l *LFSService) Fetch(options FetchOptions) (*FetchResult, error) {
+	remote, err := core.RemoteLFSStore(l.configService)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	pointers, err := l.tipPointers(options.All)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+
+	result := &FetchResult{}
+	for _, pointer := range pointers {
+		present, err := l.store.Has(pointer.OID)
+		if err != nil {
+			return nil, fmt.Errorf("lfs: %w", err)
+		}
+		if present {
+			continue
+		}
+		if err := l.store.CopyFrom(remote, pointer); err != nil {
+			if errors.Is(err, core.ErrLFSObjectNotFound) {
+				result.Missing = append(result.Missing, pointer)
+				continue
+			}
+			return nil, fmt.Errorf("lfs: %w", err)
+		}
+		result.Fetched = append(result.Fetched, pointer)
+	}
+
+	if l.workspace.RequireWorkTree() == nil {
+		result.Updated, err = l.checkoutPointers()
+		if err != nil {
+			return result, fmt.Errorf("lfs: %w", err)
+		}
+	}
+	if len(result.Missing) > 0 {
+		return result, fmt.Errorf("lfs: %w on the remote store", ErrMissingLFSObjects)
+	}
+	return result, nil
+}
+
+// Push uploads the payloads referenced by the history of HEAD, or of every
+// branch with options.All, that the remote store does not have yet.
+func (l *LFSService) Push(options PushOptions) (*PushResult, error) {
+	remote, err := core.RemoteLFSStore(l.configService)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	tips, err := l.tips(options.All)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	pointers, err := l.historyPointers(tips, make(map[domain.Hash]*domain.LFSPointer))
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+
+	result := &PushResult{}
+	for _, pointer := range pointers {
+		present, err := remote.Has(pointer.OID)
+		if err != nil {
+			return nil, fmt.Errorf("lfs: %w", err)
+		}
+		if present {
+			continue
+		}
+		if err := remote.CopyFrom(l.store, pointer); err != nil {
+			if errors.Is(err, core.ErrLFSObjectNotFound) {
+				result.Missing = append(result.Missing, pointer)
+				continue
+			}
+			return nil, fmt.Errorf("lfs: %w", err)
+		}
+		result.Pushed = append(result.Pushed, pointer)
+	}
+	if len(result.Missing) > 0 {
+		return result, fmt.Errorf("lfs: %w in the local store", ErrMissingLFSObjects)
+	}
+	return result, nil
+}
+
+// Prune deletes local payloads that are not referenced by the index, HEAD, or
+// a branch tip. Payloads still referenced by older reachable commits are kept
+// unless the remote store has a copy, so nothing unpushed is lost.
+func (l *LFSService) Prune(options PruneOptions) (*PruneResult, error) {
+	stored, err := l.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[domain.Hash]bool)
+	cache := make(map[domain.Hash]*domain.LFSPointer)
+	indexHashes, err := l.treeResolver.ResolveIndex()
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	indexModes, err := l.treeResolver.ResolveIndexModes()
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	indexFiles, err := l.files(indexHashes, indexModes, cache)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	for _, file := range indexFiles {
+		keep[file.Pointer.OID] = true
+	}
+	tipPointers, err := l.tipPointers(true)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	for _, pointer := range tipPointers {
+		keep[pointer.OID] = true
+	}
+
+	tips, err := l.tips(true)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	history, err := l.historyPointers(tips, cache)
+	if err != nil {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+	historical := make(map[domain.Hash]bool)
+	for _, pointer := range history {
+		historical[pointer.OID] = true
+	}
+	remote, err := core.RemoteLFSStore(l.configService)
+	if err != nil && !errors.Is(err, core.ErrNoLFSRemote) {
+		return nil, fmt.Errorf("lfs: %w", err)
+	}
+
+	result := &PruneResult{}
+	for _, pointer := range stored {
+		if keep[pointer.OID] {
+			result.Retained++
+			continue
+		}
+		if historical[pointer.OID] {
+			pushed := false
+			if remote != nil {
+				if pushed, err = remote.Has(pointer.OID); err != nil {
+					return nil, fmt.Errorf("lfs: %w", err)
+				}
+			}
+			if !pushed {
+				result.Retained++
+				continue
+			}
+		}
+		if !options.DryRun {
+			if err := l.store.Remove(pointer.OID); err != nil {
+				return nil, err
+			}
+		}
+		result.Pruned = append(result.Pruned, pointer)
+	}
+	return result, nil
+}
+
+// tipPointers returns the distinct pointers in the tree of HEAD and, with all,
+// of every local branch tip.
+func (l *LFSService) tipPointers(all bool) ([]domain.LFSPointer, error) {
+	tips, err := l.tips(all)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointers []domain.LFSPointer
+	seen := make(map[domain.Hash]bool)
+	cache := make(map[domain.Hash]*domain.LFSPointer)
+	for _, tip := range tips {
+		pathHashes, err := l.treeResolver.ResolveCommit(tip)
+		if err != nil {
+			return nil, err
+		}
+		pathModes, err := l.treeResolver.ResolveCommitModes(tip)
+		if err != nil {
+			return nil, err
+		}
+		files, err := l.files(pathHashes, pathModes, cache)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !seen[file.Pointer.OID] {
+				seen[file.Pointer.OID] = true
+				pointers = append(pointers, file.Pointer)
+			}
+		}
+	}
+	return pointers, nil
+}
+
+// tips returns the commit of HEAD and, with all, of every local branch.
+// An unborn HEAD contributes nothing.
+func (l *LFSService) tips(all bool) ([]domain.Hash, error) {
+	var tips []domain.Hash
+	head, err := l.refService.Resolve(domain.HeadFileName)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, err
+	}
+	if err == nil && !head.IsEmpty() {
+		tips = append(tips, head)
+	}
+	if !all {
+		return tips, nil
+	}
+
+	branches, err := l.branchService.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range branches {
+		hash, err := l.refService.Read(filepath.Join(domain.RefsDirName, domain.HeadsDirName, item.Name))
+		if err != nil {
+			return nil, err
+		}
+		if !hash.IsEmpty() {
+			tips = append(tips, hash)
+		}
+	}
+	return tips, nil
+}
+
+// historyPointers returns the distinct pointers referenced by any commit
+// reachable from tips, in the order they are first found.
+func (l *LFSService) historyPointers(
+	tips []domain.Hash,
+	cache map[domain.Hash]*domain.LFSPointer,
+) ([]domain.LFSPointer, error) {
+	grafts, err := l.shallowService.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var pointers []domain.LFSPointer
+	referenced := make(map[domain.Hash]bool)
+	record := func(pointer domain.LFSPointer) {
+		if !referenced[pointer.OID] {
+			referenced[pointer.OID] = true
+			pointers = append(pointers, pointer)
+		}
+	}
+	seenCommits := make(map[domain.Hash]bool)
+	seenTrees := make(map[domain.Hash]bool)
+	queue := tips
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if seenCommits[hash] {
+			continue
+		}
+		seenCommits[hash] = true
+
+		commit, err := l.objectService.ReadCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.walkTree(commit.TreeHash, seenTrees, cache, record); err != nil {
+			return nil, err
+		}
+		queue = append(queue, grafts.Parents(hash, commit)...)
+	}
+	return pointers, nil
+}
+
+// walkTree calls record for every pointer below treeHash, visiting each tree once.
+func (l *LFSService) walkTree(
+	treeHash domain.Hash,
+	seenTrees map[domain.Hash]bool,
+	cache map[domain.Hash]*domain.LFSPointer,
+	record func(domain.LFSPointer),
+) error {
+	if seenTrees[treeHash] {
+		return nil
+	}
+	seenTrees[treeHash] = true
+
+	tree, err := l.objectService.ReadTree(treeHash)
+	if err != nil {
+		return err
+	}
+	for _, entry := range tree.Entries() {
+		if entry.Mode.IsDirectory() {
+			if err := l.walkTree(entry.Hash, seenTrees, cache, record); err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.Mode.IsSymlink() || entry.Mode.IsGitlink() {
+			continue
+		}
+		pointer, err := l.readPointer(entry.Hash, cache)
+		if err != nil {
+			return err
+		}
+		if pointer != nil {
+			record(*pointer)
+		}
+	}
+	return nil
+}
+
+// files returns the large files among pathHashes, sorted by path. Submodules,
+// whose commits live in another repository, are skipped.
+func (l *LFSService) files(
+	pathHashes core.PathHashes,
+	pathModes core.PathModes,
+	cache map[domain.Hash]*domain.LFSPointer,
+) ([]File, error) {
+	paths := make([]domain.NormalizedPath, 0, len(pathHashes))
+	for path := range pathHashes {
+		if !pathModes[path].IsGitlink() {
+			paths = append(paths, path)
+		}
+	}
+	domain.SortPaths(paths)
+
+	var files []File
+	for _, path := range paths {
+		pointer, err := l.readPointer(pathHashes[path], cache)
+		if err != nil {
+			return nil, err
+		}
+		if pointer == nil {
+			continue
+		}
+		present, err := l.store.Has(pointer.OID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Path: path, Pointer: *pointer, Present: present})
+	}
+	return files, nil
+}
+
+// readPointer returns the pointer stored in blob hash, or nil when the blob is not a pointer.
+func (l *LFSService) readPointer(hash domain.Hash, cache map[domain.Hash]*domain.LFSPointer) (*domain.LFSPointer, error) {
+	if pointer, ok := cache[hash]; ok {
+		return pointer, nil
+	}
+	blob, err := l.objectService.ReadBlob(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointer *domain.LFSPointer
+	if parsed, err := domain.ParseLFSPointer(blob.Body()); err == nil {
+		pointer = &parsed
+	}
+	cache[hash] = pointer
+	return pointer, nil
+}
+
+// checkoutPointers replaces working tree files that hold the pointer text of a
+// locally available payload with the payload, returning the replaced paths.
+func (l *LFSService) checkoutPointers() ([]domain.NormalizedPath, error) {
+	pathHashes, err := l.treeResolver.ResolveIndex()
+	if err != nil {
+		return nil, err
+	}
+	pathModes, err := l.treeResolver.ResolveIndexModes()
+	if err != nil {
+		return nil, err
+	}
+	files, err := l.files(pathHashes, pathModes, make(map[domain.Hash]*domain.LFSPointer))
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []domain.NormalizedPath
+	for _, file := range files {
+		if !file.Present || pathModes[file.Path].IsSymlink() {
+			continue
+		}
+		absPath, err := file.Path.ToAbsolutePath(l.workspace.RepoDir)
+		if err != nil {
+			return nil, err
+		}
+		data, err := core.ReadWorkingFile(absPath)
+		if err != nil || !bytes.Equal(data, file.Pointer.Serialize()) {
+			continue
+		}
+		if err := l.objectService.CheckoutBlob(pathHashes[file.Path], absPath, pathModes[file.Path]); err != nil {
+			return nil, err
+		}
+		updated = append(updated, file.Path)
+	}
+	return updated, nil
+}
@@ -0,0 +1,11 @@
+package merge
+
+import "errors"
+
+var (
+	// ErrMergeConflict is returned when both sides changed the same path differently.
+	ErrMergeConflict = errors.New("merge conflict")
+
+	// ErrNoMergeBase is returned when two commits share no common ancestor.
+	ErrNoMergeBase = errors.New("no merge base")
+)
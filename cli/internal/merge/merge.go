@@ -0,0 +1,272 @@
+package merge
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/tree"
+	"fmt"
+	"strings"
+)
+
+// TreeMergeResult reports the outcome of a three-way tree merge.
+type TreeMergeResult struct {
+	// TreeHash is the merged root tree. It is zero when Conflicts is non-empty.
+	TreeHash domain.Hash
+	// Conflicts lists paths changed differently on both sides, sorted by path.
+	Conflicts []domain.NormalizedPath
+}
+
+// MergeService computes merge bases and path-level three-way merges of commit trees.
+//
+// Merges are resolved per path, not per line: a path changed on only one side
+// takes that side, identical changes on both sides are accepted, and any other
+// combination is reported as a conflict.
+//
+// History walks treat shallow boundary commits as roots.
+type MergeService struct {
+	objectService    *core.ObjectService
+	writeTreeService *tree.WriteTreeService
+	shallowService   *core.ShallowService
+}
+
+// NewMergeService creates a merge service.
+func NewMergeService(
+	objectService *core.ObjectService,
+	writeTreeService *tree.WriteTreeService,
+	shallowService *core.ShallowService,
+) *MergeService {
+	return &MergeService{
+		objectService:    objectService,
+		writeTreeService: writeTreeService,
+		shallowService:   shallowService,
+	}
+}
+
+// MergeBase returns the nearest common ancestor of a and b.
+// Ancestors of a are collected first, then b's history is walked breadth-first
+// and the first commit also reachable from a is returned.
+func (m *MergeService) MergeBase(a, b domain.Hash) (domain.Hash, error) {
+	grafts, err := m.shallowService.Read()
+	if err != nil {
+		return domain.Hash{}, fmt.Errorf("merge-base: %w", err)
+	}
+	ancestors, err := m.collectAncestors(a, grafts)
+	if err != nil {
+		return domain.Hash{}, fmt.Errorf("merge-base: %w", err)
+	}
+
+	visited := make(map[domain.Hash]bool)
+	queue := []domain.Hash{b}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if visited[hash] {
+			continue
+		}
+		visited[hash] = true
+		if ancestors[hash] {
+			return hash, nil
+		}
+
+		commit, err := m.objectService.ReadCommit(hash)
+		if err != nil {
+			return domain.Hash{}, fmt.Errorf("merge-base: %w", err)
+		}
+		queue = append(queue, grafts.Parents(hash, commit)...)
+	}
+	return domain.Hash{}, ErrNoMergeBase
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant, including equality.
+func (m *MergeService) IsAncestor(ancestor, descendant domain.Hash) (bool, error) {
+	grafts, err := m.shallowService.Read()
+	if err != nil {
+		return false, fmt.Errorf("merge: %w", err)
+	}
+	ancestors, err := m.collectAncestors(descendant, grafts)
+	if err != nil {
+		return false, fmt.Errorf("merge: %w", err)
+	}
+	return ancestors[ancestor], nil
+}
+
+// ExclusiveCommits returns the commits reachable from include and not from
+// exclude, the set "exclude..include", ordered so that every commit follows
+// its parents in the set.
+func (m *MergeService) ExclusiveCommits(include, exclude domain.Hash) ([]domain.Hash, error) {
+	grafts, err := m.shallowService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	excluded := make(map[domain.Hash]bool)
+	if !exclude.IsEmpty() {
+		if excluded, err = m.collectAncestors(exclude, grafts); err != nil {
+			return nil, fmt.Errorf("merge: %w", err)
+		}
+	}
+
+	// Commits are appended after their parents: a commit is pushed back
+	// above its parents when first seen and emitted when seen again.
+	var commits []domain.Hash
+	visited := make(map[domain.Hash]bool)
+	type walkEntry struct {
+		hash     domain.Hash
+		expanded bool
+	}
+	stack := []walkEntry{{hash: include}}
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if entry.expanded {
+			commits = append(commits, entry.hash)
+			continue
+		}
+		if visited[entry.hash] || excluded[entry.hash] {
+			continue
+		}
+		visited[entry.hash] = true
+
+		commit, err := m.objectService.ReadCommit(entry.hash)
+		if err != nil {
+			return nil, fmt.Errorf("merge: %w", err)
+		}
+		stack = append(stack, walkEntry{hash: entry.hash, expanded: true})
+		parents := grafts.Parents(entry.hash, commit)
+		for i := len(parents) - 1; i >= 0; i-- {
+			stack = append(stack, walkEntry{hash: parents[i]})
+		}
+	}
+	return commits, nil
+}
+
+// MergeTrees merges ours and theirs relative to base and writes the merged tree.
+// A zero hash for any tree is treated as an empty snapshot. When conflicts are
+// found, no tree is written and the conflicting paths are returned instead.
+func (m *MergeService) MergeTrees(baseTree, oursTree, theirsTree domain.Hash) (*TreeMergeResult, error) {
+	baseEntries, err := m.flattenTree(baseTree)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	oursEntries, err := m.flattenTree(oursTree)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	theirsEntries, err := m.flattenTree(theirsTree)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+
+	paths := make(map[domain.NormalizedPath]struct{})
+	for _, entries := range []map[domain.NormalizedPath]domain.TreeEntry{baseEntries, oursEntries, theirsEntries} {
+		for path := range entries {
+			paths[path] = struct{}{}
+		}
+	}
+
+	var mergedEntries []*domain.IndexEntry
+	var conflicts []domain.NormalizedPath
+	for _, path := range domain.SortedPathSet(paths) {
+		base := newSideState(baseEntries, path)
+		ours := newSideState(oursEntries, path)
+		theirs := newSideState(theirsEntries, path)
+
+		var merged sideState
+		switch {
+		case ours == theirs:
+			merged = ours
+		case base == ours:
+			merged = theirs
+		case base == theirs:
+			merged = ours
+		default:
+			conflicts = append(conflicts, path)
+			continue
+		}
+		if !merged.exists {
+			continue
+		}
+		mergedEntries = append(
+			mergedEntries,
+			domain.NewEmptyIndexEntry(path, merged.hash, merged.mode.Uint32()),
+		)
+	}
+	if len(conflicts) > 0 {
+		return &TreeMergeResult{Conflicts: conflicts}, nil
+	}
+
+	treeHash, err := m.writeTreeService.WriteTreeFromEntries(mergedEntries)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	return &TreeMergeResult{TreeHash: treeHash}, nil
+}
+
+// collectAncestors returns every commit reachable from hash through grafts, including hash itself.
+func (m *MergeService) collectAncestors(hash domain.Hash, grafts domain.ShallowGrafts) (map[domain.Hash]bool, error) {
+	ancestors := make(map[domain.Hash]bool)
+	stack := []domain.Hash{hash}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if ancestors[current] {
+			continue
+		}
+		ancestors[current] = true
+
+		commit, err := m.objectService.ReadCommit(current)
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, grafts.Parents(current, commit)...)
+	}
+	return ancestors, nil
+}
+
+// flattenTree walks a root tree recursively and returns its blob entries keyed by path.
+func (m *MergeService) flattenTree(treeHash domain.Hash) (map[domain.NormalizedPath]domain.TreeEntry, error) {
+	entries := make(map[domain.NormalizedPath]domain.TreeEntry)
+	if treeHash.IsEmpty() {
+		return entries, nil
+	}
+
+	walker := core.NewTreeWalker(m.objectService, core.WalkOptions{Recursive: true})
+	err := walker.Walk(
+		treeHash, "", func(entry domain.TreeEntry, relPath string) error {
+			path, err := domain.ParseNormalizedPath(relPath)
+			if err != nil {
+				return err
+			}
+			entries[path] = entry
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// sideState models one path's state on one side of a merge, including absence.
+type sideState struct {
+	exists bool
+	mode   domain.FileMode
+	hash   domain.Hash
+}
+
+// newSideState builds a sideState from a flattened tree snapshot.
+func newSideState(entries map[domain.NormalizedPath]domain.TreeEntry, path domain.NormalizedPath) sideState {
+	entry, ok := entries[path]
+	if !ok {
+		return sideState{}
+	}
+	return sideState{exists: true, mode: entry.Mode, hash: entry.Hash}
+}
+
+// FormatConflicts renders conflicting paths as a single comma-separated string for error messages.
+func FormatConflicts(conflicts []domain.NormalizedPath) string {
+	names := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		names[i] = conflict.String()
+	}
+	return strings.Join(names, ", ")
+}
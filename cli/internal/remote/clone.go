@@ -0,0 +1,178 @@
+package remote
+
+import (
+	"Gel/internal/bundle"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRemoteName is the remote name configured by clone.
+const DefaultRemoteName = "origin"
+
+// CloneOptions controls clone behavior.
+type CloneOptions struct {
+	// Depth creates a shallow clone with this many commits per branch. Zero clones full history.
+	Depth int
+	// Filter creates a partial clone that omits the selected objects.
+	Filter ObjectFilter
+}
+
+// CloneResult reports the repository created by clone.
+type CloneResult struct {
+	// Workspace is the newly created repository.
+	Workspace *domain.Workspace
+	// Remote is the configured remote name.
+	Remote string
+	// Branch is the checked-out branch, or empty when the remote has no commits.
+	Branch string
+	// CommitHash is the branch tip to check out, or zero when the remote has no commits.
+	CommitHash domain.Hash
+}
+
+// CloneService creates a repository from a remote and sets up its default branch.
+//
+// CloneService is stateless like setup.InitService: it builds the services it
+// needs for the new repository itself. Checking out the working tree is left
+// to the caller, which owns the regular service graph for the new workspace.
+type CloneService struct {
+}
+
+// NewCloneService creates a stateless clone service.
+func NewCloneService() *CloneService {
+	return &CloneService{}
+}
+
+// Clone initializes path, configures url as the origin remote, fetches it, and
+// points HEAD at the remote's current branch.
+//
+// url names a repository directory or a bundle file. When path is empty, its
+// base name is used. The destination must be missing or an empty directory. If
+// a directory created by Clone cannot be fully populated, it is removed again.
+func (c *CloneService) Clone(url, path string, options CloneOptions) (result *CloneResult, err error) {
+	if options.Depth < 0 {
+		return nil, fmt.Errorf("clone: %w: %d", ErrInvalidDepth, options.Depth)
+	}
+
+	absURL, err := filepath.Abs(url)
+	if err != nil {
+		return nil, fmt.Errorf("clone: resolve url %q: %w", url, err)
+	}
+	src, err := openRemote(DefaultRemoteName, absURL)
+	if err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+
+	if path == "" {
+		path = defaultClonePath(absURL)
+	}
+	created, err := prepareCloneDestination(path)
+	if err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	defer func() {
+		if err != nil && created {
+			_ = os.RemoveAll(path)
+		}
+	}()
+
+	if _, err := setup.NewInitService().Init(path, setup.InitOptions{}); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	workspace, err := domain.NewWorkspace(path)
+	if err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+
+	objectService := core.NewObjectService(storage.NewObjectStorage(workspace))
+	refService := core.NewRefService(workspace)
+	configService := core.NewConfigService(storage.NewConfigStorage(workspace))
+	shallowService := core.NewShallowService(workspace)
+
+	remoteSettings := map[string]string{core.ConfigKeyURL: absURL}
+	if options.Filter != ObjectFilterNone {
+		remoteSettings[core.ConfigKeyPromisor] = "true"
+		remoteSettings[core.ConfigKeyPartialCloneFilter] = options.Filter.String()
+	}
+	for key, value := range remoteSettings {
+		if err := configService.Set(core.ConfigSectionRemote, DefaultRemoteName+"."+key, value); err != nil {
+			return nil, fmt.Errorf("clone: %w", err)
+		}
+	}
+
+	fetchService := NewFetchService(objectService, refService, configService, shallowService)
+	if _, err := fetchService.Fetch(DefaultRemoteName, FetchOptions(options)); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+
+	result = &CloneResult{Workspace: workspace, Remote: DefaultRemoteName}
+	branchName, ok, err := src.HeadBranch()
+	if err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	if !ok {
+		return result, nil
+	}
+
+	commitHash, err := refService.Read(RemoteTrackingRef(DefaultRemoteName, branchName))
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+
+	branchRef := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branchName)
+	if err := refService.Write(branchRef, commitHash); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	if err := refService.WriteSymbolic(domain.HeadFileName, branchRef); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	if err := configService.Set(
+		core.ConfigSectionBranch, branchName+"."+core.ConfigKeyRemote, DefaultRemoteName,
+	); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+	if err := configService.Set(
+		core.ConfigSectionBranch, branchName+"."+core.ConfigKeyMerge, branchRef,
+	); err != nil {
+		return nil, fmt.Errorf("clone: %w", err)
+	}
+
+	result.Branch = branchName
+	result.CommitHash = commitHash
+	return result, nil
+}
+
+// prepareCloneDestination verifies path is missing or an empty directory and
+// reports whether Clone will create it.
+func prepareCloneDestination(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("check destination %q: %w", path, err)
+	}
+	if len(entries) > 0 {
+		return false, fmt.Errorf("'%s': %w", path, ErrCloneDestinationExists)
+	}
+	return false, nil
+}
+
+// defaultClonePath derives the destination directory from a remote URL:
+// the repository directory name, or a bundle file name without extension.
+func defaultClonePath(absURL string) string {
+	base := filepath.Base(strings.TrimSuffix(absURL, domain.GelDirName))
+	if bundle.IsBundleFile(absURL) {
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return base
+}
@@ -0,0 +1,41 @@
+package remote
+
+import "errors"
+
+var (
+	// ErrRemoteNotFound is returned when remote.<name>.url is not configured.
+	ErrRemoteNotFound = errors.New("remote not found")
+
+	// ErrNoUpstream is returned when the current branch has no configured upstream.
+	ErrNoUpstream = errors.New("no upstream configured for branch")
+
+	// ErrInvalidPullMode is returned when pull.mode or flags name an unknown strategy.
+	ErrInvalidPullMode = errors.New("invalid pull mode")
+
+	// ErrNotFastForward is returned by fast-forward-only pulls when histories diverged.
+	ErrNotFastForward = errors.New("not possible to fast-forward")
+
+	// ErrUpstreamBranchNotFound is returned when the upstream branch is missing on the remote.
+	ErrUpstreamBranchNotFound = errors.New("upstream branch not found on remote")
+
+	// ErrInvalidObjectFilter is returned when --filter names an unsupported filter spec.
+	ErrInvalidObjectFilter = errors.New("invalid object filter")
+
+	// ErrInvalidDepth is returned when --depth is not a positive number.
+	ErrInvalidDepth = errors.New("depth must be positive")
+
+	// ErrCloneDestinationExists is returned when the clone target is a non-empty directory.
+	ErrCloneDestinationExists = errors.New("destination path already exists and is not an empty directory")
+
+	// ErrPushRejected is returned when the remote branch is not an ancestor of the pushed commit.
+	ErrPushRejected = errors.New("updates were rejected because the remote branch has commits not in the pushed branch")
+
+	// ErrPushBranchNotFound is returned when the branch to push does not exist locally.
+	ErrPushBranchNotFound = errors.New("branch to push not found")
+
+	// ErrPushToCheckedOutBranch is returned when the remote branch is checked out in a working tree.
+	ErrPushToCheckedOutBranch = errors.New("refusing to update checked out branch")
+
+	// ErrPushToBundle is returned when the remote names a bundle file, which is read-only.
+	ErrPushToBundle = errors.New("cannot push to a bundle file")
+)
@@ -0,0 +1,339 @@
+package remote
+
+import (
+	"Gel/internal/bundle"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FetchedRef reports one remote-tracking ref updated by fetch.
+type FetchedRef struct {
+	// Ref is the local remote-tracking ref (refs/remotes/<remote>/<branch>).
+	Ref string
+	// OldHash is the previous value, or zero when the ref was created.
+	OldHash domain.Hash
+	// NewHash is the fetched commit hash.
+	NewHash domain.Hash
+}
+
+// FetchResult reports the outcome of a fetch invocation.
+type FetchResult struct {
+	// Remote is the fetched remote name.
+	Remote string
+	// URL is the location fetched from.
+	URL string
+	// Updated lists remote-tracking refs that changed, sorted by ref.
+	Updated []FetchedRef
+}
+
+// ObjectFilter selects objects omitted by a partial fetch.
+type ObjectFilter int
+
+const (
+	// ObjectFilterNone fetches every reachable object.
+	ObjectFilterNone ObjectFilter = iota
+	// ObjectFilterBlobNone omits all blobs; they are fetched lazily on first read.
+	ObjectFilterBlobNone
+)
+
+const objectFilterBlobNoneName = "blob:none"
+
+// ParseObjectFilter converts a --filter value to an ObjectFilter.
+// The empty string selects ObjectFilterNone.
+func ParseObjectFilter(value string) (ObjectFilter, error) {
+	switch value {
+	case "":
+		return ObjectFilterNone, nil
+	case objectFilterBlobNoneName:
+		return ObjectFilterBlobNone, nil
+	default:
+		return ObjectFilterNone, fmt.Errorf("%w: %q (expected %s)", ErrInvalidObjectFilter, value, objectFilterBlobNoneName)
+	}
+}
+
+// String returns the filter spec accepted by ParseObjectFilter.
+func (f ObjectFilter) String() string {
+	if f == ObjectFilterBlobNone {
+		return objectFilterBlobNoneName
+	}
+	return ""
+}
+
+// FetchOptions controls how much history and which objects fetch copies.
+type FetchOptions struct {
+	// Depth limits history to this many commits per branch tip. Zero means unlimited.
+	// Boundary commits whose parents were not fetched are recorded in .gel/shallow.
+	Depth int
+	// Filter omits objects from the fetch. When unset, the remote's configured
+	// partial clone filter is used.
+	Filter ObjectFilter
+}
+
+// FetchService downloads objects and branch refs from a configured remote.
+//
+// Remotes are addressed by local filesystem path (remote.<name>.url), naming
+// either a repository directory or a bundle file. Every object reachable from
+// the remote's branches is copied when missing locally.
+type FetchService struct {
+	objectService  *core.ObjectService
+	refService     *core.RefService
+	configService  *core.ConfigService
+	shallowService *core.ShallowService
+}
+
+// NewFetchService creates a fetch service.
+func NewFetchService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	configService *core.ConfigService,
+	shallowService *core.ShallowService,
+) *FetchService {
+	return &FetchService{
+		objectService:  objectService,
+		refService:     refService,
+		configService:  configService,
+		shallowService: shallowService,
+	}
+}
+
+// Fetch copies all branches of remoteName into refs/remotes/<remoteName>/*.
+//
+// Objects already present locally are assumed to have their full closure
+// present, so fetching into a shallow or partial clone keeps its boundaries.
+func (f *FetchService) Fetch(remoteName string, options FetchOptions) (*FetchResult, error) {
+	if options.Depth < 0 {
+		return nil, fmt.Errorf("fetch: %w: %d", ErrInvalidDepth, options.Depth)
+	}
+
+	remoteName, url, err := f.resolveRemote(remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	src, err := openRemote(remoteName, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	if err := f.checkPrerequisites(src); err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	remoteBranches, err := src.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	if options.Filter == ObjectFilterNone {
+		options.Filter, err = f.configuredFilter(remoteName)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+	}
+	grafts, err := f.shallowService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	result := &FetchResult{Remote: remoteName, URL: url}
+	for _, branchName := range remoteBranches {
+		remoteHash, err := src.ReadBranch(branchName)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if remoteHash.IsEmpty() {
+			continue
+		}
+		if err := f.copyReachableObjects(src, remoteHash, options, grafts); err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if err := f.shallowService.Write(grafts); err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+
+		trackingRef := RemoteTrackingRef(remoteName, branchName)
+		oldHash, err := f.refService.Read(trackingRef)
+		if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if oldHash == remoteHash {
+			continue
+		}
+		if err := f.refService.Write(trackingRef, remoteHash); err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		result.Updated = append(
+			result.Updated, FetchedRef{
+				Ref:     trackingRef,
+				OldHash: oldHash,
+				NewHash: remoteHash,
+			},
+		)
+	}
+	return result, nil
+}
+
+// RemoteURL returns the configured remote.<name>.url value.
+func (f *FetchService) RemoteURL(remoteName string) (string, error) {
+	return remoteURL(f.configService, remoteName)
+}
+
+// resolveRemote returns the remote name and URL for a configured remote name
+// or a bundle file path.
+//
+// A bundle file that is not a configured remote is fetched anonymously: its
+// branches are stored under refs/remotes/<file name without extension>/*.
+func (f *FetchService) resolveRemote(nameOrPath string) (string, string, error) {
+	url, err := f.RemoteURL(nameOrPath)
+	if err == nil || !errors.Is(err, ErrRemoteNotFound) || !bundle.IsBundleFile(nameOrPath) {
+		return nameOrPath, url, err
+	}
+	base := filepath.Base(nameOrPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)), nameOrPath, nil
+}
+
+// checkPrerequisites verifies the local repository has every commit src builds on.
+func (f *FetchService) checkPrerequisites(src source) error {
+	var missing []string
+	for _, prerequisite := range src.Prerequisites() {
+		exists, err := f.objectService.Exists(prerequisite)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, prerequisite.Hex())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", bundle.ErrMissingPrerequisites, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// configuredFilter returns remote.<name>.partialclonefilter, or ObjectFilterNone when unset.
+func (f *FetchService) configuredFilter(remoteName string) (ObjectFilter, error) {
+	value, ok, err := f.configService.GetOptional(
+		core.ConfigSectionRemote, remoteName+"."+core.ConfigKeyPartialCloneFilter,
+	)
+	if err != nil || !ok {
+		return ObjectFilterNone, err
+	}
+	return ParseObjectFilter(value)
+}
+
+// copyReachableObjects copies the commit graph rooted at tip from the remote store.
+// Objects already present locally are assumed to have their full closure present too.
+// With options.Depth, commits deeper than the limit are skipped and the boundary
+// commits are added to grafts.
+func (f *FetchService) copyReachableObjects(
+	src source,
+	tip domain.Hash,
+	options FetchOptions,
+	grafts domain.ShallowGrafts,
+) error {
+	type queuedCommit struct {
+		hash  domain.Hash
+		depth int
+	}
+
+	visited := make(map[domain.Hash]bool)
+	queue := []queuedCommit{{hash: tip, depth: 1}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current.hash] {
+			continue
+		}
+		visited[current.hash] = true
+
+		exists, err := f.objectService.Exists(current.hash)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		commit, err := readSourceCommit(src, current.hash)
+		if err != nil {
+			return err
+		}
+		if err := copyTree(src, f.objectService, commit.TreeHash, options.Filter); err != nil {
+			return err
+		}
+		if err := f.objectService.Write(current.hash, commit.Serialize()); err != nil {
+			return err
+		}
+
+		if options.Depth > 0 && current.depth >= options.Depth && len(commit.ParentHashes) > 0 {
+			grafts[current.hash] = struct{}{}
+			continue
+		}
+		for _, parentHash := range commit.ParentHashes {
+			queue = append(queue, queuedCommit{hash: parentHash, depth: current.depth + 1})
+		}
+	}
+	return nil
+}
+
+// copyTree copies a tree and its subtrees from src to dst, plus blobs unless
+// filter omits them. Trees dst already has are assumed complete.
+func copyTree(src source, dst *core.ObjectService, treeHash domain.Hash, filter ObjectFilter) error {
+	exists, err := dst.Exists(treeHash)
+	if err != nil || exists {
+		return err
+	}
+
+	tree, err := readSourceTree(src, treeHash)
+	if err != nil {
+		return err
+	}
+	for _, entry := range tree.Entries() {
+		if entry.Mode.IsDirectory() {
+			if err := copyTree(src, dst, entry.Hash, filter); err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.Mode.IsGitlink() || filter == ObjectFilterBlobNone {
+			continue
+		}
+		if err := copyObject(src, dst, entry.Hash); err != nil {
+			return err
+		}
+	}
+	return dst.Write(treeHash, tree.Serialize())
+}
+
+// copyObject copies hash from src to dst when dst does not already have it.
+func copyObject(src source, dst *core.ObjectService, hash domain.Hash) error {
+	exists, err := dst.Exists(hash)
+	if err != nil || exists {
+		return err
+	}
+	object, err := src.Read(hash)
+	if err != nil {
+		return err
+	}
+	return dst.Write(hash, object.Serialize())
+}
+
+// RemoteTrackingRef returns refs/remotes/<remoteName>/<branchName>.
+func RemoteTrackingRef(remoteName, branchName string) string {
+	return filepath.Join(domain.RefsDirName, domain.RemotesDirName, remoteName, branchName)
+}
+
+// remoteURL reads remote.<name>.url from configService.
+func remoteURL(configService *core.ConfigService, remoteName string) (string, error) {
+	url, ok, err := configService.GetOptional(core.ConfigSectionRemote, remoteName+"."+core.ConfigKeyURL)
+	if err != nil {
+		return "", err
+	}
+	if !ok || strings.TrimSpace(url) == "" {
+		return "", fmt.Errorf("'%s': %w", remoteName, ErrRemoteNotFound)
+	}
+	return url, nil
+}
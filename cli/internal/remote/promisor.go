@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+)
+
+// PromisorFetcher lazily fetches objects omitted by a partial clone.
+//
+// It implements core.PromisorFetcher. The promisor remote is looked up from
+// remote.<name>.promisor on first use and kept open for subsequent fetches.
+type PromisorFetcher struct {
+	objectService *core.ObjectService
+	configService *core.ConfigService
+	remote        source
+}
+
+// NewPromisorFetcher creates a promisor fetcher that writes into objectService.
+func NewPromisorFetcher(
+	objectService *core.ObjectService,
+	configService *core.ConfigService,
+) *PromisorFetcher {
+	return &PromisorFetcher{
+		objectService: objectService,
+		configService: configService,
+	}
+}
+
+// FetchObject copies hash from the promisor remote.
+// It reports false without error when no promisor remote is configured.
+func (p *PromisorFetcher) FetchObject(hash domain.Hash) (bool, error) {
+	if p.remote == nil {
+		remoteName, ok, err := p.configService.PromisorRemote()
+		if err != nil || !ok {
+			return false, err
+		}
+		url, err := remoteURL(p.configService, remoteName)
+		if err != nil {
+			return false, err
+		}
+		remote, err := openRemote(remoteName, url)
+		if err != nil {
+			return false, err
+		}
+		p.remote = remote
+	}
+	return true, copyObject(p.remote, p.objectService, hash)
+}
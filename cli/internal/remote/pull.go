@@ -0,0 +1,436 @@
+package remote
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/commit"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/merge"
+	"Gel/internal/tree"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PullMode selects how fetched upstream history is integrated.
+type PullMode int
+
+const (
+	// PullModeUnspecified defers to pull.mode in config, falling back to PullModeMerge.
+	PullModeUnspecified PullMode = iota
+	// PullModeFastForwardOnly only advances the branch when no local commits diverge.
+	PullModeFastForwardOnly
+	// PullModeMerge creates a merge commit when histories diverged.
+	PullModeMerge
+	// PullModeRebase replays local commits on top of the upstream tip.
+	PullModeRebase
+)
+
+const (
+	pullModeFastForwardOnlyName = "ff-only"
+	pullModeMergeName           = "merge"
+	pullModeRebaseName          = "rebase"
+)
+
+// ParsePullMode converts a pull.mode config value to a PullMode.
+func ParsePullMode(value string) (PullMode, error) {
+	switch strings.TrimSpace(value) {
+	case pullModeFastForwardOnlyName:
+		return PullModeFastForwardOnly, nil
+	case pullModeMergeName:
+		return PullModeMerge, nil
+	case pullModeRebaseName:
+		return PullModeRebase, nil
+	default:
+		return PullModeUnspecified, fmt.Errorf(
+			"%w: %q (expected %s, %s, or %s)",
+			ErrInvalidPullMode, value,
+			pullModeFastForwardOnlyName, pullModeMergeName, pullModeRebaseName,
+		)
+	}
+}
+
+// PullOutcome describes how a pull changed the current branch.
+type PullOutcome int
+
+const (
+	// PullOutcomeUpToDate means the branch already contained the upstream tip.
+	PullOutcomeUpToDate PullOutcome = iota
+	// PullOutcomeFastForward means the branch was advanced to the upstream tip.
+	PullOutcomeFastForward
+	// PullOutcomeMerged means a merge commit was created.
+	PullOutcomeMerged
+	// PullOutcomeRebased means local commits were replayed onto the upstream tip.
+	PullOutcomeRebased
+)
+
+// PullOptions controls pull behavior.
+type PullOptions struct {
+	// Mode overrides pull.mode when it is not PullModeUnspecified.
+	Mode PullMode
+	// NoVerify skips the pre-merge-commit hook.
+	NoVerify bool
+}
+
+// PullResult reports the outcome of a pull invocation.
+type PullResult struct {
+	// Fetch is the result of the fetch step.
+	Fetch *FetchResult
+	// Branch is the current local branch name.
+	Branch string
+	// Upstream is the remote-tracking ref that was integrated.
+	Upstream string
+	// Outcome describes what happened to the branch.
+	Outcome PullOutcome
+	// OldHash is the branch tip before the pull, or zero for an unborn branch.
+	OldHash domain.Hash
+	// NewHash is the branch tip after the pull.
+	NewHash domain.Hash
+}
+
+// PullService fetches the current branch's upstream and integrates it.
+type PullService struct {
+	fetchService      *FetchService
+	mergeService      *merge.MergeService
+	switchService     *branch.SwitchService
+	branchService     *branch.BranchService
+	commitTreeService *commit.CommitTreeService
+	readTreeService   *tree.ReadTreeService
+	refService        *core.RefService
+	objectService     *core.ObjectService
+	configService     *core.ConfigService
+	hookRunner        *core.HookRunner
+}
+
+// NewPullService creates a pull service.
+func NewPullService(
+	fetchService *FetchService,
+	mergeService *merge.MergeService,
+	switchService *branch.SwitchService,
+	branchService *branch.BranchService,
+	commitTreeService *commit.CommitTreeService,
+	readTreeService *tree.ReadTreeService,
+	refService *core.RefService,
+	objectService *core.ObjectService,
+	configService *core.ConfigService,
+) *PullService {
+	return &PullService{
+		fetchService:      fetchService,
+		mergeService:      mergeService,
+		switchService:     switchService,
+		branchService:     branchService,
+		commitTreeService: commitTreeService,
+		readTreeService:   readTreeService,
+		refService:        refService,
+		objectService:     objectService,
+		configService:     configService,
+	}
+}
+
+// SetHookRunner enables the pre-merge-commit and post-merge hooks.
+func (p *PullService) SetHookRunner(hookRunner *core.HookRunner) {
+	p.hookRunner = hookRunner
+}
+
+// Pull fetches branch.<current>.remote and integrates branch.<current>.merge.
+//
+// The resulting commit is computed before the working tree is touched. The pull
+// is refused when applying it would overwrite local index or working tree
+// changes, using the same rules as switch.
+//
+// The pre-merge-commit hook can refuse a merge commit before it is recorded,
+// and the post-merge hook runs after a merge or fast-forward is applied.
+func (p *PullService) Pull(options PullOptions) (*PullResult, error) {
+	branchName, err := p.branchService.Current()
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	remoteName, upstreamBranch, err := p.resolveUpstream(branchName)
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	mode, err := p.resolveMode(options.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	fetchResult, err := p.fetchService.Fetch(remoteName, FetchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	upstreamRef := RemoteTrackingRef(remoteName, upstreamBranch)
+	theirsHash, err := p.refService.Read(upstreamRef)
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return nil, fmt.Errorf("pull: '%s': %w", upstreamBranch, ErrUpstreamBranchNotFound)
+		}
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	headRef, err := p.refService.ReadSymbolic(domain.HeadFileName)
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+	oursHash, err := p.refService.Read(headRef)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	result := &PullResult{
+		Fetch:    fetchResult,
+		Branch:   branchName,
+		Upstream: upstreamRef,
+		Outcome:  PullOutcomeUpToDate,
+		OldHash:  oursHash,
+		NewHash:  oursHash,
+	}
+
+	newHash, outcome, err := p.integrate(
+		oursHash, theirsHash, mode, remoteName, upstreamBranch, branchName, options.NoVerify,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+	if outcome == PullOutcomeUpToDate {
+		return result, nil
+	}
+	if err := p.apply(headRef, oursHash, newHash); err != nil {
+		return nil, fmt.Errorf("pull: %w", err)
+	}
+
+	if outcome == PullOutcomeMerged || outcome == PullOutcomeFastForward {
+		p.hookRunner.RunPost(core.HookPostMerge, "0")
+	}
+
+	result.Outcome = outcome
+	result.NewHash = newHash
+	return result, nil
+}
+
+// resolveUpstream reads branch.<name>.remote and branch.<name>.merge.
+// When merge is unset, the upstream branch defaults to the same name as the local branch.
+func (p *PullService) resolveUpstream(branchName string) (string, string, error) {
+	remoteName, ok, err := p.configService.GetOptional(
+		core.ConfigSectionBranch, branchName+"."+core.ConfigKeyRemote,
+	)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok || strings.TrimSpace(remoteName) == "" {
+		return "", "", fmt.Errorf("'%s': %w", branchName, ErrNoUpstream)
+	}
+
+	mergeRef, ok, err := p.configService.GetOptional(
+		core.ConfigSectionBranch, branchName+"."+core.ConfigKeyMerge,
+	)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok || strings.TrimSpace(mergeRef) == "" {
+		return remoteName, branchName, nil
+	}
+
+	headsPrefix := filepath.Join(domain.RefsDirName, domain.HeadsDirName) + "/"
+	return remoteName, strings.TrimPrefix(mergeRef, headsPrefix), nil
+}
+
+// resolveMode returns the explicit mode, then pull.mode, then PullModeMerge.
+func (p *PullService) resolveMode(mode PullMode) (PullMode, error) {
+	if mode != PullModeUnspecified {
+		return mode, nil
+	}
+
+	value, ok, err := p.configService.GetOptional(core.ConfigSectionPull, core.ConfigKeyMode)
+	if err != nil {
+		return PullModeUnspecified, err
+	}
+	if !ok {
+		return PullModeMerge, nil
+	}
+	return ParsePullMode(value)
+}
+
+// integrate computes the new branch tip without touching the index or working tree.
+func (p *PullService) integrate(
+	oursHash, theirsHash domain.Hash,
+	mode PullMode,
+	remoteName, upstreamBranch, branchName string,
+	noVerify bool,
+) (domain.Hash, PullOutcome, error) {
+	if oursHash == theirsHash {
+		return oursHash, PullOutcomeUpToDate, nil
+	}
+	if oursHash.IsEmpty() {
+		return theirsHash, PullOutcomeFastForward, nil
+	}
+
+	upToDate, err := p.mergeService.IsAncestor(theirsHash, oursHash)
+	if err != nil {
+		return domain.Hash{}, 0, err
+	}
+	if upToDate {
+		return oursHash, PullOutcomeUpToDate, nil
+	}
+
+	fastForward, err := p.mergeService.IsAncestor(oursHash, theirsHash)
+	if err != nil {
+		return domain.Hash{}, 0, err
+	}
+	if fastForward {
+		return theirsHash, PullOutcomeFastForward, nil
+	}
+
+	switch mode {
+	case PullModeFastForwardOnly:
+		return domain.Hash{}, 0, ErrNotFastForward
+	case PullModeMerge:
+		message := fmt.Sprintf("Merge branch '%s' of %s into %s\n", upstreamBranch, remoteName, branchName)
+		mergeHash, err := p.createMergeCommit(oursHash, theirsHash, message, noVerify)
+		if err != nil {
+			return domain.Hash{}, 0, err
+		}
+		return mergeHash, PullOutcomeMerged, nil
+	case PullModeRebase:
+		rebasedHash, err := p.rebaseCommits(oursHash, theirsHash)
+		if err != nil {
+			return domain.Hash{}, 0, err
+		}
+		return rebasedHash, PullOutcomeRebased, nil
+	default:
+		return domain.Hash{}, 0, fmt.Errorf("%w: %d", ErrInvalidPullMode, mode)
+	}
+}
+
+// createMergeCommit merges theirs into ours and records a two-parent commit.
+// Unless noVerify is set, the pre-merge-commit hook runs before the commit is recorded.
+func (p *PullService) createMergeCommit(
+	oursHash, theirsHash domain.Hash, message string, noVerify bool,
+) (domain.Hash, error) {
+	baseHash, err := p.mergeService.MergeBase(oursHash, theirsHash)
+	if err != nil && !errors.Is(err, merge.ErrNoMergeBase) {
+		return domain.Hash{}, err
+	}
+
+	baseTree, err := p.commitTree(baseHash)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	oursTree, err := p.commitTree(oursHash)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	theirsTree, err := p.commitTree(theirsHash)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+
+	mergeResult, err := p.mergeService.MergeTrees(baseTree, oursTree, theirsTree)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	if len(mergeResult.Conflicts) > 0 {
+		return domain.Hash{}, fmt.Errorf("%w in %s", merge.ErrMergeConflict, merge.FormatConflicts(mergeResult.Conflicts))
+	}
+	if !noVerify {
+		if err := p.hookRunner.Run(core.HookPreMergeCommit); err != nil {
+			return domain.Hash{}, err
+		}
+	}
+	return p.commitTreeService.CommitTree(mergeResult.TreeHash, message, []domain.Hash{oursHash, theirsHash})
+}
+
+// rebaseCommits replays the commits reachable from ours and not from theirs
+// onto theirs, parents first. Like git rebase, merge commits are skipped and
+// commits whose changes are already present upstream are dropped. Original
+// authors are kept.
+func (p *PullService) rebaseCommits(oursHash, theirsHash domain.Hash) (domain.Hash, error) {
+	localCommits, err := p.mergeService.ExclusiveCommits(oursHash, theirsHash)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+
+	tipHash := theirsHash
+	for _, localHash := range localCommits {
+		localCommit, err := p.objectService.ReadCommit(localHash)
+		if err != nil {
+			return domain.Hash{}, err
+		}
+		if len(localCommit.ParentHashes) > 1 {
+			continue
+		}
+
+		var parentTree domain.Hash
+		if len(localCommit.ParentHashes) > 0 {
+			parentTree, err = p.commitTree(localCommit.ParentHashes[0])
+			if err != nil {
+				return domain.Hash{}, err
+			}
+		}
+		tipTree, err := p.commitTree(tipHash)
+		if err != nil {
+			return domain.Hash{}, err
+		}
+
+		mergeResult, err := p.mergeService.MergeTrees(parentTree, tipTree, localCommit.TreeHash)
+		if err != nil {
+			return domain.Hash{}, err
+		}
+		if len(mergeResult.Conflicts) > 0 {
+			return domain.Hash{}, fmt.Errorf(
+				"%w while replaying %s in %s",
+				merge.ErrMergeConflict, localHash, merge.FormatConflicts(mergeResult.Conflicts),
+			)
+		}
+		if mergeResult.TreeHash == tipTree {
+			continue
+		}
+
+		author := localCommit.Author
+		tipHash, err = p.commitTreeService.CommitTreeWithAuthor(
+			mergeResult.TreeHash, localCommit.Message, []domain.Hash{tipHash}, &author,
+		)
+		if err != nil {
+			return domain.Hash{}, err
+		}
+	}
+	return tipHash, nil
+}
+
+// apply checks for overwrite conflicts and moves index, working tree, and branch to newHash.
+func (p *PullService) apply(headRef string, oursHash, newHash domain.Hash) error {
+	conflicts, err := p.switchService.FindOverwriteConflicts(oursHash, newHash)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("local changes to '%s' would be overwritten", conflicts[0])
+	}
+
+	if err := p.switchService.CheckoutWorkingTree(oursHash, newHash); err != nil {
+		return err
+	}
+	newTree, err := p.commitTree(newHash)
+	if err != nil {
+		return err
+	}
+	if err := p.readTreeService.ReadTree(newTree); err != nil {
+		return err
+	}
+	return p.refService.Write(headRef, newHash)
+}
+
+// commitTree returns the root tree of commitHash, or zero for a zero commit hash.
+func (p *PullService) commitTree(commitHash domain.Hash) (domain.Hash, error) {
+	if commitHash.IsEmpty() {
+		return domain.Hash{}, nil
+	}
+	c, err := p.objectService.ReadCommit(commitHash)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	return c.TreeHash, nil
+}
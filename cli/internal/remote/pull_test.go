@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/merge"
+	"Gel/internal/testutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rebaseTestRepository writes commits of flat trees for rebaseCommits.
+type rebaseTestRepository struct {
+	*testutil.Repository
+	pullService *PullService
+}
+
+// newRebaseTestRepository initializes a repository and a pull service that
+// can only rebase.
+func newRebaseTestRepository(t *testing.T) *rebaseTestRepository {
+	t.Helper()
+	repository := testutil.NewRepository(t)
+	mergeService := merge.NewMergeService(
+		repository.ObjectService, repository.WriteTree, core.NewShallowService(repository.Workspace),
+	)
+	return &rebaseTestRepository{
+		Repository: repository,
+		pullService: &PullService{
+			mergeService:      mergeService,
+			commitTreeService: repository.CommitTree,
+			objectService:     repository.ObjectService,
+		},
+	}
+}
+
+// commit records a commit whose tree holds files, name to content, on parents.
+func (r *rebaseTestRepository) commit(
+	t *testing.T, message string, files map[string]string, parents ...domain.Hash,
+) domain.Hash {
+	t.Helper()
+	entries := make([]domain.TreeEntry, 0, len(files))
+	for name, content := range files {
+		blobHash, err := r.ObjectService.WriteBlob([]byte(content))
+		require.NoError(t, err)
+		entries = append(entries, domain.NewTreeEntry(domain.FileModeRegular, blobHash, name))
+	}
+	treeObject, err := domain.NewTreeFromEntries(entries)
+	require.NoError(t, err)
+	data := treeObject.Serialize()
+	treeHash, err := domain.NewHashFromHex(core.ComputeSHA256(data))
+	require.NoError(t, err)
+	require.NoError(t, r.ObjectService.Write(treeHash, data))
+
+	commitHash, err := r.CommitTree.CommitTree(treeHash, message, parents)
+	require.NoError(t, err)
+	return commitHash
+}
+
+// files returns the file contents of the tree of commitHash.
+func (r *rebaseTestRepository) files(t *testing.T, commitHash domain.Hash) map[string]string {
+	t.Helper()
+	commitObject, err := r.ObjectService.ReadCommit(commitHash)
+	require.NoError(t, err)
+	treeObject, err := r.ObjectService.ReadTree(commitObject.TreeHash)
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, entry := range treeObject.Entries() {
+		blob, err := r.ObjectService.ReadBlob(entry.Hash)
+		require.NoError(t, err)
+		files[entry.Name] = string(blob.Body())
+	}
+	return files
+}
+
+// TestRebaseCommitsAfterLocalMerge rebases a branch that merged upstream
+// before: the merge base is only reachable through the merge's second
+// parent, so only the local commit is replayed and the merge is skipped.
+func TestRebaseCommitsAfterLocalMerge(t *testing.T) {
+	repository := newRebaseTestRepository(t)
+	root := repository.commit(t, "root", map[string]string{"base": "1"})
+	local := repository.commit(t, "L1", map[string]string{"base": "1", "local": "L1"}, root)
+	upstream := repository.commit(t, "U1", map[string]string{"base": "1", "up": "U1"}, root)
+	merged := repository.commit(
+		t, "merge", map[string]string{"base": "1", "local": "L1", "up": "U1"}, local, upstream,
+	)
+	upstreamTip := repository.commit(t, "U2", map[string]string{"base": "2", "up": "U1"}, upstream)
+
+	rebased, err := repository.pullService.rebaseCommits(merged, upstreamTip)
+
+	require.NoError(t, err)
+	rebasedCommit, err := repository.ObjectService.ReadCommit(rebased)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Hash{upstreamTip}, rebasedCommit.ParentHashes)
+	assert.Equal(t, "L1", rebasedCommit.Message)
+	assert.Equal(t, map[string]string{"base": "2", "local": "L1", "up": "U1"}, repository.files(t, rebased))
+}
+
+func TestRebaseCommitsReplaysInOrder(t *testing.T) {
+	repository := newRebaseTestRepository(t)
+	root := repository.commit(t, "root", map[string]string{"base": "1"})
+	first := repository.commit(t, "first", map[string]string{"base": "1", "a": "1"}, root)
+	second := repository.commit(t, "second", map[string]string{"base": "1", "a": "2"}, first)
+	upstreamTip := repository.commit(t, "upstream", map[string]string{"base": "2"}, root)
+
+	rebased, err := repository.pullService.rebaseCommits(second, upstreamTip)
+
+	require.NoError(t, err)
+	rebasedCommit, err := repository.ObjectService.ReadCommit(rebased)
+	require.NoError(t, err)
+	assert.Equal(t, "second", rebasedCommit.Message)
+	require.Len(t, rebasedCommit.ParentHashes, 1)
+	parentCommit, err := repository.ObjectService.ReadCommit(rebasedCommit.ParentHashes[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first", parentCommit.Message)
+	assert.Equal(t, []domain.Hash{upstreamTip}, parentCommit.ParentHashes)
+	assert.Equal(t, map[string]string{"base": "2", "a": "2"}, repository.files(t, rebased))
+}
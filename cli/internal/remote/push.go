@@ -0,0 +1,291 @@
+package remote
+
+import (
+	"Gel/internal/bundle"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/merge"
+	"Gel/internal/storage"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PushOptions controls push behavior.
+type PushOptions struct {
+	// Force updates the remote branch even when it is not an ancestor of the
+	// pushed commit.
+	Force bool
+	// NoVerify skips the pre-push hook.
+	NoVerify bool
+}
+
+// PushResult reports the outcome of a push invocation.
+type PushResult struct {
+	// Remote is the pushed remote name.
+	Remote string
+	// URL is the location pushed to.
+	URL string
+	// Ref is the branch ref updated on the remote (refs/heads/<branch>).
+	Ref string
+	// OldHash is the previous remote value, or zero when the ref was created.
+	OldHash domain.Hash
+	// NewHash is the pushed commit hash.
+	NewHash domain.Hash
+	// Forced reports that the update was not a fast-forward.
+	Forced bool
+}
+
+// UpToDate reports whether the remote branch already had the pushed commit.
+func (r *PushResult) UpToDate() bool {
+	return r.OldHash == r.NewHash
+}
+
+// PushService uploads a local branch to a configured remote.
+//
+// Like fetch, remotes are addressed by local filesystem path and must name a
+// repository directory; bundle files cannot be pushed to. Every object
+// reachable from the pushed commit is copied when missing on the remote.
+type PushService struct {
+	objectService *core.ObjectService
+	refService    *core.RefService
+	configService *core.ConfigService
+	mergeService  *merge.MergeService
+	workspace     *domain.Workspace
+	hookRunner    *core.HookRunner
+}
+
+// NewPushService creates a push service.
+func NewPushService(
+	objectService *core.ObjectService,
+	refService *core.RefService,
+	configService *core.ConfigService,
+	mergeService *merge.MergeService,
+	workspace *domain.Workspace,
+) *PushService {
+	return &PushService{
+		objectService: objectService,
+		refService:    refService,
+		configService: configService,
+		mergeService:  mergeService,
+		workspace:     workspace,
+	}
+}
+
+// SetHookRunner enables the pre-push hook.
+func (p *PushService) SetHookRunner(hookRunner *core.HookRunner) {
+	p.hookRunner = hookRunner
+}
+
+// Push updates refs/heads/<branchName> of remoteName to the local branch of
+// the same name and records it in refs/remotes/<remoteName>/<branchName>.
+//
+// An empty branchName pushes the current branch. An empty remoteName uses
+// branch.<current>.remote, falling back to origin.
+//
+// Unless options.Force is set, the remote branch must be an ancestor of the
+// pushed commit. A branch checked out in a working tree of the remote is
+// never updated. Unless options.NoVerify is set, the pre-push hook runs
+// before anything is written to the remote and can refuse the push.
+func (p *PushService) Push(remoteName, branchName string, options PushOptions) (*PushResult, error) {
+	currentBranch, err := p.currentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("push: %w", err)
+	}
+	if branchName == "" {
+		if currentBranch == "" {
+			return nil, fmt.Errorf("push: HEAD is detached: %w", ErrPushBranchNotFound)
+		}
+		branchName = currentBranch
+	}
+	if remoteName == "" {
+		remoteName, err = p.defaultRemote(currentBranch)
+		if err != nil {
+			return nil, fmt.Errorf("push: %w", err)
+		}
+	}
+
+	url, err := remoteURL(p.configService, remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("push: %w", err)
+	}
+	if bundle.IsBundleFile(url) {
+		return nil, fmt.Errorf("push: '%s': %w", remoteName, ErrPushToBundle)
+	}
+	remoteWorkspace, err := domain.NewWorkspace(url)
+	if err != nil {
+		return nil, fmt.Errorf("push: open remote '%s': %w", remoteName, err)
+	}
+	remoteRefService := core.NewRefService(remoteWorkspace)
+
+	ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branchName)
+	localHash, err := p.refService.Read(ref)
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return nil, fmt.Errorf("push: '%s': %w", branchName, ErrPushBranchNotFound)
+		}
+		return nil, fmt.Errorf("push: %w", err)
+	}
+	remoteHash, err := remoteRefService.Read(ref)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, fmt.Errorf("push: %w", err)
+	}
+
+	result := &PushResult{
+		Remote:  remoteName,
+		URL:     url,
+		Ref:     ref,
+		OldHash: remoteHash,
+		NewHash: localHash,
+	}
+	if result.UpToDate() {
+		if err := p.updateTrackingRef(remoteName, branchName, localHash); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result.Forced, err = p.checkFastForward(remoteHash, localHash, options.Force)
+	if err != nil {
+		return nil, fmt.Errorf("push: '%s': %w", branchName, err)
+	}
+	if err := checkNotCheckedOut(remoteWorkspace, ref); err != nil {
+		return nil, fmt.Errorf("push: '%s': %w", branchName, err)
+	}
+
+	if !options.NoVerify {
+		refLine := fmt.Sprintf("%s %s %s %s\n", ref, localHash, ref, remoteHash)
+		err := p.hookRunner.RunWithInput(core.HookPrePush, strings.NewReader(refLine), remoteName, url)
+		if err != nil {
+			return nil, fmt.Errorf("push: %w", err)
+		}
+	}
+
+	localSource := &repositorySource{workspace: p.workspace, objectService: p.objectService}
+	remoteObjectService := core.NewObjectService(storage.NewObjectStorage(remoteWorkspace))
+	if err := copyCommitGraph(localSource, remoteObjectService, localHash); err != nil {
+		return nil, fmt.Errorf("push: %w", err)
+	}
+	if err := remoteRefService.Write(ref, localHash); err != nil {
+		return nil, fmt.Errorf("push: %w", err)
+	}
+	if err := p.updateTrackingRef(remoteName, branchName, localHash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// currentBranch returns the branch HEAD points at, or "" when HEAD is detached.
+func (p *PushService) currentBranch() (string, error) {
+	ref, err := p.refService.ReadSymbolic(domain.HeadFileName)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidSymbolicRef) {
+			return "", nil
+		}
+		return "", err
+	}
+	headsPrefix := filepath.Join(domain.RefsDirName, domain.HeadsDirName) + "/"
+	return strings.TrimPrefix(ref, headsPrefix), nil
+}
+
+// defaultRemote returns branch.<branchName>.remote, or origin when unset.
+func (p *PushService) defaultRemote(branchName string) (string, error) {
+	if branchName == "" {
+		return DefaultRemoteName, nil
+	}
+	remoteName, ok, err := p.configService.GetOptional(
+		core.ConfigSectionBranch, branchName+"."+core.ConfigKeyRemote,
+	)
+	if err != nil {
+		return "", err
+	}
+	if !ok || strings.TrimSpace(remoteName) == "" {
+		return DefaultRemoteName, nil
+	}
+	return remoteName, nil
+}
+
+// checkFastForward returns ErrPushRejected unless remoteHash is empty or an
+// ancestor of localHash. With force, it instead reports whether the update
+// discards remote commits.
+func (p *PushService) checkFastForward(remoteHash, localHash domain.Hash, force bool) (bool, error) {
+	if remoteHash.IsEmpty() {
+		return false, nil
+	}
+	fastForward := false
+	exists, err := p.objectService.Exists(remoteHash)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		fastForward, err = p.mergeService.IsAncestor(remoteHash, localHash)
+		if err != nil {
+			return false, err
+		}
+	}
+	if !fastForward && !force {
+		return false, ErrPushRejected
+	}
+	return !fastForward, nil
+}
+
+// updateTrackingRef records hash as refs/remotes/<remoteName>/<branchName>.
+func (p *PushService) updateTrackingRef(remoteName, branchName string, hash domain.Hash) error {
+	if err := p.refService.Write(RemoteTrackingRef(remoteName, branchName), hash); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
+
+// checkNotCheckedOut returns ErrPushToCheckedOutBranch when a working tree of
+// the repository at remoteWorkspace has ref checked out, since updating it
+// would leave that working tree and index out of step with HEAD.
+func checkNotCheckedOut(remoteWorkspace *domain.Workspace, ref string) error {
+	worktrees, err := core.NewWorktreeRegistry(remoteWorkspace).List()
+	if err != nil {
+		return err
+	}
+	for _, worktree := range worktrees {
+		if !worktree.Bare && worktree.HeadRef == ref {
+			return fmt.Errorf("%w at '%s'", ErrPushToCheckedOutBranch, worktree.Dir)
+		}
+	}
+	return nil
+}
+
+// copyCommitGraph copies the commits reachable from tip, with their trees and
+// blobs, from src to dst. Commits dst already has are assumed to have their
+// full closure present, as in fetch.
+func copyCommitGraph(src source, dst *core.ObjectService, tip domain.Hash) error {
+	visited := make(map[domain.Hash]bool)
+	queue := []domain.Hash{tip}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if visited[hash] {
+			continue
+		}
+		visited[hash] = true
+
+		exists, err := dst.Exists(hash)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		commit, err := readSourceCommit(src, hash)
+		if err != nil {
+			return err
+		}
+		if err := copyTree(src, dst, commit.TreeHash, ObjectFilterNone); err != nil {
+			return err
+		}
+		if err := dst.Write(hash, commit.Serialize()); err != nil {
+			return err
+		}
+		queue = append(queue, commit.ParentHashes...)
+	}
+	return nil
+}
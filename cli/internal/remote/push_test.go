@@ -0,0 +1,163 @@
+package remote
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/merge"
+	"Gel/internal/setup"
+	"Gel/internal/testutil"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prePushHook records its arguments and standard input in pre-push.out next
+// to itself and exits with the status in the PRE_PUSH_STATUS variable.
+const prePushHook = `#!/bin/sh
+out="$(dirname "$0")/pre-push.out"
+echo "$@" > "$out"
+cat >> "$out"
+exit "${PRE_PUSH_STATUS:-0}"
+`
+
+// pushTestRepository is a local repository pushing to a bare remote.
+type pushTestRepository struct {
+	*testutil.Repository
+	pushService *PushService
+	remoteURL   string
+	remote      *testutil.Repository
+}
+
+// newPushTestRepository initializes a repository whose origin is a new bare
+// repository, and installs prePushHook.
+func newPushTestRepository(t *testing.T) *pushTestRepository {
+	t.Helper()
+	remoteURL := filepath.Join(t.TempDir(), "remote.gel")
+	remote := testutil.InitRepository(t, remoteURL, setup.InitOptions{Bare: true})
+
+	repository := testutil.NewRepository(t)
+	configService := repository.ConfigService
+	require.NoError(t, configService.Set(core.ConfigSectionRemote, DefaultRemoteName+"."+core.ConfigKeyURL, remoteURL))
+	pushService := NewPushService(
+		repository.ObjectService, repository.RefService, configService,
+		merge.NewMergeService(repository.ObjectService, repository.WriteTree, core.NewShallowService(repository.Workspace)),
+		repository.Workspace,
+	)
+	pushService.SetHookRunner(core.NewHookRunner(configService, repository.Workspace))
+	hooksDir := filepath.Join(repository.Workspace.CommonDir.String(), domain.HooksDirName)
+	require.NoError(t, os.MkdirAll(hooksDir, domain.DefaultDirPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, core.HookPrePush), []byte(prePushHook), 0o755))
+
+	return &pushTestRepository{
+		Repository:  repository,
+		pushService: pushService,
+		remoteURL:   remoteURL,
+		remote:      remote,
+	}
+}
+
+// hookOutput returns what the pre-push hook recorded, or "" when it did not run.
+func (r *pushTestRepository) hookOutput(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(r.Workspace.CommonDir.String(), domain.HooksDirName, "pre-push.out"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+// remoteBranch returns the default branch of the remote, zero when missing.
+func (r *pushTestRepository) remoteBranch(t *testing.T) domain.Hash {
+	t.Helper()
+	hash, err := r.remote.RefService.Read(domain.DefaultBranchRef)
+	if err != nil {
+		require.ErrorIs(t, err, core.ErrRefNotFound)
+	}
+	return hash
+}
+
+func TestPushRunsPrePushHook(t *testing.T) {
+	repository := newPushTestRepository(t)
+	first := repository.Commit(t, "first")
+
+	result, err := repository.pushService.Push("", "", PushOptions{})
+
+	require.NoError(t, err)
+	assert.True(t, result.OldHash.IsEmpty())
+	assert.Equal(t, first, result.NewHash)
+	assert.Equal(t, first, repository.remoteBranch(t))
+	exists, err := repository.remote.ObjectService.Exists(first)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	tracking, err := repository.RefService.Read(RemoteTrackingRef(DefaultRemoteName, domain.DefaultBranchName))
+	require.NoError(t, err)
+	assert.Equal(t, first, tracking)
+	assert.Equal(
+		t,
+		fmt.Sprintf(
+			"%s %s\n%s %s %s %s\n",
+			DefaultRemoteName, repository.remoteURL,
+			domain.DefaultBranchRef, first, domain.DefaultBranchRef, domain.Hash{},
+		),
+		repository.hookOutput(t),
+	)
+}
+
+func TestPushPrePushHookRefusal(t *testing.T) {
+	tests := []struct {
+		name      string
+		noVerify  bool
+		wantErr   error
+		wantHook  bool
+		wantWrite bool
+	}{
+		{name: "hook refuses", wantErr: core.ErrHookFailed, wantHook: true},
+		{name: "no-verify skips hook", noVerify: true, wantWrite: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repository := newPushTestRepository(t)
+				first := repository.Commit(t, "first")
+				t.Setenv("PRE_PUSH_STATUS", "1")
+
+				_, err := repository.pushService.Push(DefaultRemoteName, "", PushOptions{NoVerify: tt.noVerify})
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+				assert.Equal(t, tt.wantHook, repository.hookOutput(t) != "")
+				assert.Equal(t, tt.wantWrite, repository.remoteBranch(t) == first)
+				exists, err := repository.remote.ObjectService.Exists(first)
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantWrite, exists)
+			},
+		)
+	}
+}
+
+func TestPushRejectsNonFastForward(t *testing.T) {
+	repository := newPushTestRepository(t)
+	first := repository.Commit(t, "first")
+	second := repository.Commit(t, "second", first)
+	_, err := repository.pushService.Push("", "", PushOptions{NoVerify: true})
+	require.NoError(t, err)
+	rewritten := repository.Commit(t, "rewritten", first)
+
+	_, err = repository.pushService.Push("", "", PushOptions{NoVerify: true})
+	require.ErrorIs(t, err, ErrPushRejected)
+	assert.Equal(t, second, repository.remoteBranch(t))
+
+	result, err := repository.pushService.Push("", "", PushOptions{Force: true, NoVerify: true})
+	require.NoError(t, err)
+	assert.True(t, result.Forced)
+	assert.Equal(t, second, result.OldHash)
+	assert.Equal(t, rewritten, repository.remoteBranch(t))
+}
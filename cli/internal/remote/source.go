@@ -0,0 +1,216 @@
+package remote
+
+import (
+	"Gel/internal/bundle"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/storage"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// source is a location objects and branch refs are fetched from.
+//
+// A remote URL names either a Gel repository directory or a bundle file;
+// fetch, clone and the promisor fetcher read both through this interface.
+type source interface {
+	// Branches returns the branch names the source advertises, sorted.
+	Branches() ([]string, error)
+	// ReadBranch returns the tip of a branch returned by Branches.
+	ReadBranch(branchName string) (domain.Hash, error)
+	// HeadBranch returns the branch the source's HEAD points at, if any.
+	HeadBranch() (string, bool, error)
+	// Prerequisites returns commits the receiving repository must already have.
+	Prerequisites() []domain.Hash
+	// Read returns the object stored under hash.
+	Read(hash domain.Hash) (domain.Object, error)
+}
+
+// openRemote opens the repository or bundle file at url.
+func openRemote(remoteName, url string) (source, error) {
+	if bundle.IsBundleFile(url) {
+		b, err := bundle.ReadFile(url)
+		if err != nil {
+			return nil, fmt.Errorf("open remote '%s': %w", remoteName, err)
+		}
+		return &bundleSource{bundle: b}, nil
+	}
+
+	remoteWorkspace, err := domain.NewWorkspace(url)
+	if err != nil {
+		return nil, fmt.Errorf("open remote '%s': %w", remoteName, err)
+	}
+	return &repositorySource{
+		workspace:     remoteWorkspace,
+		objectService: core.NewObjectService(storage.NewObjectStorage(remoteWorkspace)),
+	}, nil
+}
+
+// readSourceCommit reads hash from src and checks that it is a commit.
+func readSourceCommit(src source, hash domain.Hash) (*domain.Commit, error) {
+	object, err := src.Read(hash)
+	if err != nil {
+		return nil, err
+	}
+	commit, ok := object.(*domain.Commit)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %s, got %s", domain.ErrObjectTypeMismatch, domain.ObjectTypeCommit, object.Type())
+	}
+	return commit, nil
+}
+
+// readSourceTree reads hash from src and checks that it is a tree.
+func readSourceTree(src source, hash domain.Hash) (*domain.Tree, error) {
+	object, err := src.Read(hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, ok := object.(*domain.Tree)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %s, got %s", domain.ErrObjectTypeMismatch, domain.ObjectTypeTree, object.Type())
+	}
+	return tree, nil
+}
+
+// repositorySource reads a Gel repository on the local filesystem.
+type repositorySource struct {
+	workspace     *domain.Workspace
+	objectService *core.ObjectService
+}
+
+// Branches returns the branch names under the repository's refs/heads directory.
+func (r *repositorySource) Branches() ([]string, error) {
+	headsDir := r.workspace.HeadsDir.String()
+	var branches []string
+	err := filepath.WalkDir(
+		headsDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			name, err := filepath.Rel(headsDir, path)
+			if err != nil {
+				return err
+			}
+			branches = append(branches, filepath.ToSlash(name))
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list remote branches: %w", err)
+	}
+	return branches, nil
+}
+
+// ReadBranch reads refs/heads/<branchName> from the repository.
+func (r *repositorySource) ReadBranch(branchName string) (domain.Hash, error) {
+	ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branchName)
+	return core.NewRefService(r.workspace).Read(ref)
+}
+
+// HeadBranch returns the branch HEAD points at.
+// It reports false when HEAD is detached or unreadable as a branch.
+func (r *repositorySource) HeadBranch() (string, bool, error) {
+	ref, err := core.NewRefService(r.workspace).ReadSymbolic(domain.HeadFileName)
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	headsPrefix := filepath.Join(domain.RefsDirName, domain.HeadsDirName) + "/"
+	if !strings.HasPrefix(ref, headsPrefix) {
+		return "", false, nil
+	}
+	return strings.TrimPrefix(ref, headsPrefix), true, nil
+}
+
+// Prerequisites returns nil; a repository carries its complete history.
+func (r *repositorySource) Prerequisites() []domain.Hash {
+	return nil
+}
+
+// Read reads hash from the repository's object store.
+func (r *repositorySource) Read(hash domain.Hash) (domain.Object, error) {
+	return r.objectService.Read(hash)
+}
+
+// bundleSource reads refs and objects from a decoded bundle file.
+type bundleSource struct {
+	bundle *bundle.Bundle
+}
+
+// Branches returns the refs/heads/* names recorded in the bundle header.
+func (b *bundleSource) Branches() ([]string, error) {
+	headsPrefix := filepath.Join(domain.RefsDirName, domain.HeadsDirName) + "/"
+	var branches []string
+	for _, ref := range b.bundle.Refs {
+		if name, ok := strings.CutPrefix(ref.Name, headsPrefix); ok {
+			branches = append(branches, name)
+		}
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// ReadBranch returns the recorded tip of refs/heads/<branchName>.
+func (b *bundleSource) ReadBranch(branchName string) (domain.Hash, error) {
+	ref := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branchName)
+	for _, bundleRef := range b.bundle.Refs {
+		if bundleRef.Name == ref {
+			return bundleRef.Hash, nil
+		}
+	}
+	return domain.Hash{}, fmt.Errorf("%w: %s", core.ErrRefNotFound, ref)
+}
+
+// HeadBranch returns the branch whose tip matches the recorded HEAD.
+//
+// Bundles store HEAD by value, so when several branches share its commit the
+// default branch is preferred, then the first in name order. A bundle created
+// without HEAD yields its only branch, or else the default branch if bundled.
+func (b *bundleSource) HeadBranch() (string, bool, error) {
+	var head domain.Hash
+	for _, ref := range b.bundle.Refs {
+		if ref.Name == domain.HeadFileName {
+			head = ref.Hash
+		}
+	}
+
+	branches, _ := b.Branches()
+	var matched []string
+	for _, branchName := range branches {
+		if hash, _ := b.ReadBranch(branchName); head.IsEmpty() || hash == head {
+			matched = append(matched, branchName)
+		}
+	}
+	if len(matched) == 0 {
+		return "", false, nil
+	}
+	for _, branchName := range matched {
+		if branchName == domain.DefaultBranchName {
+			return branchName, true, nil
+		}
+	}
+	if head.IsEmpty() && len(matched) > 1 {
+		return "", false, nil
+	}
+	return matched[0], true, nil
+}
+
+// Prerequisites returns the commits the bundle's history builds on.
+func (b *bundleSource) Prerequisites() []domain.Hash {
+	return b.bundle.Prerequisites
+}
+
+// Read returns an object packed in the bundle.
+func (b *bundleSource) Read(hash domain.Hash) (domain.Object, error) {
+	return b.bundle.ReadObject(hash)
+}
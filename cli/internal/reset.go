@@ -40,6 +40,7 @@ type ResetService struct {
 	readTreeService *tree.ReadTreeService
 	treeResolver    *core.TreeResolver
 	commitResolver  *core.CommitResolver
+	hookRunner      *core.HookRunner
 	workspace       *domain.Workspace
 }
 
@@ -62,7 +63,14 @@ func NewResetService(
 	}
 }
 
+// SetHookRunner enables the post-checkout hook run after a hard reset.
+func (r *ResetService) SetHookRunner(hookRunner *core.HookRunner) {
+	r.hookRunner = hookRunner
+}
+
 // Reset applies the requested reset mode to the target revision and returns the resolved commit hash.
+// A hard reset rewrites the working tree like a checkout and runs the
+// post-checkout hook with the previous and new HEAD commits.
 func (r *ResetService) Reset(target string, options ResetOptions) (*ResetResult, error) {
 	if err := validateMode(options.Mode); err != nil {
 		return nil, fmt.Errorf("reset: %w", err)
@@ -91,9 +99,16 @@ func (r *ResetService) Reset(target string, options ResetOptions) (*ResetResult,
 			return nil, fmt.Errorf("reset: %w", err)
 		}
 	}
+	previousHash, err := r.refService.Resolve(domain.HeadFileName)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, fmt.Errorf("reset: %w", err)
+	}
 	if err := r.moveHEADPointer(targetHash); err != nil {
 		return nil, fmt.Errorf("reset: %w", err)
 	}
+	if options.Mode == ResetModeHard {
+		r.hookRunner.RunPost(core.HookPostCheckout, previousHash.String(), targetHash.String(), "1")
+	}
 	return &ResetResult{
 		TargetHash: targetHash,
 	}, nil
@@ -125,14 +140,28 @@ func (r *ResetService) checkoutWorkingTree(targetHash domain.Hash) error {
 		return err
 	}
 
+	targetPathModes, err := r.treeResolver.ResolveCommitModes(targetHash)
+	if err != nil {
+		return err
+	}
+
+	workingTreePathModes, err := r.treeResolver.ResolveWorkingTreeModes(workingTreePathHashes.ExtractPaths())
+	if err != nil {
+		return err
+	}
+
 	deletePaths := collectDeletePaths(headPathHashes, targetPathHashes)
-	writePaths := collectWritePaths(targetPathHashes, workingTreePathHashes)
+	writePaths := collectWritePaths(targetPathHashes, workingTreePathHashes, targetPathModes, workingTreePathModes)
 
 	for _, path := range deletePaths {
 		absPath, err := path.ToAbsolutePath(r.workspace.RepoDir)
 		if err != nil {
 			return err
 		}
+		// A checked-out submodule holds its own repository and is left in place.
+		if core.IsRepositoryRoot(absPath.String()) {
+			continue
+		}
 		if err := os.Remove(absPath.String()); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return fmt.Errorf("failed to delete '%s': %w", absPath, err)
 		}
@@ -140,22 +169,13 @@ func (r *ResetService) checkoutWorkingTree(targetHash domain.Hash) error {
 			return fmt.Errorf("failed to prune empty parents for '%s': %w", absPath, err)
 		}
 	}
-	for _, path := range writePaths {
-		blobHash := targetPathHashes[path]
-		blob, err := r.objectService.ReadBlob(blobHash)
-		if err != nil {
-			return err
-		}
-
+	for _, path := range core.CheckoutOrder(writePaths) {
 		absPath, err := path.ToAbsolutePath(r.workspace.RepoDir)
 		if err != nil {
 			return err
 		}
-		if err := os.MkdirAll(filepath.Dir(absPath.String()), domain.DefaultDirPermission); err != nil {
-			return fmt.Errorf("failed to create parent dir for '%s': %w", absPath.String(), err)
-		}
-		if err := os.WriteFile(absPath.String(), blob.Body(), domain.DefaultFilePermission); err != nil {
-			return fmt.Errorf("failed to write '%s': %w", absPath, err)
+		if err := r.objectService.CheckoutBlob(targetPathHashes[path], absPath, targetPathModes[path]); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -202,10 +222,14 @@ func collectDeletePaths(oldPathHashes, newPathHashes core.PathHashes) (deletePat
 	return
 }
 
-// collectWritePaths returns paths whose target content differs from the current working tree.
-func collectWritePaths(targetPathHashes, workingTreePathHashes core.PathHashes) (writePaths []domain.NormalizedPath) {
+// collectWritePaths returns paths whose target content or file type differs from the current working tree.
+func collectWritePaths(
+	targetPathHashes, workingTreePathHashes core.PathHashes,
+	targetPathModes, workingTreePathModes core.PathModes,
+) (writePaths []domain.NormalizedPath) {
 	for path, targetHash := range targetPathHashes {
-		if workingHash, inWorking := workingTreePathHashes[path]; !inWorking || workingHash != targetHash {
+		workingHash, inWorking := workingTreePathHashes[path]
+		if !inWorking || workingHash != targetHash || !targetPathModes[path].SameType(workingTreePathModes[path]) {
 			writePaths = append(writePaths, path)
 		}
 	}
@@ -30,6 +30,13 @@ var (
 type InitService struct {
 }
 
+// InitOptions controls repository initialization.
+type InitOptions struct {
+	// Bare creates the metadata layout directly in the target directory with no
+	// working tree or .gel subdirectory.
+	Bare bool
+}
+
 // NewInitService creates a stateless service for repository initialization.
 // The service only performs filesystem operations and keeps no in-memory state.
 func NewInitService() *InitService {
@@ -45,6 +52,8 @@ func NewInitService() *InitService {
 //   - Ensures .gel, .gel/objects, and .gel/refs/heads directories exist.
 //   - Creates .gel/HEAD with "ref: refs/heads/main" when missing.
 //   - Creates an empty .gel/config.toml when missing.
+//   - With options.Bare, uses path itself instead of path/.gel as the
+//     metadata directory, so the repository has no working tree.
 //   - Leaves existing HEAD and config files unchanged when they are regular
 //     files.
 //
@@ -54,7 +63,7 @@ func NewInitService() *InitService {
 // Returned errors wrap ErrInitEmptyPath, ErrInitPathNotDirectory, or
 // ErrInitPathNotRegularFile for expected initialization conflicts. Filesystem
 // failures are wrapped with the operation and path that failed.
-func (i *InitService) Init(path string) (string, error) {
+func (i *InitService) Init(path string, options InitOptions) (string, error) {
 	if path == "" {
 		return "", fmt.Errorf("init: validate path: %w", ErrInitEmptyPath)
 	}
@@ -65,12 +74,18 @@ func (i *InitService) Init(path string) (string, error) {
 	}
 
 	gelPath := filepath.Join(absPath, domain.GelDirName)
+	if options.Bare {
+		gelPath = absPath
+	}
 	objectsPath := filepath.Join(gelPath, domain.ObjectsDirName)
 	headsPath := filepath.Join(gelPath, domain.RefsDirName, domain.HeadsDirName)
 	headPath := filepath.Join(gelPath, domain.HeadFileName)
 	configPath := filepath.Join(gelPath, domain.ConfigFileName)
 
 	gelExists, err := directoryExists(gelPath)
+	if options.Bare && err == nil {
+		gelExists, err = directoryExists(objectsPath)
+	}
 	if err != nil {
 		return "", fmt.Errorf("init: check existing repository: %w", err)
 	}
@@ -95,10 +110,14 @@ func (i *InitService) Init(path string) (string, error) {
 		return "", fmt.Errorf("init: prepare config: %w", err)
 	}
 
+	kind := "Gel"
+	if options.Bare {
+		kind = "bare Gel"
+	}
 	if gelExists {
-		return fmt.Sprintf("Reinitialized existing Gel repository in %v", gelPath), nil
+		return fmt.Sprintf("Reinitialized existing %s repository in %v", kind, gelPath), nil
 	}
-	return fmt.Sprintf("Initialized empty Gel repository in %v", gelPath), nil
+	return fmt.Sprintf("Initialized empty %s repository in %v", kind, gelPath), nil
 }
 
 // ensureDirectory creates path when it is missing and verifies that an existing
@@ -0,0 +1,11 @@
+package sparse
+
+import "errors"
+
+var (
+	// ErrNotSparse is returned when a command needs sparse checkout but it is disabled.
+	ErrNotSparse = errors.New("this worktree is not sparse")
+
+	// ErrNoDirectories is returned when set or add is given no directories.
+	ErrNoDirectories = errors.New("no directories given")
+)
@@ -0,0 +1,186 @@
+package sparse
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ApplyResult reports how the working tree changed to match the cone.
+type ApplyResult struct {
+	// Materialized lists paths written back into the working tree.
+	Materialized []domain.NormalizedPath
+	// Removed lists paths deleted from the working tree and marked skip-worktree.
+	Removed []domain.NormalizedPath
+	// Kept lists paths outside the cone left in place because they have local modifications.
+	Kept []domain.NormalizedPath
+}
+
+// SparseCheckoutService narrows the working tree to a cone of directories.
+//
+// Index entries outside the cone carry the skip-worktree bit and their files
+// are absent from disk; status, diff, switch, reset and restore leave them alone.
+type SparseCheckoutService struct {
+	indexService   *core.IndexService
+	objectService  *core.ObjectService
+	changeDetector *core.ChangeDetector
+	sparseCheckout *core.SparseCheckout
+	workspace      *domain.Workspace
+}
+
+// NewSparseCheckoutService creates a sparse checkout service.
+func NewSparseCheckoutService(
+	indexService *core.IndexService,
+	objectService *core.ObjectService,
+	changeDetector *core.ChangeDetector,
+	sparseCheckout *core.SparseCheckout,
+	workspace *domain.Workspace,
+) *SparseCheckoutService {
+	return &SparseCheckoutService{
+		indexService:   indexService,
+		objectService:  objectService,
+		changeDetector: changeDetector,
+		sparseCheckout: sparseCheckout,
+		workspace:      workspace,
+	}
+}
+
+// List returns the directories of the active cone.
+func (s *SparseCheckoutService) List() ([]string, error) {
+	cone, err := s.sparseCheckout.Cone()
+	if err != nil {
+		return nil, err
+	}
+	if cone == nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", ErrNotSparse)
+	}
+	return cone.Dirs, nil
+}
+
+// Set enables sparse checkout with a cone of exactly dirs and updates the working tree.
+func (s *SparseCheckoutService) Set(dirs []string) (*ApplyResult, error) {
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("sparse-checkout: %w", ErrNoDirectories)
+	}
+	return s.update(domain.NewSparseCone(dirs))
+}
+
+// Add widens the active cone by dirs and updates the working tree.
+func (s *SparseCheckoutService) Add(dirs []string) (*ApplyResult, error) {
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("sparse-checkout: %w", ErrNoDirectories)
+	}
+	cone, err := s.sparseCheckout.Cone()
+	if err != nil {
+		return nil, err
+	}
+	if cone == nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", ErrNotSparse)
+	}
+	return s.update(domain.NewSparseCone(append(cone.Dirs, dirs...)))
+}
+
+// Disable turns sparse checkout off and restores every skipped path.
+// The cone definition is kept so a later set or add can start from it.
+func (s *SparseCheckoutService) Disable() (*ApplyResult, error) {
+	if err := s.sparseCheckout.SetEnabled(false); err != nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	result, err := s.apply(nil)
+	if err != nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	return result, nil
+}
+
+// update stores cone, enables sparse checkout and applies it.
+func (s *SparseCheckoutService) update(cone domain.SparseCone) (*ApplyResult, error) {
+	if err := s.sparseCheckout.WriteCone(cone); err != nil {
+		return nil, err
+	}
+	if err := s.sparseCheckout.SetEnabled(true); err != nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	result, err := s.apply(&cone)
+	if err != nil {
+		return nil, fmt.Errorf("sparse-checkout: %w", err)
+	}
+	return result, nil
+}
+
+// apply makes the working tree and skip-worktree bits match cone; a nil cone includes everything.
+// Files leaving the cone are deleted unless they have local modifications.
+func (s *SparseCheckoutService) apply(cone *domain.SparseCone) (*ApplyResult, error) {
+	index, err := s.indexService.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+	for _, entry := range index.Entries {
+		// Intent-to-add files are untracked content and are never touched.
+		if entry.GetStage() != 0 || entry.IntentToAdd() {
+			continue
+		}
+		included := cone == nil || cone.Includes(entry.Path)
+		absPath, err := entry.Path.ToAbsolutePath(s.workspace.RepoDir)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case included && entry.SkipWorktree():
+			entry.SetSkipWorktree(false)
+			if err := s.objectService.CheckoutBlob(entry.Hash, absPath, domain.FileMode(entry.Mode)); err != nil {
+				return nil, err
+			}
+			result.Materialized = append(result.Materialized, entry.Path)
+		case !included && !entry.SkipWorktree():
+			changeResult, err := s.changeDetector.DetectFileChange(entry)
+			if err != nil {
+				return nil, err
+			}
+			if changeResult.FileState == core.FileStateModified && changeResult.NewHash != entry.Hash {
+				result.Kept = append(result.Kept, entry.Path)
+				continue
+			}
+			if err := os.Remove(absPath.String()); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			if err := pruneEmptyParentDirs(absPath.String(), s.workspace.RepoDir.String()); err != nil {
+				return nil, err
+			}
+			entry.SetSkipWorktree(true)
+			result.Removed = append(result.Removed, entry.Path)
+		}
+	}
+
+	if err := s.indexService.Write(index); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// pruneEmptyParentDirs removes empty ancestor directories of filePath up to repoRoot.
+func pruneEmptyParentDirs(filePath, repoRoot string) error {
+	repoRoot = filepath.Clean(repoRoot)
+	for dir := filepath.Dir(filePath); dir != repoRoot; dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if len(entries) != 0 {
+			return nil
+		}
+		if err := os.Remove(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package staging
 
 import (
 	"Gel/internal/core"
+	"Gel/internal/diff"
 	"Gel/internal/domain"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // AddOptions controls add command execution mode.
@@ -12,6 +15,11 @@ type AddOptions struct {
 	DryRun bool
 	// Verbose includes added/removed path output after a real update.
 	Verbose bool
+	// Force stages paths matched by ignore rules.
+	Force bool
+	// IntentToAdd records untracked paths as intent-to-add entries without
+	// staging their content, and leaves tracked paths alone.
+	IntentToAdd bool
 }
 
 // AddResult returns the outcome of an add invocation.
@@ -24,22 +32,28 @@ type AddResult struct {
 // AddService stages working tree paths and reconciles scoped removals.
 type AddService struct {
 	indexService       *core.IndexService
+	objectService      *core.ObjectService
 	updateIndexService *UpdateIndexService
 	pathResolver       *core.PathResolver
+	changeDetector     *core.ChangeDetector
 	workspace          *domain.Workspace
 }
 
 // NewAddService creates an add service with required dependencies.
 func NewAddService(
 	indexService *core.IndexService,
+	objectService *core.ObjectService,
 	updateIndexService *UpdateIndexService,
 	pathResolver *core.PathResolver,
+	changeDetector *core.ChangeDetector,
 	workspace *domain.Workspace,
 ) *AddService {
 	return &AddService{
 		indexService:       indexService,
+		objectService:      objectService,
 		updateIndexService: updateIndexService,
 		pathResolver:       pathResolver,
+		changeDetector:     changeDetector,
 		workspace:          workspace,
 	}
 }
@@ -53,7 +67,9 @@ func (a *AddService) Add(pathspecs []string, options AddOptions) AddResult {
 		return AddResult{Error: fmt.Errorf("add: %w", err)}
 	}
 
-	resolvedPaths, err := a.pathResolver.Resolve(pathspecs)
+	resolvedPaths, err := a.pathResolver.Resolve(
+		pathspecs, core.ResolveOptions{IncludeIgnored: options.Force},
+	)
 	if err != nil {
 		return AddResult{Error: fmt.Errorf("add: %w", err)}
 	}
@@ -63,6 +79,9 @@ func (a *AddService) Add(pathspecs []string, options AddOptions) AddResult {
 		return AddResult{Error: fmt.Errorf("add: %w", err)}
 	}
 
+	if options.IntentToAdd {
+		return a.addIntentToAdd(index, pathsToAdd, options)
+	}
 	if options.DryRun {
 		return AddResult{Added: pathsToAdd, Removed: pathsToRemove}
 	}
@@ -94,8 +113,175 @@ func (a *AddService) Add(pathspecs []string, options AddOptions) AddResult {
 	return AddResult{}
 }
 
+// addIntentToAdd records the untracked paths among paths as intent-to-add
+// entries: placeholders with the empty blob that keep the files visible to
+// status and diff until their content is staged.
+func (a *AddService) addIntentToAdd(index *domain.Index, paths []domain.NormalizedPath, options AddOptions) AddResult {
+	emptyBlobHash, err := a.objectService.WriteBlob(nil)
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+
+	var added []domain.NormalizedPath
+	for _, path := range paths {
+		if index.HasEntry(path) {
+			continue
+		}
+		absolutePath, err := path.ToAbsolutePath(a.workspace.RepoDir)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		if core.IsRepositoryRoot(absolutePath.String()) {
+			return AddResult{Error: fmt.Errorf("add: '%s': %w", path, ErrIntentToAddSubmodule)}
+		}
+		stat, err := domain.NewFileStatFromPath(absolutePath)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		fileMode, err := domain.NewFileModeFromOSMode(stat.Mode)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		entry := domain.NewEmptyIndexEntry(path, emptyBlobHash, fileMode.Uint32())
+		entry.Flags = domain.ComputeIndexFlags(path.String(), 0)
+		entry.SetIntentToAdd(true)
+		index.SetEntry(entry)
+		added = append(added, path)
+	}
+
+	if options.DryRun {
+		return AddResult{Added: added}
+	}
+	if err := a.indexService.Write(index); err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+	if options.Verbose {
+		return AddResult{Added: added}
+	}
+	return AddResult{}
+}
+
+// AddTracked stages the working tree content of every tracked file that was
+// modified and removes the entries of tracked files that were deleted, as
+// commit -a does before committing. Untracked files are left alone, while
+// intent-to-add entries get their content staged.
+func (a *AddService) AddTracked() AddResult {
+	index, err := a.indexService.Read()
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+
+	var pathsToAdd, pathsToRemove []domain.NormalizedPath
+	for _, entry := range index.Entries {
+		if entry.GetStage() != 0 || entry.SkipWorktree() {
+			continue
+		}
+		changeResult, err := a.changeDetector.DetectFileChange(entry)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		switch changeResult.FileState {
+		case core.FileStateModified:
+			pathsToAdd = append(pathsToAdd, entry.Path)
+		case core.FileStateDeleted:
+			pathsToRemove = append(pathsToRemove, entry.Path)
+		}
+	}
+
+	added, err := a.updateIndexService.UpdateIndex(pathsToAdd, UpdateIndexOptions{Add: true, Write: true})
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+	removed, err := a.updateIndexService.UpdateIndex(pathsToRemove, UpdateIndexOptions{Remove: true, Write: true})
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+	return AddResult{Added: added, Removed: removed}
+}
+
+// AddPatch interactively stages hunks of tracked files modified in the working tree.
+//
+// Each file's index blob is diffed against the working tree file and selector
+// decides which hunks to stage. A partially staged file gets a new blob built
+// from the selected hunks; the working tree file is left untouched. With no
+// pathspecs every modified tracked file is offered.
+func (a *AddService) AddPatch(pathspecs []string, selector *diff.PatchSelector) AddResult {
+	scopes, err := core.NormalizePathspecs(pathspecs, a.workspace.RepoDir)
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+	index, err := a.indexService.Read()
+	if err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+
+	var staged, fullyStaged []domain.NormalizedPath
+	for _, entry := range index.Entries {
+		if selector.Done() {
+			break
+		}
+		if !core.MatchesPathspecs(entry.Path, scopes) || domain.FileMode(entry.Mode).IsGitlink() {
+			continue
+		}
+		changeResult, err := a.changeDetector.DetectFileChange(entry)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		if changeResult.FileState != core.FileStateModified || changeResult.NewHash == entry.Hash {
+			continue
+		}
+
+		blob, err := a.objectService.ReadBlob(entry.Hash)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		absolutePath, err := entry.Path.ToAbsolutePath(a.workspace.RepoDir)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		content, err := a.objectService.ReadWorkingContent(absolutePath)
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+
+		result, selected, err := selector.Select(entry.Path.String(), string(blob.Body()), string(content))
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		if !selected {
+			continue
+		}
+		staged = append(staged, entry.Path)
+		if result == string(content) {
+			fullyStaged = append(fullyStaged, entry.Path)
+			continue
+		}
+		hash, err := a.objectService.WriteBlob([]byte(result))
+		if err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+		index.SetEntry(domain.NewEmptyIndexEntry(entry.Path, hash, entry.Mode))
+	}
+
+	if err := a.indexService.Write(index); err != nil {
+		return AddResult{Error: fmt.Errorf("add: %w", err)}
+	}
+	if len(fullyStaged) > 0 {
+		if _, err := a.updateIndexService.UpdateIndex(
+			fullyStaged, UpdateIndexOptions{Add: true, Write: true},
+		); err != nil {
+			return AddResult{Error: fmt.Errorf("add: %w", err)}
+		}
+	}
+	return AddResult{Added: staged}
+}
+
 // collectPaths computes deterministic add/remove path lists for update-index.
 // Paths are deduplicated and sorted to avoid map-iteration order instability.
+//
+// Ignore rules only apply to untracked paths: tracked files the resolver
+// skipped because they are ignored are restaged while they exist on disk.
+// Explicitly named untracked paths that are ignored fail with ErrPathIgnored.
 func (a *AddService) collectPaths(
 	index *domain.Index,
 	resolvedPaths []core.ResolvedPath,
@@ -104,6 +290,7 @@ func (a *AddService) collectPaths(
 ) {
 	pathsToAddSet := make(map[domain.NormalizedPath]struct{})
 	pathsToRemoveSet := make(map[domain.NormalizedPath]struct{})
+	var ignoredPaths []string
 
 	for _, resolved := range resolvedPaths {
 		for path := range resolved.NormalizedPaths {
@@ -137,17 +324,46 @@ func (a *AddService) collectPaths(
 		}
 
 		for _, entry := range indexEntries {
-			if !resolved.NormalizedPaths[entry.Path] {
+			if resolved.NormalizedPaths[entry.Path] {
+				continue
+			}
+			exists, err := a.existsInWorkingTree(entry.Path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("add: %w", err)
+			}
+			if exists {
+				pathsToAddSet[entry.Path] = struct{}{}
+			} else {
 				pathsToRemoveSet[entry.Path] = struct{}{}
 			}
 		}
-		if len(resolved.NormalizedPaths) == 0 && len(indexEntries) == 0 {
+		for path := range resolved.IgnoredPaths {
+			if !index.HasEntry(path) && len(index.FindEntriesByPathPrefix(path.String()+"/")) == 0 {
+				ignoredPaths = append(ignoredPaths, path.String())
+			}
+		}
+		if len(resolved.NormalizedPaths) == 0 && len(indexEntries) == 0 && len(resolved.IgnoredPaths) == 0 {
 			return nil, nil, fmt.Errorf("'%s': %w", resolved.NormalizedScope, ErrPathDidNotMatch)
 		}
 
 	}
+	if len(ignoredPaths) > 0 {
+		slices.Sort(ignoredPaths)
+		return nil, nil, fmt.Errorf(
+			"%w (use -f to add them): %s", ErrPathIgnored, strings.Join(ignoredPaths, ", "),
+		)
+	}
 	for path := range pathsToAddSet {
 		delete(pathsToRemoveSet, path)
 	}
 	return domain.SortedPathSet(pathsToAddSet), domain.SortedPathSet(pathsToRemoveSet), nil
 }
+
+// existsInWorkingTree reports whether the tracked path is still present on disk.
+func (a *AddService) existsInWorkingTree(path domain.NormalizedPath) (bool, error) {
+	absolutePath, err := path.ToAbsolutePath(a.workspace.RepoDir)
+	if err != nil {
+		return false, err
+	}
+	return core.Exists(absolutePath.String())
+}
@@ -0,0 +1,129 @@
+package staging
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/diff"
+	"Gel/internal/testutil"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	addPatchOld = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	addPatchNew = "one\nTWO\nthree\nfour\nfive\nSIX\nseven\neight\n"
+)
+
+// addPatchRepository is a repository with the services add -p needs.
+type addPatchRepository struct {
+	*testutil.Repository
+	addService *AddService
+}
+
+// newAddPatchRepository initializes a repository in a temporary directory and
+// makes it the working directory of the test.
+func newAddPatchRepository(t *testing.T) *addPatchRepository {
+	t.Helper()
+	repository := testutil.NewRepository(t)
+	updateIndexService := NewUpdateIndexService(
+		repository.IndexService, repository.ObjectService, core.NewHashObjectService(repository.ObjectService),
+		repository.ChangeDetector, repository.Workspace,
+	)
+	return &addPatchRepository{
+		Repository: repository,
+		addService: NewAddService(
+			repository.IndexService, repository.ObjectService, updateIndexService, repository.PathResolver,
+			repository.ChangeDetector, repository.Workspace,
+		),
+	}
+}
+
+func TestAddPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		answers     string
+		wantStaged  string
+		wantSecond  string
+		secondFiles bool
+	}{
+		{name: "stage hunk", answers: "y\n", wantStaged: addPatchNew},
+		{name: "skip hunk", answers: "n\n", wantStaged: addPatchOld},
+		{name: "quit", answers: "q\n", wantStaged: addPatchOld},
+		{name: "end of input", answers: "", wantStaged: addPatchOld},
+		{
+			name:       "split and stage first",
+			answers:    "s\ny\nn\n",
+			wantStaged: "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\n",
+		},
+		{
+			name:       "split and stage second",
+			answers:    "s\nn\ny\n",
+			wantStaged: "one\ntwo\nthree\nfour\nfive\nSIX\nseven\neight\n",
+		},
+		{
+			name:        "quit skips later files",
+			answers:     "y\nq\n",
+			wantStaged:  addPatchNew,
+			wantSecond:  addPatchOld,
+			secondFiles: true,
+		},
+		{
+			name:        "answers cover both files",
+			answers:     "n\ny\n",
+			wantStaged:  addPatchOld,
+			wantSecond:  addPatchNew,
+			secondFiles: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repository := newAddPatchRepository(t)
+				repository.WriteFile(t, "a.txt", addPatchOld)
+				if tt.secondFiles {
+					repository.WriteFile(t, "b.txt", addPatchOld)
+				}
+				require.NoError(t, repository.addService.Add([]string{"."}, AddOptions{}).Error)
+				repository.WriteFile(t, "a.txt", addPatchNew)
+				if tt.secondFiles {
+					repository.WriteFile(t, "b.txt", addPatchNew)
+				}
+
+				selector := diff.NewPatchSelector(
+					strings.NewReader(tt.answers), io.Discard, "Stage", diff.PatchDirectionForward, nil,
+				)
+				result := repository.addService.AddPatch(nil, selector)
+
+				require.NoError(t, result.Error)
+				assert.Equal(t, tt.wantStaged, repository.StagedContent(t, "a.txt"))
+				assert.Equal(t, addPatchNew, repository.ReadFile(t, "a.txt"))
+				if tt.secondFiles {
+					assert.Equal(t, tt.wantSecond, repository.StagedContent(t, "b.txt"))
+					assert.Equal(t, addPatchNew, repository.ReadFile(t, "b.txt"))
+				}
+			},
+		)
+	}
+}
+
+func TestAddPatchLimitsToPathspecs(t *testing.T) {
+	repository := newAddPatchRepository(t)
+	repository.WriteFile(t, "a.txt", addPatchOld)
+	repository.WriteFile(t, "b.txt", addPatchOld)
+	require.NoError(t, repository.addService.Add([]string{"."}, AddOptions{}).Error)
+	repository.WriteFile(t, "a.txt", addPatchNew)
+	repository.WriteFile(t, "b.txt", addPatchNew)
+
+	selector := diff.NewPatchSelector(
+		strings.NewReader("y\ny\n"), io.Discard, "Stage", diff.PatchDirectionForward, nil,
+	)
+	result := repository.addService.AddPatch([]string{"b.txt"}, selector)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, addPatchOld, repository.StagedContent(t, "a.txt"))
+	assert.Equal(t, addPatchNew, repository.StagedContent(t, "b.txt"))
+}
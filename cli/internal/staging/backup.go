@@ -0,0 +1,93 @@
+package staging
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileBackup is an in-memory snapshot of one working tree file.
+type fileBackup struct {
+	mode os.FileMode
+	body []byte
+}
+
+// captureFileBackups snapshots working tree files before they are deleted or
+// overwritten so rollback can restore them. Missing paths are skipped.
+func captureFileBackups(repoDir domain.AbsolutePath, paths []domain.NormalizedPath) (
+	map[domain.NormalizedPath]fileBackup, error,
+) {
+	backups := make(map[domain.NormalizedPath]fileBackup)
+	for _, path := range paths {
+		absPath, err := path.ToAbsolutePath(repoDir)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Lstat(absPath.String())
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to stat '%s': %w", absPath, err)
+		}
+
+		body, err := core.ReadWorkingFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", absPath, err)
+		}
+		backups[path] = fileBackup{
+			mode: info.Mode() & (os.ModePerm | os.ModeSymlink),
+			body: body,
+		}
+	}
+	return backups, nil
+}
+
+// restoreBackups returns the original error unless rollback itself also fails.
+func restoreBackups(
+	repoDir domain.AbsolutePath,
+	operationErr error,
+	backups map[domain.NormalizedPath]fileBackup,
+) error {
+	if err := restoreFileBackups(repoDir, backups); err != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", operationErr, err)
+	}
+	return operationErr
+}
+
+// restoreFileBackups recreates files from their in-memory snapshots,
+// replacing whatever is at their paths now.
+func restoreFileBackups(repoDir domain.AbsolutePath, backups map[domain.NormalizedPath]fileBackup) error {
+	paths := make(map[domain.NormalizedPath]struct{}, len(backups))
+	for path := range backups {
+		paths[path] = struct{}{}
+	}
+
+	for _, path := range domain.SortedPathSet(paths) {
+		backup := backups[path]
+		absPath, err := path.ToAbsolutePath(repoDir)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(absPath.String()), domain.DefaultDirPermission); err != nil {
+			return fmt.Errorf("failed to create directory for '%s': %w", absPath, err)
+		}
+		if err := os.Remove(absPath.String()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to restore '%s': %w", absPath, err)
+		}
+		if backup.mode&os.ModeSymlink != 0 {
+			if err := os.Symlink(string(backup.body), absPath.String()); err != nil {
+				return fmt.Errorf("failed to restore '%s': %w", absPath, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(absPath.String(), backup.body, backup.mode); err != nil {
+			return fmt.Errorf("failed to restore '%s': %w", absPath, err)
+		}
+	}
+	return nil
+}
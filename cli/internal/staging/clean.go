@@ -0,0 +1,400 @@
+package staging
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cleanExcludeSource names -e patterns in ignore rule sources.
+const cleanExcludeSource = "<command line>"
+
+// cleanMetadataDirNames are never cleaned, and directories holding one are
+// nested repositories that clean leaves alone.
+var cleanMetadataDirNames = map[string]bool{
+	domain.GelDirName: true,
+	".git":            true,
+}
+
+// CleanOptions controls which untracked paths clean selects.
+type CleanOptions struct {
+	// Directories also selects untracked directories, removing them whole.
+	Directories bool
+	// IncludeIgnored selects ignored paths along with other untracked paths.
+	IncludeIgnored bool
+	// OnlyIgnored selects ignored paths only.
+	OnlyIgnored bool
+	// ExcludePatterns are extra ignore patterns, as in .gelignore.
+	ExcludePatterns []string
+}
+
+// CleanCandidate is one untracked path clean would remove.
+type CleanCandidate struct {
+	Path domain.NormalizedPath
+	// IsDir is set for a directory removed with everything below it.
+	IsDir bool
+}
+
+// String returns the path, with a trailing "/" for directories.
+func (c CleanCandidate) String() string {
+	if c.IsDir {
+		return c.Path.String() + "/"
+	}
+	return c.Path.String()
+}
+
+// CleanPlan lists what clean would do.
+type CleanPlan struct {
+	// Candidates are the paths to remove, sorted.
+	Candidates []CleanCandidate
+	// SkippedRepositories are untracked directories left alone because they
+	// hold a repository of their own.
+	SkippedRepositories []domain.NormalizedPath
+}
+
+// CleanService removes untracked files and directories from the working tree.
+type CleanService struct {
+	indexService  *core.IndexService
+	configService *core.ConfigService
+	ignoreMatcher *core.IgnoreMatcher
+	workspace     *domain.Workspace
+}
+
+// NewCleanService creates a clean service with its dependencies.
+func NewCleanService(
+	indexService *core.IndexService,
+	configService *core.ConfigService,
+	ignoreMatcher *core.IgnoreMatcher,
+	workspace *domain.Workspace,
+) *CleanService {
+	return &CleanService{
+		indexService:  indexService,
+		configService: configService,
+		ignoreMatcher: ignoreMatcher,
+		workspace:     workspace,
+	}
+}
+
+// RequireForce reports whether clean.requireForce, true by default, forbids
+// removing files without -f.
+func (c *CleanService) RequireForce() (bool, error) {
+	value, ok, err := c.configService.GetOptional(core.ConfigSectionClean, core.ConfigKeyRequireForce)
+	if err != nil {
+		return false, fmt.Errorf("clean: %w", err)
+	}
+	if !ok {
+		return true, nil
+	}
+	requireForce, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf(
+			"clean: invalid %s.%s %q", core.ConfigSectionClean, core.ConfigKeyRequireForce, value,
+		)
+	}
+	return requireForce, nil
+}
+
+// Plan selects the untracked paths at or below pathspecs, resolved against
+// the current directory, that clean would remove. Tracked paths and
+// repository metadata are never selected. An untracked directory is selected
+// whole when options.Directories is set and everything below it is selected;
+// otherwise untracked directories are left alone.
+func (c *CleanService) Plan(pathspecs []string, options CleanOptions) (*CleanPlan, error) {
+	index, err := c.indexService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("clean: %w", err)
+	}
+
+	excludes := []byte(strings.Join(options.ExcludePatterns, "\n"))
+	walk := &cleanWalk{
+		service:  c,
+		index:    index,
+		options:  options,
+		excludes: core.ParseIgnoreRules(excludes, cleanExcludeSource, ""),
+	}
+	if len(pathspecs) == 0 {
+		pathspecs = []string{"."}
+	}
+	for _, pathspec := range pathspecs {
+		normalizedPath, err := domain.NewNormalizedPath(pathspec, c.workspace.RepoDir)
+		if err != nil {
+			return nil, fmt.Errorf("clean: %w", err)
+		}
+		if err := walk.root(normalizedPath); err != nil {
+			return nil, fmt.Errorf("clean: %w", err)
+		}
+	}
+
+	sort.Slice(
+		walk.plan.Candidates, func(i, j int) bool {
+			return walk.plan.Candidates[i].Path.String() < walk.plan.Candidates[j].Path.String()
+		},
+	)
+	walk.plan.Candidates = dedupeCandidates(walk.plan.Candidates)
+	sort.Slice(
+		walk.plan.SkippedRepositories, func(i, j int) bool {
+			return walk.plan.SkippedRepositories[i].String() < walk.plan.SkippedRepositories[j].String()
+		},
+	)
+	return &walk.plan, nil
+}
+
+// Remove deletes the candidates from the working tree. Each is checked
+// against the current index first, so a path that became tracked since
+// Plan is refused rather than deleted.
+func (c *CleanService) Remove(candidates []CleanCandidate) error {
+	index, err := c.indexService.Read()
+	if err != nil {
+		return fmt.Errorf("clean: %w", err)
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Path.IsRoot() || isTrackedPath(index, candidate.Path) {
+			return fmt.Errorf("clean: %w: '%s'", ErrCleanTracked, candidate)
+		}
+		absPath, err := candidate.Path.ToAbsolutePath(c.workspace.RepoDir)
+		if err != nil {
+			return fmt.Errorf("clean: %w", err)
+		}
+		if candidate.IsDir {
+			err = os.RemoveAll(absPath.String())
+		} else {
+			err = os.Remove(absPath.String())
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("clean: failed to remove '%s': %w", candidate, err)
+		}
+	}
+	return nil
+}
+
+// cleanWalk collects clean candidates below the requested pathspecs.
+type cleanWalk struct {
+	service  *CleanService
+	index    *domain.Index
+	options  CleanOptions
+	excludes []core.IgnoreRule
+	plan     CleanPlan
+}
+
+// root plans one pathspec. Ignore rules matching one of its ancestor
+// directories apply to everything below it.
+func (w *cleanWalk) root(path domain.NormalizedPath) error {
+	if path.IsRoot() {
+		candidates, _, err := w.walkDir(path, false)
+		w.plan.Candidates = append(w.plan.Candidates, candidates...)
+		return err
+	}
+	if w.hasMetadataSegment(path) {
+		return nil
+	}
+	absPath, err := path.ToAbsolutePath(w.service.workspace.RepoDir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(absPath.String())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	parentIgnored := false
+	for dir := path.String(); strings.Contains(dir, "/") && !parentIgnored; {
+		dir = dir[:strings.LastIndexByte(dir, '/')]
+		parentIgnored, err = w.ignored(dir, true, false)
+		if err != nil {
+			return err
+		}
+	}
+	candidates, _, err := w.visit(path, info.IsDir(), parentIgnored)
+	if err != nil {
+		return err
+	}
+	w.plan.Candidates = append(w.plan.Candidates, candidates...)
+	return nil
+}
+
+// walkDir plans the contents of dir. It returns the candidates found and
+// whether every entry of dir is one, in which case dir itself can go.
+func (w *cleanWalk) walkDir(dir domain.NormalizedPath, dirIgnored bool) ([]CleanCandidate, bool, error) {
+	absDir, err := dir.ToAbsolutePath(w.service.workspace.RepoDir)
+	if err != nil {
+		return nil, false, err
+	}
+	dirEntries, err := os.ReadDir(absDir.String())
+	if err != nil {
+		return nil, false, err
+	}
+
+	var candidates []CleanCandidate
+	complete := true
+	for _, dirEntry := range dirEntries {
+		if cleanMetadataDirNames[dirEntry.Name()] {
+			complete = false
+			continue
+		}
+		child, err := domain.ParseNormalizedPath(strings.TrimPrefix(dir.String()+"/"+dirEntry.Name(), "/"))
+		if err != nil {
+			return nil, false, err
+		}
+		childCandidates, removable, err := w.visit(child, dirEntry.IsDir(), dirIgnored)
+		if err != nil {
+			return nil, false, err
+		}
+		candidates = append(candidates, childCandidates...)
+		complete = complete && removable
+	}
+	return candidates, complete, nil
+}
+
+// visit plans one path and reports whether the path is removed as a whole.
+func (w *cleanWalk) visit(path domain.NormalizedPath, isDir, parentIgnored bool) ([]CleanCandidate, bool, error) {
+	if !isDir {
+		if w.index.HasEntry(path) {
+			return nil, false, nil
+		}
+		selected, err := w.selected(path.String(), false, parentIgnored)
+		if err != nil || !selected {
+			return nil, false, err
+		}
+		return []CleanCandidate{{Path: path}}, true, nil
+	}
+
+	ignored, err := w.ignored(path.String(), true, parentIgnored)
+	if err != nil {
+		return nil, false, err
+	}
+	if isTrackedPath(w.index, path) {
+		candidates, _, err := w.walkDir(path, ignored)
+		return candidates, false, err
+	}
+
+	if !w.options.Directories {
+		return nil, false, nil
+	}
+	nested, err := w.isRepository(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if nested {
+		w.plan.SkippedRepositories = append(w.plan.SkippedRepositories, path)
+		return nil, false, nil
+	}
+	if ignored {
+		// An ignored directory goes whole or not at all.
+		selected, err := w.selected(path.String(), true, parentIgnored)
+		if err != nil || !selected {
+			return nil, false, err
+		}
+		return []CleanCandidate{{Path: path, IsDir: true}}, true, nil
+	}
+
+	candidates, complete, err := w.walkDir(path, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if complete && len(candidates) == 0 && w.options.OnlyIgnored {
+		// An empty directory is not ignored content.
+		return nil, false, nil
+	}
+	if complete {
+		return []CleanCandidate{{Path: path, IsDir: true}}, true, nil
+	}
+	return candidates, false, nil
+}
+
+// selected reports whether an untracked path is removed under the ignore mode.
+func (w *cleanWalk) selected(relPath string, isDir, parentIgnored bool) (bool, error) {
+	excluded := parentIgnored || matchesExcludes(w.excludes, relPath, isDir)
+	switch {
+	case w.options.OnlyIgnored:
+		if excluded {
+			return true, nil
+		}
+		return w.service.ignoreMatcher.IsIgnored(relPath, isDir)
+	case w.options.IncludeIgnored:
+		return !matchesExcludes(w.excludes, relPath, isDir), nil
+	default:
+		ignored, err := w.ignored(relPath, isDir, parentIgnored)
+		return !ignored, err
+	}
+}
+
+// ignored reports whether a path is excluded by the ignore files or -e patterns.
+func (w *cleanWalk) ignored(relPath string, isDir, parentIgnored bool) (bool, error) {
+	if parentIgnored || matchesExcludes(w.excludes, relPath, isDir) {
+		return true, nil
+	}
+	return w.service.ignoreMatcher.IsIgnored(relPath, isDir)
+}
+
+// isRepository reports whether an untracked directory holds a repository of its own.
+func (w *cleanWalk) isRepository(path domain.NormalizedPath) (bool, error) {
+	absPath, err := path.ToAbsolutePath(w.service.workspace.RepoDir)
+	if err != nil {
+		return false, err
+	}
+	for name := range cleanMetadataDirNames {
+		if _, err := os.Stat(absPath.String() + string(os.PathSeparator) + name); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasMetadataSegment reports whether path lies inside repository metadata.
+func (w *cleanWalk) hasMetadataSegment(path domain.NormalizedPath) bool {
+	for _, segment := range strings.Split(path.String(), "/") {
+		if cleanMetadataDirNames[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeCandidates drops sorted candidates repeated or covered by a
+// directory candidate, since pathspecs may overlap.
+func dedupeCandidates(candidates []CleanCandidate) []CleanCandidate {
+	kept := make(map[string]bool, len(candidates))
+	result := candidates[:0]
+	for _, candidate := range candidates {
+		covered := false
+		for dir := candidate.Path.String(); dir != "" && !covered; dir = parentPath(dir) {
+			covered = kept[dir]
+		}
+		if covered {
+			continue
+		}
+		kept[candidate.Path.String()] = true
+		result = append(result, candidate)
+	}
+	return result
+}
+
+// matchesExcludes applies -e patterns: the last matching rule decides.
+func matchesExcludes(rules []core.IgnoreRule, relPath string, isDir bool) bool {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Matches(relPath, isDir) {
+			return !rules[i].Negate
+		}
+	}
+	return false
+}
+
+// isTrackedPath reports whether path is tracked or a directory holding tracked paths.
+func isTrackedPath(index *domain.Index, path domain.NormalizedPath) bool {
+	return index.HasEntry(path) || len(findDescendantEntries(index, path)) > 0
+}
+
+// parentPath returns the directory containing the slash-separated path, "" at the root.
+func parentPath(relPath string) string {
+	return strings.TrimSuffix(path.Dir(relPath), ".")
+}
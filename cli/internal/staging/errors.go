@@ -10,6 +10,51 @@ var (
 	// ErrPathDidNotMatch is returned when a pathspec matches no files in the index or working tree.
 	ErrPathDidNotMatch = errors.New("pathspec did not match any files")
 
+	// ErrPathIgnored is returned when add names untracked paths matched by ignore rules.
+	ErrPathIgnored = errors.New("paths are ignored by one of your ignore files")
+
+	// ErrNeedsUpdate is returned when a refresh finds index entries whose files no longer match.
+	ErrNeedsUpdate = errors.New("index entries need update")
+
+	// ErrSubmoduleNoCommit is returned when a nested repository to stage has no commit checked out.
+	ErrSubmoduleNoCommit = errors.New("submodule has no commit checked out")
+
+	// ErrIntentToAddSubmodule is returned when add -N names a nested repository, which has no file content to add.
+	ErrIntentToAddSubmodule = errors.New("cannot record a submodule as intent to add")
+
+	// ErrNotInIndex is returned when update-index marks a path that has no index entry.
+	ErrNotInIndex = errors.New("unable to mark file: not in the index")
+
+	// ErrMoveBadSource is returned when a mv source does not exist in the working tree.
+	ErrMoveBadSource = errors.New("bad source")
+
+	// ErrMoveNotTracked is returned when a mv source is not under version control.
+	ErrMoveNotTracked = errors.New("not under version control")
+
+	// ErrMoveConflicted is returned when a mv source has unmerged index entries.
+	ErrMoveConflicted = errors.New("conflicted")
+
+	// ErrMoveIntoItself is returned when a mv destination is its source or lies below it.
+	ErrMoveIntoItself = errors.New("can not move directory into itself")
+
+	// ErrMoveDestinationExists is returned when a mv destination exists and -f was not given.
+	ErrMoveDestinationExists = errors.New("destination exists")
+
+	// ErrMoveDestinationNotDirectory is returned when several mv sources target a non-directory.
+	ErrMoveDestinationNotDirectory = errors.New("destination is not a directory")
+
+	// ErrMoveDestinationDirectoryMissing is returned when the directory that would hold a mv destination does not exist.
+	ErrMoveDestinationDirectoryMissing = errors.New("destination directory does not exist")
+
+	// ErrMoveMultipleSources is returned when two mv sources map to the same destination.
+	ErrMoveMultipleSources = errors.New("multiple sources for the same target")
+
+	// ErrCleanRequireForce is returned when clean would delete files without -f while clean.requireForce is set.
+	ErrCleanRequireForce = errors.New("clean.requireForce defaults to true and neither -i, -n, nor -f given; refusing to clean")
+
+	// ErrCleanTracked is returned when a path selected for clean has become tracked.
+	ErrCleanTracked = errors.New("refusing to remove tracked path")
+
 	// errRemovePathDidNotMatch identifies rm failures where no tracked path matches the pathspec.
 	errRemovePathDidNotMatch = errors.New("remove: pathspec did not match any tracked files")
 
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -23,12 +25,21 @@ type LsFilesOptions struct {
 	Modified bool
 	// Deleted lists tracked paths missing from the working tree.
 	Deleted bool
+	// Others lists untracked working tree paths not matched by ignore rules.
+	Others bool
+	// Ignored, together with Others, lists only untracked paths matched by ignore rules.
+	Ignored bool
+	// Tags prefixes each line with a status tag: H for a cached entry, S for a
+	// skip-worktree entry, C for a modified path, R for a deleted path and ?
+	// for an untracked path. Tags of assume-unchanged entries are lowercase.
+	Tags bool
 }
 
 // LsFilesService implements ls-files queries over index and working tree state.
 type LsFilesService struct {
 	indexService   *core.IndexService
 	changeDetector *core.ChangeDetector
+	pathResolver   *core.PathResolver
 	workspace      *domain.Workspace
 }
 
@@ -36,11 +47,13 @@ type LsFilesService struct {
 func NewLsFilesService(
 	indexService *core.IndexService,
 	changeDetector *core.ChangeDetector,
+	pathResolver *core.PathResolver,
 	workspace *domain.Workspace,
 ) *LsFilesService {
 	return &LsFilesService{
 		indexService:   indexService,
 		changeDetector: changeDetector,
+		pathResolver:   pathResolver,
 		workspace:      workspace,
 	}
 }
@@ -50,14 +63,27 @@ func NewLsFilesService(
 // Pathspec supports prefix matching by default and glob matching when wildcard
 // characters are present.
 func (l *LsFilesService) LsFiles(pathspec string, options LsFilesOptions) ([]string, error) {
-	if !options.Stage && !options.Cached && !options.Modified && !options.Deleted {
-		return nil, fmt.Errorf("ls-files: must specify --stage, --cached, --modified, or --deleted")
+	if !options.Stage && !options.Cached && !options.Modified && !options.Deleted && !options.Others {
+		return nil, fmt.Errorf("ls-files: must specify --stage, --cached, --modified, --deleted, or --others")
+	}
+	if options.Ignored && !options.Others {
+		return nil, fmt.Errorf("ls-files: --ignored requires --others")
 	}
 
 	index, err := l.indexService.Read()
 	if err != nil {
 		return nil, fmt.Errorf("ls-files: %w", err)
 	}
+	if options.Others {
+		files, err := l.lsFilesWithOthers(index, pathspec, options.Ignored)
+		if err != nil || !options.Tags {
+			return files, err
+		}
+		for i, file := range files {
+			files[i] = lsFilesTagUntracked + " " + file
+		}
+		return files, nil
+	}
 
 	var entries []*domain.IndexEntry
 	if pathspec != "" {
@@ -72,19 +98,91 @@ func (l *LsFilesService) LsFiles(pathspec string, options LsFilesOptions) ([]str
 
 	switch {
 	case options.Stage:
-		return l.lsFilesWithStage(entries), nil
+		return l.lsFilesWithStage(entries, options.Tags), nil
 	case options.Cached:
-		return l.lsFilesWithCached(entries), nil
+		return l.lsFilesWithCached(entries, options.Tags), nil
 	case options.Modified:
-		return l.lsFilesWithModified(entries)
+		return l.lsFilesWithModified(entries, options.Tags)
 	case options.Deleted:
-		return l.lsFilesWithDeleted(entries)
+		return l.lsFilesWithDeleted(entries, options.Tags)
 	}
 	return nil, nil
 }
 
+// ls-files -v status tags.
+const (
+	lsFilesTagCached       = "H"
+	lsFilesTagSkipWorktree = "S"
+	lsFilesTagModified     = "C"
+	lsFilesTagDeleted      = "R"
+	lsFilesTagUntracked    = "?"
+)
+
+// tagLsFilesLine prefixes line with the status tag of entry: tag, or S in
+// place of H for a skip-worktree entry, lowercased when the entry is
+// assume-unchanged. Without tags, line is returned unchanged.
+func tagLsFilesLine(line string, entry *domain.IndexEntry, tag string, tags bool) string {
+	if !tags {
+		return line
+	}
+	if tag == lsFilesTagCached && entry.SkipWorktree() {
+		tag = lsFilesTagSkipWorktree
+	}
+	if entry.AssumeUnchanged() {
+		tag = strings.ToLower(tag)
+	}
+	return tag + " " + line
+}
+
+// lsFilesWithOthers returns untracked working tree paths matching pathspec.
+// With ignored, only paths excluded by ignore rules are returned.
+func (l *LsFilesService) lsFilesWithOthers(index *domain.Index, pathspec string, ignored bool) ([]string, error) {
+	if err := l.workspace.RequireWorkTree(); err != nil {
+		return nil, fmt.Errorf("ls-files: %w", err)
+	}
+	resolvedPaths, err := l.pathResolver.Resolve(
+		[]string{l.workspace.RepoDir.String()}, core.ResolveOptions{IncludeIgnored: ignored},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ls-files: %w", err)
+	}
+
+	files := make([]string, 0)
+	for _, resolved := range resolvedPaths {
+		for path := range resolved.NormalizedPaths {
+			if index.HasEntry(path) || !matchesLsFilesPathspec(path.String(), pathspec) {
+				continue
+			}
+			if ignored {
+				isIgnored, err := l.pathResolver.IsIgnored(path, false)
+				if err != nil {
+					return nil, fmt.Errorf("ls-files: %w", err)
+				}
+				if !isIgnored {
+					continue
+				}
+			}
+			files = append(files, path.String())
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// matchesLsFilesPathspec applies the index pathspec rules (prefix or glob) to a path.
+func matchesLsFilesPathspec(path, pathspec string) bool {
+	if pathspec == "" {
+		return true
+	}
+	if strings.ContainsAny(pathspec, globPatterns) {
+		matched, _ := filepath.Match(pathspec, path)
+		return matched
+	}
+	return strings.HasPrefix(path, pathspec)
+}
+
 // lsFilesWithStage formats entries as mode/hash/stage/path.
-func (l *LsFilesService) lsFilesWithStage(entries []*domain.IndexEntry) []string {
+func (l *LsFilesService) lsFilesWithStage(entries []*domain.IndexEntry, tags bool) []string {
 	files := make([]string, len(entries))
 	for i, entry := range entries {
 		fileMode, err := domain.NewFileMode(entry.Mode)
@@ -92,28 +190,30 @@ func (l *LsFilesService) lsFilesWithStage(entries []*domain.IndexEntry) []string
 			// TODO: Skip entries with invalid mode, as they cannot be meaningfully represented in stage format.
 			continue
 		}
-		files[i] = fmt.Sprintf(
-			"%s %s %d\t%s",
-			fileMode,
-			entry.Hash,
-			entry.GetStage(),
-			entry.Path,
+		files[i] = tagLsFilesLine(
+			fmt.Sprintf(
+				"%s %s %d\t%s",
+				fileMode,
+				entry.Hash,
+				entry.GetStage(),
+				entry.Path,
+			), entry, lsFilesTagCached, tags,
 		)
 	}
 	return files
 }
 
 // lsFilesWithCached returns entry paths exactly as stored in index.
-func (l *LsFilesService) lsFilesWithCached(entries []*domain.IndexEntry) []string {
+func (l *LsFilesService) lsFilesWithCached(entries []*domain.IndexEntry, tags bool) []string {
 	files := make([]string, len(entries))
 	for i, entry := range entries {
-		files[i] = entry.Path.String()
+		files[i] = tagLsFilesLine(entry.Path.String(), entry, lsFilesTagCached, tags)
 	}
 	return files
 }
 
 // lsFilesWithModified returns tracked paths classified as modified.
-func (l *LsFilesService) lsFilesWithModified(entries []*domain.IndexEntry) ([]string, error) {
+func (l *LsFilesService) lsFilesWithModified(entries []*domain.IndexEntry, tags bool) ([]string, error) {
 	files := make([]string, 0)
 	for _, entry := range entries {
 		changeResult, err := l.changeDetector.DetectFileChange(entry)
@@ -121,24 +221,28 @@ func (l *LsFilesService) lsFilesWithModified(entries []*domain.IndexEntry) ([]st
 			return nil, err
 		}
 		if changeResult.FileState == core.FileStateModified {
-			files = append(files, entry.Path.String())
+			files = append(files, tagLsFilesLine(entry.Path.String(), entry, lsFilesTagModified, tags))
 		}
 	}
 	return files, nil
 }
 
 // lsFilesWithDeleted returns tracked paths that no longer exist on disk.
-func (l *LsFilesService) lsFilesWithDeleted(entries []*domain.IndexEntry) ([]string, error) {
+// Paths left out by sparse checkout are not deleted.
+func (l *LsFilesService) lsFilesWithDeleted(entries []*domain.IndexEntry, tags bool) ([]string, error) {
 	files := make([]string, 0)
 	for _, entry := range entries {
+		if entry.SkipWorktree() {
+			continue
+		}
 		absolutePath, err := entry.Path.ToAbsolutePath(l.workspace.RepoDir)
 		if err != nil {
 			return nil, fmt.Errorf("ls-files: %w", err)
 		}
-		_, err = os.Stat(absolutePath.String())
+		_, err = os.Lstat(absolutePath.String())
 		switch {
 		case errors.Is(err, os.ErrNotExist):
-			files = append(files, entry.Path.String())
+			files = append(files, tagLsFilesLine(entry.Path.String(), entry, lsFilesTagDeleted, tags))
 		case err != nil:
 			return nil, fmt.Errorf("ls-files: failed to stat file '%s': %w", entry.Path, err)
 		}
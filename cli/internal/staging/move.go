@@ -0,0 +1,321 @@
+package staging
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// MoveOptions controls mv command execution mode.
+type MoveOptions struct {
+	// Force overwrites existing destination files.
+	Force bool
+	// DryRun reports the moves that would happen without mutating state.
+	DryRun bool
+}
+
+// Move is one source renamed to one destination.
+type Move struct {
+	Source      domain.NormalizedPath
+	Destination domain.NormalizedPath
+}
+
+// MoveResult returns the moves performed by an mv invocation, in argument order.
+type MoveResult struct {
+	Moves []Move
+}
+
+// MoveService renames tracked files and directories in the working tree and the index.
+type MoveService struct {
+	indexService *core.IndexService
+	workspace    *domain.Workspace
+}
+
+type movePlan struct {
+	moves []Move
+	// renames maps each affected index entry's old path to its new path.
+	renames map[domain.NormalizedPath]domain.NormalizedPath
+	// overwritten lists destination files replaced under --force.
+	overwritten []domain.NormalizedPath
+}
+
+// NewMoveService creates an mv service with the required dependencies.
+func NewMoveService(indexService *core.IndexService, workspace *domain.Workspace) *MoveService {
+	return &MoveService{
+		indexService: indexService,
+		workspace:    workspace,
+	}
+}
+
+// Move renames each source to destination, or into destination when it is an
+// existing directory, on disk and in the index. Index entries keep their
+// hashes and stat data. If any step fails, the files already moved are moved
+// back, overwritten files are restored and the index is left untouched.
+func (m *MoveService) Move(sources []string, destination string, options MoveOptions) (*MoveResult, error) {
+	index, err := m.indexService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("mv: %w", err)
+	}
+
+	plan, err := m.collectPlan(index, sources, destination, options.Force)
+	if err != nil {
+		return nil, fmt.Errorf("mv: %w", err)
+	}
+
+	result := &MoveResult{Moves: plan.moves}
+	if options.DryRun {
+		return result, nil
+	}
+	if err := m.applyMoves(index, plan); err != nil {
+		return nil, fmt.Errorf("mv: %w", err)
+	}
+	return result, nil
+}
+
+// collectPlan validates every source against the index and the working tree
+// and resolves the final destination of each before anything is touched.
+func (m *MoveService) collectPlan(
+	index *domain.Index,
+	sources []string,
+	destination string,
+	force bool,
+) (movePlan, error) {
+	destinationPath, err := domain.NewNormalizedPath(destination, m.workspace.RepoDir)
+	if err != nil {
+		return movePlan{}, err
+	}
+	destinationInfo, err := m.lstat(destinationPath)
+	if err != nil {
+		return movePlan{}, err
+	}
+	intoDirectory := destinationInfo != nil && destinationInfo.IsDir()
+	if len(sources) > 1 && !intoDirectory {
+		return movePlan{}, fmt.Errorf("%w: '%s'", ErrMoveDestinationNotDirectory, destinationPath)
+	}
+
+	plan := movePlan{renames: make(map[domain.NormalizedPath]domain.NormalizedPath)}
+	targets := make(map[domain.NormalizedPath]domain.NormalizedPath)
+	for _, source := range sources {
+		sourcePath, err := domain.NewNormalizedPath(source, m.workspace.RepoDir)
+		if err != nil {
+			return movePlan{}, err
+		}
+		target := destinationPath
+		if intoDirectory {
+			target, err = domain.ParseNormalizedPath(
+				strings.TrimPrefix(destinationPath.String()+"/"+path.Base(sourcePath.String()), "/"),
+			)
+			if err != nil {
+				return movePlan{}, err
+			}
+		}
+
+		move := Move{Source: sourcePath, Destination: target}
+		if previous, ok := targets[target]; ok {
+			return movePlan{}, newMoveError(ErrMoveMultipleSources, Move{Source: previous, Destination: target})
+		}
+		targets[target] = sourcePath
+
+		overwrite, err := m.checkMove(index, move, force, plan.renames)
+		if err != nil {
+			return movePlan{}, err
+		}
+		if overwrite {
+			plan.overwritten = append(plan.overwritten, target)
+		}
+		plan.moves = append(plan.moves, move)
+	}
+	return plan, nil
+}
+
+// checkMove validates one move, records the index entries it renames, and
+// reports whether it replaces an existing destination file.
+func (m *MoveService) checkMove(
+	index *domain.Index,
+	move Move,
+	force bool,
+	renames map[domain.NormalizedPath]domain.NormalizedPath,
+) (bool, error) {
+	if move.Source.IsRoot() {
+		return false, newMoveError(ErrMoveBadSource, move)
+	}
+	if move.Destination.IsWithin(move.Source) {
+		return false, newMoveError(ErrMoveIntoItself, move)
+	}
+	sourceInfo, err := m.lstat(move.Source)
+	if err != nil {
+		return false, err
+	}
+	if sourceInfo == nil {
+		return false, newMoveError(ErrMoveBadSource, move)
+	}
+	if err := m.checkDestinationDirectory(move); err != nil {
+		return false, err
+	}
+	destinationInfo, err := m.lstat(move.Destination)
+	if err != nil {
+		return false, err
+	}
+
+	if sourceInfo.IsDir() {
+		// Entries outside the sparse checkout are renamed along with the
+		// directory even though they have no file to move.
+		entries := findDescendantEntries(index, move.Source)
+		if len(entries) == 0 {
+			return false, newMoveError(ErrMoveNotTracked, move)
+		}
+		if destinationInfo != nil {
+			return false, newMoveError(ErrMoveDestinationExists, move)
+		}
+		for _, entry := range entries {
+			if entry.GetStage() != 0 {
+				return false, newMoveError(ErrMoveConflicted, move)
+			}
+			renamed, err := domain.ParseNormalizedPath(
+				move.Destination.String() + strings.TrimPrefix(entry.Path.String(), move.Source.String()),
+			)
+			if err != nil {
+				return false, err
+			}
+			renames[entry.Path] = renamed
+		}
+		return false, nil
+	}
+
+	entry, _ := index.FindEntry(move.Source)
+	if entry == nil {
+		return false, newMoveError(ErrMoveNotTracked, move)
+	}
+	if entry.GetStage() != 0 {
+		return false, newMoveError(ErrMoveConflicted, move)
+	}
+	renames[entry.Path] = move.Destination
+
+	if destinationInfo == nil {
+		return false, nil
+	}
+	if !force || destinationInfo.IsDir() {
+		return false, newMoveError(ErrMoveDestinationExists, move)
+	}
+	return true, nil
+}
+
+// checkDestinationDirectory verifies that the directory that will hold the destination exists.
+func (m *MoveService) checkDestinationDirectory(move Move) error {
+	parent := path.Dir(move.Destination.String())
+	if parent == "." {
+		return nil
+	}
+	parentPath, err := domain.ParseNormalizedPath(parent)
+	if err != nil {
+		return err
+	}
+	info, err := m.lstat(parentPath)
+	if err != nil {
+		return err
+	}
+	if info == nil || !info.IsDir() {
+		return newMoveError(ErrMoveDestinationDirectoryMissing, move)
+	}
+	return nil
+}
+
+// applyMoves renames the planned paths on disk and then writes the renamed
+// index, undoing the renames and restoring overwritten files on failure.
+func (m *MoveService) applyMoves(index *domain.Index, plan movePlan) error {
+	backups, err := captureFileBackups(m.workspace.RepoDir, plan.overwritten)
+	if err != nil {
+		return err
+	}
+
+	for i, move := range plan.moves {
+		if err := m.rename(move.Source, move.Destination); err != nil {
+			return m.rollback(err, plan.moves[:i], backups)
+		}
+	}
+
+	if err := m.indexService.Write(renameIndexEntries(index, plan)); err != nil {
+		return m.rollback(err, plan.moves, backups)
+	}
+	return nil
+}
+
+// rollback moves completed renames back in reverse order, then restores
+// overwritten files. It returns the original error unless rollback fails too.
+func (m *MoveService) rollback(
+	moveErr error,
+	completed []Move,
+	backups map[domain.NormalizedPath]fileBackup,
+) error {
+	for i := len(completed) - 1; i >= 0; i-- {
+		if err := m.rename(completed[i].Destination, completed[i].Source); err != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", moveErr, err)
+		}
+	}
+	return restoreBackups(m.workspace.RepoDir, moveErr, backups)
+}
+
+// rename moves one working tree path.
+func (m *MoveService) rename(source, destination domain.NormalizedPath) error {
+	sourcePath, err := source.ToAbsolutePath(m.workspace.RepoDir)
+	if err != nil {
+		return err
+	}
+	destinationPath, err := destination.ToAbsolutePath(m.workspace.RepoDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(sourcePath.String(), destinationPath.String()); err != nil {
+		return fmt.Errorf("failed to rename '%s' to '%s': %w", source, destination, err)
+	}
+	return nil
+}
+
+// lstat returns the file info of a working tree path, or nil when it does not exist.
+func (m *MoveService) lstat(path domain.NormalizedPath) (os.FileInfo, error) {
+	absPath, err := path.ToAbsolutePath(m.workspace.RepoDir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(absPath.String())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat '%s': %w", absPath, err)
+	}
+	return info, nil
+}
+
+// renameIndexEntries derives the post-mv index state without mutating the
+// original index. Renamed entries keep their hash, mode and stat data, and
+// replace any entry already at their new path.
+func renameIndexEntries(index *domain.Index, plan movePlan) *domain.Index {
+	clonedIndex := index.Clone()
+
+	var renamed []*domain.IndexEntry
+	for oldPath, newPath := range plan.renames {
+		entry, _ := clonedIndex.FindEntry(oldPath)
+		if entry == nil {
+			continue
+		}
+		clonedIndex.RemoveEntry(oldPath)
+		entry.Path = newPath
+		entry.Flags = domain.ComputeIndexFlags(newPath.String(), 0) | entry.Flags&domain.AssumeValidFlag
+		entry.FSMonitorValid = false
+		renamed = append(renamed, entry)
+	}
+	for _, entry := range renamed {
+		clonedIndex.SetEntry(entry)
+	}
+	return clonedIndex
+}
+
+// newMoveError reports a rejected move with both of its paths.
+func newMoveError(err error, move Move) error {
+	return fmt.Errorf("%w, source=%s, destination=%s", err, move.Source, move.Destination)
+}
@@ -41,11 +41,6 @@ type removePlan struct {
 	pruneRoots []domain.NormalizedPath
 }
 
-type fileBackup struct {
-	mode os.FileMode
-	body []byte
-}
-
 // NewRemoveService creates an rm service with the required dependencies.
 func NewRemoveService(
 	indexService *core.IndexService,
@@ -228,54 +223,23 @@ func (r *RemoveService) hasLocalModifications(entry *domain.IndexEntry, check bo
 
 // applyRemoval executes the working tree and index mutations after planning and validation.
 func (r *RemoveService) applyRemoval(updatedIndex *domain.Index, plan removePlan) error {
-	backups, err := r.captureFileBackups(plan.paths)
+	backups, err := captureFileBackups(r.workspace.RepoDir, plan.paths)
 	if err != nil {
 		return err
 	}
 
 	if err := r.deleteWorkingTreeFiles(plan.paths); err != nil {
-		return r.restoreBackups(err, backups)
+		return restoreBackups(r.workspace.RepoDir, err, backups)
 	}
 	if err := r.pruneEmptyDirectories(plan.paths, plan.pruneRoots); err != nil {
-		return r.restoreBackups(err, backups)
+		return restoreBackups(r.workspace.RepoDir, err, backups)
 	}
 	if err := r.indexService.Write(updatedIndex); err != nil {
-		return r.restoreBackups(err, backups)
+		return restoreBackups(r.workspace.RepoDir, err, backups)
 	}
 	return nil
 }
 
-// captureFileBackups snapshots working tree files before deletion so rollback can restore them.
-func (r *RemoveService) captureFileBackups(paths []domain.NormalizedPath) (
-	map[domain.NormalizedPath]fileBackup, error,
-) {
-	backups := make(map[domain.NormalizedPath]fileBackup)
-	for _, path := range paths {
-		absPath, err := path.ToAbsolutePath(r.workspace.RepoDir)
-		if err != nil {
-			return nil, err
-		}
-
-		info, err := os.Stat(absPath.String())
-		switch {
-		case errors.Is(err, os.ErrNotExist):
-			continue
-		case err != nil:
-			return nil, fmt.Errorf("failed to stat '%s': %w", absPath, err)
-		}
-
-		body, err := os.ReadFile(absPath.String())
-		if err != nil {
-			return nil, fmt.Errorf("failed to read '%s': %w", absPath, err)
-		}
-		backups[path] = fileBackup{
-			mode: info.Mode().Perm(),
-			body: body,
-		}
-	}
-	return backups, nil
-}
-
 // deleteWorkingTreeFiles removes the target files from disk and ignores already-missing paths.
 func (r *RemoveService) deleteWorkingTreeFiles(paths []domain.NormalizedPath) error {
 	for _, path := range paths {
@@ -321,40 +285,6 @@ func (r *RemoveService) pruneEmptyDirectories(paths, pruneRoots []domain.Normali
 	return nil
 }
 
-// restoreBackups returns the original removal error unless rollback itself also fails.
-func (r *RemoveService) restoreBackups(
-	removeErr error,
-	backups map[domain.NormalizedPath]fileBackup,
-) error {
-	if err := r.restoreFileBackups(backups); err != nil {
-		return fmt.Errorf("%w (rollback failed: %v)", removeErr, err)
-	}
-	return removeErr
-}
-
-// restoreFileBackups recreates deleted files from their in-memory snapshots.
-func (r *RemoveService) restoreFileBackups(backups map[domain.NormalizedPath]fileBackup) error {
-	paths := make(map[domain.NormalizedPath]struct{}, len(backups))
-	for path := range backups {
-		paths[path] = struct{}{}
-	}
-
-	for _, path := range domain.SortedPathSet(paths) {
-		backup := backups[path]
-		absPath, err := path.ToAbsolutePath(r.workspace.RepoDir)
-		if err != nil {
-			return err
-		}
-		if err := os.MkdirAll(filepath.Dir(absPath.String()), domain.DefaultDirPermission); err != nil {
-			return fmt.Errorf("failed to create directory for '%s': %w", absPath, err)
-		}
-		if err := os.WriteFile(absPath.String(), backup.body, backup.mode); err != nil {
-			return fmt.Errorf("failed to restore '%s': %w", absPath, err)
-		}
-	}
-	return nil
-}
-
 // findDescendantEntries returns tracked files beneath a directory-style pathspec.
 func findDescendantEntries(index *domain.Index, path domain.NormalizedPath) []*domain.IndexEntry {
 	prefix := path.String()
@@ -6,6 +6,7 @@ import (
 	"Gel/internal/validate"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // UpdateIndexOptions controls update-index behavior.
@@ -16,6 +17,16 @@ type UpdateIndexOptions struct {
 	Remove bool
 	// Write persists the updated index to disk when true.
 	Write bool
+	// Refresh re-checks the stat data of the provided paths, or of every
+	// entry when none are given, instead of adding or removing entries.
+	Refresh bool
+	// ReallyRefresh is Refresh that also re-hashes entries whose stat data matches.
+	ReallyRefresh bool
+	// AssumeUnchanged marks the entries of the provided paths assume-unchanged,
+	// so their files are no longer checked for changes.
+	AssumeUnchanged bool
+	// NoAssumeUnchanged clears the assume-unchanged mark of the provided paths.
+	NoAssumeUnchanged bool
 }
 
 // UpdateIndexService updates index entries from working tree files.
@@ -46,14 +57,21 @@ func NewUpdateIndexService(
 
 // UpdateIndex applies add/remove operations for normalized repository paths.
 //
-// At least one of options.Add or options.Remove must be enabled. Returned paths
-// are the paths that were actually affected by the selected operation.
+// At least one of options.Add, options.Remove, options.Refresh,
+// options.ReallyRefresh, options.AssumeUnchanged or options.NoAssumeUnchanged
+// must be enabled. Returned paths are the paths that were actually affected by
+// the selected operation; for a refresh, they are the paths that need update.
 func (u *UpdateIndexService) UpdateIndex(
 	paths []domain.NormalizedPath,
 	options UpdateIndexOptions,
 ) ([]domain.NormalizedPath, error) {
-	if !options.Add && !options.Remove {
-		return nil, errors.New("update-index: must specify --add or --remove")
+	refresh := options.Refresh || options.ReallyRefresh
+	assumeUnchanged := options.AssumeUnchanged || options.NoAssumeUnchanged
+	if !options.Add && !options.Remove && !refresh && !assumeUnchanged {
+		return nil, errors.New("update-index: must specify --add, --remove, --refresh or --[no-]assume-unchanged")
+	}
+	if options.AssumeUnchanged && options.NoAssumeUnchanged {
+		return nil, errors.New("update-index: --assume-unchanged and --no-assume-unchanged are mutually exclusive")
 	}
 
 	index, err := u.indexService.Read()
@@ -62,6 +80,10 @@ func (u *UpdateIndexService) UpdateIndex(
 	}
 
 	switch {
+	case refresh:
+		return u.updateIndexWithRefresh(index, paths, options.ReallyRefresh, options.Write)
+	case assumeUnchanged:
+		return u.updateIndexWithAssumeUnchanged(index, paths, options.AssumeUnchanged, options.Write)
 	case options.Add:
 		return u.updateIndexWithAdd(index, paths, options.Write)
 	case options.Remove:
@@ -71,9 +93,20 @@ func (u *UpdateIndexService) UpdateIndex(
 	}
 }
 
+// SetIndexVersion converts the index file in place to the given format
+// version and returns the versions it was stored in before and after.
+func (u *UpdateIndexService) SetIndexVersion(version uint32) (uint32, uint32, error) {
+	previous, current, err := u.indexService.SetVersion(version)
+	if err != nil {
+		return 0, 0, fmt.Errorf("update-index: %w", err)
+	}
+	return previous, current, nil
+}
+
 // updateIndexWithAdd stages file content for the given normalized paths.
 // It computes object hashes, writes blob objects when requested, and updates
-// entry metadata only for paths that are newly added or modified.
+// entry metadata only for paths that are newly added or modified. A nested
+// repository is staged as a submodule recording its checked-out commit.
 func (u *UpdateIndexService) updateIndexWithAdd(
 	index *domain.Index,
 	paths []domain.NormalizedPath,
@@ -87,6 +120,16 @@ func (u *UpdateIndexService) updateIndexWithAdd(
 		if err != nil {
 			return nil, fmt.Errorf("update-index: %w", err)
 		}
+		if core.IsRepositoryRoot(absolutePath.String()) {
+			added, err := stageSubmodule(index, path, absolutePath)
+			if err != nil {
+				return nil, fmt.Errorf("update-index: %w", err)
+			}
+			if added {
+				addedPaths = append(addedPaths, path)
+			}
+			continue
+		}
 		if err := validate.PathMustBeFile(absolutePath.String()); err != nil {
 			return nil, fmt.Errorf("update-index: %w", err)
 		}
@@ -187,6 +230,57 @@ func (u *UpdateIndexService) updateIndexWithAdd(
 	return addedPaths, nil
 }
 
+// stageSubmodule records the commit checked out in the nested repository at
+// absolutePath as a submodule entry, and reports whether the entry changed.
+func stageSubmodule(index *domain.Index, path domain.NormalizedPath, absolutePath domain.AbsolutePath) (bool, error) {
+	head, ok, err := core.ReadSubmoduleHead(absolutePath)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, fmt.Errorf("'%s': %w", path, ErrSubmoduleNoCommit)
+	}
+	if entry, _ := index.FindEntry(path); entry != nil && entry.Hash == head &&
+		domain.FileMode(entry.Mode).IsGitlink() {
+		return false, nil
+	}
+	entry := domain.NewEmptyIndexEntry(path, head, domain.FileModeGitlink.Uint32())
+	entry.Flags = domain.ComputeIndexFlags(path.String(), 0)
+	index.RemoveEntry(path)
+	index.SetEntry(entry)
+	return true, nil
+}
+
+// updateIndexWithAssumeUnchanged sets or clears the assume-unchanged mark of
+// the entries of paths and returns the paths whose mark changed. Every path
+// must be in the index.
+func (u *UpdateIndexService) updateIndexWithAssumeUnchanged(
+	index *domain.Index,
+	paths []domain.NormalizedPath,
+	assumeUnchanged bool,
+	write bool,
+) ([]domain.NormalizedPath, error) {
+	var markedPaths []domain.NormalizedPath
+	for _, path := range paths {
+		entry, _ := index.FindEntry(path)
+		if entry == nil {
+			return nil, fmt.Errorf("update-index: '%s': %w", path, ErrNotInIndex)
+		}
+		if entry.AssumeUnchanged() == assumeUnchanged {
+			continue
+		}
+		entry.SetAssumeUnchanged(assumeUnchanged)
+		markedPaths = append(markedPaths, path)
+	}
+	if !write {
+		return markedPaths, nil
+	}
+	if err := u.indexService.Write(index); err != nil {
+		return nil, fmt.Errorf("update-index: %w", err)
+	}
+	return markedPaths, nil
+}
+
 // updateIndexWithRemove removes the given paths from the index.
 // Missing paths are treated as no-op removals.
 func (u *UpdateIndexService) updateIndexWithRemove(index *domain.Index, paths []domain.NormalizedPath, write bool) (
@@ -207,3 +301,61 @@ func (u *UpdateIndexService) updateIndexWithRemove(index *domain.Index, paths []
 	}
 	return removedPaths, nil
 }
+
+// updateIndexWithRefresh refreshes the stat data of stage-0 entries at or
+// below paths, or of every entry when paths is empty, and returns the paths
+// whose files no longer match the index. The index is written even when no
+// entry changed so that entries found racily clean stop being racy.
+func (u *UpdateIndexService) updateIndexWithRefresh(
+	index *domain.Index,
+	paths []domain.NormalizedPath,
+	really bool,
+	write bool,
+) ([]domain.NormalizedPath, error) {
+	var entries []*domain.IndexEntry
+	for _, entry := range index.Entries {
+		if entry.GetStage() == 0 && refreshSelects(entry.Path, paths) {
+			entries = append(entries, entry)
+		}
+	}
+
+	upToDate := make([]bool, len(entries))
+	err := core.RunParallel(
+		len(entries), core.DefaultWorkers(), func(i int) error {
+			ok, err := u.changeDetector.RefreshEntry(entries[i], really)
+			upToDate[i] = ok
+			return err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update-index: %w", err)
+	}
+
+	var needsUpdate []domain.NormalizedPath
+	for i, entry := range entries {
+		if !upToDate[i] {
+			needsUpdate = append(needsUpdate, entry.Path)
+		}
+	}
+	if !write {
+		return needsUpdate, nil
+	}
+	if err := u.indexService.Write(index); err != nil {
+		return nil, fmt.Errorf("update-index: %w", err)
+	}
+	return needsUpdate, nil
+}
+
+// refreshSelects reports whether path is one of paths or lies below one of
+// them. An empty paths selects everything.
+func refreshSelects(path domain.NormalizedPath, paths []domain.NormalizedPath) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, selected := range paths {
+		if selected.String() == "" || path == selected || strings.HasPrefix(path.String(), selected.String()+"/") {
+			return true
+		}
+	}
+	return false
+}
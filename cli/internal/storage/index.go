@@ -3,7 +3,9 @@ package storage
 import (
 	"Gel/internal/domain"
 	"fmt"
+	"io"
 	"os"
+	"time"
 )
 
 // IndexStorage provides raw index file persistence under .gel/index.
@@ -18,19 +20,34 @@ func NewIndexStorage(workspace *domain.Workspace) *IndexStorage {
 	}
 }
 
-// Read loads the entire index file as bytes.
-func (i *IndexStorage) Read() ([]byte, error) {
-	data, err := os.ReadFile(i.workspace.IndexPath.String())
+// Read loads the entire index file as bytes together with its modification time.
+func (i *IndexStorage) Read() ([]byte, time.Time, error) {
+	file, err := os.Open(i.workspace.IndexPath.String())
 	if err != nil {
-		return nil, fmt.Errorf("error reading index file: %w", err)
+		return nil, time.Time{}, fmt.Errorf("error reading index file: %w", err)
 	}
-	return data, nil
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error reading index file: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error reading index file: %w", err)
+	}
+	return data, info.ModTime(), nil
 }
 
-// Write replaces the index file with data.
-func (i *IndexStorage) Write(data []byte) error {
-	if err := os.WriteFile(i.workspace.IndexPath.String(), data, domain.DefaultFilePermission); err != nil {
-		return fmt.Errorf("error writing index file: %w", err)
+// Write replaces the index file with data and returns its new modification time.
+func (i *IndexStorage) Write(data []byte) (time.Time, error) {
+	path := i.workspace.IndexPath.String()
+	if err := os.WriteFile(path, data, domain.DefaultFilePermission); err != nil {
+		return time.Time{}, fmt.Errorf("error writing index file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error writing index file: %w", err)
 	}
-	return nil
+	return info.ModTime(), nil
 }
@@ -0,0 +1,23 @@
+package submodule
+
+import "errors"
+
+var (
+	// ErrSubmoduleExists is returned when add targets a path already tracked or listed in .gelmodules.
+	ErrSubmoduleExists = errors.New("already exists in the index")
+
+	// ErrEmptySubmodule is returned when add clones a repository that has no commits.
+	ErrEmptySubmodule = errors.New("repository has no commits to record")
+
+	// ErrSubmoduleNotRecorded is returned when the index records no commit for a submodule.
+	ErrSubmoduleNotRecorded = errors.New("no commit recorded in the index")
+
+	// ErrDirtySubmodule is returned when update would overwrite changes in a submodule.
+	ErrDirtySubmodule = errors.New("has local changes that would be overwritten by update")
+
+	// ErrCommitNotFetched is returned when the recorded commit is missing from the submodule's origin.
+	ErrCommitNotFetched = errors.New("not found in the submodule's origin")
+
+	// ErrForeachFailed is returned when the foreach command exits with a non-zero status.
+	ErrForeachFailed = errors.New("command returned non-zero status")
+)
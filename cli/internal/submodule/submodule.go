@@ -0,0 +1,452 @@
+package submodule
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/remote"
+	"Gel/internal/staging"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// AddResult describes a submodule recorded by Add. A freshly cloned
+// submodule's files are not checked out yet: callers populate the working
+// tree and index of Workspace.
+type AddResult struct {
+	Submodule core.Submodule
+	// Workspace is the layout of the submodule's repository.
+	Workspace *domain.Workspace
+	// CommitHash is the commit recorded for the submodule.
+	CommitHash domain.Hash
+	// Cloned reports whether Add cloned the repository, rather than adopting
+	// one already at the path.
+	Cloned bool
+}
+
+// Status describes a submodule and the commit checked out in it.
+type Status struct {
+	Submodule core.Submodule
+	// Recorded is the commit the index records for the submodule.
+	Recorded domain.Hash
+	// Head is the commit checked out in the submodule, zero when CheckedOut is false.
+	Head domain.Hash
+	// Initialized reports whether the submodule's URL is set in config.
+	Initialized bool
+	// CheckedOut reports whether the submodule's repository exists and has a commit.
+	CheckedOut bool
+}
+
+// UpdateTarget is a submodule whose checkout must be moved to the recorded
+// commit. Its files are not touched yet: callers check CommitHash out in
+// Workspace.
+type UpdateTarget struct {
+	Submodule core.Submodule
+	// Workspace is the layout of the submodule's repository.
+	Workspace *domain.Workspace
+	// CommitHash is the commit recorded for the submodule.
+	CommitHash domain.Hash
+	// Cloned reports whether the repository was cloned by this update.
+	Cloned bool
+	// ClonedHash is the branch tip the clone starts from, or zero when the
+	// repository was not cloned or its origin has no commits.
+	ClonedHash domain.Hash
+}
+
+// SubmoduleService manages repositories embedded in the working tree. The
+// index records each submodule as a gitlink entry naming a commit of the
+// submodule's repository; .gelmodules maps submodule paths to the URLs they
+// are cloned from, and config holds the URLs of initialized submodules.
+//
+// URLs are local repository paths. A relative URL in .gelmodules is resolved
+// against the URL of the superproject's origin remote, so that clones of the
+// superproject find sibling repositories of the one they were cloned from.
+// Without an origin remote it is resolved against the working tree root.
+type SubmoduleService struct {
+	indexService       *core.IndexService
+	configService      *core.ConfigService
+	updateIndexService *staging.UpdateIndexService
+	workspace          *domain.Workspace
+}
+
+// NewSubmoduleService creates a submodule service.
+func NewSubmoduleService(
+	indexService *core.IndexService,
+	configService *core.ConfigService,
+	updateIndexService *staging.UpdateIndexService,
+	workspace *domain.Workspace,
+) *SubmoduleService {
+	return &SubmoduleService{
+		indexService:       indexService,
+		configService:      configService,
+		updateIndexService: updateIndexService,
+		workspace:          workspace,
+	}
+}
+
+// List returns the submodules in .gelmodules sorted by path.
+func (s *SubmoduleService) List() ([]core.Submodule, error) {
+	data, err := os.ReadFile(s.gelModulesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	submodules, err := core.ParseGelModules(data)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	return submodules, nil
+}
+
+// Add clones the repository at url into path, or adopts the repository
+// already there, and records its checked-out commit in the index together
+// with a .gelmodules entry. The submodule is initialized. A url starting with
+// "./" or "../" is relative to the origin remote like in .gelmodules; other
+// relative urls are taken from the current directory. When path is empty,
+// the repository's directory name is used. A directory cloned by Add is
+// removed again if the submodule cannot be recorded.
+func (s *SubmoduleService) Add(url, path string) (_ *AddResult, err error) {
+	base, err := s.urlBase()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	absURL, storedURL := "", filepath.ToSlash(url)
+	if isRelativeURL(storedURL) {
+		absURL = filepath.Join(base, filepath.FromSlash(storedURL))
+	} else {
+		absURL, err = filepath.Abs(url)
+		if err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		storedURL = relativeURL(base, absURL)
+	}
+	if path == "" {
+		path = filepath.Base(strings.TrimSuffix(absURL, string(filepath.Separator)+domain.GelDirName))
+	}
+	submodulePath, err := domain.NewNormalizedPath(path, s.workspace.RepoDir)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	if submodulePath.IsRoot() {
+		return nil, fmt.Errorf("submodule: '%s': %w", path, ErrSubmoduleExists)
+	}
+	if err := s.checkNotTracked(submodulePath); err != nil {
+		return nil, err
+	}
+	submodules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, submodule := range submodules {
+		if submodule.Path == submodulePath || submodule.Name == submodulePath.String() {
+			return nil, fmt.Errorf("submodule: '%s' %w", submodulePath, ErrSubmoduleExists)
+		}
+	}
+
+	absPath, err := submodulePath.ToAbsolutePath(s.workspace.RepoDir)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	result := &AddResult{
+		Submodule: core.Submodule{Name: submodulePath.String(), Path: submodulePath, URL: storedURL},
+	}
+	if core.IsRepositoryRoot(absPath.String()) {
+		workspace, _, err := domain.OpenWorkspace(absPath.String())
+		if err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		result.Workspace = workspace
+	} else {
+		cloneResult, cloneErr := remote.NewCloneService().Clone(absURL, absPath.String(), remote.CloneOptions{})
+		if cloneErr != nil {
+			return nil, fmt.Errorf("submodule: %w", cloneErr)
+		}
+		defer func() {
+			if err != nil {
+				_ = os.RemoveAll(absPath.String())
+			}
+		}()
+		result.Workspace = cloneResult.Workspace
+		result.Cloned = true
+	}
+
+	head, ok, err := core.ReadSubmoduleHead(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("submodule: '%s': %w", url, ErrEmptySubmodule)
+	}
+	result.CommitHash = head
+
+	if err := s.writeGelModules(append(submodules, result.Submodule)); err != nil {
+		return nil, err
+	}
+	if err := s.configService.Set(
+		core.ConfigSectionSubmodule, result.Submodule.Name+"."+core.ConfigKeyURL, absURL,
+	); err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	gelModulesPath, err := domain.ParseNormalizedPath(domain.GelModulesFileName)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	if _, err := s.updateIndexService.UpdateIndex(
+		[]domain.NormalizedPath{gelModulesPath, submodulePath},
+		staging.UpdateIndexOptions{Add: true, Write: true},
+	); err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	return result, nil
+}
+
+// Init copies the URLs of the submodules matching pathspecs, or of all
+// submodules when none are given, from .gelmodules into config, so that
+// Update clones them. It returns the submodules it initialized; ones already
+// initialized keep their configured URL.
+func (s *SubmoduleService) Init(pathspecs []string) ([]core.Submodule, error) {
+	submodules, err := s.selected(pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.configService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	base, err := s.urlBase()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+
+	var initialized []core.Submodule
+	for _, submodule := range submodules {
+		if _, ok := config.Get(core.ConfigSectionSubmodule, submodule.Name+"."+core.ConfigKeyURL); ok {
+			continue
+		}
+		if err := config.Set(
+			core.ConfigSectionSubmodule, submodule.Name+"."+core.ConfigKeyURL, absoluteURL(base, submodule.URL),
+		); err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		initialized = append(initialized, submodule)
+	}
+	if len(initialized) == 0 {
+		return nil, nil
+	}
+	if err := s.configService.Write(config); err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	return initialized, nil
+}
+
+// Status describes the submodules matching pathspecs, or all submodules when
+// none are given.
+func (s *SubmoduleService) Status(pathspecs []string) ([]Status, error) {
+	submodules, err := s.selected(pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.indexService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	config, err := s.configService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+
+	statuses := make([]Status, 0, len(submodules))
+	for _, submodule := range submodules {
+		status := Status{Submodule: submodule}
+		if entry, _ := index.FindEntry(submodule.Path); entry != nil && domain.FileMode(entry.Mode).IsGitlink() {
+			status.Recorded = entry.Hash
+		}
+		_, status.Initialized = config.Get(core.ConfigSectionSubmodule, submodule.Name+"."+core.ConfigKeyURL)
+		absPath, err := submodule.Path.ToAbsolutePath(s.workspace.RepoDir)
+		if err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		status.Head, status.CheckedOut, err = core.ReadSubmoduleHead(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// PrepareUpdate clones the initialized submodules matching pathspecs, or all
+// initialized submodules when none are given, that are not checked out yet,
+// and returns those whose checkout differs from the recorded commit.
+// Uninitialized submodules are skipped.
+func (s *SubmoduleService) PrepareUpdate(pathspecs []string) ([]UpdateTarget, error) {
+	statuses, err := s.Status(pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.configService.Read()
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+
+	var targets []UpdateTarget
+	for _, status := range statuses {
+		if !status.Initialized {
+			continue
+		}
+		if status.Recorded.IsEmpty() {
+			return nil, fmt.Errorf("submodule: '%s': %w", status.Submodule.Path, ErrSubmoduleNotRecorded)
+		}
+		if status.CheckedOut && status.Head == status.Recorded {
+			continue
+		}
+
+		absPath, err := status.Submodule.Path.ToAbsolutePath(s.workspace.RepoDir)
+		if err != nil {
+			return nil, fmt.Errorf("submodule: %w", err)
+		}
+		target := UpdateTarget{Submodule: status.Submodule, CommitHash: status.Recorded}
+		if core.IsRepositoryRoot(absPath.String()) {
+			target.Workspace, _, err = domain.OpenWorkspace(absPath.String())
+			if err != nil {
+				return nil, fmt.Errorf("submodule: %w", err)
+			}
+		} else {
+			url, _ := config.Get(core.ConfigSectionSubmodule, status.Submodule.Name+"."+core.ConfigKeyURL)
+			cloneResult, err := remote.NewCloneService().Clone(url, absPath.String(), remote.CloneOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("submodule: '%s': %w", status.Submodule.Path, err)
+			}
+			target.Workspace = cloneResult.Workspace
+			target.Cloned = true
+			target.ClonedHash = cloneResult.CommitHash
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Foreach runs command through the shell in the checked-out submodule
+// described by status. The command sees the submodule's name, sm_path, sha1
+// and the superproject's toplevel as environment variables.
+func (s *SubmoduleService) Foreach(status Status, command string, stdout, stderr io.Writer) error {
+	absPath, err := status.Submodule.Path.ToAbsolutePath(s.workspace.RepoDir)
+	if err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = absPath.String()
+	cmd.Env = append(
+		os.Environ(),
+		"name="+status.Submodule.Name,
+		"sm_path="+status.Submodule.Path.String(),
+		"sha1="+status.Head.String(),
+		"toplevel="+s.workspace.RepoDir.String(),
+	)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("submodule: stopping at '%s': %w", status.Submodule.Path, ErrForeachFailed)
+		}
+		return fmt.Errorf("submodule: %w", err)
+	}
+	return nil
+}
+
+// selected returns the submodules whose path matches pathspecs, or all
+// submodules when none are given.
+func (s *SubmoduleService) selected(pathspecs []string) ([]core.Submodule, error) {
+	scopes, err := core.NormalizePathspecs(pathspecs, s.workspace.RepoDir)
+	if err != nil {
+		return nil, fmt.Errorf("submodule: %w", err)
+	}
+	submodules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var selected []core.Submodule
+	for _, submodule := range submodules {
+		if core.MatchesPathspecs(submodule.Path, scopes) {
+			selected = append(selected, submodule)
+		}
+	}
+	return selected, nil
+}
+
+// checkNotTracked fails when the index has an entry at or below path.
+func (s *SubmoduleService) checkNotTracked(path domain.NormalizedPath) error {
+	index, err := s.indexService.Read()
+	if err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+	if index.HasEntry(path) || len(index.FindEntriesByPathPrefix(path.String()+"/")) > 0 {
+		return fmt.Errorf("submodule: '%s' %w", path, ErrSubmoduleExists)
+	}
+	return nil
+}
+
+// writeGelModules replaces .gelmodules with submodules.
+func (s *SubmoduleService) writeGelModules(submodules []core.Submodule) error {
+	data, err := core.SerializeGelModules(submodules)
+	if err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+	if err := os.WriteFile(s.gelModulesPath(), data, domain.DefaultFilePermission); err != nil {
+		return fmt.Errorf("submodule: %w", err)
+	}
+	return nil
+}
+
+// urlBase returns the location relative submodule URLs are resolved against:
+// the URL of the origin remote, or the working tree root when it is unset.
+func (s *SubmoduleService) urlBase() (string, error) {
+	url, ok, err := s.configService.GetOptional(
+		core.ConfigSectionRemote, remote.DefaultRemoteName+"."+core.ConfigKeyURL,
+	)
+	if err != nil || !ok || url == "" {
+		return s.workspace.RepoDir.String(), err
+	}
+	if !filepath.IsAbs(url) {
+		return filepath.Join(s.workspace.RepoDir.String(), url), nil
+	}
+	return filepath.Clean(url), nil
+}
+
+// isRelativeURL reports whether url is relative to the URL base, which is
+// how URLs starting with "./" or "../" are read.
+func isRelativeURL(url string) bool {
+	return strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../")
+}
+
+// relativeURL expresses absURL relative to base when it lies outside of it,
+// so .gelmodules stays valid for clones of sibling repositories; other URLs
+// are kept absolute.
+func relativeURL(base, absURL string) string {
+	rel, err := filepath.Rel(base, absURL)
+	if err != nil || !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(absURL)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// absoluteURL resolves a .gelmodules URL against base.
+func absoluteURL(base, url string) string {
+	if filepath.IsAbs(url) {
+		return filepath.Clean(url)
+	}
+	return filepath.Join(base, filepath.FromSlash(url))
+}
+
+// gelModulesPath returns the absolute path of .gelmodules.
+func (s *SubmoduleService) gelModulesPath() string {
+	return filepath.Join(s.workspace.RepoDir.String(), domain.GelModulesFileName)
+}
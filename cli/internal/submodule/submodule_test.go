@@ -0,0 +1,167 @@
+package submodule
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/remote"
+	"Gel/internal/setup"
+	"Gel/internal/staging"
+	"Gel/internal/testutil"
+	"Gel/internal/tree"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepository is a repository with the services submodule tests need.
+type testRepository struct {
+	*testutil.Repository
+	addService       *staging.AddService
+	readTree         *tree.ReadTreeService
+	submoduleService *SubmoduleService
+}
+
+// openTestRepository wires the services of the repository at dir.
+func openTestRepository(t *testing.T, dir string) *testRepository {
+	t.Helper()
+	return newSubmoduleTestRepository(testutil.OpenRepository(t, dir))
+}
+
+// newTestRepository initializes a repository at dir and wires its services.
+func newTestRepository(t *testing.T, dir string) *testRepository {
+	t.Helper()
+	return newSubmoduleTestRepository(testutil.InitRepository(t, dir, setup.InitOptions{}))
+}
+
+// newSubmoduleTestRepository adds the submodule services to repository.
+func newSubmoduleTestRepository(repository *testutil.Repository) *testRepository {
+	updateIndexService := staging.NewUpdateIndexService(
+		repository.IndexService, repository.ObjectService, core.NewHashObjectService(repository.ObjectService),
+		repository.ChangeDetector, repository.Workspace,
+	)
+	return &testRepository{
+		Repository: repository,
+		addService: staging.NewAddService(
+			repository.IndexService, repository.ObjectService, updateIndexService, repository.PathResolver,
+			repository.ChangeDetector, repository.Workspace,
+		),
+		readTree: tree.NewReadTreeService(
+			repository.IndexService, repository.ObjectService,
+			core.NewSparseCheckout(repository.ConfigService, repository.Workspace),
+		),
+		submoduleService: NewSubmoduleService(
+			repository.IndexService, repository.ConfigService, updateIndexService, repository.Workspace,
+		),
+	}
+}
+
+// checkout loads commitHash into the index and writes its .gelmodules file,
+// which is all the submodule commands read from the working tree.
+func (r *testRepository) checkout(t *testing.T, commitHash domain.Hash) {
+	t.Helper()
+	commitObject, err := r.ObjectService.ReadCommit(commitHash)
+	require.NoError(t, err)
+	require.NoError(t, r.readTree.ReadTree(commitObject.TreeHash))
+
+	pathHashes, err := r.TreeResolver.ResolveCommit(commitHash)
+	require.NoError(t, err)
+	gelModulesPath, err := domain.ParseNormalizedPath(domain.GelModulesFileName)
+	require.NoError(t, err)
+	blob, err := r.ObjectService.ReadBlob(pathHashes[gelModulesPath])
+	require.NoError(t, err)
+	require.NoError(
+		t, os.WriteFile(r.submoduleService.gelModulesPath(), blob.Body(), domain.DefaultFilePermission),
+	)
+}
+
+// newSuperproject creates root/smlib with one commit and root/sm with smlib
+// added as submodule "lib" through the relative URL "../smlib" and committed.
+func newSuperproject(t *testing.T, root string) *testRepository {
+	t.Helper()
+	library := newTestRepository(t, filepath.Join(root, "smlib"))
+	libraryFile := filepath.Join(library.Workspace.RepoDir.String(), "lib.txt")
+	require.NoError(t, os.WriteFile(libraryFile, []byte("lib\n"), domain.DefaultFilePermission))
+	t.Chdir(library.Workspace.RepoDir.String())
+	require.NoError(t, library.addService.Add([]string{"."}, staging.AddOptions{}).Error)
+	library.Commit(t, "initial")
+
+	superproject := newTestRepository(t, filepath.Join(root, "sm"))
+	t.Chdir(superproject.Workspace.RepoDir.String())
+	_, err := superproject.submoduleService.Add("../smlib", "lib")
+	require.NoError(t, err)
+	superproject.Commit(t, "initial")
+	return superproject
+}
+
+func TestSubmoduleRelativeURLFollowsOriginInClone(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	superproject := newSuperproject(t, root)
+
+	submodules, err := superproject.submoduleService.List()
+	require.NoError(t, err)
+	require.Len(t, submodules, 1)
+	assert.Equal(t, "../smlib", submodules[0].URL)
+
+	clonePath := filepath.Join(root, "deep", "x", "smc")
+	cloneResult, err := remote.NewCloneService().Clone(
+		superproject.Workspace.RepoDir.String(), clonePath, remote.CloneOptions{},
+	)
+	require.NoError(t, err)
+	clone := openTestRepository(t, clonePath)
+	clone.checkout(t, cloneResult.CommitHash)
+	t.Chdir(clonePath)
+
+	initialized, err := clone.submoduleService.Init(nil)
+	require.NoError(t, err)
+	require.Len(t, initialized, 1)
+	url, err := clone.ConfigService.Get(core.ConfigSectionSubmodule, "lib."+core.ConfigKeyURL)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "smlib"), url)
+
+	targets, err := clone.submoduleService.PrepareUpdate(nil)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.True(t, targets[0].Cloned)
+	assert.True(t, core.IsRepositoryRoot(filepath.Join(clonePath, "lib")))
+}
+
+func TestSubmoduleURLBase(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	repository := newTestRepository(t, filepath.Join(root, "repo"))
+	repoDir := repository.Workspace.RepoDir.String()
+
+	tests := []struct {
+		name      string
+		originURL string
+		url       string
+		want      string
+	}{
+		{name: "sibling without origin", url: "../lib", want: filepath.Join(root, "lib")},
+		{name: "nested without origin", url: "./lib", want: filepath.Join(repoDir, "lib")},
+		{name: "sibling of origin", originURL: "/srv/repos/app", url: "../lib", want: "/srv/repos/lib"},
+		{name: "nested in origin", originURL: "/srv/repos/app", url: "./lib", want: "/srv/repos/app/lib"},
+		{name: "relative origin", originURL: "../up/app", url: "../lib", want: filepath.Join(root, "up", "lib")},
+		{name: "absolute url", originURL: "/srv/repos/app", url: "/opt/lib", want: "/opt/lib"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				require.NoError(
+					t, repository.ConfigService.Set(
+						core.ConfigSectionRemote, remote.DefaultRemoteName+"."+core.ConfigKeyURL, tt.originURL,
+					),
+				)
+
+				base, err := repository.submoduleService.urlBase()
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, absoluteURL(base, tt.url))
+			},
+		)
+	}
+}
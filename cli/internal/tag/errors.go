@@ -0,0 +1,17 @@
+package tag
+
+import "errors"
+
+var (
+	// ErrTagNotFound is returned when a tag does not exist.
+	ErrTagNotFound = errors.New("tag not found")
+
+	// ErrTagAlreadyExists is returned when creating a tag that exists without force.
+	ErrTagAlreadyExists = errors.New("tag already exists")
+
+	// ErrInvalidTagName is returned when a tag name violates naming rules.
+	ErrInvalidTagName = errors.New("invalid tag name")
+
+	// ErrNotAnnotated is returned when verifying a lightweight tag, which has no tag object to sign.
+	ErrNotAnnotated = errors.New("tag is not annotated")
+)
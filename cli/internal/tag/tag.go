@@ -0,0 +1,252 @@
+package tag
+
+import (
+	"Gel/internal/commit"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CreateOptions controls how Create writes a tag.
+type CreateOptions struct {
+	// Message makes the tag annotated: a tag object with this message and the
+	// current tagger is written, and the ref points at it.
+	Message string
+	// Annotate writes a tag object even when Message is empty.
+	Annotate bool
+	// Sign signs the tag object with the SSH key in user.signingkey. It
+	// implies Annotate.
+	Sign bool
+	// Force replaces an existing tag of the same name.
+	Force bool
+}
+
+// CreateResult reports the outcome of Create.
+type CreateResult struct {
+	// Hash is the value of refs/tags/<name>: the tag object of an annotated
+	// tag, or the tagged commit of a lightweight tag.
+	Hash domain.Hash
+	// OldHash is the replaced value when an existing tag was forced, zero otherwise.
+	OldHash domain.Hash
+}
+
+// TagService lists, creates, deletes, and verifies tags under refs/tags.
+//
+// Tags only point at commits. Fetch, push, bundles, and the Git bridge
+// transfer branches only, so tags stay in the repository they were made in.
+type TagService struct {
+	refService     *core.RefService
+	objectService  *core.ObjectService
+	commitResolver *core.CommitResolver
+	commitTree     *commit.CommitTreeService
+	signingService *core.SigningService
+	workspace      *domain.Workspace
+}
+
+// NewTagService creates a tag service. The tagger identity is the committer
+// identity of commitTree.
+func NewTagService(
+	refService *core.RefService,
+	objectService *core.ObjectService,
+	commitResolver *core.CommitResolver,
+	commitTree *commit.CommitTreeService,
+	workspace *domain.Workspace,
+) *TagService {
+	return &TagService{
+		refService:     refService,
+		objectService:  objectService,
+		commitResolver: commitResolver,
+		commitTree:     commitTree,
+		workspace:      workspace,
+	}
+}
+
+// SetSigningService enables signed tags and Verify.
+func (t *TagService) SetSigningService(signingService *core.SigningService) {
+	t.signingService = signingService
+}
+
+// List returns the names of all tags, sorted.
+func (t *TagService) List() ([]string, error) {
+	tagsDir := filepath.Join(t.workspace.RefsDir.String(), domain.TagsDirName)
+	var names []string
+	err := filepath.WalkDir(
+		tagsDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			names = append(names, filepath.ToSlash(strings.TrimPrefix(path, tagsDir+string(filepath.Separator))))
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("tag: failed to list tags: %w", err)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Create points refs/tags/<name> at the commit target resolves to, or HEAD
+// when target is empty. With a message, Annotate, or Sign, the ref points at
+// a new tag object instead of the commit.
+func (t *TagService) Create(name, target string, options CreateOptions) (*CreateResult, error) {
+	if err := validateTagName(name); err != nil {
+		return nil, fmt.Errorf("tag: '%s': %w", name, err)
+	}
+	ref := tagRef(name)
+	oldHash, err := t.refService.Read(ref)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return nil, fmt.Errorf("tag: %w", err)
+	}
+	if err == nil && !options.Force {
+		return nil, fmt.Errorf("tag: '%s': %w", name, ErrTagAlreadyExists)
+	}
+
+	if target == "" {
+		target = domain.HeadFileName
+	}
+	commitHash, err := t.commitResolver.Resolve(target)
+	if err != nil {
+		return nil, fmt.Errorf("tag: '%s': %w", target, err)
+	}
+
+	hash := commitHash
+	if options.Message != "" || options.Annotate || options.Sign {
+		hash, err = t.writeTagObject(name, commitHash, options)
+		if err != nil {
+			return nil, fmt.Errorf("tag: %w", err)
+		}
+	}
+	if err := t.refService.Write(ref, hash); err != nil {
+		return nil, fmt.Errorf("tag: %w", err)
+	}
+	return &CreateResult{Hash: hash, OldHash: oldHash}, nil
+}
+
+// writeTagObject writes a tag object for commitHash, signed when
+// options.Sign is set, and returns its hash.
+func (t *TagService) writeTagObject(name string, commitHash domain.Hash, options CreateOptions) (domain.Hash, error) {
+	tagger, err := t.commitTree.DefaultCommitter()
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	message := options.Message
+	if message != "" && !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	fields := domain.TagFields{
+		ObjectHash: commitHash,
+		ObjectType: domain.ObjectTypeCommit,
+		Name:       name,
+		Tagger:     tagger,
+		Message:    message,
+	}
+	tag, err := domain.NewTagFromFields(fields)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	if options.Sign {
+		if t.signingService == nil {
+			return domain.Hash{}, core.ErrNoSigningKey
+		}
+		if fields.Signature, err = t.signingService.Sign(tag.Body()); err != nil {
+			return domain.Hash{}, err
+		}
+		if tag, err = domain.NewTagFromFields(fields); err != nil {
+			return domain.Hash{}, err
+		}
+	}
+
+	data := tag.Serialize()
+	hash, err := domain.NewHashFromHex(core.ComputeSHA256(data))
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	if err := t.objectService.Write(hash, data); err != nil {
+		return domain.Hash{}, err
+	}
+	return hash, nil
+}
+
+// Delete removes refs/tags/<name> and returns the hash it pointed at. The
+// tag object of an annotated tag is left in the object store.
+func (t *TagService) Delete(name string) (domain.Hash, error) {
+	if err := validateTagName(name); err != nil {
+		return domain.Hash{}, fmt.Errorf("tag: '%s': %w", name, err)
+	}
+	ref := tagRef(name)
+	hash, err := t.refService.Read(ref)
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return domain.Hash{}, fmt.Errorf("tag: '%s': %w", name, ErrTagNotFound)
+		}
+		return domain.Hash{}, fmt.Errorf("tag: %w", err)
+	}
+	if err := t.refService.Delete(ref); err != nil {
+		return domain.Hash{}, fmt.Errorf("tag: failed to delete '%s': %w", name, err)
+	}
+	return hash, nil
+}
+
+// Verify checks the signature of the annotated tag name against the allowed
+// signers file. Like VerifyService.Verify, it returns the verification of a
+// good signature even when its key is not allowed, and an error unless the
+// signature is good and made by an allowed signer.
+func (t *TagService) Verify(name string) (*domain.Tag, *core.SignatureVerification, error) {
+	if err := validateTagName(name); err != nil {
+		return nil, nil, fmt.Errorf("verify-tag: '%s': %w", name, err)
+	}
+	hash, err := t.refService.Read(tagRef(name))
+	if err != nil {
+		if errors.Is(err, core.ErrRefNotFound) {
+			return nil, nil, fmt.Errorf("verify-tag: '%s': %w", name, ErrTagNotFound)
+		}
+		return nil, nil, fmt.Errorf("verify-tag: %w", err)
+	}
+	tag, err := t.objectService.ReadTag(hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectTypeMismatch) {
+			return nil, nil, fmt.Errorf("verify-tag: '%s': %w", name, ErrNotAnnotated)
+		}
+		return nil, nil, fmt.Errorf("verify-tag: %w", err)
+	}
+	if t.signingService == nil {
+		return tag, nil, fmt.Errorf("verify-tag: '%s': %w", name, core.ErrNoAllowedSigners)
+	}
+	verification, err := t.signingService.VerifyTag(tag)
+	if err != nil {
+		return tag, verification, fmt.Errorf("verify-tag: '%s': %w", name, err)
+	}
+	return tag, verification, nil
+}
+
+// tagRef returns the ref of tag name.
+func tagRef(name string) string {
+	return filepath.Join(domain.RefsDirName, domain.TagsDirName, name)
+}
+
+// validateTagName applies tag naming rules, which match those of branches
+// and also keep the name on a single tag header line.
+func validateTagName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("must not be empty: %w", ErrInvalidTagName)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("must not start with '-': %w", ErrInvalidTagName)
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("must not contain '..': %w", ErrInvalidTagName)
+	case strings.HasSuffix(name, "/"):
+		return fmt.Errorf("must not end with '/': %w", ErrInvalidTagName)
+	case strings.ContainsAny(name, " \t\n"):
+		return fmt.Errorf("must not contain whitespace: %w", ErrInvalidTagName)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package tag
+
+import (
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/testutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagTestRepository is a repository with one commit on the default branch.
+type tagTestRepository struct {
+	*testutil.Repository
+	tagService *TagService
+	resolver   *core.CommitResolver
+	commitHash domain.Hash
+}
+
+// newTagTestRepository initializes a repository and commits an empty tree.
+func newTagTestRepository(t *testing.T) *tagTestRepository {
+	t.Helper()
+	repository := testutil.NewRepository(t)
+	commitHash := repository.Commit(t, "first")
+
+	resolver := core.NewCommitResolver(repository.RefService, repository.ObjectService)
+	tagService := NewTagService(
+		repository.RefService, repository.ObjectService, resolver, repository.CommitTree, repository.Workspace,
+	)
+	tagService.SetSigningService(core.NewSigningService(repository.ConfigService, repository.Workspace))
+	return &tagTestRepository{
+		Repository: repository,
+		tagService: tagService,
+		resolver:   resolver,
+		commitHash: commitHash,
+	}
+}
+
+func TestTagCreate(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       CreateOptions
+		wantAnnotated bool
+	}{
+		{name: "lightweight"},
+		{name: "message", options: CreateOptions{Message: "release"}, wantAnnotated: true},
+		{name: "annotate", options: CreateOptions{Annotate: true}, wantAnnotated: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repository := newTagTestRepository(t)
+
+				result, err := repository.tagService.Create("v1.0", "", tt.options)
+
+				require.NoError(t, err)
+				assert.Equal(t, !tt.wantAnnotated, result.Hash == repository.commitHash)
+				if tt.wantAnnotated {
+					tag, err := repository.ObjectService.ReadTag(result.Hash)
+					require.NoError(t, err)
+					assert.Equal(t, repository.commitHash, tag.ObjectHash)
+					assert.Equal(t, "v1.0", tag.Name)
+					assert.Equal(t, "Test", tag.Tagger.Name)
+				}
+				resolved, err := repository.resolver.Resolve("v1.0")
+				require.NoError(t, err)
+				assert.Equal(t, repository.commitHash, resolved)
+				names, err := repository.tagService.List()
+				require.NoError(t, err)
+				assert.Equal(t, []string{"v1.0"}, names)
+			},
+		)
+	}
+}
+
+func TestTagCreateExistingAndDelete(t *testing.T) {
+	repository := newTagTestRepository(t)
+	first, err := repository.tagService.Create("v1.0", "", CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = repository.tagService.Create("v1.0", "", CreateOptions{Message: "again"})
+	require.ErrorIs(t, err, ErrTagAlreadyExists)
+
+	forced, err := repository.tagService.Create("v1.0", "", CreateOptions{Message: "again", Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, first.Hash, forced.OldHash)
+
+	deleted, err := repository.tagService.Delete("v1.0")
+	require.NoError(t, err)
+	assert.Equal(t, forced.Hash, deleted)
+	_, err = repository.tagService.Delete("v1.0")
+	require.ErrorIs(t, err, ErrTagNotFound)
+	_, err = repository.tagService.Create("../heads/main", "", CreateOptions{Force: true})
+	require.ErrorIs(t, err, ErrInvalidTagName)
+}
+
+func TestTagSignAndVerify(t *testing.T) {
+	sshKeygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+	repository := newTagTestRepository(t)
+	keyPath := filepath.Join(repository.Workspace.RepoDir.String(), "id_ed25519")
+	output, err := exec.Command(sshKeygen, "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", keyPath).CombinedOutput()
+	require.NoError(t, err, string(output))
+	publicKey, err := os.ReadFile(keyPath + ".pub")
+	require.NoError(t, err)
+	allowedSignersPath := filepath.Join(repository.Workspace.RepoDir.String(), "allowed_signers")
+	require.NoError(t, os.WriteFile(allowedSignersPath, append([]byte("test@example.com "), publicKey...), 0o600))
+	require.NoError(t, repository.ConfigService.Set(core.ConfigSectionUser, core.ConfigKeySigningKey, keyPath))
+	require.NoError(
+		t, repository.ConfigService.Set(core.ConfigSectionGPG, core.ConfigKeyAllowedSignersFile, allowedSignersPath),
+	)
+
+	_, err = repository.tagService.Create("signed", "", CreateOptions{Message: "release", Sign: true})
+	require.NoError(t, err)
+	_, err = repository.tagService.Create("light", "", CreateOptions{})
+	require.NoError(t, err)
+
+	tag, verification, err := repository.tagService.Verify("signed")
+	require.NoError(t, err)
+	assert.Equal(t, "release\n", tag.Message)
+	assert.Equal(t, "test@example.com", verification.Principal)
+
+	_, _, err = repository.tagService.Verify("light")
+	require.ErrorIs(t, err, ErrNotAnnotated)
+}
@@ -0,0 +1,129 @@
+package testutil
+
+import (
+	"Gel/internal/commit"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"Gel/internal/setup"
+	"Gel/internal/storage"
+	"Gel/internal/tree"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// UserName is the user.name of every test repository.
+	UserName = "Test"
+	// UserEmail is the user.email of every test repository.
+	UserEmail = "test@example.com"
+)
+
+// Repository is a repository with the core services most tests need, wired
+// like the CLI wires them, and an identity to commit with.
+type Repository struct {
+	Workspace      *domain.Workspace
+	ObjectService  *core.ObjectService
+	IndexService   *core.IndexService
+	ConfigService  *core.ConfigService
+	RefService     *core.RefService
+	ChangeDetector *core.ChangeDetector
+	PathResolver   *core.PathResolver
+	TreeResolver   *core.TreeResolver
+	WriteTree      *tree.WriteTreeService
+	CommitTree     *commit.CommitTreeService
+}
+
+// NewRepository initializes a repository in a temporary directory and makes
+// it the working directory of the test.
+func NewRepository(t *testing.T) *Repository {
+	t.Helper()
+	dir := t.TempDir()
+	repository := InitRepository(t, dir, setup.InitOptions{})
+	t.Chdir(dir)
+	return repository
+}
+
+// InitRepository initializes a repository at dir and opens it.
+func InitRepository(t *testing.T, dir string, options setup.InitOptions) *Repository {
+	t.Helper()
+	_, err := setup.NewInitService().Init(dir, options)
+	require.NoError(t, err)
+	return OpenRepository(t, dir)
+}
+
+// OpenRepository wires the services of the existing repository at dir and
+// sets user.name and user.email.
+func OpenRepository(t *testing.T, dir string) *Repository {
+	t.Helper()
+	workspace, err := domain.NewWorkspace(dir)
+	require.NoError(t, err)
+	objectService := core.NewObjectService(storage.NewObjectStorage(workspace))
+	indexService := core.NewIndexService(storage.NewIndexStorage(workspace))
+	configService := core.NewConfigService(storage.NewConfigStorage(workspace))
+	require.NoError(t, configService.Set(core.ConfigSectionUser, core.ConfigKeyName, UserName))
+	require.NoError(t, configService.Set(core.ConfigSectionUser, core.ConfigKeyEmail, UserEmail))
+	indexService.SetConfigService(configService)
+	changeDetector := core.NewChangeDetector(objectService, workspace.RepoDir)
+	indexService.SetChangeDetector(changeDetector)
+	refService := core.NewRefService(workspace)
+	pathResolver := core.NewPathResolver(workspace.RepoDir, core.NewIgnoreMatcher(workspace, configService))
+	return &Repository{
+		Workspace:      workspace,
+		ObjectService:  objectService,
+		IndexService:   indexService,
+		ConfigService:  configService,
+		RefService:     refService,
+		ChangeDetector: changeDetector,
+		PathResolver:   pathResolver,
+		TreeResolver: core.NewTreeResolver(
+			objectService, indexService, refService, pathResolver, changeDetector, configService, workspace,
+		),
+		WriteTree:  tree.NewWriteTreeService(indexService, objectService),
+		CommitTree: commit.NewCommitTreeService(objectService, configService),
+	}
+}
+
+// Commit records the index as a commit with message on parents and moves the
+// default branch to it.
+func (r *Repository) Commit(t *testing.T, message string, parents ...domain.Hash) domain.Hash {
+	t.Helper()
+	treeHash, err := r.WriteTree.WriteTree()
+	require.NoError(t, err)
+	commitHash, err := r.CommitTree.CommitTree(treeHash, message, parents)
+	require.NoError(t, err)
+	require.NoError(t, r.RefService.Write(domain.DefaultBranchRef, commitHash))
+	return commitHash
+}
+
+// WriteFile writes content to path in the working tree.
+func (r *Repository) WriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	absolutePath := filepath.Join(r.Workspace.RepoDir.String(), path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(absolutePath), domain.DefaultDirPermission))
+	require.NoError(t, os.WriteFile(absolutePath, []byte(content), domain.DefaultFilePermission))
+}
+
+// ReadFile returns the working tree content of path.
+func (r *Repository) ReadFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(r.Workspace.RepoDir.String(), path))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// StagedContent returns the content of the index entry for path.
+func (r *Repository) StagedContent(t *testing.T, path string) string {
+	t.Helper()
+	index, err := r.IndexService.Read()
+	require.NoError(t, err)
+	normalizedPath, err := domain.ParseNormalizedPath(path)
+	require.NoError(t, err)
+	entry, _ := index.FindEntry(normalizedPath)
+	require.NotNil(t, entry, "no index entry for %s", path)
+	blob, err := r.ObjectService.ReadBlob(entry.Hash)
+	require.NoError(t, err)
+	return string(blob.Body())
+}
@@ -9,26 +9,35 @@ import (
 
 // ReadTreeService replaces index contents from a tree object.
 type ReadTreeService struct {
-	indexService  *core.IndexService
-	objectService *core.ObjectService
+	indexService   *core.IndexService
+	objectService  *core.ObjectService
+	sparseCheckout *core.SparseCheckout
 }
 
 // NewReadTreeService creates a read-tree service.
 func NewReadTreeService(
 	indexService *core.IndexService,
 	objectService *core.ObjectService,
+	sparseCheckout *core.SparseCheckout,
 ) *ReadTreeService {
 	return &ReadTreeService{
-		indexService:  indexService,
-		objectService: objectService,
+		indexService:   indexService,
+		objectService:  objectService,
+		sparseCheckout: sparseCheckout,
 	}
 }
 
 // ReadTree reads all blob entries from the given tree hash and rewrites index.
 //
 // Existing index entries are discarded. New entries are created with tree mode
-// and hash data; filesystem stat fields are set to zero values.
+// and hash data; filesystem stat fields are set to zero values. Entries outside
+// the sparse checkout cone get the skip-worktree bit.
 func (r *ReadTreeService) ReadTree(hash domain.Hash) error {
+	cone, err := r.sparseCheckout.Cone()
+	if err != nil {
+		return fmt.Errorf("read-tree: %w", err)
+	}
+
 	var indexEntries []*domain.IndexEntry
 	processor := func(entry domain.TreeEntry, path string) error {
 		normPath, err := domain.ParseNormalizedPath(path)
@@ -49,6 +58,9 @@ func (r *ReadTreeService) ReadTree(hash domain.Hash) error {
 			time.Time{},
 			time.Time{},
 		)
+		if cone != nil && !cone.Includes(normPath) {
+			indexEntry.SetSkipWorktree(true)
+		}
 		indexEntries = append(indexEntries, indexEntry)
 		return nil
 	}
@@ -58,8 +70,7 @@ func (r *ReadTreeService) ReadTree(hash domain.Hash) error {
 		OnlyTrees:    false,
 	}
 	treeWalker := core.NewTreeWalker(r.objectService, options)
-	err := treeWalker.Walk(hash, "", processor)
-	if err != nil {
+	if err := treeWalker.Walk(hash, "", processor); err != nil {
 		return fmt.Errorf("read-tree: %w", err)
 	}
 
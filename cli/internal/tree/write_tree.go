@@ -37,14 +37,35 @@ func NewWriteTreeService(indexService *core.IndexService, objectService *core.Ob
 
 // WriteTree converts all current index entries into a root tree object.
 // It returns the root tree hash and writes any missing tree objects to storage.
+//
+// Directories whose cache-tree node is still valid reuse the cached hash
+// without being re-serialized. The refreshed cache is saved back to the index.
+// Intent-to-add entries are left out: their content is not staged yet.
 func (w *WriteTreeService) WriteTree() (domain.Hash, error) {
-	entries, err := w.indexService.GetEntries()
+	index, err := w.indexService.Read()
 	if err != nil {
 		return domain.Hash{}, err
 	}
+	if index.CacheTree.Valid() {
+		return index.CacheTree.Hash, nil
+	}
+
+	rootHash, cacheTree, err := w.writeTreeRecursive(buildRootTree(index.Entries), index.CacheTree)
+	if err != nil {
+		return domain.Hash{}, err
+	}
+	index.CacheTree = cacheTree
+	if err := w.indexService.Write(index); err != nil {
+		return domain.Hash{}, err
+	}
+	return rootHash, nil
+}
 
+// WriteTreeFromEntries converts entries into a root tree object without reading the index.
+// It is used by operations that build snapshots outside the staging area, such as merges.
+func (w *WriteTreeService) WriteTreeFromEntries(entries []*domain.IndexEntry) (domain.Hash, error) {
 	root := buildRootTree(entries)
-	rootHash, err := w.writeTreeRecursive(root)
+	rootHash, _, err := w.writeTreeRecursive(root, nil)
 	if err != nil {
 		return domain.Hash{}, err
 	}
@@ -53,14 +74,26 @@ func (w *WriteTreeService) WriteTree() (domain.Hash, error) {
 
 // writeTreeRecursive materializes tree objects for a directory subtree.
 // Child trees are written first so parent tree entries can reference their hashes.
-func (w *WriteTreeService) writeTreeRecursive(root *directoryNode) (domain.Hash, error) {
-	var entries []domain.TreeEntry
+// Subtrees with a valid node in cached are taken from the cache. It returns the
+// tree hash together with the up-to-date cache for the subtree.
+func (w *WriteTreeService) writeTreeRecursive(
+	root *directoryNode,
+	cached *domain.CacheTree,
+) (domain.Hash, *domain.CacheTree, error) {
+	if cached.Valid() {
+		return cached.Hash, cached, nil
+	}
 
+	var entries []domain.TreeEntry
+	cacheTree := domain.NewCacheTree()
+	entryCount := len(root.files)
 	for _, childDir := range root.children {
-		subTreeHash, err := w.writeTreeRecursive(childDir)
+		subTreeHash, childCache, err := w.writeTreeRecursive(childDir, cached.Child(childDir.name))
 		if err != nil {
-			return domain.Hash{}, err
+			return domain.Hash{}, nil, err
 		}
+		cacheTree.Children[childDir.name] = childCache
+		entryCount += childCache.EntryCount
 
 		entry := domain.NewTreeEntry(domain.FileModeDirectory, subTreeHash, childDir.name)
 		entries = append(entries, entry)
@@ -70,6 +103,18 @@ func (w *WriteTreeService) writeTreeRecursive(root *directoryNode) (domain.Hash,
 		entries = append(entries, entry)
 	}
 
+	hash, err := w.writeTreeObject(entries)
+	if err != nil {
+		return domain.Hash{}, nil, err
+	}
+	cacheTree.Hash = hash
+	cacheTree.EntryCount = entryCount
+	return hash, cacheTree, nil
+}
+
+// writeTreeObject sorts entries into a tree object and stores it unless it already exists.
+func (w *WriteTreeService) writeTreeObject(entries []domain.TreeEntry) (domain.Hash, error) {
+
 	sortTreeEntries(entries)
 
 	tree, err := domain.NewTreeFromEntries(entries)
@@ -100,6 +145,7 @@ func (w *WriteTreeService) writeTreeRecursive(root *directoryNode) (domain.Hash,
 }
 
 // buildRootTree groups flat index entries into an in-memory directory tree.
+// Intent-to-add entries have no content yet and are left out.
 func buildRootTree(entries []*domain.IndexEntry) *directoryNode {
 	root := &directoryNode{
 		name:     "",
@@ -108,6 +154,9 @@ func buildRootTree(entries []*domain.IndexEntry) *directoryNode {
 	}
 
 	for _, entry := range entries {
+		if entry.IntentToAdd() {
+			continue
+		}
 		parentDir := root
 		names := strings.Split(entry.Path.String(), "/")
 
@@ -30,13 +30,14 @@ func PathMustBeDirectory(path string) error {
 	return nil
 }
 
-// PathMustBeFile returns an error unless path exists and is a regular file.
+// PathMustBeFile returns an error unless path exists and is a regular file or a symlink.
+// Symlinks are not followed, so a link to a directory or a dangling link is accepted.
 func PathMustBeFile(path string) error {
-	info, err := os.Stat(path)
+	info, err := os.Lstat(path)
 	if err != nil {
 		return err
 	}
-	if !info.Mode().IsRegular() {
+	if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
 		return fmt.Errorf("path %s is not a file", path)
 	}
 	return nil
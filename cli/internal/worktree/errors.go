@@ -0,0 +1,20 @@
+package worktree
+
+import "errors"
+
+var (
+	// ErrWorktreeNotFound is returned when a path names no linked worktree.
+	ErrWorktreeNotFound = errors.New("is not a working tree")
+
+	// ErrMainWorktree is returned when an operation only applies to linked worktrees.
+	ErrMainWorktree = errors.New("is a main working tree")
+
+	// ErrCurrentWorktree is returned when removing the worktree the command runs in.
+	ErrCurrentWorktree = errors.New("is the current working tree")
+
+	// ErrDestinationExists is returned when add targets a non-empty existing path.
+	ErrDestinationExists = errors.New("already exists")
+
+	// ErrDirtyWorktree is returned when removing a worktree with changes without --force.
+	ErrDirtyWorktree = errors.New("contains modified or untracked files, use --force to delete it")
+)
@@ -0,0 +1,315 @@
+package worktree
+
+import (
+	"Gel/internal/branch"
+	"Gel/internal/core"
+	"Gel/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// AddOptions controls worktree creation.
+type AddOptions struct {
+	// NewBranch creates a branch of this name at the start point and checks it out.
+	NewBranch string
+	// Force checks out a branch even when another worktree has it checked out.
+	Force bool
+}
+
+// AddResult describes a worktree created by Add. Its files are not checked
+// out yet: callers populate the working tree and index of Workspace.
+type AddResult struct {
+	// Workspace is the layout of the new worktree.
+	Workspace *domain.Workspace
+	// Branch is the branch checked out in the new worktree.
+	Branch string
+	// Created reports whether Branch was created for the worktree.
+	Created bool
+	// CommitHash is the commit Branch points at.
+	CommitHash domain.Hash
+}
+
+// WorktreeService manages working trees linked to the repository. Each
+// linked worktree has its own HEAD and index below .gel/worktrees/<name>
+// and shares objects, refs and config with the main worktree.
+type WorktreeService struct {
+	refService    *core.RefService
+	branchService *branch.BranchService
+	worktrees     *core.WorktreeRegistry
+	workspace     *domain.Workspace
+}
+
+// NewWorktreeService creates a worktree service.
+func NewWorktreeService(
+	refService *core.RefService,
+	branchService *branch.BranchService,
+	worktrees *core.WorktreeRegistry,
+	workspace *domain.Workspace,
+) *WorktreeService {
+	return &WorktreeService{
+		refService:    refService,
+		branchService: branchService,
+		worktrees:     worktrees,
+		workspace:     workspace,
+	}
+}
+
+// Add creates a linked worktree at path with HEAD on a branch. With
+// options.NewBranch, that branch is created at startPoint (HEAD when empty).
+// Otherwise startPoint names an existing branch; when it is empty too, the
+// branch named after the last path component is checked out, created from
+// HEAD if needed. A branch checked out in another worktree is refused unless
+// options.Force is set. On failure nothing is left behind.
+func (w *WorktreeService) Add(path, startPoint string, options AddOptions) (_ *AddResult, err error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	if err := checkDestination(absPath); err != nil {
+		return nil, err
+	}
+
+	branchName, created, err := w.resolveBranch(absPath, startPoint, options)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil && created {
+			_ = w.branchService.Delete(branchName)
+		}
+	}()
+	branchRef := filepath.Join(domain.RefsDirName, domain.HeadsDirName, branchName)
+	commitHash, err := w.refService.Read(branchRef)
+	if err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+
+	gelDir, err := w.createMetadata(absPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(gelDir)
+		}
+	}()
+
+	createdDir := false
+	if _, statErr := os.Stat(absPath); errors.Is(statErr, os.ErrNotExist) {
+		createdDir = true
+	}
+	if err := os.MkdirAll(absPath, domain.DefaultDirPermission); err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	gelFile := filepath.Join(absPath, domain.GelDirName)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if createdDir {
+			_ = os.RemoveAll(absPath)
+		} else {
+			_ = os.Remove(gelFile)
+		}
+	}()
+	if err := os.WriteFile(
+		gelFile, []byte(domain.GelDirFilePrefix+gelDir+"\n"), domain.DefaultFilePermission,
+	); err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+
+	workspace, err := domain.NewWorkspace(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	if err := core.NewRefService(workspace).WriteSymbolic(domain.HeadFileName, branchRef); err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	return &AddResult{
+		Workspace:  workspace,
+		Branch:     branchName,
+		Created:    created,
+		CommitHash: commitHash,
+	}, nil
+}
+
+// List returns the main worktree followed by the linked worktrees.
+func (w *WorktreeService) List() ([]core.Worktree, error) {
+	return w.worktrees.List()
+}
+
+// Find returns the linked worktree whose directory is path or whose name is path.
+func (w *WorktreeService) Find(path string) (*core.Worktree, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+	worktrees, err := w.worktrees.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, worktree := range worktrees {
+		if worktree.Dir != absPath && (worktree.Name == "" || worktree.Name != path) {
+			continue
+		}
+		if worktree.Name == "" {
+			return nil, fmt.Errorf("worktree: '%s' %w", path, ErrMainWorktree)
+		}
+		return &worktree, nil
+	}
+	return nil, fmt.Errorf("worktree: '%s' %w", path, ErrWorktreeNotFound)
+}
+
+// Remove deletes a linked worktree's directory and metadata. Callers check
+// that the worktree has no changes worth keeping first.
+func (w *WorktreeService) Remove(worktree *core.Worktree) error {
+	if worktree.Name == "" {
+		return fmt.Errorf("worktree: '%s' %w", worktree.Dir, ErrMainWorktree)
+	}
+	if worktree.Current {
+		return fmt.Errorf("worktree: '%s' %w", worktree.Dir, ErrCurrentWorktree)
+	}
+	if worktree.Dir != "" {
+		if err := os.RemoveAll(worktree.Dir); err != nil {
+			return fmt.Errorf("worktree: %w", err)
+		}
+	}
+	if err := os.RemoveAll(worktree.GelDir); err != nil {
+		return fmt.Errorf("worktree: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the metadata of linked worktrees whose directory is gone and
+// returns them. With dryRun, it only reports them.
+func (w *WorktreeService) Prune(dryRun bool) ([]core.Worktree, error) {
+	worktrees, err := w.worktrees.List()
+	if err != nil {
+		return nil, err
+	}
+	var pruned []core.Worktree
+	for _, worktree := range worktrees {
+		if !worktree.Prunable {
+			continue
+		}
+		pruned = append(pruned, worktree)
+		if dryRun {
+			continue
+		}
+		if err := os.RemoveAll(worktree.GelDir); err != nil {
+			return nil, fmt.Errorf("worktree: %w", err)
+		}
+	}
+	if !dryRun {
+		// Leave no empty worktrees directory behind; ignore it still being in use.
+		_ = os.Remove(w.workspace.WorktreesDir.String())
+	}
+	return pruned, nil
+}
+
+// resolveBranch picks, and creates if asked to, the branch the new worktree
+// checks out. It reports whether the branch was created.
+func (w *WorktreeService) resolveBranch(absPath, startPoint string, options AddOptions) (string, bool, error) {
+	if options.NewBranch != "" {
+		if err := w.branchService.Create(options.NewBranch, startPoint); err != nil {
+			return "", false, fmt.Errorf("worktree: %w", err)
+		}
+		return options.NewBranch, true, nil
+	}
+
+	name := startPoint
+	if name == "" {
+		name = filepath.Base(absPath)
+		exists, err := w.branchService.Exists(name)
+		if err != nil {
+			return "", false, fmt.Errorf("worktree: %w", err)
+		}
+		if !exists {
+			if err := w.branchService.Create(name, ""); err != nil {
+				return "", false, fmt.Errorf("worktree: %w", err)
+			}
+			return name, true, nil
+		}
+	} else if exists, err := w.branchService.Exists(name); err != nil {
+		return "", false, fmt.Errorf("worktree: %w", err)
+	} else if !exists {
+		return "", false, fmt.Errorf("worktree: '%s': %w", name, branch.ErrBranchNotFound)
+	}
+
+	if options.Force {
+		return name, false, nil
+	}
+	currentRef, err := w.refService.ReadSymbolic(domain.HeadFileName)
+	if err != nil && !errors.Is(err, core.ErrRefNotFound) {
+		return "", false, fmt.Errorf("worktree: %w", err)
+	}
+	if currentRef == filepath.Join(domain.RefsDirName, domain.HeadsDirName, name) && !w.workspace.Bare {
+		return "", false, fmt.Errorf(
+			"worktree: '%s': %w at '%s'", name, branch.ErrBranchCheckedOut, w.workspace.RepoDir,
+		)
+	}
+	if err := w.branchService.CheckNotCheckedOutElsewhere(name); err != nil {
+		return "", false, fmt.Errorf("worktree: %w", err)
+	}
+	return name, false, nil
+}
+
+// createMetadata creates the metadata directory of a worktree at absPath,
+// named after its last path component and made unique with a numeric
+// suffix, and returns its path.
+func (w *WorktreeService) createMetadata(absPath string) (string, error) {
+	worktreesDir := w.workspace.WorktreesDir.String()
+	if err := os.MkdirAll(worktreesDir, domain.DefaultDirPermission); err != nil {
+		return "", fmt.Errorf("worktree: %w", err)
+	}
+
+	base := filepath.Base(absPath)
+	gelDir := ""
+	for i := 0; ; i++ {
+		name := base
+		if i > 0 {
+			name += strconv.Itoa(i)
+		}
+		gelDir = filepath.Join(worktreesDir, name)
+		err := os.Mkdir(gelDir, domain.DefaultDirPermission)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("worktree: %w", err)
+		}
+	}
+
+	commonDir, err := filepath.Rel(gelDir, w.workspace.CommonDir.String())
+	if err != nil {
+		commonDir = w.workspace.CommonDir.String()
+	}
+	files := map[string]string{
+		domain.CommonDirFileName: commonDir,
+		domain.GelDirFileName:    filepath.Join(absPath, domain.GelDirName),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(
+			filepath.Join(gelDir, name), []byte(content+"\n"), domain.DefaultFilePermission,
+		); err != nil {
+			_ = os.RemoveAll(gelDir)
+			return "", fmt.Errorf("worktree: %w", err)
+		}
+	}
+	return gelDir, nil
+}
+
+// checkDestination accepts a missing path or an empty directory.
+func checkDestination(absPath string) error {
+	dirEntries, err := os.ReadDir(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || len(dirEntries) > 0 {
+		return fmt.Errorf("worktree: '%s' %w", absPath, ErrDestinationExists)
+	}
+	return nil
+}